@@ -76,6 +76,9 @@ var DefaultConfig = func() *commonConfig.KwildConfig {
 			DBName:               "kwild",
 			RPCTimeout:           commonConfig.Duration(45 * time.Second),
 			RPCMaxReqSize:        4_200_000,
+			RPCMaxCursors:        8,
+			RPCMaxCursorsPerIP:   2,
+			RPCCursorTimeout:     commonConfig.Duration(30 * time.Second),
 			ChallengeExpiry:      commonConfig.Duration(10 * time.Second),
 			ChallengeRateLimit:   10.0, // req/s
 			ReadTxTimeout:        commonConfig.Duration(5 * time.Second),
@@ -85,6 +88,10 @@ var DefaultConfig = func() *commonConfig.KwildConfig {
 				RecurringHeight: 14400, // 1 day at 6s block time
 				MaxSnapshots:    3,
 			},
+			CDC: commonConfig.CDCConfig{
+				Enable:       false,
+				RetainBlocks: 100_000,
+			},
 			GenesisState: "",
 		},
 		Logging: &commonConfig.Logging{
@@ -135,6 +142,10 @@ var DefaultConfig = func() *commonConfig.KwildConfig {
 				TimeoutPrecommit: commonConfig.Duration(2 * time.Second),
 				TimeoutCommit:    commonConfig.Duration(6 * time.Second),
 			},
+			RemoteSigner: &commonConfig.RemoteSignerConfig{
+				Timeout:     commonConfig.Duration(5 * time.Second),
+				ConnTimeout: commonConfig.Duration(time.Minute),
+			},
 		},
 	}
 }
@@ -0,0 +1,24 @@
+package resolutions
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+)
+
+var resolutionsCmd = &cobra.Command{
+	Use:   "resolutions",
+	Short: "The `resolutions` command provides functions for browsing, inspecting and proposing resolutions.",
+	Long:  "The `resolutions` command provides functions for browsing, inspecting and proposing resolutions, which are the events that validators vote on, such as join requests, migration proposals and WebAssembly module deployments.",
+}
+
+func NewResolutionsCmd() *cobra.Command {
+	resolutionsCmd.AddCommand(
+		listCmd(),
+		showCmd(),
+		proposeWasmCmd(),
+	)
+
+	common.BindRPCFlags(resolutionsCmd)
+	return resolutionsCmd
+}
@@ -0,0 +1,116 @@
+package resolutions
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listLong = `List pending resolutions, and approved and expired resolutions, with their votes and the power required to approve them.
+
+Results are paginated. If there are more resolutions, the offset of the next page is shown, which may be given with the ` + "`--offset`" + ` flag.`
+
+	listExample = `# List all pending resolutions
+kwil-admin resolutions list --status pending
+
+# List the validator join requests that expire between heights 1000 and 2000
+kwil-admin resolutions list --type validator_join --min-expiration 1000 --max-expiration 2000
+
+# List the resolutions proposed by a validator, by hex public key
+kwil-admin resolutions list --proposer 6ecaca8e9394c939a858c2c7b47acb1db26a96d7ab38bd702fa3820c5034e9d0`
+)
+
+func listCmd() *cobra.Command {
+	var filter types.ResolutionFilter
+	var proposer string
+	var offset, limit int64
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List resolutions and their votes.",
+		Long:    listLong,
+		Example: listExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if proposer != "" {
+				var err error
+				filter.Proposer, err = hex.DecodeString(proposer)
+				if err != nil {
+					return display.PrintErr(cmd, fmt.Errorf("invalid proposer: %w", err))
+				}
+			}
+
+			clt, err := common.GetAdminSvcClient(ctx, cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			res, nextOffset, err := clt.ListResolutions(ctx, &filter, offset, limit)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, &respResolutions{Data: res, NextOffset: nextOffset})
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.Type, "type", "", "only list resolutions of this type")
+	cmd.Flags().StringVar(&proposer, "proposer", "", "only list resolutions proposed by this hex public key")
+	cmd.Flags().StringVar(&filter.Status, "status", "", "only list resolutions with this status (pending, approved, or expired)")
+	cmd.Flags().Int64Var(&filter.MinExpiration, "min-expiration", 0, "only list resolutions expiring at or after this height")
+	cmd.Flags().Int64Var(&filter.MaxExpiration, "max-expiration", 0, "only list resolutions expiring at or before this height")
+	cmd.Flags().Int64Var(&offset, "offset", 0, "number of resolutions to skip")
+	cmd.Flags().Int64Var(&limit, "limit", 0, "maximum number of resolutions to list (the node's default if 0)")
+
+	return cmd
+}
+
+// respResolutions represents a page of resolutions in cli
+type respResolutions struct {
+	Data       []*types.ResolutionInfo
+	NextOffset int64
+}
+
+func (r *respResolutions) MarshalJSON() ([]byte, error) {
+	data := r.Data
+	if data == nil {
+		data = []*types.ResolutionInfo{}
+	}
+	return json.Marshal(struct {
+		Resolutions []*types.ResolutionInfo `json:"resolutions"`
+		NextOffset  int64                   `json:"next_offset,omitempty"`
+	}{data, r.NextOffset})
+}
+
+func (r *respResolutions) MarshalText() ([]byte, error) {
+	if len(r.Data) == 0 {
+		return []byte("No resolutions found."), nil
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("Resolutions:\n")
+	for i, res := range r.Data {
+		msg.WriteString(fmt.Sprintf("% 3d. %s %s (%s): expires at %d, %d votes, power %d of %d required",
+			i, res.ID, res.Type, res.Status, res.ExpiresAt, res.Votes, res.ApprovedPower, res.RequiredPower))
+		if res.ClosedHeight > 0 {
+			msg.WriteString(fmt.Sprintf(", closed at %d", res.ClosedHeight))
+		}
+		if i != len(r.Data)-1 {
+			msg.WriteString("\n")
+		}
+	}
+	if r.NextOffset > 0 {
+		msg.WriteString(fmt.Sprintf("\nMore resolutions from offset %d", r.NextOffset))
+	}
+
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package resolutions
+
+import (
+	"os"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+	"github.com/kwilteam/kwil-db/internal/wasm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proposeWasmLong = `Propose the deployment of a WebAssembly module, which can be used by schemas once it is approved by a super-majority of validators.
+
+The module is checked before it is proposed. Once deployed, a schema uses the module with ` + "`" + `use wasm {module: '<name>'} as <alias>` + "`" + `. Module names are unique, and a deployed module cannot be replaced.`
+
+	proposeWasmExample = `# Propose the deployment of the module in pricing.wasm with the name pricing
+kwil-admin resolutions propose-wasm pricing ./pricing.wasm`
+)
+
+func proposeWasmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "propose-wasm <name> <module_file>",
+		Short:   "Propose the deployment of a WebAssembly module.",
+		Long:    proposeWasmLong,
+		Example: proposeWasmExample,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			bin, err := os.ReadFile(args[1])
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if err = wasm.ValidateModule(args[0], bin); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			body, err := (&wasm.ModuleDeployment{Name: args[0], Module: bin}).MarshalBinary()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			clt, err := common.GetAdminSvcClient(ctx, cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			txHash, err := clt.CreateResolution(ctx, body, wasm.ModuleDeploymentType)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, display.RespTxHash(txHash))
+		},
+	}
+}
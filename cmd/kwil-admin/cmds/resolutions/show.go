@@ -0,0 +1,85 @@
+package resolutions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showLong = `Show a resolution and its votes. For a pending resolution, the body and the validators that approved it are shown. For an approved or expired resolution, its result is shown.`
+
+	showExample = `# Show a resolution
+kwil-admin resolutions show 0f1d8c2a-6e7b-5a3c-9d4e-2b1f0a9c8d7e`
+)
+
+func showCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "show <resolution_id>",
+		Short:   "Show a resolution and its votes.",
+		Long:    showLong,
+		Example: showExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			id, err := types.ParseUUID(args[0])
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			clt, err := common.GetAdminSvcClient(ctx, cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			res, err := clt.Resolution(ctx, id)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, &respResolution{Data: res})
+		},
+	}
+}
+
+// respResolution represents a resolution in cli
+type respResolution struct {
+	Data *types.ResolutionInfo
+}
+
+func (r *respResolution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Data)
+}
+
+func (r *respResolution) MarshalText() ([]byte, error) {
+	res := r.Data
+
+	var msg bytes.Buffer
+	msg.WriteString("Resolution:\n")
+	msg.WriteString(fmt.Sprintf("\tID: %s\n", res.ID))
+	msg.WriteString(fmt.Sprintf("\tType: %s\n", res.Type))
+	msg.WriteString(fmt.Sprintf("\tStatus: %s\n", res.Status))
+	if len(res.Proposer) > 0 {
+		msg.WriteString(fmt.Sprintf("\tProposer: %x\n", []byte(res.Proposer)))
+	}
+	msg.WriteString(fmt.Sprintf("\tExpires At: %d\n", res.ExpiresAt))
+	if res.ClosedHeight > 0 {
+		msg.WriteString(fmt.Sprintf("\tClosed At: %d\n", res.ClosedHeight))
+	}
+	msg.WriteString(fmt.Sprintf("\tVotes: %d\n", res.Votes))
+	msg.WriteString(fmt.Sprintf("\tApproved Power: %d (required %d)", res.ApprovedPower, res.RequiredPower))
+	for _, voter := range res.Voters {
+		msg.WriteString(fmt.Sprintf("\n\t\tApproved by %x", []byte(voter)))
+	}
+	if len(res.Body) > 0 {
+		msg.WriteString(fmt.Sprintf("\n\tBody: %x", []byte(res.Body)))
+	}
+
+	return msg.Bytes(), nil
+}
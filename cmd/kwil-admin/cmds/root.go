@@ -9,6 +9,7 @@ import (
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/key"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/migration"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/node"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/resolutions"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/setup"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/snapshot"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/utils"
@@ -36,6 +37,7 @@ func NewRootCmd() *cobra.Command {
 		snapshot.NewSnapshotCmd(),
 		whitelist.WhitelistCmd(),
 		migration.NewMigrationCmd(),
+		resolutions.NewResolutionsCmd(),
 	)
 
 	display.BindOutputFormatFlag(rootCmd)
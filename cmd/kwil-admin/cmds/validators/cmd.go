@@ -6,7 +6,7 @@ import (
 )
 
 const validatorsShort = "The `validators` command provides functions for creating and broadcasting validator-related transactions."
-const validatorsLong = "The `validators` command provides functions for creating and broadcasting validator-related transactions (join/approve/leave), and retrieving information on the current validators and join requests."
+const validatorsLong = "The `validators` command provides functions for creating and broadcasting validator-related transactions (join/approve/leave/unjail), and retrieving information on the current validators and join requests."
 
 var validatorsCmd = &cobra.Command{
 	Use:   "validators",
@@ -22,6 +22,8 @@ func NewValidatorsCmd() *cobra.Command {
 		approveCmd(),
 		removeCmd(),
 		leaveCmd(),
+		unjailCmd(),
+		evidenceCmd(),
 		listJoinRequestsCmd(),
 	)
 
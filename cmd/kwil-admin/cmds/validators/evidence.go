@@ -0,0 +1,89 @@
+package validators
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	evidenceLong = `List the validator misbehavior, such as double signing, that was reported by the consensus engine, and the punishment applied to each validator.`
+
+	evidenceExample = `# List all recorded validator misbehavior
+kwil-admin validators evidence
+
+# List the misbehavior of a validator, by hex public key
+kwil-admin validators evidence 6ecaca8e9394c939a858c2c7b47acb1db26a96d7ab38bd702fa3820c5034e9d0`
+)
+
+func evidenceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "evidence [validator]",
+		Short:   "List recorded validator misbehavior and punishments.",
+		Long:    evidenceLong,
+		Example: evidenceExample,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			clt, err := common.GetAdminSvcClient(ctx, cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			var pubkey []byte
+			if len(args) == 1 {
+				pubkey, err = hex.DecodeString(args[0])
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+
+			data, err := clt.ListEvidence(ctx, pubkey)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, &respEvidence{Data: data})
+		},
+	}
+
+	return cmd
+}
+
+// respEvidence represents recorded validator misbehavior in cli
+type respEvidence struct {
+	Data []*types.ValidatorEvidence
+}
+
+func (r *respEvidence) MarshalJSON() ([]byte, error) {
+	if r.Data == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r.Data)
+}
+
+func (r *respEvidence) MarshalText() ([]byte, error) {
+	if len(r.Data) == 0 {
+		return []byte("No validator misbehavior recorded."), nil
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("Validator misbehavior:\n")
+	for i, ev := range r.Data {
+		msg.WriteString(fmt.Sprintf("% 3d. validator %x: %s at height %d, punished at height %d, power %d -> %d, slashed %s",
+			i, []byte(ev.Validator), ev.Type, ev.Height, ev.CommitHeight, ev.PowerBefore, ev.PowerAfter, ev.Slashed))
+		if i != len(r.Data)-1 {
+			msg.WriteString("\n")
+		}
+	}
+
+	return msg.Bytes(), nil
+}
@@ -2,6 +2,8 @@ package validators
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 
 	"github.com/kwilteam/kwil-db/cmd/common/display"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
@@ -9,13 +11,20 @@ import (
 )
 
 var (
-	joinLong = "A node may request to join the validator set by submitting a join request using the `join` command. The key used to sign the join request will be the treated as the node request to join the validator set. The node will be added to the validator set if the join request is approved by the current validator set. The status of a join request can be queried using the `join-status` command."
+	joinLong = "A node may request to join the validator set by submitting a join request using the `join` command. The key used to sign the join request will be the treated as the node request to join the validator set. The node will be added to the validator set if the join request is approved by the current validator set. The status of a join request can be queried using the `join-status` command." + `
+
+On networks with staking enabled, the node's validator power is determined by its stake. The ` + "`--stake`" + ` flag stakes the given amount from the node's account before requesting to join.`
 
 	joinExample = `# Request to join the validator set
-kwil-admin validators join`
+kwil-admin validators join
+
+# Stake 1000 tokens and request to join the validator set
+kwil-admin validators join --stake 1000`
 )
 
 func joinCmd() *cobra.Command {
+	var stake string
+
 	cmd := &cobra.Command{
 		Use:     "join",
 		Short:   "A node may request to join the validator set by submitting a join request using the `join` command.",
@@ -25,12 +34,21 @@ func joinCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			var stakeAmt *big.Int
+			if stake != "" {
+				var ok bool
+				stakeAmt, ok = new(big.Int).SetString(stake, 10)
+				if !ok || stakeAmt.Sign() <= 0 {
+					return display.PrintErr(cmd, fmt.Errorf("invalid stake amount: %s", stake))
+				}
+			}
+
 			clt, err := common.GetAdminSvcClient(ctx, cmd)
 			if err != nil {
 				return display.PrintErr(cmd, err)
 			}
 
-			txHash, err := clt.Join(ctx)
+			txHash, err := clt.JoinWithStake(ctx, stakeAmt)
 			if err != nil {
 				return display.PrintErr(cmd, err)
 			}
@@ -39,5 +57,7 @@ func joinCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&stake, "stake", "", "amount to stake before requesting to join, on networks with staking enabled")
+
 	return cmd
 }
@@ -53,8 +53,9 @@ type respValSets struct {
 }
 
 type valInfo struct {
-	PubKey string `json:"pubkey"`
-	Power  int64  `json:"power"`
+	PubKey string                 `json:"pubkey"`
+	Power  int64                  `json:"power"`
+	Uptime *types.ValidatorUptime `json:"uptime,omitempty"`
 }
 
 func (r *respValSets) MarshalJSON() ([]byte, error) {
@@ -63,6 +64,7 @@ func (r *respValSets) MarshalJSON() ([]byte, error) {
 		valInfos[i] = valInfo{
 			PubKey: fmt.Sprintf("%x", v.PubKey),
 			Power:  v.Power,
+			Uptime: v.Uptime,
 		}
 	}
 
@@ -74,6 +76,12 @@ func (r *respValSets) MarshalText() ([]byte, error) {
 	msg.WriteString("Current validator set:\n")
 	for i, v := range r.Data {
 		msg.WriteString(fmt.Sprintf("% 3d. %s", i, v))
+		if u := v.Uptime; u != nil {
+			msg.WriteString(fmt.Sprintf(" missed %d of the last %d blocks", u.MissedBlocks, u.Window))
+			if u.Jailed {
+				msg.WriteString(fmt.Sprintf(", jailed at height %d", u.JailedHeight))
+			}
+		}
 		if i != len(r.Data)-1 {
 			msg.WriteString("\n")
 		}
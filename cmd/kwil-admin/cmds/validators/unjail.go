@@ -0,0 +1,43 @@
+package validators
+
+import (
+	"context"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unjailLong = "A validator that was jailed for missing too many blocks may restore its power using the `unjail` command, once its jail period has passed."
+
+	unjailExample = `# Unjail the validator
+kwil-admin validators unjail`
+)
+
+func unjailCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "unjail",
+		Short:   "A jailed validator may restore its power using the `unjail` command.",
+		Long:    unjailLong,
+		Example: unjailExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			clt, err := common.GetAdminSvcClient(ctx, cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			txHash, err := clt.Unjail(ctx)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, display.RespTxHash(txHash))
+		},
+	}
+
+	return cmd
+}
@@ -125,6 +125,18 @@ from = "{{ .MigrationConfig.MigrateFrom }}"
 # Node's Private key
 private_key_path = "{{ .AppConfig.PrivateKeyPath }}"
 
+# The path to a file containing the hex encoded secp256k1 private key that the
+# node uses to attest to withdrawal epochs as a validator.
+# It may be either an absolute path or a path related to the kwild root directory.
+# If unset, the node does not attest to withdrawal epochs, unless
+# derive_attester_key is true.
+attester_key_path = "{{ .AppConfig.AttesterKeyPath }}"
+
+# Derive the withdrawal attestation key from the node's private key if
+# attester_key_path is unset. Anything that holds the node key, such as a
+# remote signer, can then sign attestations.
+derive_attester_key = {{ .AppConfig.DeriveAttesterKey }}
+
 # TCP address for the KWILD App's JSON-RPC server to listen on
 jsonrpc_listen_addr = "{{ .AppConfig.JSONRPCListenAddress }}"
 
@@ -142,11 +154,30 @@ db_read_timeout = "{{ .AppConfig.ReadTxTimeout }}"
 # RPC request size limit in bytes
 rpc_max_req_size = {{ .AppConfig.RPCMaxReqSize }}
 
+# The maximum number of rows returned by a user.query or user.call request,
+# and the maximum page size for user.query_page. Larger query results must be
+# fetched in pages with user.query_page. Zero is unlimited.
+rpc_max_rows = {{ .AppConfig.RPCMaxRows }}
+
+# The maximum number of query cursors opened by user.query_page, in total and
+# for each client IP address (zero is unlimited). Each open cursor holds a
+# database connection from the reader pool until its last page is read or it
+# goes unused for rpc_cursor_timeout.
+rpc_max_cursors = {{ .AppConfig.RPCMaxCursors }}
+rpc_max_cursors_per_ip = {{ .AppConfig.RPCMaxCursorsPerIP }}
+rpc_cursor_timeout = "{{ .AppConfig.RPCCursorTimeout }}"
+
 # Enforce data privacy: authenticate JSON-RPC call requests using challenge-based
 # authentication. the node will only accept JSON-RPC requests that has a valid signed
 # challenge response. This also disables ad hoc queries, and no raw transaction retrieval.
 private_rpc = {{ .AppConfig.PrivateRPC }}
 
+# Enable the user.explain RPC method, which returns the Kwil logical plan and
+# the PostgreSQL query plan for ad-hoc queries and procedures. Query plans
+# reveal the generated SQL and row estimates, so this should usually be
+# disabled on public nodes. It is always disabled with private_rpc.
+enable_explain = {{ .AppConfig.EnableExplain }}
+
 # Time after which a "call" challenge expires.
 challenge_expiry = "{{ .AppConfig.ChallengeExpiry }}"
 
@@ -171,6 +202,13 @@ pg_db_pass = "{{ .AppConfig.DBPass }}"
 # PostgreSQL database name (override database name, default is "kwild")
 pg_db_name = "{{ .AppConfig.DBName }}"
 
+# Connection strings for read replicas of the above database, such as hot
+# standbys using streaming replication. Read-only RPC requests (query, call,
+# etc.) are served by a replica that has applied at least the last committed
+# block, or by the above database if none has. e.g.
+# ["host=10.0.0.2 port=5432 user=kwild database=kwild sslmode=disable"]
+pg_db_read_replicas = {{arrayFormatter .AppConfig.DBReadReplicas}}
+
 # The admin RPC server can require a password, if set. Ensure the connection is
 # encrypted since the password is sent unencrypted in the HTTP Authorization
 # header. Not needed if client authentication is done with mutual TLS (clients.pem).
@@ -240,6 +278,26 @@ recurring_height = {{.AppConfig.Snapshots.RecurringHeight}}
 # Maximum number of snapshots to store
 max_snapshots = {{.AppConfig.Snapshots.MaxSnapshots}}
 
+#######################################################################
+###              Change Data Capture Configuration                  ###
+#######################################################################
+
+[app.cdc]
+
+# Enables the change data capture feed of the dataset changes committed in each
+# block. The changes are served by the user.changes RPC method and delivered to
+# the configured sinks.
+enable = {{.AppConfig.CDC.Enable}}
+
+# Number of most recent blocks for which changes are kept. 0 keeps all changes.
+retain_blocks = {{.AppConfig.CDC.RetainBlocks}}
+
+# File to which all committed changes are appended as newline-delimited JSON.
+ndjson_file = "{{.AppConfig.CDC.NDJSONFile}}"
+
+# URLs to which the committed changes of each block are POSTed as JSON.
+webhook_urls = {{arrayFormatter .AppConfig.CDC.WebhookURLs}}
+
 #######################################################################
 ###                 Chain  Main Base Config Options                 ###
 #######################################################################
@@ -285,6 +343,40 @@ timeout_precommit = "{{configDuration .ChainConfig.Consensus.TimeoutPrecommit }}
 # though we already have +2/3).
 timeout_commit = "{{configDuration .ChainConfig.Consensus.TimeoutCommit }}"
 
+#######################################################
+###        Remote Signer Configuration Options      ###
+#######################################################
+
+[chain.remote_signer]
+
+# A remote signer, such as tmkms, holds the validator's consensus key in a
+# separate signing host and signs votes and proposals using CometBFT's remote
+# signer protocol. If neither address is set, votes and proposals are signed
+# with the node's private key.
+#
+# The node still applies its own height/round/step double-sign protection to
+# every signature returned by the remote signer.
+#
+# The remote signer's key is the validator's consensus key, and need not be the
+# node's private key, which still identifies the node on the network and signs
+# its transactions, such as resolution votes. The consensus key is registered
+# when the node requests to join the validator set, or by the consensus_key of
+# a genesis validator.
+
+# TCP or UNIX socket address on which to listen for the remote signer to connect
+# example: tcp://0.0.0.0:26659
+listen_addr = "{{ .ChainConfig.RemoteSigner.ListenAddress }}"
+
+# TCP or UNIX socket address of a remote signer that listens for this node to
+# connect. Only one of listen_addr and dial_addr may be set.
+dial_addr = "{{ .ChainConfig.RemoteSigner.DialAddress }}"
+
+# Read and write timeout for requests to the remote signer
+timeout = "{{configDuration .ChainConfig.RemoteSigner.Timeout }}"
+
+# How long to wait at startup for a connection to the remote signer
+conn_timeout = "{{configDuration .ChainConfig.RemoteSigner.ConnTimeout }}"
+
 #######################################################
 ###           P2P Configuration Options             ###
 #######################################################
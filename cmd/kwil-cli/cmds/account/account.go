@@ -5,8 +5,8 @@ import (
 	"errors"
 
 	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
-	"github.com/kwilteam/kwil-db/core/crypto/auth"
 
 	"github.com/spf13/cobra"
 )
@@ -22,18 +22,19 @@ var idCmd = &cobra.Command{
 			return display.PrintErr(cmd, err)
 		}
 
-		if conf.PrivateKey == nil {
+		if conf.Signer() == nil {
 			return display.PrintErr(cmd, errors.New("no private key configured"))
 		}
 
-		signer := &auth.EthPersonalSigner{Key: *conf.PrivateKey}
-		return display.PrintCmd(cmd, display.RespString(hex.EncodeToString(signer.Identity())))
+		return display.PrintCmd(cmd, display.RespString(hex.EncodeToString(conf.Identity())))
 	},
 }
 
 var (
 	nonceOverride int64
 	syncBcast     bool
+	unsignedFile  string
+	fee           string
 )
 
 func NewCmdAccount() *cobra.Command {
@@ -53,6 +54,7 @@ func NewCmdAccount() *cobra.Command {
 
 	trCmd.Flags().Int64VarP(&nonceOverride, "nonce", "N", -1, "nonce override (-1 means request from server)")
 	trCmd.Flags().BoolVar(&syncBcast, "sync", false, "synchronous broadcast (wait for it to be included in a block)")
+	common.BindUnsignedTxFlags(trCmd, &unsignedFile, &fee)
 
 	return cmd
 }
@@ -12,14 +12,19 @@ import (
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	clientType "github.com/kwilteam/kwil-db/core/types/client"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
 	"github.com/spf13/cobra"
 )
 
+var transferLong = `Transfers value to an account.
+
+To sign the transfer offline, write it to an unsigned transaction file with ` + "`--unsigned`" + `, giving the nonce, fee, and chain ID.  The file is signed with ` + "`utils sign-tx`" + ` and broadcast with ` + "`utils broadcast-tx`" + `.`
+
 func transferCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "transfer <recipient> <amount>",
 		Short: "Transfer value to an account",
-		Long:  `Transfers value to an account.`,
+		Long:  transferLong,
 		Args:  cobra.ExactArgs(2), // recipient, amt
 		RunE: func(cmd *cobra.Command, args []string) error {
 			recipient, amt := args[0], args[1]
@@ -32,6 +37,13 @@ func transferCmd() *cobra.Command {
 				return display.PrintErr(cmd, errors.New("invalid decimal amount"))
 			}
 
+			if unsignedFile != "" {
+				return common.WriteUnsignedTx(cmd, unsignedFile, &transactions.Transfer{
+					To:     to,
+					Amount: amount.String(),
+				}, nonceOverride, fee)
+			}
+
 			return common.DialClient(cmd.Context(), cmd, 0, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
 				txHash, err := cl.Transfer(ctx, to, amount, clientType.WithNonce(nonceOverride),
 					clientType.WithSyncBroadcast(syncBcast))
@@ -14,6 +14,8 @@ type Prompter struct {
 	MaskDefault bool
 	ShowLast    int
 	HideEntered bool
+	// Mask, if set, is displayed instead of each entered character.
+	Mask rune
 }
 
 func (p Prompter) Run() (string, error) {
@@ -44,6 +46,7 @@ func (p Prompter) Run() (string, error) {
 		Validate:    p.Validate,
 		Default:     p.Default,
 		HideEntered: p.HideEntered,
+		Mask:        p.Mask,
 		AllowEdit:   true,
 	}
 
@@ -51,8 +51,8 @@ func DialClient(ctx context.Context, cmd *cobra.Command, flags uint8, fn RoundTr
 	authCalls := flags&AuthenticatedCalls != 0
 
 	clientConfig := clientType.Options{}
-	if conf.PrivateKey != nil {
-		clientConfig.Signer = &auth.EthPersonalSigner{Key: *conf.PrivateKey}
+	if signer := conf.Signer(); signer != nil {
+		clientConfig.Signer = signer
 		if needPrivateKey { // only check chain ID if signing something
 			clientConfig.ChainID = conf.ChainID
 		}
@@ -71,7 +71,7 @@ func DialClient(ctx context.Context, cmd *cobra.Command, flags uint8, fn RoundTr
 
 		// if we are making authenticated calls, we need to ensure that the private key is provided
 		// if the Kwild node is in private mode.
-		if authCalls && client.PrivateMode() && conf.PrivateKey == nil {
+		if authCalls && client.PrivateMode() && conf.Signer() == nil {
 			return fmt.Errorf("private key not provided for authenticated calls")
 		}
 
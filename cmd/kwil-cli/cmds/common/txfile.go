@@ -0,0 +1,129 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/spf13/cobra"
+)
+
+// ReadTxFile reads a transaction file, which is a JSON encoded transaction
+// that may be unsigned or partially signed.
+func ReadTxFile(path string) (*transactions.Transaction, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx transactions.Transaction
+	if err = json.Unmarshal(bts, &tx); err != nil {
+		return nil, err
+	}
+	if tx.Body == nil {
+		return nil, errors.New("transaction file has no body")
+	}
+	return &tx, nil
+}
+
+// WriteTxFile writes a transaction file.
+func WriteTxFile(path string, tx *transactions.Transaction) error {
+	bts, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bts, '\n'), 0600)
+}
+
+// BindUnsignedTxFlags binds the flags used by transaction commands to write an
+// unsigned transaction file instead of signing and broadcasting the
+// transaction. Nothing is requested from the node to build it, so the nonce,
+// fee, and chain ID must all be given.
+func BindUnsignedTxFlags(cmd *cobra.Command, file, fee *string) {
+	cmd.Flags().StringVar(file, "unsigned", "", "write the unsigned transaction to this file instead of broadcasting it, to be signed offline with utils sign-tx (requires --nonce, --fee, and --chain-id)")
+	cmd.Flags().StringVar(fee, "fee", "", "the transaction fee, required with --unsigned")
+}
+
+// NewUnsignedTx creates an unsigned transaction with an explicit nonce, fee,
+// and chain ID. The sender is set when it is signed.
+func NewUnsignedTx(payload transactions.Payload, chainID string, nonce int64, fee string) (*transactions.Transaction, error) {
+	if chainID == "" {
+		return nil, errors.New("a chain ID is required for an unsigned transaction")
+	}
+	if nonce <= 0 {
+		return nil, errors.New("a nonce is required for an unsigned transaction")
+	}
+	if fee == "" {
+		return nil, errors.New("a fee is required for an unsigned transaction")
+	}
+	amt, ok := new(big.Int).SetString(fee, 10)
+	if !ok || amt.Sign() < 0 {
+		return nil, fmt.Errorf("invalid fee %q", fee)
+	}
+
+	tx, err := transactions.CreateTransaction(payload, chainID, uint64(nonce))
+	if err != nil {
+		return nil, err
+	}
+	tx.Body.Fee = amt
+	return tx, nil
+}
+
+// WriteUnsignedTx creates an unsigned transaction for a payload using the
+// configured chain ID, writes it to a transaction file, and prints the result.
+func WriteUnsignedTx(cmd *cobra.Command, file string, payload transactions.Payload, nonce int64, fee string) error {
+	conf, err := config.LoadCliConfig()
+	if err != nil {
+		return display.PrintErr(cmd, err)
+	}
+
+	tx, err := NewUnsignedTx(payload, conf.ChainID, nonce, fee)
+	if err != nil {
+		return display.PrintErr(cmd, err)
+	}
+	if err = WriteTxFile(file, tx); err != nil {
+		return display.PrintErr(cmd, err)
+	}
+
+	return display.PrintCmd(cmd, &RespTxFile{File: file, Tx: tx})
+}
+
+// RespTxFile is the response of a command that writes a transaction file.
+type RespTxFile struct {
+	File string
+	Tx   *transactions.Transaction
+}
+
+func (r *RespTxFile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File    string `json:"file"`
+		Type    string `json:"type"`
+		ChainID string `json:"chain_id"`
+		Nonce   uint64 `json:"nonce"`
+		Fee     string `json:"fee"`
+		Signed  bool   `json:"signed"`
+	}{
+		File:    r.File,
+		Type:    r.Tx.Body.PayloadType.String(),
+		ChainID: r.Tx.Body.ChainID,
+		Nonce:   r.Tx.Body.Nonce,
+		Fee:     r.Tx.Body.Fee.String(),
+		Signed:  r.Tx.Signature != nil,
+	})
+}
+
+func (r *RespTxFile) MarshalText() ([]byte, error) {
+	state := "Unsigned"
+	if r.Tx.Signature != nil {
+		state = "Signed"
+	}
+	msg := fmt.Sprintf("%s %s transaction written to %s (chain ID %s, nonce %d, fee %s).\n",
+		state, r.Tx.Body.PayloadType, r.File, r.Tx.Body.ChainID, r.Tx.Body.Nonce, r.Tx.Body.Fee)
+	return []byte(msg), nil
+}
@@ -0,0 +1,64 @@
+package common
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUnsignedTx(t *testing.T) {
+	payload := &transactions.Transfer{To: []byte{0xab}, Amount: "100"}
+
+	tests := []struct {
+		name    string
+		chainID string
+		nonce   int64
+		fee     string
+		wantErr bool
+	}{
+		{"valid", "kwil-chain", 1, "210000", false},
+		{"zero fee", "kwil-chain", 1, "0", false},
+		{"no chain ID", "", 1, "210000", true},
+		{"no nonce", "kwil-chain", 0, "210000", true},
+		{"no fee", "kwil-chain", 1, "", true},
+		{"negative fee", "kwil-chain", 1, "-1", true},
+		{"invalid fee", "kwil-chain", 1, "1.5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewUnsignedTx(payload, tt.chainID, tt.nonce, tt.fee)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, tx.Signature)
+			assert.Equal(t, tt.chainID, tx.Body.ChainID)
+			assert.Equal(t, uint64(tt.nonce), tx.Body.Nonce)
+			assert.Equal(t, tt.fee, tx.Body.Fee.String())
+		})
+	}
+}
+
+func TestTxFile(t *testing.T) {
+	tx, err := NewUnsignedTx(&transactions.Transfer{To: []byte{0xab}, Amount: "100"}, "kwil-chain", 3, "210000")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tx.json")
+	require.NoError(t, WriteTxFile(path, tx))
+
+	got, err := ReadTxFile(path)
+	require.NoError(t, err)
+	assert.Nil(t, got.Signature)
+	assert.Equal(t, tx.Body.PayloadType, got.Body.PayloadType)
+	assert.Equal(t, tx.Body.Payload, got.Body.Payload)
+	assert.Equal(t, tx.Body.ChainID, got.Body.ChainID)
+	assert.Equal(t, uint64(3), got.Body.Nonce)
+	assert.Equal(t, 0, got.Body.Fee.Cmp(big.NewInt(210000)))
+}
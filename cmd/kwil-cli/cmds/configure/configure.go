@@ -2,11 +2,12 @@ package configure
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kwilteam/kwil-db/cmd/common/display"
 	common "github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common/prompt"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
-	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
 
 	"github.com/spf13/cobra"
 )
@@ -17,9 +18,18 @@ This command will prompt you for the following settings:
 
 - Kwil RPC provider URL: the RPC URL of the Kwil node you wish to connect to.
 - Kwil Chain ID: the chain ID of the Kwil node you wish to connect to.  If left empty, the Kwil node will provide this value.
-- Private Key: the private key to use for signing transactions.  If left empty, the Kwil CLI will not sign transactions.`
+- Auth type: the authenticator type used to sign with the key: secp256k1_ep (Ethereum personal sign), secp256k1_eip712 (Ethereum EIP-712 typed data), ed25519, or nep413 (NEAR wallet message signing).  If left empty, secp256k1_ep is used with secp256k1 keys and ed25519 with ed25519 keys.
+- External signer: the http(s):// or unix:// URL of an external signer, or a command to run to sign, for keys held by an HSM or custody service.  If given, no key is prompted for.
+- Keystore key: the name of an encrypted key in the keystore to use for signing transactions.  Keys are added to the keystore with the 'import-key' subcommand.
+- Private Key: if no keystore key is given, a plain text private key to use for signing transactions.  If left empty, the Kwil CLI will not sign transactions.
 
-var configureExample = `kwil-cli configure`
+If the global '--profile' flag is given, the settings of the named profile are configured instead.  A profile's settings override the default settings when the profile is selected with '--profile'.  Profiles may only use keystore keys.`
+
+var configureExample = `# Configure the default settings
+kwil-cli configure
+
+# Configure the settings of the "testnet" profile
+kwil-cli configure --profile testnet`
 
 func NewCmdConfigure() *cobra.Command {
 	var cmd = &cobra.Command{
@@ -37,11 +47,17 @@ func NewCmdConfigure() *cobra.Command {
 				return display.PrintErr(cmd, err)
 			}
 
-			err = runErrs(conf,
-				promptRPCProvider,
-				promptChainID,
-				promptPrivateKey,
-			)
+			if name := config.ActiveProfile(); name != "" {
+				err = configureProfile(conf, name)
+			} else {
+				err = runErrs(conf,
+					promptRPCProvider,
+					promptChainID,
+					promptAuthType,
+					promptExternalSigner,
+					promptKeyOrPrivateKey,
+				)
+			}
 			if err != nil {
 				return display.PrintErr(cmd, err)
 			}
@@ -50,9 +66,61 @@ func NewCmdConfigure() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(
+		importKeyCmd(),
+		exportKeyCmd(),
+		listKeysCmd(),
+		deleteKeyCmd(),
+	)
+
 	return cmd
 }
 
+// configureProfile prompts for the settings of a profile, creating it if it
+// does not exist.
+func configureProfile(conf *config.KwilCliConfig, name string) error {
+	if err := keystore.ValidateName(name); err != nil {
+		return err
+	}
+
+	// The profile's settings are prompted for using a config with only those
+	// settings, so that the defaults shown are the profile's.
+	profile := conf.Profiles[name]
+	profileConf := &config.KwilCliConfig{
+		AuthType:  profile.AuthType,
+		Provider:  profile.Provider,
+		ChainID:   profile.ChainID,
+		Key:       profile.Key,
+		SignerCmd: profile.SignerCmd,
+		SignerURL: profile.SignerURL,
+	}
+
+	err := runErrs(profileConf,
+		promptRPCProvider,
+		promptChainID,
+		promptAuthType,
+		promptExternalSigner,
+		promptKey,
+	)
+	if err != nil {
+		return err
+	}
+
+	if conf.Profiles == nil {
+		conf.Profiles = make(map[string]config.Profile)
+	}
+	conf.Profiles[name] = config.Profile{
+		AuthType:  profileConf.AuthType,
+		Provider:  profileConf.Provider,
+		ChainID:   profileConf.ChainID,
+		Key:       profileConf.Key,
+		SignerCmd: profileConf.SignerCmd,
+		SignerURL: profileConf.SignerURL,
+	}
+
+	return nil
+}
+
 func runErrs(conf *config.KwilCliConfig, fns ...func(*config.KwilCliConfig) error) error {
 	for _, fn := range fns {
 
@@ -95,6 +163,110 @@ func promptChainID(conf *config.KwilCliConfig) error {
 	return nil
 }
 
+func promptAuthType(conf *config.KwilCliConfig) error {
+	prompt := &common.Prompter{
+		Label:   fmt.Sprintf("Auth type (%s; leave empty for the key type's default)", strings.Join(config.AuthTypes, ", ")),
+		Default: conf.AuthType,
+		Validate: func(authType string) error {
+			if authType == "" {
+				return nil
+			}
+			_, err := config.KeyType(authType)
+			return err
+		},
+	}
+	res, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	conf.AuthType = res
+
+	return nil
+}
+
+// promptExternalSigner prompts for an external signer URL or command.
+func promptExternalSigner(conf *config.KwilCliConfig) error {
+	prompt := &common.Prompter{
+		Label:   "External signer URL or command (leave empty for none)",
+		Default: conf.SignerURL + conf.SignerCmd, // at most one is set
+	}
+	res, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	conf.SignerCmd, conf.SignerURL = "", ""
+	if isSignerURL(res) {
+		conf.SignerURL = res
+	} else {
+		conf.SignerCmd = res
+	}
+
+	return nil
+}
+
+// isSignerURL reports whether an external signer is a URL rather than a
+// command.
+func isSignerURL(signer string) bool {
+	for _, scheme := range []string{"http://", "https://", "unix://"} {
+		if strings.HasPrefix(signer, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptKeyOrPrivateKey prompts for a keystore key, and if none is given, a
+// plain text private key. No key is prompted for if an external signer is
+// configured.
+func promptKeyOrPrivateKey(conf *config.KwilCliConfig) error {
+	if err := promptKey(conf); err != nil {
+		return err
+	}
+
+	if conf.Key != "" || conf.SignerCmd != "" || conf.SignerURL != "" {
+		conf.PrivateKey = nil
+		return nil
+	}
+
+	return promptPrivateKey(conf)
+}
+
+func promptKey(conf *config.KwilCliConfig) error {
+	if conf.SignerCmd != "" || conf.SignerURL != "" {
+		conf.Key = ""
+		return nil
+	}
+
+	store := config.Keystore()
+	prompt := &common.Prompter{
+		Label:   "Keystore key name (leave empty for none)",
+		Default: conf.Key,
+		Validate: func(name string) error {
+			if name == "" {
+				return nil
+			}
+			info, err := store.Info(name)
+			if err != nil {
+				return err
+			}
+			if conf.AuthType != "" {
+				return config.CheckKeyType(conf.AuthType, info.KeyType)
+			}
+			return nil
+		},
+	}
+	res, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	conf.Key = res
+
+	return nil
+}
+
 func promptPrivateKey(conf *config.KwilCliConfig) error {
 	var defaultPrivKeyHex string
 	if conf.PrivateKey != nil {
@@ -114,7 +286,7 @@ func promptPrivateKey(conf *config.KwilCliConfig) error {
 		return nil
 	}
 
-	pk, err := crypto.Secp256k1PrivateKeyFromHex(res)
+	pk, err := config.ParsePrivateKey(conf.AuthType, res)
 	if err != nil {
 		fmt.Printf("invalid private key: %v\n", err)
 		promptAskAgain := &common.Prompter{
@@ -0,0 +1,329 @@
+package configure
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	cmdCommon "github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	common "github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common/prompt"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
+
+	"github.com/spf13/cobra"
+)
+
+var importKeyLong = `Import a private key into the encrypted keystore.
+
+The key is encrypted with a passphrase that is prompted for, or read from the
+` + "`KWIL_CLI_PASSPHRASE`" + ` environment variable if it is set. The key may be entered
+when prompted, generated, taken from the plain text private key in the config
+file, or read from an Ethereum V3 keystore file. Keystore keys are selected by
+name in the config file or a profile using ` + "`kwil-cli configure`" + `.
+
+Entered and generated keys are secp256k1 keys unless ` + "`--key-type ed25519`" + ` is
+given. An ed25519 key may be entered as the 64 byte private key or its 32 byte
+seed.`
+
+var importKeyExample = `# Import a key, entering the private key when prompted
+kwil-cli configure import-key mykey
+
+# Generate a new key
+kwil-cli configure import-key mykey --generate
+
+# Generate a new ed25519 key, e.g. for use with the nep413 auth type
+kwil-cli configure import-key mynearkey --generate --key-type ed25519
+
+# Encrypt the plain text private key in the config file, and use the keystore key instead
+kwil-cli configure import-key mykey --from-config
+
+# Import a key from an Ethereum V3 keystore file
+kwil-cli configure import-key mykey --keystore-file ./UTC--2024-01-01T00-00-00.0Z--7c4239345790560b00bca7bf5bc7c6bc3c34a4d5`
+
+func importKeyCmd() *cobra.Command {
+	var keystoreFile, keyType string
+	var generate, fromConfig bool
+
+	cmd := &cobra.Command{
+		Use:     "import-key <name>",
+		Short:   "Import a private key into the encrypted keystore.",
+		Long:    importKeyLong,
+		Example: importKeyExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := keystore.ValidateName(name); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if keyType != keystore.KeyTypeSecp256k1 && keyType != keystore.KeyTypeEd25519 {
+				return display.PrintErr(cmd, fmt.Errorf("unsupported key type %q", keyType))
+			}
+
+			store := config.Keystore()
+			if store.Has(name) {
+				return display.PrintErr(cmd, fmt.Errorf("key %q already exists", name))
+			}
+
+			var conf *config.KwilCliConfig
+			var key keystore.PrivateKey
+			var err error
+			switch {
+			case countTrue(keystoreFile != "", generate, fromConfig) > 1:
+				return display.PrintErr(cmd, errors.New("only one of --keystore-file, --generate, and --from-config may be used"))
+			case keystoreFile != "":
+				key, err = readKeystoreFile(keystoreFile)
+			case generate:
+				key, err = config.GenerateKey(keyType)
+			case fromConfig:
+				conf, err = config.LoadPersistedConfig()
+				if err == nil && conf.PrivateKey == nil {
+					err = errors.New("no plain text private key in the config file")
+				}
+				if err == nil {
+					key = conf.PrivateKey
+				}
+			default:
+				key, err = promptHexKey(keyType)
+			}
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			passphrase, err := newPassphrase()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if err = store.Import(name, key, passphrase); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if fromConfig {
+				conf.Key = name // PersistConfig omits the plain text key
+				if err = config.PersistConfig(conf); err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+
+			return display.PrintCmd(cmd, &respKeyInfo{
+				KeyInfo: keystore.KeyInfo{
+					Name:    name,
+					KeyType: keystore.KeyType(key),
+					Address: keystore.Address(key),
+				},
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&keystoreFile, "keystore-file", "", "import the key from an Ethereum V3 keystore file")
+	cmd.Flags().BoolVar(&generate, "generate", false, "generate a new key")
+	cmd.Flags().StringVar(&keyType, "key-type", keystore.KeyTypeSecp256k1, "the type of an entered or generated key: secp256k1 or ed25519")
+	cmd.Flags().BoolVar(&fromConfig, "from-config", false, "import the plain text private key in the config file, and use the keystore key instead")
+
+	return cmd
+}
+
+var exportKeyLong = `Export a key from the keystore as an Ethereum V3 keystore file.
+
+The exported key is encrypted with the same passphrase, using the standard V3
+keystore cipher so that it may be imported by other Ethereum tools. It is
+written to the file given by ` + "`--out`" + `, or printed if no file is given.
+Exported ed25519 keys may only be imported by kwil-cli.`
+
+var exportKeyExample = `# Export a key to a file
+kwil-cli configure export-key mykey --out ./mykey.json`
+
+func exportKeyCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:     "export-key <name>",
+		Short:   "Export a key from the keystore as an Ethereum V3 keystore file.",
+		Long:    exportKeyLong,
+		Example: exportKeyExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			store := config.Keystore()
+			if !store.Has(name) {
+				return display.PrintErr(cmd, fmt.Errorf("%w: %q", keystore.ErrKeyNotFound, name))
+			}
+
+			passphrase, err := config.Passphrase(fmt.Sprintf("Passphrase for key %q", name))
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			key, err := store.Load(name, passphrase)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			keyJSON, err := keystore.Encrypt(key, passphrase, keystore.CipherAES128CTR)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if out != "" {
+				if err = os.WriteFile(out, keyJSON, 0600); err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+
+			return display.PrintCmd(cmd, &respExportedKey{
+				KeyJSON: keyJSON,
+				Path:    out,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the exported key to")
+
+	return cmd
+}
+
+func listKeysCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-keys",
+		Short: "List the keys in the keystore.",
+		Long:  "List the names and addresses of the keys in the encrypted keystore.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			keys, err := config.Keystore().List()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, &respKeys{Keys: keys})
+		},
+	}
+}
+
+func deleteKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-key <name>",
+		Short: "Delete a key from the keystore.",
+		Long:  "Delete a key from the encrypted keystore. The key cannot be recovered unless it was exported.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			store := config.Keystore()
+			if !store.Has(name) {
+				return display.PrintErr(cmd, fmt.Errorf("%w: %q", keystore.ErrKeyNotFound, name))
+			}
+
+			assumeYes, err := cmdCommon.GetAssumeYesFlag(cmd)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if !assumeYes {
+				label := fmt.Sprintf("Delete key %q? It cannot be recovered unless it was exported. (y/n)", name)
+				if users := keyUsers(name); len(users) > 0 {
+					label = fmt.Sprintf("Key %q is used by %s. ", name, strings.Join(users, ", ")) + label
+				}
+				res, err := (&common.Prompter{Label: label}).Run()
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				if res != "y" && res != "yes" {
+					return display.PrintCmd(cmd, display.RespString("Key not deleted."))
+				}
+			}
+
+			if err = store.Delete(name); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, display.RespString(fmt.Sprintf("Deleted key %q.", name)))
+		},
+	}
+}
+
+// keyUsers returns descriptions of the settings in the config file that use
+// the named key.
+func keyUsers(name string) []string {
+	conf, err := config.LoadPersistedConfig()
+	if err != nil {
+		return nil
+	}
+
+	var users []string
+	if conf.Key == name {
+		users = append(users, "the default settings")
+	}
+	for profile, p := range conf.Profiles {
+		if p.Key == name {
+			users = append(users, fmt.Sprintf("profile %q", profile))
+		}
+	}
+
+	return users
+}
+
+func countTrue(bs ...bool) int {
+	var n int
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+func readKeystoreFile(path string) (keystore.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := config.PromptPassphrase("Passphrase of the keystore file")
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.Decrypt(keyJSON, passphrase)
+}
+
+func promptHexKey(keyType string) (keystore.PrivateKey, error) {
+	p := &common.Prompter{
+		Label:       fmt.Sprintf("Private %s key (hex)", keyType),
+		Mask:        '*',
+		HideEntered: true,
+		Validate: func(s string) error {
+			_, err := config.ParseKey(keyType, s)
+			return err
+		},
+	}
+	res, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ParseKey(keyType, res)
+}
+
+// newPassphrase returns the passphrase from the environment, or prompts for a
+// new passphrase and its confirmation.
+func newPassphrase() (string, error) {
+	if p, ok := os.LookupEnv(config.PassphraseEnv); ok {
+		return p, nil
+	}
+
+	p1, err := config.PromptPassphrase("New passphrase")
+	if err != nil {
+		return "", err
+	}
+	p2, err := config.PromptPassphrase("Confirm passphrase")
+	if err != nil {
+		return "", err
+	}
+	if p1 != p2 {
+		return "", errors.New("passphrases do not match")
+	}
+
+	return p1, nil
+}
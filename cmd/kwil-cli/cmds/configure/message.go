@@ -0,0 +1,82 @@
+package configure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
+)
+
+// displayAddress returns a key's address for display. Ethereum addresses of
+// secp256k1 keys have a 0x prefix, while ed25519 public keys do not.
+func displayAddress(key *keystore.KeyInfo) string {
+	if key.KeyType == keystore.KeyTypeSecp256k1 {
+		return "0x" + key.Address
+	}
+	return key.Address
+}
+
+// respKeyInfo is the name, type, and address of a key in the keystore.
+type respKeyInfo struct {
+	keystore.KeyInfo
+}
+
+func (r *respKeyInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&r.KeyInfo)
+}
+
+func (r *respKeyInfo) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("Key: %s\nType: %s\nAddress: %s", r.Name, r.KeyType, displayAddress(&r.KeyInfo))), nil
+}
+
+// respKeys lists the keys in the keystore.
+type respKeys struct {
+	Keys []*keystore.KeyInfo
+}
+
+func (r *respKeys) MarshalJSON() ([]byte, error) {
+	keys := r.Keys
+	if keys == nil {
+		keys = []*keystore.KeyInfo{}
+	}
+	return json.Marshal(keys)
+}
+
+func (r *respKeys) MarshalText() ([]byte, error) {
+	if len(r.Keys) == 0 {
+		return []byte("No keys in the keystore."), nil
+	}
+
+	var msg strings.Builder
+	for i, key := range r.Keys {
+		if i > 0 {
+			msg.WriteString("\n")
+		}
+		fmt.Fprintf(&msg, "%s\t%s\t%s", key.Name, key.KeyType, displayAddress(key))
+	}
+	return []byte(msg.String()), nil
+}
+
+// respExportedKey is an exported V3 keystore key, and the file it was written
+// to, if any.
+type respExportedKey struct {
+	KeyJSON json.RawMessage
+	Path    string
+}
+
+func (r *respExportedKey) MarshalJSON() ([]byte, error) {
+	if r.Path != "" {
+		return json.Marshal(struct {
+			Path string `json:"path"`
+		}{r.Path})
+	}
+	return r.KeyJSON, nil
+}
+
+func (r *respExportedKey) MarshalText() ([]byte, error) {
+	if r.Path != "" {
+		return []byte("Key exported to " + r.Path), nil
+	}
+	return r.KeyJSON, nil
+}
@@ -0,0 +1,40 @@
+package configure
+
+import (
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
+)
+
+var testKeys = &respKeys{
+	Keys: []*keystore.KeyInfo{
+		{Name: "alice", KeyType: keystore.KeyTypeSecp256k1, Address: "7c4239345790560b00bca7bf5bc7c6bc3c34a4d5"},
+		{Name: "bob", KeyType: keystore.KeyTypeEd25519, Address: "bcb7c8d4ae100a39d8d39be9443b96e14dcc3764e682ae9fb004afecc1cba33d"},
+	},
+}
+
+func Example_respKeys_text() {
+	display.Print(testKeys, nil, "text")
+	// Output:
+	// alice	secp256k1	0x7c4239345790560b00bca7bf5bc7c6bc3c34a4d5
+	// bob	ed25519	bcb7c8d4ae100a39d8d39be9443b96e14dcc3764e682ae9fb004afecc1cba33d
+}
+
+func Example_respKeys_json() {
+	display.Print(testKeys, nil, "json")
+	// Output:
+	// {
+	//   "result": [
+	//     {
+	//       "name": "alice",
+	//       "key_type": "secp256k1",
+	//       "address": "7c4239345790560b00bca7bf5bc7c6bc3c34a4d5"
+	//     },
+	//     {
+	//       "name": "bob",
+	//       "key_type": "ed25519",
+	//       "address": "bcb7c8d4ae100a39d8d39be9443b96e14dcc3764e682ae9fb004afecc1cba33d"
+	//     }
+	//   ],
+	//   "error": ""
+	// }
+}
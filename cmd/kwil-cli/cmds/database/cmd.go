@@ -1,6 +1,8 @@
 package database
 
 import (
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +16,8 @@ var (
 
 	nonceOverride int64
 	syncBcast     bool
+	unsignedFile  string
+	fee           string
 )
 
 func NewCmdDatabase() *cobra.Command {
@@ -22,15 +26,17 @@ func NewCmdDatabase() *cobra.Command {
 		listCmd(),
 		readSchemaCmd(),
 		queryCmd(),
+		explainCmd(),
 		callCmd(), // no tx, but may required key for signature, for now
 	}
 	dbCmd.AddCommand(readOnlyCmds...)
 
 	// writeCmds create a transactions, requiring a private key for signing/
+	deploy, execute := deployCmd(), executeCmd()
 	writeCmds := []*cobra.Command{
-		deployCmd(),
+		deploy,
 		dropCmd(),
-		executeCmd(),
+		execute,
 		batchCmd(),
 	}
 	dbCmd.AddCommand(writeCmds...)
@@ -42,5 +48,11 @@ func NewCmdDatabase() *cobra.Command {
 		cmd.Flags().BoolVar(&syncBcast, "sync", false, "synchronous broadcast (wait for it to be included in a block)")
 	}
 
+	// The deploy and execute commands may instead write an unsigned
+	// transaction to a file, to be signed offline.
+	for _, cmd := range []*cobra.Command{deploy, execute} {
+		common.BindUnsignedTxFlags(cmd, &unsignedFile, &fee)
+	}
+
 	return dbCmd
 }
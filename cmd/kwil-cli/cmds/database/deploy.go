@@ -13,6 +13,7 @@ import (
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	"github.com/kwilteam/kwil-db/core/types"
 	clientType "github.com/kwilteam/kwil-db/core/types/client"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
 	"github.com/kwilteam/kwil-db/parse"
 	"github.com/spf13/cobra"
 )
@@ -22,10 +23,15 @@ var (
 A path to a file containing the database schema must be provided as the first positional argument.
 
 Either a Kuneiform or a JSON file can be provided.  The file type is determined by the --type flag.
-By default, the file type is kf (Kuneiform).  Pass --type json to deploy a JSON file.`
+By default, the file type is kf (Kuneiform).  Pass --type json to deploy a JSON file.
+
+To sign the deployment offline, write it to an unsigned transaction file with --unsigned, giving the nonce, fee, and chain ID.`
 
 	deployExample = `# Deploy a database schema to the target Kwil node
-kwil-cli database deploy ./schema.kf`
+kwil-cli database deploy ./schema.kf
+
+# Write an unsigned deployment transaction to a file, to be signed offline
+kwil-cli database deploy ./schema.kf --unsigned tx.json --nonce 4 --fee 1000000000000000000 --chain-id kwil-chain`
 )
 
 func deployCmd() *cobra.Command {
@@ -37,44 +43,50 @@ func deployCmd() *cobra.Command {
 		Long:    deployLong,
 		Example: deployExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return common.DialClient(cmd.Context(), cmd, 0, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
-				if cmd.Flags().Changed("path") {
-					if len(args) > 0 {
-						return display.PrintErr(cmd, fmt.Errorf("no positional arguments are allowed when using the --path flag"))
-					}
-				} else {
-					if len(args) == 0 {
-						return display.PrintErr(cmd, fmt.Errorf("must provide a path to the database schema file"))
-					}
-					filePath = args[0]
-				}
-
-				// read in the file
-				file, err := os.Open(filePath)
-				if err != nil {
-					return display.PrintErr(cmd, fmt.Errorf("failed to read file: %w", err))
+			if cmd.Flags().Changed("path") {
+				if len(args) > 0 {
+					return display.PrintErr(cmd, fmt.Errorf("no positional arguments are allowed when using the --path flag"))
 				}
-				defer file.Close()
-
-				var db *types.Schema
-				if fileType == "kf" {
-					db, err = UnmarshalKf(file)
-				} else if fileType == "json" {
-					db, err = UnmarshalJson(file)
-				} else {
-					return display.PrintErr(cmd, fmt.Errorf("invalid file type: %s", fileType))
+			} else {
+				if len(args) == 0 {
+					return display.PrintErr(cmd, fmt.Errorf("must provide a path to the database schema file"))
 				}
-				if err != nil {
-					return display.PrintErr(cmd, fmt.Errorf("failed to unmarshal file: %w", err))
+				filePath = args[0]
+			}
+
+			// read in the file
+			file, err := os.Open(filePath)
+			if err != nil {
+				return display.PrintErr(cmd, fmt.Errorf("failed to read file: %w", err))
+			}
+			defer file.Close()
+
+			var db *types.Schema
+			if fileType == "kf" {
+				db, err = UnmarshalKf(file)
+			} else if fileType == "json" {
+				db, err = UnmarshalJson(file)
+			} else {
+				return display.PrintErr(cmd, fmt.Errorf("invalid file type: %s", fileType))
+			}
+			if err != nil {
+				return display.PrintErr(cmd, fmt.Errorf("failed to unmarshal file: %w", err))
+			}
+
+			if cmd.Flags().Changed("name") {
+				if overrideName == "" {
+					return display.PrintErr(cmd, fmt.Errorf("--name flag cannot be empty string"))
 				}
+				db.Name = overrideName
+			}
 
-				if cmd.Flags().Changed("name") {
-					if overrideName == "" {
-						return display.PrintErr(cmd, fmt.Errorf("--name flag cannot be empty string"))
-					}
-					db.Name = overrideName
-				}
+			if unsignedFile != "" {
+				payload := &transactions.Schema{}
+				payload.FromTypes(db)
+				return common.WriteUnsignedTx(cmd, unsignedFile, payload, nonceOverride, fee)
+			}
 
+			return common.DialClient(cmd.Context(), cmd, 0, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
 				txHash, err := cl.DeployDatabase(ctx, db, clientType.WithNonce(nonceOverride),
 					clientType.WithSyncBroadcast(syncBcast))
 				if err != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	clientType "github.com/kwilteam/kwil-db/core/types/client"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
 
 	"github.com/spf13/cobra"
 )
@@ -25,13 +26,20 @@ For example, for procedure ` + "`" + `get_user($username)` + "`" + `, you would
 
 You can either specify the database to execute this against with the ` + "`" + `--name` + "`" + ` and ` + "`" + `--owner` + "`" + `
 flags, or you can specify the database by passing the database id with the ` + "`" + `--dbid` + "`" + ` flag.  If a ` + "`" + `--name` + "`" + `
-flag is passed and no ` + "`" + `--owner` + "`" + ` flag is passed, the owner will be inferred from your configured wallet.`
+flag is passed and no ` + "`" + `--owner` + "`" + ` flag is passed, the owner will be inferred from your configured wallet.
+
+To sign the transaction offline, write it to an unsigned transaction file with ` + "`" + `--unsigned` + "`" + `, giving the nonce, fee,
+and chain ID.  The node is still used to get the procedure's parameters, but no private key is needed.`
 
 	executeExample = `# Executing the ` + "`" + `create_user($username, $age)` + "`" + ` procedure on the "mydb" database
 kwil-cli database execute create_user username:satoshi age:32 --name mydb --owner 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64
 
 # Executing the ` + "`" + `create_user($username, $age)` + "`" + ` procedure on a database using a dbid
-kwil-cli database execute create_user username:satoshi age:32 --dbid 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64`
+kwil-cli database execute create_user username:satoshi age:32 --dbid 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64
+
+# Writing an unsigned transaction for the ` + "`" + `create_user($username, $age)` + "`" + ` procedure, to be signed offline
+kwil-cli database execute create_user username:satoshi age:32 --dbid 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64 \
+  --unsigned tx.json --nonce 7 --fee 2000000000000000 --chain-id kwil-chain`
 )
 
 func executeCmd() *cobra.Command {
@@ -41,7 +49,14 @@ func executeCmd() *cobra.Command {
 		Long:    executeLong,
 		Example: executeExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return common.DialClient(cmd.Context(), cmd, 0, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
+			// The node is still needed for the procedure's parameters when
+			// writing an unsigned transaction, but not the private key.
+			var dialFlags uint8
+			if unsignedFile != "" {
+				dialFlags = common.WithoutPrivateKey
+			}
+
+			return common.DialClient(cmd.Context(), cmd, dialFlags, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
 				dbid, err := getSelectedDbid(cmd, conf)
 				if err != nil {
 					return display.PrintErr(cmd, fmt.Errorf("error getting selected dbid from CLI flags: %w", err))
@@ -62,6 +77,14 @@ func executeCmd() *cobra.Command {
 					return display.PrintErr(cmd, fmt.Errorf("error getting inputs: %w", err))
 				}
 
+				if unsignedFile != "" {
+					payload, err := newExecutionPayload(dbid, action, inputs)
+					if err != nil {
+						return display.PrintErr(cmd, fmt.Errorf("error encoding inputs: %w", err))
+					}
+					return common.WriteUnsignedTx(cmd, unsignedFile, payload, nonceOverride, fee)
+				}
+
 				// Could actually just directly pass nonce to the client method,
 				// but those methods don't need tx details in the inputs.
 				txHash, err := cl.Execute(ctx, dbid, action, inputs,
@@ -87,6 +110,28 @@ func executeCmd() *cobra.Command {
 	return cmd
 }
 
+// newExecutionPayload creates the payload to execute a procedure or action with
+// each of the tuples of inputs.
+func newExecutionPayload(dbid, action string, tuples [][]any) (*transactions.ActionExecution, error) {
+	args := make([][]*transactions.EncodedValue, len(tuples))
+	for i, tuple := range tuples {
+		args[i] = make([]*transactions.EncodedValue, len(tuple))
+		for j, val := range tuple {
+			encoded, err := transactions.EncodeValue(val)
+			if err != nil {
+				return nil, err
+			}
+			args[i][j] = encoded
+		}
+	}
+
+	return &transactions.ActionExecution{
+		DBID:      dbid,
+		Action:    action,
+		Arguments: args,
+	}, nil
+}
+
 // inputs will be received as args.  The args will be in the form of
 // $argname:value.  Example $username:satoshi $age:32
 func parseInputs(args []string) ([]map[string]string, error) {
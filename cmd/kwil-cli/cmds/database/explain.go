@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	"github.com/kwilteam/kwil-db/core/types"
+	clientType "github.com/kwilteam/kwil-db/core/types/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainLong = `Explain how an ad-hoc SQL statement or a procedure would be executed.
+
+Either a SQL statement must be passed as an argument, or a procedure name must be
+passed with the ` + "`" + `--procedure` + "`" + ` flag. For each SQL statement, the generated
+PostgreSQL SQL, Kwil's logical plan, and PostgreSQL's query plan are printed.
+Statements are not executed. Procedure variables are left as parameters, so
+PostgreSQL returns a generic plan for statements in a procedure.
+
+You can either specify the database to execute this against with the ` + "`" + `--name` + "`" + ` and ` + "`" + `--owner` + "`" + `
+flags, or you can specify the database by passing the database id with the ` + "`" + `--dbid` + "`" + ` flag.  If a ` + "`" + `--name` + "`" + `
+flag is passed and no ` + "`" + `--owner` + "`" + ` flag is passed, the owner will be inferred from your configured wallet.
+
+The RPC server must have explain enabled, and it will reject the request if it
+is operating in private mode.`
+
+	explainExample = `# Explaining a query on the "users" table in the "mydb" database
+kwil-cli database explain "SELECT * FROM users WHERE age > 25" --name mydb --owner 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64
+
+# Explaining all statements in the "get_user" procedure
+kwil-cli database explain --procedure get_user --name mydb --owner 0x9228624C3185FCBcf24c1c9dB76D8Bef5f5DAd64`
+)
+
+func explainCmd() *cobra.Command {
+	var procedure string
+
+	cmd := &cobra.Command{
+		Use:     `explain [<sql_statement>]`,
+		Short:   "Explain how an ad-hoc SQL statement or a procedure would be executed.",
+		Long:    explainLong,
+		Example: explainExample,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 1) == (procedure != "") {
+				return display.PrintErr(cmd, fmt.Errorf("exactly one of a SQL statement or --procedure must be specified"))
+			}
+
+			return common.DialClient(cmd.Context(), cmd, common.WithoutPrivateKey,
+				func(ctx context.Context, client clientType.Client, conf *config.KwilCliConfig) error {
+					dbid, err := getSelectedDbid(cmd, conf)
+					if err != nil {
+						return display.PrintErr(cmd, fmt.Errorf("target database not properly specified: %w", err))
+					}
+
+					if procedure != "" {
+						plans, err := client.ExplainProcedure(ctx, dbid, procedure)
+						if err != nil {
+							return display.PrintErr(cmd, fmt.Errorf("error explaining procedure: %w", err))
+						}
+
+						return display.PrintCmd(cmd, &respPlans{Plans: plans})
+					}
+
+					plan, err := client.ExplainQuery(ctx, dbid, args[0])
+					if err != nil {
+						return display.PrintErr(cmd, fmt.Errorf("error explaining query: %w", err))
+					}
+
+					return display.PrintCmd(cmd, &respPlans{Plans: []*types.StatementPlan{plan}})
+				})
+		},
+	}
+
+	bindFlagsTargetingDatabase(cmd)
+	cmd.Flags().StringVarP(&procedure, "procedure", "p", "", "the procedure to explain instead of an ad-hoc SQL statement")
+	return cmd
+}
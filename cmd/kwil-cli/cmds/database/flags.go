@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
-	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/utils"
 	"github.com/spf13/cobra"
 )
@@ -40,12 +39,11 @@ func getSelectedOwner(cmd *cobra.Command, conf *config.KwilCliConfig) ([]byte, e
 		}
 
 	} else {
-		if conf.PrivateKey == nil {
+		if conf.Signer() == nil {
 			return nil, nil // nil is a valid owner, as it will return all
 		}
 
-		signer := auth.EthPersonalSigner{Key: *conf.PrivateKey}
-		ident = signer.Identity()
+		ident = conf.Identity()
 	}
 
 	return ident, nil
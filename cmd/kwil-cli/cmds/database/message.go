@@ -162,3 +162,54 @@ func (s *respSchema) MarshalText() ([]byte, error) {
 
 	return msg.Bytes(), nil
 }
+
+// respPlans is used to represent the query plans of one or more SQL statements
+// in cli
+type respPlans struct {
+	Plans []*types.StatementPlan
+}
+
+func (p *respPlans) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Plans)
+}
+
+func (p *respPlans) MarshalText() ([]byte, error) {
+	if len(p.Plans) == 0 {
+		return []byte("No SQL statements to explain."), nil
+	}
+
+	var msg bytes.Buffer
+	for i, plan := range p.Plans {
+		if len(p.Plans) > 1 {
+			msg.WriteString(fmt.Sprintf("Statement %d:\n", i+1))
+		}
+		msg.WriteString(fmt.Sprintf("SQL: %s\n", plan.SQL))
+		if len(plan.Params) > 0 {
+			msg.WriteString(fmt.Sprintf("Params: %v\n", plan.Params))
+		}
+		if plan.LogicalPlan != "" {
+			msg.WriteString("Logical Plan:\n")
+			msg.WriteString(plan.LogicalPlan)
+			if plan.LogicalPlan[len(plan.LogicalPlan)-1] != '\n' {
+				msg.WriteString("\n")
+			}
+		}
+		if len(plan.PostgresPlan) > 0 {
+			var pgPlan bytes.Buffer
+			if err := json.Indent(&pgPlan, plan.PostgresPlan, "", "  "); err != nil {
+				return nil, err
+			}
+			msg.WriteString("PostgreSQL Plan:\n")
+			msg.Write(pgPlan.Bytes())
+			msg.WriteString("\n")
+		}
+		if plan.Error != "" {
+			msg.WriteString(fmt.Sprintf("Error: %s\n", plan.Error))
+		}
+		if i != len(p.Plans)-1 {
+			msg.WriteString("\n")
+		}
+	}
+
+	return bytes.TrimRight(msg.Bytes(), "\n"), nil
+}
@@ -42,6 +42,41 @@ func Example_respDBlist_text() {
 	//     Owner: 6f776e6572
 }
 
+func Example_respPlans_text() {
+	display.Print(
+		&respPlans{Plans: []*types.StatementPlan{
+			{
+				SQL:          `SELECT name FROM "xabc".users WHERE id = $1;`,
+				Params:       []string{"$id"},
+				LogicalPlan:  "Return: name [text]\n",
+				PostgresPlan: []byte(`[{"Plan":{"Node Type":"Seq Scan"}}]`),
+			},
+			{
+				SQL:   `DELETE FROM "xabc".users;`,
+				Error: "postgres plan: permission denied",
+			},
+		}},
+		nil, "text")
+	// Output:
+	// Statement 1:
+	// SQL: SELECT name FROM "xabc".users WHERE id = $1;
+	// Params: [$id]
+	// Logical Plan:
+	// Return: name [text]
+	// PostgreSQL Plan:
+	// [
+	//   {
+	//     "Plan": {
+	//       "Node Type": "Seq Scan"
+	//     }
+	//   }
+	// ]
+	//
+	// Statement 2:
+	// SQL: DELETE FROM "xabc".users;
+	// Error: postgres plan: permission denied
+}
+
 func mustDecodeHex(s string) []byte {
 	b, err := hex.DecodeString(s)
 	if err != nil {
@@ -0,0 +1,68 @@
+package multisig
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	clientType "github.com/kwilteam/kwil-db/core/types/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	broadcastLong = `Broadcast a multisig transaction file that has been signed by at least the threshold number of members.`
+
+	broadcastExample = `# Broadcast a signed multisig transaction, waiting for it to be included in a block
+kwil-cli multisig broadcast tx.json --sync`
+)
+
+func broadcastCmd() *cobra.Command {
+	var syncBcast bool
+
+	cmd := &cobra.Command{
+		Use:     "broadcast <tx_file>",
+		Short:   "Broadcast a signed multisig transaction file.",
+		Long:    broadcastLong,
+		Example: broadcastExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tx, err := common.ReadTxFile(args[0])
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			signer, err := txSigner(tx, nil)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if signer.Count() < int(signer.MultiSig.Threshold) {
+				return display.PrintErr(cmd, fmt.Errorf("transaction has %d of %d required signatures",
+					signer.Count(), signer.MultiSig.Threshold))
+			}
+
+			return common.DialClient(cmd.Context(), cmd, common.WithoutPrivateKey, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
+				txHash, err := cl.Broadcast(ctx, tx, clientType.WithSyncBroadcast(syncBcast))
+				if err != nil {
+					return display.PrintErr(cmd, fmt.Errorf("broadcast failed: %w", err))
+				}
+				// If sycnBcast, and we have a txHash (error or not), do a query-tx.
+				if len(txHash) != 0 && syncBcast {
+					time.Sleep(500 * time.Millisecond) // otherwise it says not found at first
+					resp, err := cl.TxQuery(ctx, txHash)
+					if err != nil {
+						return display.PrintErr(cmd, fmt.Errorf("tx query failed: %w", err))
+					}
+					return display.PrintCmd(cmd, display.NewTxHashAndExecResponse(resp))
+				}
+				return display.PrintCmd(cmd, display.RespTxHash(txHash))
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&syncBcast, "sync", false, "synchronous broadcast (wait for it to be included in a block)")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+package multisig
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	combineLong = `Combine the signatures of copies of a multisig transaction file.
+
+Each file must contain the same transaction, signed by one or more members.  The combined transaction is written to the ` + "`--out`" + ` file.`
+
+	combineExample = `# Combine the signatures of two members
+kwil-cli multisig combine alice-tx.json bob-tx.json --out tx.json`
+)
+
+func combineCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:     "combine <tx_file>...",
+		Short:   "Combine the signatures of multisig transaction files.",
+		Long:    combineLong,
+		Example: combineExample,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tx, err := common.ReadTxFile(args[0])
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			body, err := tx.Body.MarshalBinary()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			signer, err := txSigner(tx, nil)
+			if err != nil {
+				return display.PrintErr(cmd, fmt.Errorf("%s: %w", args[0], err))
+			}
+
+			for _, txFile := range args[1:] {
+				other, err := common.ReadTxFile(txFile)
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				otherBody, err := other.Body.MarshalBinary()
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				if !bytes.Equal(body, otherBody) || other.Serialization != tx.Serialization {
+					return display.PrintErr(cmd, fmt.Errorf("%s is a different transaction than %s", txFile, args[0]))
+				}
+				if other.Signature == nil {
+					continue
+				}
+				if err = signer.Merge(other.Signature); err != nil {
+					return display.PrintErr(cmd, fmt.Errorf("%s: %w", txFile, err))
+				}
+			}
+
+			if err = setPartial(tx, signer); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if err = common.WriteTxFile(out, tx); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, newRespSigned(out, signer))
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the combined transaction to (required)")
+	cmd.MarkFlagRequired("out")
+
+	return cmd
+}
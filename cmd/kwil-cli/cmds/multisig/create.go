@@ -0,0 +1,100 @@
+package multisig
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	createLong = `Create a multisig account definition from its member keys and threshold.
+
+Each member is given as ` + "`<auth_type>:<identity>`" + `, where the identity is the hex encoded identity of the key for the auth type: an Ethereum address for secp256k1_ep, and a public key for ed25519.  Any auth type enabled in kwild may be used, except multisig.  The order of the members does not matter.
+
+The definition is written to the ` + "`--out`" + ` file, which is given to ` + "`sign`" + ` for the first signature of a transaction.  The account ID is the hash of the definition.`
+
+	createExample = `# Create a 2-of-3 multisig account
+kwil-cli multisig create --threshold 2 --out treasury.json \
+  secp256k1_ep:0xc89D42189f0450C2b2c3c61f58Ec5d628176A1E7 \
+  secp256k1_ep:0x7C4239345790560b00bcA7bF5bC7c6BC3C34a4D5 \
+  ed25519:0aa611bf555596912bc6f9a9f169f8785918e7bab9924001895798ff13f05842`
+)
+
+func createCmd() *cobra.Command {
+	var threshold uint16
+	var out string
+
+	cmd := &cobra.Command{
+		Use:     "create <auth_type:identity>...",
+		Short:   "Create a multisig account definition.",
+		Long:    createLong,
+		Example: createExample,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys := make([]*auth.MultiSigKey, len(args))
+			for i, arg := range args {
+				key, err := parseMember(arg)
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				keys[i] = key
+			}
+
+			ms, err := auth.NewMultiSig(threshold, keys)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			identity, err := ms.Identity()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if out != "" {
+				bts, err := json.MarshalIndent(ms, "", "  ")
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				if err = os.WriteFile(out, append(bts, '\n'), 0644); err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+
+			return display.PrintCmd(cmd, &respMultiSig{
+				Identity: identity,
+				MultiSig: ms,
+				File:     out,
+			})
+		},
+	}
+
+	cmd.Flags().Uint16VarP(&threshold, "threshold", "t", 0, "the number of members that must sign (required)")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the multisig definition to")
+	cmd.MarkFlagRequired("threshold")
+
+	return cmd
+}
+
+// parseMember parses a member key given as <auth_type>:<hex identity>.
+func parseMember(member string) (*auth.MultiSigKey, error) {
+	authType, identHex, ok := strings.Cut(member, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid member %q, expected <auth_type>:<identity>", member)
+	}
+	if authType == "" {
+		return nil, fmt.Errorf("no auth type for member %q", member)
+	}
+
+	identity, err := hex.DecodeString(strings.TrimPrefix(identHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity of member %q: %w", member, err)
+	}
+
+	return &auth.MultiSigKey{AuthType: authType, Identity: identity}, nil
+}
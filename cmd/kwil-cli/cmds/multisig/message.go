@@ -0,0 +1,77 @@
+package multisig
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+// respMultiSig is the response of the create command.
+type respMultiSig struct {
+	Identity []byte
+	MultiSig *auth.MultiSig
+	File     string
+}
+
+func (r *respMultiSig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID        string              `json:"id"`
+		Threshold uint16              `json:"threshold"`
+		Keys      []*auth.MultiSigKey `json:"keys"`
+		File      string              `json:"file,omitempty"`
+	}{
+		ID:        hex.EncodeToString(r.Identity),
+		Threshold: r.MultiSig.Threshold,
+		Keys:      r.MultiSig.Keys,
+		File:      r.File,
+	})
+}
+
+func (r *respMultiSig) MarshalText() ([]byte, error) {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "Multisig account ID: %x\n", r.Identity)
+	fmt.Fprintf(&msg, "Threshold: %d of %d\n", r.MultiSig.Threshold, len(r.MultiSig.Keys))
+	for _, key := range r.MultiSig.Keys {
+		fmt.Fprintf(&msg, "  %s:%x\n", key.AuthType, []byte(key.Identity))
+	}
+	if r.File != "" {
+		fmt.Fprintf(&msg, "Definition written to %s\n", r.File)
+	}
+	return []byte(msg.String()), nil
+}
+
+// respSigned is the response of the sign and combine commands.
+type respSigned struct {
+	File       string
+	Signatures int
+	Threshold  uint16
+}
+
+func newRespSigned(file string, signer *auth.MultiSigSigner) *respSigned {
+	return &respSigned{
+		File:       file,
+		Signatures: signer.Count(),
+		Threshold:  signer.MultiSig.Threshold,
+	}
+}
+
+func (r *respSigned) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File       string `json:"file"`
+		Signatures int    `json:"signatures"`
+		Threshold  uint16 `json:"threshold"`
+	}{
+		File:       r.File,
+		Signatures: r.Signatures,
+		Threshold:  r.Threshold,
+	})
+}
+
+func (r *respSigned) MarshalText() ([]byte, error) {
+	msg := fmt.Sprintf("Transaction written to %s with %d of %d required signatures.\n",
+		r.File, r.Signatures, r.Threshold)
+	return []byte(msg), nil
+}
@@ -0,0 +1,107 @@
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/spf13/cobra"
+)
+
+var multisigLong = `Multisig (k-of-n) account commands.
+
+A multisig account is defined by a set of member keys, which may be of any authenticator type such as secp256k1_ep or ed25519, and a threshold number of them that must sign its transactions.  The account's ID is the hash of its definition, which is created with the ` + "`create`" + ` subcommand.
+
+Transactions of a multisig account are signed offline by passing a transaction file between the members.  Each member signs it with ` + "`sign`" + `, or members sign copies that are joined with ` + "`combine`" + `.  Once it has enough signatures, it is sent with ` + "`broadcast`" + `.  A transaction file is a JSON encoded transaction, which must use the "concat" serialization.
+
+The multisig authenticator must be enabled in kwild with the auth_multisig build tag.`
+
+func NewCmdMultisig() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "Multisig account commands.",
+		Long:  multisigLong,
+	}
+
+	cmd.AddCommand(
+		createCmd(),
+		signCmd(),
+		combineCmd(),
+		broadcastCmd(),
+	)
+
+	return cmd
+}
+
+// readMultiSig reads a multisig definition file, which is a JSON encoded
+// auth.MultiSig.
+func readMultiSig(path string) (*auth.MultiSig, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms auth.MultiSig
+	if err = json.Unmarshal(bts, &ms); err != nil {
+		return nil, fmt.Errorf("invalid multisig file: %w", err)
+	}
+	if err = ms.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid multisig file: %w", err)
+	}
+	return &ms, nil
+}
+
+// txSigner returns a MultiSigSigner for a transaction file, with the member
+// signatures it has. If the transaction is not yet signed, the multisig
+// definition must be given.
+func txSigner(tx *transactions.Transaction, ms *auth.MultiSig) (*auth.MultiSigSigner, error) {
+	if tx.Serialization != transactions.SignedMsgConcat {
+		return nil, fmt.Errorf("multisig transactions must use the %q serialization", transactions.SignedMsgConcat)
+	}
+
+	if tx.Signature == nil {
+		if ms == nil {
+			return nil, errors.New("transaction is not signed, and no multisig file was given")
+		}
+		return auth.NewMultiSigSigner(ms)
+	}
+
+	if tx.Signature.Type != auth.MultiSigAuth {
+		return nil, fmt.Errorf("transaction has a %q signature, not a multisig signature", tx.Signature.Type)
+	}
+	var msSig auth.MultiSigSignature
+	if err := msSig.UnmarshalBinary(tx.Signature.Signature); err != nil {
+		return nil, fmt.Errorf("invalid multisig signature: %w", err)
+	}
+	if msSig.MultiSig == nil {
+		return nil, errors.New("invalid multisig signature: no multisig")
+	}
+	if ms == nil {
+		ms = msSig.MultiSig
+	}
+
+	signer, err := auth.NewMultiSigSigner(ms)
+	if err != nil {
+		return nil, err
+	}
+	if err = signer.Merge(tx.Signature); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// setPartial sets the transaction's sender and signature to the multisig
+// account and its collected member signatures.
+func setPartial(tx *transactions.Transaction, signer *auth.MultiSigSigner) error {
+	sig, err := signer.Partial()
+	if err != nil {
+		return err
+	}
+	tx.Signature = sig
+	tx.Sender = signer.Identity()
+	return nil
+}
@@ -0,0 +1,86 @@
+package multisig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+)
+
+func Test_parseMember(t *testing.T) {
+	key, err := parseMember("secp256k1_ep:0xc89D42189f0450C2b2c3c61f58Ec5d628176A1E7")
+	require.NoError(t, err)
+	require.Equal(t, auth.EthPersonalSignAuth, key.AuthType)
+	require.Len(t, key.Identity, 20)
+
+	for _, bad := range []string{"c89D42189f0450C2b2c3c61f58Ec5d628176A1E7", ":00", "ed25519:xyz"} {
+		_, err = parseMember(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func Test_txSigner(t *testing.T) {
+	var signers []auth.Signer
+	var keys []*auth.MultiSigKey
+	for range 3 {
+		key, err := crypto.GenerateSecp256k1Key()
+		require.NoError(t, err)
+		signer := &auth.EthPersonalSigner{Key: *key}
+		signers = append(signers, signer)
+		keys = append(keys, &auth.MultiSigKey{AuthType: signer.AuthType(), Identity: signer.Identity()})
+	}
+	ms, err := auth.NewMultiSig(2, keys)
+	require.NoError(t, err)
+
+	newTx := func() *transactions.Transaction {
+		return &transactions.Transaction{
+			Body: &transactions.TransactionBody{
+				Payload:     []byte{1},
+				PayloadType: transactions.PayloadTypeTransfer,
+				Fee:         big.NewInt(0),
+				Nonce:       1,
+				ChainID:     "kwil-testnet",
+			},
+			Serialization: transactions.SignedMsgConcat,
+		}
+	}
+
+	// The first signer needs the multisig definition.
+	tx := newTx()
+	_, err = txSigner(tx, nil)
+	require.Error(t, err)
+
+	sign := func(tx *transactions.Transaction, ms *auth.MultiSig, member auth.Signer) {
+		signer, err := txSigner(tx, ms)
+		require.NoError(t, err)
+		msg, err := tx.SerializeMsg()
+		require.NoError(t, err)
+		require.NoError(t, signer.SignWith(member, msg))
+		require.NoError(t, setPartial(tx, signer))
+	}
+
+	sign(tx, ms, signers[0])
+	tx2 := newTx()
+	sign(tx2, ms, signers[2])
+
+	// Later signers get the definition from the signature.
+	signer, err := txSigner(tx, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, signer.Count())
+	require.NoError(t, signer.Merge(tx2.Signature))
+	require.Equal(t, 2, signer.Count())
+
+	identity, err := ms.Identity()
+	require.NoError(t, err)
+	require.Equal(t, identity, []byte(tx.Sender))
+
+	// EIP-712 transactions cannot be multisig transactions.
+	tx3 := newTx()
+	tx3.Serialization = transactions.SignedMsgEip712
+	_, err = txSigner(tx3, ms)
+	require.Error(t, err)
+}
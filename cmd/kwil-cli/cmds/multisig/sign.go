@@ -0,0 +1,89 @@
+package multisig
+
+import (
+	"errors"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	signLong = `Sign a multisig transaction file with the configured key.
+
+The configured key must be a member of the multisig account.  Its signature is added to those already in the file, and the file is updated, or written to the ` + "`--out`" + ` file if given.  For the first signature of a transaction, the multisig definition file must be given with ` + "`--multisig`" + `, which sets the transaction's sender to the multisig account.  Later signers do not need it.`
+
+	signExample = `# Add the first signature to a transaction
+kwil-cli multisig sign tx.json --multisig treasury.json
+
+# Add a second signature
+kwil-cli multisig sign tx.json`
+)
+
+func signCmd() *cobra.Command {
+	var msFile, out string
+
+	cmd := &cobra.Command{
+		Use:     "sign <tx_file>",
+		Short:   "Add a signature to a multisig transaction file.",
+		Long:    signLong,
+		Example: signExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txFile := args[0]
+			if out == "" {
+				out = txFile
+			}
+
+			conf, err := config.LoadCliConfig()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			member := conf.Signer()
+			if member == nil {
+				return display.PrintErr(cmd, errors.New("no private key configured"))
+			}
+
+			tx, err := common.ReadTxFile(txFile)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			var ms *auth.MultiSig
+			if msFile != "" {
+				if ms, err = readMultiSig(msFile); err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+			signer, err := txSigner(tx, ms)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			msg, err := tx.SerializeMsg()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if err = signer.SignWith(member, msg); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if err = setPartial(tx, signer); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if err = common.WriteTxFile(out, tx); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, newRespSigned(out, signer))
+		},
+	}
+
+	cmd.Flags().StringVarP(&msFile, "multisig", "m", "", "the multisig definition file, required for the first signature")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the signed transaction to (default is to update the transaction file)")
+
+	return cmd
+}
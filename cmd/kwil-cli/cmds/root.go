@@ -10,6 +10,7 @@ import (
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/configure"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/database"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/multisig"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/utils"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	"github.com/spf13/cobra"
@@ -55,6 +56,7 @@ func NewRootCmd() *cobra.Command {
 		account.NewCmdAccount(),
 		configure.NewCmdConfigure(),
 		database.NewCmdDatabase(),
+		multisig.NewCmdMultisig(),
 		utils.NewCmdUtils(),
 		version.NewVersionCmd(),
 	)
@@ -37,7 +37,7 @@ func kgwAuthnCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return common.DialClient(cmd.Context(), cmd, common.UsingGateway,
 				func(ctx context.Context, client clientType.Client, cfg *config.KwilCliConfig) error {
-					if cfg.PrivateKey == nil {
+					if cfg.Signer() == nil {
 						return display.PrintErr(cmd, fmt.Errorf("private key not provided"))
 					}
 
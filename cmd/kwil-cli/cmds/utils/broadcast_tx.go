@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	clientType "github.com/kwilteam/kwil-db/core/types/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	broadcastTxLong = `Broadcast a signed transaction file, such as one signed offline with ` + "`sign-tx`" + `.
+
+The transaction is sent as it is, so no private key is needed.  It must be for the chain ID of the node.`
+
+	broadcastTxExample = `# Broadcast a signed transaction, waiting for it to be included in a block
+kwil-cli utils broadcast-tx signed-tx.json --sync`
+)
+
+func broadcastTxCmd() *cobra.Command {
+	var syncBcast bool
+
+	cmd := &cobra.Command{
+		Use:     "broadcast-tx <tx_file>",
+		Short:   "Broadcast a signed transaction file.",
+		Long:    broadcastTxLong,
+		Example: broadcastTxExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tx, err := common.ReadTxFile(args[0])
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if tx.Signature == nil {
+				return display.PrintErr(cmd, fmt.Errorf("transaction is not signed"))
+			}
+
+			return common.DialClient(cmd.Context(), cmd, common.WithoutPrivateKey, func(ctx context.Context, cl clientType.Client, conf *config.KwilCliConfig) error {
+				if tx.Body.ChainID != cl.ChainID() {
+					return display.PrintErr(cmd, fmt.Errorf("transaction chain ID %q does not match the node's chain ID %q",
+						tx.Body.ChainID, cl.ChainID()))
+				}
+
+				txHash, err := cl.Broadcast(ctx, tx, clientType.WithSyncBroadcast(syncBcast))
+				if err != nil {
+					return display.PrintErr(cmd, fmt.Errorf("broadcast failed: %w", err))
+				}
+				// If sycnBcast, and we have a txHash (error or not), do a query-tx.
+				if len(txHash) != 0 && syncBcast {
+					time.Sleep(500 * time.Millisecond) // otherwise it says not found at first
+					resp, err := cl.TxQuery(ctx, txHash)
+					if err != nil {
+						return display.PrintErr(cmd, fmt.Errorf("tx query failed: %w", err))
+					}
+					return display.PrintCmd(cmd, display.NewTxHashAndExecResponse(resp))
+				}
+				return display.PrintCmd(cmd, display.RespTxHash(txHash))
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&syncBcast, "sync", false, "synchronous broadcast (wait for it to be included in a block)")
+
+	return cmd
+}
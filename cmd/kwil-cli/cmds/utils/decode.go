@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"io"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
 )
 
@@ -36,12 +38,38 @@ func fromStdIn(in io.Reader) ([]byte, error) {
 
 func decodeTxCmd() *cobra.Command {
 	var withPayload bool
+	var txFile string
 	var cmd = &cobra.Command{
-		Use:   "decode-tx",
-		Short: "Decodes a raw transaction.",
-		Long:  "Decodes a raw transaction. Given the bytes of a transaction in base64, give a structured output.",
-		Args:  cobra.ExactArgs(1),
+		Use:   "decode-tx [<raw_tx> | -]",
+		Short: "Decodes a raw transaction or a transaction file.",
+		Long: `Decodes a raw transaction or a transaction file. Given the bytes of a transaction in hex, give a structured output.
+
+With ` + "`--file`" + `, the transaction is instead read from a transaction file, which may be unsigned, such as one written with ` + "`--unsigned`" + ` for offline signing. This can be used to inspect a transaction before signing or broadcasting it.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if txFile != "" {
+				if len(args) > 0 {
+					return display.PrintErr(cmd, errors.New("no raw transaction may be given with --file"))
+				}
+				tx, err := common.ReadTxFile(txFile)
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				txBts, err := tx.MarshalBinary()
+				if err != nil {
+					return display.PrintErr(cmd, err)
+				}
+				return display.PrintCmd(cmd, &transaction{
+					Raw:         txBts,
+					Tx:          tx,
+					WithPayload: withPayload,
+				})
+			}
+
+			if len(args) == 0 {
+				return display.PrintErr(cmd, errors.New("a raw transaction or --file is required"))
+			}
+
 			var txStr string
 			var err error
 			if args[0] == "-" {
@@ -70,6 +98,7 @@ func decodeTxCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&withPayload, "payload", false, "also show the payload, which may be large")
+	cmd.Flags().StringVarP(&txFile, "file", "f", "", "decode a transaction file instead of a raw transaction")
 
 	return cmd
 }
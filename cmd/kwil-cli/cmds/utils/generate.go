@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/parse"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateCodeLong = `Generate client code from a Kuneiform schema.`
+
+	generateGoLong = `Generate a typed Go client package from a Kuneiform schema.
+
+The generated package contains one method per public action and procedure.
+Procedure parameters and return values are mapped to Go types, and view
+procedures return typed row structs. The generated code is built on the
+` + "`" + `core/client` + "`" + ` package, and works with any ` + "`" + `core/types/client.Client` + "`" + ` implementation.`
+
+	generateGoExample = `# Generate a Go package from a schema, writing to stdout
+kwil-cli utils generate go --schema ./mydb.kf
+
+# Generate a Go package named "mydb" and write it to a file
+kwil-cli utils generate go --schema ./mydb.kf --package mydb --out ./mydb/mydb.go`
+)
+
+func generateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: generateCodeLong,
+		Long:  generateCodeLong,
+	}
+
+	cmd.AddCommand(generateGoCmd())
+
+	return cmd
+}
+
+func generateGoCmd() *cobra.Command {
+	var schemaFile, pkgName, out string
+
+	cmd := &cobra.Command{
+		Use:     "go",
+		Short:   "Generate a typed Go client package from a Kuneiform schema.",
+		Long:    generateGoLong,
+		Example: generateGoExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.ReadFile(schemaFile)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			res, err := parse.ParseAndValidate(file)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if res.Err() != nil {
+				return display.PrintErr(cmd, res.Err())
+			}
+
+			if pkgName == "" {
+				pkgName = res.Schema.Name
+			}
+
+			src, err := generateGoClient(res.Schema, pkgName)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			if out == "" {
+				return display.PrintCmd(cmd, display.RespString(string(src)))
+			}
+
+			if dir := filepath.Dir(out); dir != "" {
+				if err = os.MkdirAll(dir, 0755); err != nil {
+					return display.PrintErr(cmd, err)
+				}
+			}
+
+			if err = os.WriteFile(out, src, 0644); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, display.RespString(fmt.Sprintf("Go client written to %s", out)))
+		},
+	}
+
+	cmd.Flags().StringVarP(&schemaFile, "schema", "s", "", "Path to the Kuneiform schema file")
+	cmd.Flags().StringVarP(&pkgName, "package", "p", "", "Name of the generated Go package (defaults to the schema name)")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Output file. If not set, the code is written to stdout")
+	cmd.MarkFlagRequired("schema")
+
+	return cmd
+}
@@ -0,0 +1,391 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/kwilteam/kwil-db/core/types"
+)
+
+// goImports are the packages that generated code may reference. Only the
+// imports that are used by a given schema are emitted.
+var goImports = []struct {
+	path, name string
+}{
+	{"context", "context"},
+	{"encoding/json", "json"},
+	{"github.com/kwilteam/kwil-db/core/types", "types"},
+	{"github.com/kwilteam/kwil-db/core/types/client", "clientType"},
+	{"github.com/kwilteam/kwil-db/core/types/decimal", "decimal"},
+	{"github.com/kwilteam/kwil-db/core/types/transactions", "transactions"},
+	{"github.com/kwilteam/kwil-db/core/utils", "utils"},
+}
+
+// goReservedMethods are the names of the Client methods that are always
+// generated, which procedures and actions must not collide with.
+var goReservedMethods = []string{"DBID"}
+
+// goGenerator accumulates the source of a generated Go client package.
+type goGenerator struct {
+	buf     bytes.Buffer
+	imports map[string]bool // keyed by the package name used in the source
+	methods map[string]bool // Client method names already in use
+
+	// needNullable is set if the nullable helper is referenced.
+	needNullable bool
+}
+
+func (g *goGenerator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *goGenerator) use(pkg string) {
+	g.imports[pkg] = true
+}
+
+// generateGoClient generates a gofmt'd Go package that wraps calls to the
+// public actions and procedures of the schema with typed methods.
+func generateGoClient(schema *types.Schema, pkgName string) ([]byte, error) {
+	pkgName = goPackageName(pkgName)
+	if pkgName == "" {
+		return nil, fmt.Errorf("invalid package name")
+	}
+
+	g := &goGenerator{
+		imports: map[string]bool{
+			"clientType": true,
+			"utils":      true,
+		},
+		methods: make(map[string]bool),
+	}
+	for _, name := range goReservedMethods {
+		g.methods[name] = true
+	}
+
+	g.printf("// SchemaName is the name of the %q schema.\n", schema.Name)
+	g.printf("const SchemaName = %q\n\n", schema.Name)
+
+	g.printf(`// DBID returns the dataset ID of the schema when deployed by owner.
+func DBID(owner []byte) string {
+	return utils.GenerateDBID(SchemaName, owner)
+}
+
+// Client is a typed client for a deployed %q dataset.
+type Client struct {
+	client clientType.Client
+	dbid   string
+}
+
+// NewClient creates a Client for the dataset with the given DBID.
+func NewClient(client clientType.Client, dbid string) *Client {
+	return &Client{client: client, dbid: dbid}
+}
+
+// DBID returns the dataset ID used by the client.
+func (c *Client) DBID() string {
+	return c.dbid
+}
+
+`, schema.Name)
+
+	for _, proc := range schema.Procedures {
+		if !proc.Public {
+			continue
+		}
+		if err := g.procedure(proc); err != nil {
+			return nil, fmt.Errorf("procedure %s: %w", proc.Name, err)
+		}
+	}
+
+	for _, action := range schema.Actions {
+		if !action.Public {
+			continue
+		}
+		g.action(action)
+	}
+
+	g.helpers()
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by kwil-cli utils generate go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s is a typed client for the %q Kwil schema.\n", pkgName, schema.Name)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkgName)
+	var thirdParty bool
+	for _, imp := range goImports {
+		if !g.imports[imp.name] {
+			continue
+		}
+		if strings.Contains(imp.path, ".") && !thirdParty { // separate stdlib imports
+			thirdParty = true
+			out.WriteString("\n")
+		}
+		if strings.HasSuffix(imp.path, "/"+imp.name) || imp.path == imp.name {
+			fmt.Fprintf(&out, "\t%q\n", imp.path)
+		} else {
+			fmt.Fprintf(&out, "\t%s %q\n", imp.name, imp.path)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// procedure generates the typed method, and any row type, for a procedure.
+// View procedures are called, and all others are executed in a transaction.
+func (g *goGenerator) procedure(proc *types.Procedure) error {
+	name := g.methodName(proc.Name)
+	params := make([]string, len(proc.Parameters))
+	args := make([]string, len(proc.Parameters))
+	for i, param := range proc.Parameters {
+		typ, err := g.goType(param.Type)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+		pName := goParamName(param.Name)
+		params[i] = pName + " " + typ
+		args[i] = g.argExpr(pName, param.Type)
+	}
+
+	g.use("context")
+
+	if !proc.IsView() {
+		g.use("transactions")
+		g.printf("// %s executes the %q procedure in a transaction, returning the\n// transaction hash.\n", name, proc.Name)
+		g.printf("func (c *Client) %s(ctx context.Context%s, opts ...clientType.TxOpt) (transactions.TxHash, error) {\n",
+			name, joinParams(params))
+		g.printf("\treturn c.client.Execute(ctx, c.dbid, %q, [][]any{{%s}}, opts...)\n}\n\n", proc.Name, strings.Join(args, ", "))
+		return nil
+	}
+
+	if proc.Returns == nil || len(proc.Returns.Fields) == 0 {
+		g.printf("// %s calls the %q procedure.\n", name, proc.Name)
+		g.printf("func (c *Client) %s(ctx context.Context%s) error {\n", name, joinParams(params))
+		g.printf("\t_, err := c.client.Call(ctx, c.dbid, %q, []any{%s})\n\treturn err\n}\n\n", proc.Name, strings.Join(args, ", "))
+		return nil
+	}
+
+	rowType := name + "Row"
+	g.printf("// %s is a row returned by the %q procedure.\ntype %s struct {\n", rowType, proc.Name, rowType)
+	for _, field := range proc.Returns.Fields {
+		typ, err := g.goType(field.Type)
+		if err != nil {
+			return fmt.Errorf("return field %s: %w", field.Name, err)
+		}
+		g.printf("\t%s %s `json:%q`\n", goExportedName(field.Name), typ, field.Name)
+	}
+	g.printf("}\n\n")
+
+	g.use("json")
+	if proc.Returns.IsTable {
+		g.printf("// %s calls the %q procedure and returns the resulting rows.\n", name, proc.Name)
+		g.printf("func (c *Client) %s(ctx context.Context%s) ([]*%s, error) {\n", name, joinParams(params), rowType)
+		g.printf("\tres, err := c.client.Call(ctx, c.dbid, %q, []any{%s})\n", proc.Name, strings.Join(args, ", "))
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn decodeRows[%s](res.Records)\n}\n\n", rowType)
+		return nil
+	}
+
+	g.printf("// %s calls the %q procedure and returns its result.\n", name, proc.Name)
+	g.printf("func (c *Client) %s(ctx context.Context%s) (*%s, error) {\n", name, joinParams(params), rowType)
+	g.printf("\tres, err := c.client.Call(ctx, c.dbid, %q, []any{%s})\n", proc.Name, strings.Join(args, ", "))
+	g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n")
+	g.printf("\trows, err := decodeRows[%s](res.Records)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", rowType)
+	g.printf("\tif len(rows) == 0 {\n\t\treturn nil, nil\n\t}\n\n\treturn rows[0], nil\n}\n\n")
+	return nil
+}
+
+// action generates the method for an action. Action parameters are untyped,
+// so they are passed as any.
+func (g *goGenerator) action(action *types.Action) {
+	name := g.methodName(action.Name)
+	params := make([]string, len(action.Parameters))
+	args := make([]string, len(action.Parameters))
+	for i, param := range action.Parameters {
+		pName := goParamName(param)
+		params[i] = pName + " any"
+		args[i] = pName
+	}
+
+	g.use("context")
+
+	if action.IsView() {
+		g.printf("// %s calls the %q action and returns the resulting records.\n", name, action.Name)
+		g.printf("func (c *Client) %s(ctx context.Context%s) (*clientType.Records, error) {\n", name, joinParams(params))
+		g.printf("\tres, err := c.client.Call(ctx, c.dbid, %q, []any{%s})\n", action.Name, strings.Join(args, ", "))
+		g.printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn res.Records, nil\n}\n\n")
+		return
+	}
+
+	g.use("transactions")
+	g.printf("// %s executes the %q action in a transaction, returning the\n// transaction hash.\n", name, action.Name)
+	g.printf("func (c *Client) %s(ctx context.Context%s, opts ...clientType.TxOpt) (transactions.TxHash, error) {\n",
+		name, joinParams(params))
+	g.printf("\treturn c.client.Execute(ctx, c.dbid, %q, [][]any{{%s}}, opts...)\n}\n\n", action.Name, strings.Join(args, ", "))
+}
+
+// helpers emits the unexported helper functions used by generated methods.
+func (g *goGenerator) helpers() {
+	if g.imports["json"] {
+		g.printf(`// decodeRows decodes call result records into typed rows.
+func decodeRows[T any](records *clientType.Records) ([]*T, error) {
+	if records == nil {
+		return nil, nil
+	}
+
+	bts, err := json.Marshal(records.Export())
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*T
+	if err = json.Unmarshal(bts, &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+`)
+	}
+
+	if g.needNullable {
+		g.printf(`// nullable converts a nil pointer to an untyped nil, which is encoded as NULL.
+func nullable[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return v
+}
+`)
+	}
+}
+
+// methodName returns the name of the Client method for a procedure or action.
+// A name that is already used, such as DBID, is suffixed with underscores
+// until it is unique.
+func (g *goGenerator) methodName(name string) string {
+	method := goExportedName(name)
+	for g.methods[method] {
+		method += "_"
+	}
+	g.methods[method] = true
+	return method
+}
+
+// goType returns the Go type used to represent a Kwil data type.
+func (g *goGenerator) goType(dt *types.DataType) (string, error) {
+	var scalar string
+	switch strings.ToLower(dt.Name) {
+	case types.IntType.Name:
+		scalar = "int64"
+	case types.TextType.Name:
+		scalar = "string"
+	case types.BoolType.Name:
+		scalar = "bool"
+	case types.BlobType.Name:
+		scalar = "[]byte"
+	case types.UUIDType.Name:
+		g.use("types")
+		scalar = "*types.UUID"
+	case types.Uint256Type.Name:
+		g.use("types")
+		scalar = "*types.Uint256"
+	case types.DecimalStr:
+		g.use("decimal")
+		scalar = "*decimal.Decimal"
+	default:
+		return "", fmt.Errorf("unsupported type %s", dt.String())
+	}
+
+	if dt.IsArray {
+		return "[]" + scalar, nil
+	}
+
+	return scalar, nil
+}
+
+// argExpr returns the expression used to pass a typed parameter to the
+// untyped client. Nil pointers must be passed as an untyped nil.
+func (g *goGenerator) argExpr(name string, dt *types.DataType) string {
+	if dt.IsArray {
+		return name
+	}
+
+	switch strings.ToLower(dt.Name) {
+	case types.UUIDType.Name, types.Uint256Type.Name, types.DecimalStr:
+		g.needNullable = true
+		return "nullable(" + name + ")"
+	default:
+		return name
+	}
+}
+
+func joinParams(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(params, ", ")
+}
+
+// goExportedName converts a snake_case identifier to an exported CamelCase Go
+// identifier.
+func goExportedName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(strings.TrimPrefix(name, "$"), "_") {
+		if part == "" {
+			continue
+		}
+		r := []rune(part)
+		sb.WriteRune(unicode.ToUpper(r[0]))
+		sb.WriteString(string(r[1:]))
+	}
+
+	s := sb.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// goParamName converts a parameter name, which may have a $ prefix, to an
+// unexported Go identifier that does not collide with keywords, or with the
+// names used by generated methods, such as the helpers and imported packages.
+func goParamName(name string) string {
+	exported := []rune(goExportedName(name))
+	exported[0] = unicode.ToLower(exported[0])
+	s := string(exported)
+
+	switch s {
+	case "c", "ctx", "opts", "res", "err", "rows", "nullable", "decodeRows":
+		return s + "Arg"
+	}
+	for _, imp := range goImports {
+		if s == imp.name {
+			return s + "Arg"
+		}
+	}
+	if token.IsKeyword(s) {
+		return s + "_"
+	}
+	return s
+}
+
+// goPackageName sanitizes a name for use as a Go package name.
+func goPackageName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			sb.WriteRune(r)
+		}
+	}
+
+	s := sb.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) || token.IsKeyword(s) {
+		return ""
+	}
+	return s
+}
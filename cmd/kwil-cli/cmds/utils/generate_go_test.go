@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateGoClient(t *testing.T) {
+	dec, err := types.NewDecimalType(10, 2)
+	require.NoError(t, err)
+
+	schema := &types.Schema{
+		Name: "market",
+		Procedures: []*types.Procedure{
+			{
+				Name:   "list_item",
+				Public: true,
+				Parameters: []*types.ProcedureParameter{
+					{Name: "$id", Type: types.UUIDType},
+					{Name: "$price", Type: dec},
+					{Name: "$type", Type: types.TextType},
+				},
+			},
+			{
+				Name:      "get_items",
+				Public:    true,
+				Modifiers: []types.Modifier{types.ModifierView},
+				Parameters: []*types.ProcedureParameter{
+					{Name: "$owners", Type: types.BlobArrayType},
+				},
+				Returns: &types.ProcedureReturn{
+					IsTable: true,
+					Fields: []*types.NamedType{
+						{Name: "item_id", Type: types.UUIDType},
+						{Name: "supply", Type: types.Uint256Type},
+						{Name: "tags", Type: types.TextArrayType},
+					},
+				},
+			},
+			{
+				Name:   "private_proc",
+				Public: false,
+			},
+		},
+		Actions: []*types.Action{
+			{
+				Name:       "count_items",
+				Public:     true,
+				Modifiers:  []types.Modifier{types.ModifierView},
+				Parameters: []string{"$ctx"},
+			},
+		},
+	}
+
+	src, err := generateGoClient(schema, "market")
+	require.NoError(t, err)
+	code := string(src)
+
+	for _, want := range []string{
+		"package market",
+		`"github.com/kwilteam/kwil-db/core/types/decimal"`,
+		"func (c *Client) ListItem(ctx context.Context, id *types.UUID, price *decimal.Decimal, type_ string, opts ...clientType.TxOpt) (transactions.TxHash, error) {",
+		`c.client.Execute(ctx, c.dbid, "list_item", [][]any{{nullable(id), nullable(price), type_}}, opts...)`,
+		"func (c *Client) GetItems(ctx context.Context, owners [][]byte) ([]*GetItemsRow, error) {",
+		"ItemId *types.UUID    `json:\"item_id\"`",
+		"Supply *types.Uint256 `json:\"supply\"`",
+		"Tags   []string       `json:\"tags\"`",
+		"func (c *Client) CountItems(ctx context.Context, ctxArg any) (*clientType.Records, error) {",
+		"func nullable[T any](v *T) any {",
+	} {
+		require.Contains(t, code, want)
+	}
+
+	require.False(t, strings.Contains(code, "PrivateProc"), "private procedures should not be generated")
+
+	_, err = generateGoClient(schema, "type")
+	require.Error(t, err)
+}
+
+// Test_GenerateGoClientCompiles builds the generated client for a schema
+// whose procedure and parameter names collide with generated identifiers.
+func Test_GenerateGoClientCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build in short mode")
+	}
+
+	schema := &types.Schema{
+		Name: "collide",
+		Procedures: []*types.Procedure{
+			{
+				Name:   "d_b_i_d",
+				Public: true,
+				Parameters: []*types.ProcedureParameter{
+					{Name: "$nullable", Type: types.UUIDType},
+					{Name: "$decode_rows", Type: types.TextType},
+					{Name: "$json", Type: types.TextType},
+				},
+			},
+			{
+				Name:      "get_rows",
+				Public:    true,
+				Modifiers: []types.Modifier{types.ModifierView},
+				Parameters: []*types.ProcedureParameter{
+					{Name: "$context", Type: types.IntType},
+					{Name: "$utils", Type: types.BlobType},
+				},
+				Returns: &types.ProcedureReturn{
+					IsTable: true,
+					Fields: []*types.NamedType{
+						{Name: "id", Type: types.UUIDType},
+					},
+				},
+			},
+		},
+		Actions: []*types.Action{
+			{
+				Name:       "DBID",
+				Public:     true,
+				Parameters: []string{"$types", "$decimal"},
+			},
+		},
+	}
+
+	src, err := generateGoClient(schema, "collide")
+	require.NoError(t, err)
+	code := string(src)
+
+	for _, want := range []string{
+		"func (c *Client) DBID_(ctx context.Context, nullableArg *types.UUID, decodeRowsArg string, jsonArg string,",
+		"func (c *Client) GetRows(ctx context.Context, contextArg int64, utilsArg []byte)",
+		"func (c *Client) DBID__(ctx context.Context, typesArg any, decimalArg any, opts ...clientType.TxOpt)",
+	} {
+		require.Contains(t, code, want)
+	}
+
+	// the package must be inside the module to resolve its imports, and is
+	// prefixed with an underscore so that ./... patterns ignore it
+	dir, err := os.MkdirTemp(".", "_generated")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "collide.go"), src, 0644))
+
+	out, err := exec.Command("go", "build", "./"+dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
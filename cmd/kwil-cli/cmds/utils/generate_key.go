@@ -7,18 +7,29 @@ import (
 
 	"github.com/kwilteam/kwil-db/cmd/common/display"
 	"github.com/kwilteam/kwil-db/cmd/kwil-admin/cmds/common"
-	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/spf13/cobra"
 )
 
 var (
-	generateLong    = `Generates a new key ECDSA pair using the secp256k1 curve.`
+	generateLong = `Generates a new key pair for the authenticator type given by the global
+` + "`--auth-type`" + ` flag.
+
+By default, an ECDSA key pair using the secp256k1 curve is generated for
+Ethereum personal sign (secp256k1_ep), which is also used for EIP-712 typed
+data signing (secp256k1_eip712). The ed25519 and nep413 auth types use ed25519
+key pairs. The address is the identifier of the key, as derived by the
+authenticator: an Ethereum address for secp256k1_ep and secp256k1_eip712, and
+the hex public key for ed25519 and nep413 (a NEAR implicit account ID).`
 	generateExample = `# Generate a new key pair
 $ kwil-cli utils generate-key
 Private key: 5ecb2ce01dee61729f70e75830d2f0cd151514193f2e05816aad5c453a85edbd
 Public key: 0489590f68d80907d74df59b8d9392b78e060a015f4bc58f346820e0d3266d805766bc14fad70b1b8e8a76bab87c4239345790560b00bca7bf5bc7c6bc3c34a4d5
-Address: 0x7C4239345790560b00bcA7bF5bC7c6BC3C34a4D5`
+Address: 0x7C4239345790560b00bcA7bF5bC7c6BC3C34a4D5
+
+# Generate a new ed25519 key pair for NEAR (NEP-413) signing
+$ kwil-cli utils generate-key --auth-type nep413`
 )
 
 // GenerateKeyCmd returns the command for generating a new key pair.
@@ -30,13 +41,31 @@ func generateKeyCmd() *cobra.Command {
 		Long:    generateLong,
 		Example: generateExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			pk, err := crypto.GenerateSecp256k1Key()
+			authType, err := cmd.Flags().GetString(config.GlobalAuthTypeFlag)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if authType == "" {
+				authType = auth.EthPersonalSignAuth
+			}
+
+			keyType, err := config.KeyType(authType)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			authenticator, err := config.Authenticator(authType)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			pk, err := config.GenerateKey(keyType)
 			if err != nil {
 				return display.PrintErr(cmd, err)
 			}
 
-			pub := hex.EncodeToString(pk.PubKey().Bytes())
-			address, err := auth.EthSecp256k1Authenticator{}.Identifier(pk.PubKey().Bytes())
+			pubKey := config.PublicKey(pk)
+			pub := hex.EncodeToString(pubKey)
+			address, err := authenticator.Identifier(pubKey)
 			if err != nil {
 				return display.PrintErr(cmd, err)
 			}
@@ -7,7 +7,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"reflect"
+	"sort"
 
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	"github.com/kwilteam/kwil-db/core/types"
@@ -46,18 +46,26 @@ func (t *transaction) MarshalJSON() ([]byte, error) {
 		return json.MarshalIndent(tx, "", "  ")
 	}
 
-	// Decode a fresh Transaction instance and zero out the Payload.
-	tx, err := decodeTx(t.Raw)
-	if err != nil {
-		return nil, err
-	}
-	tx.Tx.Body.Payload = nil
-	return json.MarshalIndent(tx, "", "  ")
+	// Copy the Transaction and zero out the Payload. This does not decode a
+	// fresh instance from Raw since an unsigned transaction does not round trip.
+	tx, body := *t.Tx, *t.Tx.Body
+	body.Payload = nil
+	tx.Body = &body
+	return json.MarshalIndent(&tx, "", "  ")
 }
 
 func (t *transaction) MarshalText() ([]byte, error) {
-	txHash := sha256.Sum256(t.Raw) // tmhash is sha256
-	msg := fmt.Sprintf(`Transaction ID: %x
+	// An unsigned transaction, such as one in a file for offline signing, has
+	// no ID or sender until it is signed.
+	txID, sigType, sig := "(unsigned)", "(unsigned)", ""
+	if t.Tx.Signature != nil && t.Tx.Signature.Type != "" {
+		txHash := sha256.Sum256(t.Raw) // tmhash is sha256
+		txID = hex.EncodeToString(txHash[:])
+		sigType = t.Tx.Signature.Type
+		sig = base64.StdEncoding.EncodeToString(t.Tx.Signature.Signature)
+	}
+
+	msg := fmt.Sprintf(`Transaction ID: %s
 Sender: %s
 Description: %s
 Payload type: %s
@@ -67,15 +75,15 @@ Nonce: %d
 Signature type: %s
 Signature: %s
 `,
-		txHash,
+		txID,
 		hex.EncodeToString(t.Tx.Sender), // hex because it's an address or pubkey, probably address
 		t.Tx.Body.Description,
 		t.Tx.Body.PayloadType,
 		t.Tx.Body.ChainID,
 		t.Tx.Body.Fee,
 		t.Tx.Body.Nonce,
-		t.Tx.Signature.Type,
-		base64.StdEncoding.EncodeToString(t.Tx.Signature.Signature),
+		sigType,
+		sig,
 	)
 
 	if t.WithPayload { // put it at the end regardless since it' can be big
@@ -127,20 +135,31 @@ func (r *respKwilCliConfig) MarshalJSON() ([]byte, error) {
 func (r *respKwilCliConfig) MarshalText() ([]byte, error) {
 	var msg bytes.Buffer
 	cfg := r.cfg.ToPersistedConfig()
-	cfg.PrivateKey = "***"
 
-	v := reflect.ValueOf(cfg)
-	t := v.Type()
-
-	if t.Kind() == reflect.Ptr {
-		v = v.Elem()
-		t = t.Elem()
+	msg.WriteString("PrivateKey: ***\n")
+	msg.WriteString(fmt.Sprintf("Provider: %s\n", cfg.Provider))
+	msg.WriteString(fmt.Sprintf("ChainID: %s\n", cfg.ChainID))
+	if cfg.AuthType != "" {
+		msg.WriteString(fmt.Sprintf("AuthType: %s\n", cfg.AuthType))
+	}
+	if cfg.Key != "" {
+		msg.WriteString(fmt.Sprintf("Key: %s\n", cfg.Key))
+	}
+	if cfg.SignerCmd != "" {
+		msg.WriteString(fmt.Sprintf("SignerCmd: %s\n", cfg.SignerCmd))
+	}
+	if cfg.SignerURL != "" {
+		msg.WriteString(fmt.Sprintf("SignerURL: %s\n", cfg.SignerURL))
 	}
 
-	for i := range v.NumField() {
-		field := t.Field(i)
-		fieldValue := v.Field(i)
-		msg.WriteString(fmt.Sprintf("%s: %v\n", field.Name, fieldValue))
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		msg.WriteString(fmt.Sprintf("Profile %s: Provider: %s, ChainID: %s, AuthType: %s, Key: %s\n", name, p.Provider, p.ChainID, p.AuthType, p.Key))
 	}
 
 	return msg.Bytes(), nil
@@ -1,10 +1,16 @@
 package utils
 
 import (
+	"math/big"
+	"testing"
+
 	"github.com/kwilteam/kwil-db/cmd/common/display"
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
 	"github.com/kwilteam/kwil-db/core/crypto"
 	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/stretchr/testify/require"
 )
 
 func Example_respChainInfo_text() {
@@ -81,3 +87,27 @@ func Example_respKwilCliConfig_json() {
 	//   "error": ""
 	// }
 }
+
+func Test_transaction_unsigned_text(t *testing.T) {
+	tx, err := transactions.CreateTransaction(&transactions.Transfer{
+		To:     []byte{0xab},
+		Amount: "100",
+	}, "kwil-chain", 3)
+	require.NoError(t, err)
+	tx.Body.Fee = big.NewInt(210000)
+
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	msg, err := (&transaction{Raw: raw, Tx: tx, WithPayload: true}).MarshalText()
+	require.NoError(t, err)
+	require.Contains(t, string(msg), "Transaction ID: (unsigned)\n")
+	require.Contains(t, string(msg), "Signature type: (unsigned)\n")
+	require.Contains(t, string(msg), "Nonce: 3\n")
+	require.Contains(t, string(msg), `Payload (json): {"to":"qw==","amount":"100"}`)
+
+	// JSON output omits the payload, and must not recurse.
+	bts, err := (&transaction{Raw: raw, Tx: tx}).MarshalJSON()
+	require.NoError(t, err)
+	require.Contains(t, string(bts), `"chain_id": "kwil-chain"`)
+}
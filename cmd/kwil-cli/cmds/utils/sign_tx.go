@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/kwilteam/kwil-db/cmd/common/display"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/config"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	signTxLong = `Sign an unsigned transaction file with the configured key.
+
+Unsigned transaction files are written by the ` + "`database deploy`" + `, ` + "`database execute`" + `, and ` + "`account transfer`" + ` commands with the ` + "`--unsigned`" + ` flag.  Signing does not contact a node, so it may be done on an offline machine.  The file is updated with the signature, or the signed transaction is written to the ` + "`--out`" + ` file if given.  It is then sent with ` + "`broadcast-tx`" + `.`
+
+	signTxExample = `# Sign a transaction file
+kwil-cli utils sign-tx tx.json
+
+# Sign a transaction file, writing the signed transaction to a new file
+kwil-cli utils sign-tx tx.json --out signed-tx.json`
+)
+
+func signTxCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:     "sign-tx <tx_file>",
+		Short:   "Sign an unsigned transaction file.",
+		Long:    signTxLong,
+		Example: signTxExample,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txFile := args[0]
+			if out == "" {
+				out = txFile
+			}
+
+			conf, err := config.LoadCliConfig()
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			signer := conf.Signer()
+			if signer == nil {
+				return display.PrintErr(cmd, errors.New("no private key configured"))
+			}
+
+			tx, err := common.ReadTxFile(txFile)
+			if err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if tx.Signature != nil {
+				return display.PrintErr(cmd, errors.New("transaction is already signed"))
+			}
+			if tx.Body.ChainID == "" {
+				return display.PrintErr(cmd, errors.New("transaction has no chain ID"))
+			}
+
+			// EIP-712 signers sign the typed data serialization
+			if signer.AuthType() == auth.EthEip712Auth {
+				tx.Serialization = transactions.SignedMsgEip712
+			}
+
+			if err = tx.Sign(signer); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+			if err = common.WriteTxFile(out, tx); err != nil {
+				return display.PrintErr(cmd, err)
+			}
+
+			return display.PrintCmd(cmd, &common.RespTxFile{File: out, Tx: tx})
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the signed transaction to (default is to update the transaction file)")
+
+	return cmd
+}
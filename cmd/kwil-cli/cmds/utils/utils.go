@@ -17,12 +17,15 @@ func NewCmdUtils() *cobra.Command {
 		printConfigCmd(),
 		txQueryCmd(),
 		decodeTxCmd(),
+		signTxCmd(),
+		broadcastTxCmd(),
 		chainInfoCmd(),
 		kgwAuthnCmd(),
 		newParseCmd(),
 		testCmd(),
 		dbidCmd(),
 		generateKeyCmd(),
+		generateCmd(),
 	)
 
 	return cmd
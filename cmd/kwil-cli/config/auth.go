@@ -0,0 +1,136 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	extauth "github.com/kwilteam/kwil-db/extensions/auth"
+)
+
+// AuthTypes are the authenticator types that may be configured. If none is
+// configured, Ethereum personal sign is used with secp256k1 keys, and plain
+// ed25519 with ed25519 keys. The
+// NEP-413 authenticator must be enabled in kwild with the auth_nep413 build
+// tag, and the EIP-712 authenticator by activating the eip712 hardfork.
+var AuthTypes = []string{auth.EthPersonalSignAuth, auth.EthEip712Auth, auth.Ed25519Auth, extauth.Nep413Auth}
+
+// KeyType returns the type of private key used by an authenticator type.
+func KeyType(authType string) (string, error) {
+	switch authType {
+	case auth.EthPersonalSignAuth, auth.EthEip712Auth:
+		return keystore.KeyTypeSecp256k1, nil
+	case auth.Ed25519Auth, extauth.Nep413Auth:
+		return keystore.KeyTypeEd25519, nil
+	default:
+		return "", fmt.Errorf("unsupported auth type %q, must be one of %s", authType, strings.Join(AuthTypes, ", "))
+	}
+}
+
+// Authenticator returns the authenticator for an authenticator type, which is
+// used to derive the identifier of a key.
+func Authenticator(authType string) (auth.Authenticator, error) {
+	switch authType {
+	case auth.EthPersonalSignAuth:
+		return auth.EthSecp256k1Authenticator{}, nil
+	case auth.EthEip712Auth:
+		return extauth.Eip712Authenticator{}, nil
+	case auth.Ed25519Auth:
+		return auth.Ed25519Authenticator{}, nil
+	case extauth.Nep413Auth:
+		return extauth.Nep413Authenticator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q, must be one of %s", authType, strings.Join(AuthTypes, ", "))
+	}
+}
+
+// ParsePrivateKey parses a hex private key of the type used by the
+// authenticator type. If the authenticator type is empty, a 64 byte key is an
+// ed25519 key, and otherwise it is a secp256k1 key.
+func ParsePrivateKey(authType, keyHex string) (keystore.PrivateKey, error) {
+	keyHex = strings.TrimPrefix(keyHex, "0x")
+
+	keyType := keystore.KeyTypeSecp256k1
+	if authType != "" {
+		var err error
+		if keyType, err = KeyType(authType); err != nil {
+			return nil, err
+		}
+	} else if len(keyHex) == 2*ed25519.PrivateKeySize {
+		keyType = keystore.KeyTypeEd25519
+	}
+
+	return ParseKey(keyType, keyHex)
+}
+
+// ParseKey parses a hex private key of a keystore key type. An ed25519 key may
+// be either the 64 byte private key or its 32 byte seed.
+func ParseKey(keyType, keyHex string) (keystore.PrivateKey, error) {
+	keyHex = strings.TrimPrefix(keyHex, "0x")
+
+	switch keyType {
+	case keystore.KeyTypeSecp256k1:
+		return crypto.Secp256k1PrivateKeyFromHex(keyHex)
+	case keystore.KeyTypeEd25519:
+		keyBts, err := hex.DecodeString(keyHex)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyBts) == ed25519.SeedSize {
+			keyBts = ed25519.NewKeyFromSeed(keyBts)
+		}
+		return crypto.Ed25519PrivateKeyFromBytes(keyBts)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q, must be %s or %s", keyType, keystore.KeyTypeSecp256k1, keystore.KeyTypeEd25519)
+	}
+}
+
+// GenerateKey generates a private key of a keystore key type.
+func GenerateKey(keyType string) (keystore.PrivateKey, error) {
+	switch keyType {
+	case keystore.KeyTypeSecp256k1:
+		return crypto.GenerateSecp256k1Key()
+	case keystore.KeyTypeEd25519:
+		return crypto.GenerateEd25519Key()
+	default:
+		return nil, fmt.Errorf("unsupported key type %q, must be %s or %s", keyType, keystore.KeyTypeSecp256k1, keystore.KeyTypeEd25519)
+	}
+}
+
+// checkAuthType checks that a private key is of the type used by the
+// authenticator type, if one is configured.
+func checkAuthType(authType string, key keystore.PrivateKey) error {
+	if authType == "" {
+		return nil
+	}
+	return CheckKeyType(authType, keystore.KeyType(key))
+}
+
+// CheckKeyType checks that a keystore key type is used by the authenticator
+// type.
+func CheckKeyType(authType, keyType string) error {
+	wantKeyType, err := KeyType(authType)
+	if err != nil {
+		return err
+	}
+	if keyType != wantKeyType {
+		return fmt.Errorf("auth type %q requires a %s key, not %s", authType, wantKeyType, keyType)
+	}
+	return nil
+}
+
+// PublicKey returns the public key of a private key.
+func PublicKey(key keystore.PrivateKey) []byte {
+	switch key := key.(type) {
+	case *crypto.Secp256k1PrivateKey:
+		return key.PubKey().Bytes()
+	case *crypto.Ed25519PrivateKey:
+		return key.PubKey().Bytes()
+	default:
+		return nil
+	}
+}
@@ -2,43 +2,112 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/kwilteam/kwil-db/cmd/kwil-cli/cmds/common/prompt"
+	"github.com/kwilteam/kwil-db/cmd/kwil-cli/keystore"
 	"github.com/kwilteam/kwil-db/core/crypto"
 	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/crypto/auth/external"
+	extauth "github.com/kwilteam/kwil-db/extensions/auth"
 	"github.com/kwilteam/kwil-db/internal/utils"
 
 	"github.com/spf13/viper"
 )
 
 type KwilCliConfig struct {
-	PrivateKey *crypto.Secp256k1PrivateKey
-	Provider   string
-	ChainID    string
+	// PrivateKey is a *crypto.Secp256k1PrivateKey or *crypto.Ed25519PrivateKey.
+	PrivateKey keystore.PrivateKey
+	// AuthType is the authenticator type used to sign with the private key.
+	// See AuthTypes. If empty, the default for the key type is used.
+	AuthType string
+	Provider string
+	ChainID  string
+	// Key is the name of the key in the keystore to use instead of a plain
+	// text private key.
+	Key string
+	// SignerCmd or SignerURL configure an external signer to use instead of a
+	// private key. See the core/crypto/auth/external package.
+	SignerCmd string
+	SignerURL string
+	// Profiles are named settings, selected with the --profile flag, that
+	// override the settings above.
+	Profiles map[string]Profile
+
+	// externalSigner is created from SignerCmd or SignerURL when the config is
+	// loaded with LoadCliConfig.
+	externalSigner auth.Signer
+}
+
+// Profile is a named set of settings. Empty settings are not overridden.
+type Profile struct {
+	AuthType string `mapstructure:"auth_type" json:"auth_type,omitempty"`
+	Provider string `mapstructure:"provider" json:"provider,omitempty"`
+	ChainID  string `mapstructure:"chain_id" json:"chain_id,omitempty"`
+	Key      string `mapstructure:"key" json:"key,omitempty"`
+
+	SignerCmd string `mapstructure:"signer_cmd" json:"signer_cmd,omitempty"`
+	SignerURL string `mapstructure:"signer_url" json:"signer_url,omitempty"`
+}
+
+// Signer returns the external signer if one is configured, otherwise the
+// signer for the private key and authenticator type, or nil if no private key
+// is set.
+func (c *KwilCliConfig) Signer() auth.Signer {
+	if c.externalSigner != nil {
+		return c.externalSigner
+	}
+
+	switch key := c.PrivateKey.(type) {
+	case *crypto.Secp256k1PrivateKey:
+		if c.AuthType == auth.EthEip712Auth {
+			return &auth.Eip712Signer{Key: *key}
+		}
+		return &auth.EthPersonalSigner{Key: *key}
+	case *crypto.Ed25519PrivateKey:
+		if c.AuthType == extauth.Nep413Auth {
+			return &extauth.Nep413Signer{
+				Ed25519PrivateKey: *key,
+				Recipient:         c.ChainID,
+			}
+		}
+		return &auth.Ed25519Signer{Ed25519PrivateKey: *key}
+	default:
+		return nil
+	}
 }
 
 // Identity returns the account ID, or nil if no private key is set. These are
-// the bytes of the ethereum address.
+// the bytes of the ethereum address for secp256k1 keys, and the public key for
+// ed25519 keys.
 func (c *KwilCliConfig) Identity() []byte {
-	if c.PrivateKey == nil {
+	signer := c.Signer()
+	if signer == nil {
 		return nil
 	}
-	signer := &auth.EthPersonalSigner{Key: *c.PrivateKey}
 	return signer.Identity()
 }
 
 func (c *KwilCliConfig) ToPersistedConfig() *kwilCliPersistedConfig {
 	var privKeyHex string
-	if c.PrivateKey != nil {
+	if c.PrivateKey != nil && c.Key == "" { // never persist a keystore key
 		privKeyHex = c.PrivateKey.Hex()
 	}
 	return &kwilCliPersistedConfig{
 		PrivateKey: privKeyHex,
+		AuthType:   c.AuthType,
 		Provider:   c.Provider,
 		ChainID:    c.ChainID,
+		Key:        c.Key,
+		SignerCmd:  c.SignerCmd,
+		SignerURL:  c.SignerURL,
+		Profiles:   c.Profiles,
 	}
 }
 
@@ -53,15 +122,57 @@ func DefaultKwilCliPersistedConfig() *kwilCliPersistedConfig {
 type kwilCliPersistedConfig struct {
 	// NOTE: `mapstructure` is used by viper, name is same as the viper key name
 	PrivateKey string `mapstructure:"private_key" json:"private_key,omitempty"`
+	AuthType   string `mapstructure:"auth_type" json:"auth_type,omitempty"`
 	Provider   string `mapstructure:"provider" json:"provider,omitempty"`
 	ChainID    string `mapstructure:"chain_id" json:"chain_id,omitempty"`
+	Key        string `mapstructure:"key" json:"key,omitempty"`
+	SignerCmd  string `mapstructure:"signer_cmd" json:"signer_cmd,omitempty"`
+	SignerURL  string `mapstructure:"signer_url" json:"signer_url,omitempty"`
+
+	Profiles map[string]Profile `mapstructure:"profiles" json:"profiles,omitempty"`
 }
 
-func (c *kwilCliPersistedConfig) toKwilCliConfig() (*KwilCliConfig, error) {
+// toKwilCliConfig creates a KwilCliConfig from the persisted config. If unlock
+// is true and a keystore key is configured, the key is decrypted, prompting
+// for its passphrase.
+func (c *kwilCliPersistedConfig) toKwilCliConfig(unlock bool) (*KwilCliConfig, error) {
 	kwilConfig := &KwilCliConfig{
-		Provider: c.Provider,
-		ChainID:  c.ChainID,
+		AuthType:  c.AuthType,
+		Provider:  c.Provider,
+		ChainID:   c.ChainID,
+		Key:       c.Key,
+		SignerCmd: c.SignerCmd,
+		SignerURL: c.SignerURL,
+		Profiles:  c.Profiles,
+	}
+
+	// An external signer is used instead of any private key, but a plain text
+	// key is kept so that it is persisted.
+	if c.SignerCmd != "" || c.SignerURL != "" {
+		if c.PrivateKey != "" {
+			privateKey, err := ParsePrivateKey(c.AuthType, c.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse private key: %w", err)
+			}
+			kwilConfig.PrivateKey = privateKey
+		}
+		if !unlock {
+			return kwilConfig, nil
+		}
+		return kwilConfig, kwilConfig.connectExternalSigner()
+	}
+
+	if c.PrivateKey == "" && c.Key != "" {
+		if !unlock {
+			return kwilConfig, nil
+		}
+		privateKey, err := UnlockKey(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		return kwilConfig, kwilConfig.setPrivateKey(privateKey)
 	}
+	kwilConfig.Key = "" // a private key takes precedence
 
 	// NOTE: so non private_key required cmds could be run
 	if c.PrivateKey == "" {
@@ -69,13 +180,51 @@ func (c *kwilCliPersistedConfig) toKwilCliConfig() (*KwilCliConfig, error) {
 	}
 
 	// we should complain if the private key is configured and invalid
-	privateKey, err := crypto.Secp256k1PrivateKeyFromHex(c.PrivateKey)
+	privateKey, err := ParsePrivateKey(c.AuthType, c.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse private key: %w", err)
 	}
-	kwilConfig.PrivateKey = privateKey
 
-	return kwilConfig, nil
+	return kwilConfig, kwilConfig.setPrivateKey(privateKey)
+}
+
+// connectExternalSigner creates the external signer, checking that its
+// authenticator type is the configured type, if any.
+func (c *KwilCliConfig) connectExternalSigner() error {
+	ctx, cancel := context.WithTimeout(context.Background(), external.DefaultTimeout)
+	defer cancel()
+
+	var signer *external.Signer
+	var err error
+	switch {
+	case c.SignerCmd != "" && c.SignerURL != "":
+		return errors.New("only one of signer_cmd and signer_url may be set")
+	case c.SignerCmd != "":
+		args := strings.Fields(c.SignerCmd)
+		signer, err = external.NewCommandSigner(ctx, args[0], args[1:]...)
+	default:
+		signer, err = external.NewHTTPSigner(ctx, c.SignerURL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+
+	if c.AuthType != "" && c.AuthType != signer.AuthType() {
+		return fmt.Errorf("external signer auth type %q is not the configured auth type %q", signer.AuthType(), c.AuthType)
+	}
+
+	c.externalSigner = signer
+	return nil
+}
+
+// setPrivateKey sets the private key, checking that it is of the type used by
+// the configured authenticator type.
+func (c *KwilCliConfig) setPrivateKey(key keystore.PrivateKey) error {
+	if err := checkAuthType(c.AuthType, key); err != nil {
+		return err
+	}
+	c.PrivateKey = key
+	return nil
 }
 
 func PersistConfig(conf *KwilCliConfig) error {
@@ -121,7 +270,52 @@ func LoadPersistedConfig() (*KwilCliConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
 	}
 
-	return conf.toKwilCliConfig()
+	return conf.toKwilCliConfig(false)
+}
+
+// KeystoreDir returns the directory of the keystore, which is alongside the
+// config file.
+func KeystoreDir() string {
+	return filepath.Join(filepath.Dir(configFile), keystoreDirName)
+}
+
+// Keystore returns the keystore of named, encrypted keys.
+func Keystore() *keystore.Store {
+	return keystore.NewStore(KeystoreDir())
+}
+
+// UnlockKey decrypts the named key in the keystore, getting the passphrase
+// with Passphrase.
+func UnlockKey(name string) (keystore.PrivateKey, error) {
+	passphrase, err := Passphrase(fmt.Sprintf("Passphrase for key %q", name))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := Keystore().Load(name, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock key %q: %w", name, err)
+	}
+	return key, nil
+}
+
+// Passphrase returns the keystore passphrase from the PassphraseEnv
+// environment variable if it is set, otherwise it prompts for it.
+func Passphrase(label string) (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		return passphrase, nil
+	}
+	return PromptPassphrase(label)
+}
+
+// PromptPassphrase prompts for a passphrase without echoing it.
+func PromptPassphrase(label string) (string, error) {
+	p := &prompt.Prompter{
+		Label:       label,
+		Mask:        '*',
+		HideEntered: true,
+	}
+	return p.Run()
 }
 
 func fileExists(path string) bool {
@@ -169,7 +363,55 @@ func LoadCliConfig() (*KwilCliConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	return cfg.toKwilCliConfig()
+	// A selected profile's settings override the config file's, but not
+	// flags.
+	if profile != "" {
+		if err := mergeProfile(cfg, profile); err != nil {
+			return nil, err
+		}
+		cfg = &kwilCliPersistedConfig{}
+		if err := viper.Unmarshal(cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	}
+
+	return cfg.toKwilCliConfig(true)
+}
+
+// mergeProfile merges the non-empty settings of the named profile into the
+// viper config.
+func mergeProfile(cfg *kwilCliPersistedConfig, name string) error {
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	settings := make(map[string]string)
+	if p.AuthType != "" {
+		settings[viperAuthTypeName] = p.AuthType
+	}
+	if p.Provider != "" {
+		settings[viperProviderName] = p.Provider
+	}
+	if p.ChainID != "" {
+		settings[viperChainID] = p.ChainID
+	}
+	if p.Key != "" {
+		settings[viperKeyName] = p.Key
+		settings[viperPrivateKeyName] = "" // the profile's key replaces it
+		settings[viperSignerCmdName] = ""
+		settings[viperSignerURLName] = ""
+	}
+	if p.SignerCmd != "" || p.SignerURL != "" {
+		settings[viperSignerCmdName] = p.SignerCmd
+		settings[viperSignerURLName] = p.SignerURL
+	}
+
+	bs, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return viper.MergeConfig(bytes.NewReader(bs))
 }
 
 func askAndDeleteConfig() {
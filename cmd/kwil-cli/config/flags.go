@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -13,25 +14,39 @@ var cliCfg = DefaultKwilCliPersistedConfig()
 const (
 	defaultConfigDirName      = ".kwil-cli"
 	defaultConfigFileName     = "config.json"
+	keystoreDirName           = "keystore"
 	AlternativeConfigHomePath = "/tmp"
 
 	// NOTE: these flags below are also used as viper key names
 	globalPrivateKeyFlag = "private-key"
+	GlobalAuthTypeFlag   = "auth-type"
+	globalSignerCmdFlag  = "signer-cmd"
+	globalSignerURLFlag  = "signer-url"
 	GlobalProviderFlag   = "provider"
 	globalChainIDFlag    = "chain-id"
 	globalConfigFileFlag = "config"
+	GlobalProfileFlag    = "profile"
 
 	// NOTE: viper key name are used for viper related operations
 	// here they are same `mapstructure` names defined in the config struct
 	viperPrivateKeyName = "private_key"
+	viperAuthTypeName   = "auth_type"
+	viperSignerCmdName  = "signer_cmd"
+	viperSignerURLName  = "signer_url"
 	viperProviderName   = "provider"
 	viperChainID        = "chain_id"
+	viperKeyName        = "key"
 	viperConfigFile     = "config"
 )
 
 var defaultConfigFile string
 var DefaultConfigDir string
 var configFile string
+var profile string
+
+// PassphraseEnv is the environment variable that, if set, is used as the
+// passphrase for keystore keys instead of prompting.
+const PassphraseEnv = "KWIL_CLI_PASSPHRASE"
 
 func init() {
 	dirname, err := os.UserHomeDir()
@@ -47,12 +62,19 @@ func init() {
 func BindGlobalFlags(fs *pflag.FlagSet) {
 	// Bind flags to environment variables
 	fs.String(globalPrivateKeyFlag, cliCfg.PrivateKey, "the private key of the wallet that will be used for signing")
+	fs.String(GlobalAuthTypeFlag, cliCfg.AuthType, "the authenticator type used to sign with the private key: "+strings.Join(AuthTypes, ", ")+" (default is inferred from the key type)")
+	fs.String(globalSignerCmdFlag, cliCfg.SignerCmd, "a command to run to sign with an external signer instead of a private key")
+	fs.String(globalSignerURLFlag, cliCfg.SignerURL, "the http(s):// or unix:// URL of an external signer to use instead of a private key")
 	fs.String(GlobalProviderFlag, cliCfg.Provider, "the Kwil provider RPC endpoint")
 	fs.String(globalChainIDFlag, cliCfg.ChainID, "the expected/intended Kwil Chain ID")
 	fs.StringVar(&configFile, globalConfigFileFlag, defaultConfigFile, "the path to the Kwil CLI persistent global settings file")
+	fs.StringVar(&profile, GlobalProfileFlag, "", "the name of the settings profile to use")
 
 	// Bind flags to viper, named by the flag name
 	viper.BindPFlag(viperPrivateKeyName, fs.Lookup(globalPrivateKeyFlag))
+	viper.BindPFlag(viperAuthTypeName, fs.Lookup(GlobalAuthTypeFlag))
+	viper.BindPFlag(viperSignerCmdName, fs.Lookup(globalSignerCmdFlag))
+	viper.BindPFlag(viperSignerURLName, fs.Lookup(globalSignerURLFlag))
 	viper.BindPFlag(viperProviderName, fs.Lookup(GlobalProviderFlag))
 	viper.BindPFlag(viperChainID, fs.Lookup(globalChainIDFlag))
 
@@ -63,3 +85,9 @@ func BindGlobalFlags(fs *pflag.FlagSet) {
 		panic(err)
 	}
 }
+
+// ActiveProfile returns the name of the profile selected with the --profile
+// flag, or an empty string if none was selected.
+func ActiveProfile() string {
+	return profile
+}
@@ -0,0 +1,372 @@
+// Package keystore stores private keys encrypted with a passphrase. Keys are
+// stored in the Ethereum Web3 Secret Storage (V3 keystore) JSON format, using
+// scrypt to derive the encryption key from the passphrase. Keys are written
+// with the standard AES-128-CTR cipher so that other Ethereum tools can read
+// them. Keys using either AES-128-CTR or the AES-256-GCM cipher used by earlier
+// versions of kwil-cli, and either the scrypt or pbkdf2 KDF, may be imported.
+// The KDF parameters of imported keys are limited so that a crafted file
+// cannot exhaust the memory or CPU of the importer.
+//
+// In addition to secp256k1 keys, ed25519 keys may be stored. These are
+// identified by a "key_type" field, which other tools will not recognize.
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// CipherAES128CTR is the cipher of the standard V3 keystore format.
+	CipherAES128CTR = "aes-128-ctr"
+	// CipherAES256GCM is an authenticated cipher, used for keys stored by
+	// earlier versions of kwil-cli.
+	CipherAES256GCM = "aes-256-gcm"
+
+	version = 3
+
+	kdfScrypt = "scrypt"
+	kdfPBKDF2 = "pbkdf2"
+
+	scryptR     = 8
+	scryptP     = 1
+	scryptDKLen = 32
+
+	// The limits of the KDF parameters of imported keys. The scrypt limits
+	// allow 1GB of memory, four times that of the default parameters, and the
+	// pbkdf2 limit is ten times the iterations used by geth.
+	maxScryptN  = 1 << 20
+	maxScryptR  = 8
+	maxScryptP  = 16
+	maxPBKDF2C  = 1 << 21
+	maxKDFDKLen = 64
+)
+
+const (
+	// KeyTypeSecp256k1 is the type of secp256k1 keys, which is the only type
+	// of key in a standard V3 keystore.
+	KeyTypeSecp256k1 = "secp256k1"
+	// KeyTypeEd25519 is the type of ed25519 keys.
+	KeyTypeEd25519 = "ed25519"
+)
+
+// PrivateKey is a *crypto.Secp256k1PrivateKey or *crypto.Ed25519PrivateKey.
+type PrivateKey interface {
+	Bytes() []byte
+	Hex() string
+}
+
+// KeyType returns the type of a private key, or an empty string if it is not
+// a supported type.
+func KeyType(key PrivateKey) string {
+	switch key.(type) {
+	case *crypto.Secp256k1PrivateKey:
+		return KeyTypeSecp256k1
+	case *crypto.Ed25519PrivateKey:
+		return KeyTypeEd25519
+	default:
+		return ""
+	}
+}
+
+// scryptN is the scrypt CPU/memory cost parameter. The default requires 256MB
+// of memory and about a second of CPU time. It is a variable for tests.
+var scryptN = 1 << 18
+
+// ErrDecrypt is returned when a key cannot be decrypted, which is usually
+// because the passphrase is wrong.
+var ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+
+// encryptedKey is the JSON V3 keystore format.
+type encryptedKey struct {
+	Address string     `json:"address"`
+	KeyType string     `json:"key_type,omitempty"` // empty for secp256k1
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string          `json:"cipher"`
+	CipherText   string          `json:"ciphertext"`
+	CipherParams cipherParams    `json:"cipherparams"`
+	KDF          string          `json:"kdf"`
+	KDFParams    json.RawMessage `json:"kdfparams"`
+	MAC          string          `json:"mac,omitempty"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+type scryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	C     int    `json:"c"`
+	DKLen int    `json:"dklen"`
+	PRF   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+// Address returns the address of a key, without a 0x prefix. For secp256k1
+// keys, this is the hex Ethereum address as used in the V3 keystore format.
+// For ed25519 keys, it is the hex public key.
+func Address(key PrivateKey) string {
+	switch key := key.(type) {
+	case *crypto.Secp256k1PrivateKey:
+		signer := &auth.EthPersonalSigner{Key: *key}
+		return hex.EncodeToString(signer.Identity())
+	case *crypto.Ed25519PrivateKey:
+		return hex.EncodeToString(key.PubKey().Bytes())
+	default:
+		return ""
+	}
+}
+
+// Encrypt encrypts a private key with a passphrase, returning the V3 keystore
+// JSON. The cipher must be CipherAES128CTR or CipherAES256GCM.
+func Encrypt(key PrivateKey, passphrase string, cipherName string) ([]byte, error) {
+	keyType := KeyType(key)
+	if keyType == "" {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if keyType == KeyTypeSecp256k1 {
+		keyType = "" // standard V3
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	kdfParams, err := json.Marshal(&scryptParams{
+		N:     scryptN,
+		R:     scryptR,
+		P:     scryptP,
+		DKLen: scryptDKLen,
+		Salt:  hex.EncodeToString(salt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cipherText, iv []byte
+	var mac string
+	switch cipherName {
+	case CipherAES128CTR:
+		iv = make([]byte, aes.BlockSize)
+		if _, err = rand.Read(iv); err != nil {
+			return nil, err
+		}
+		if cipherText, err = aesCTR(derivedKey[:16], iv, key.Bytes()); err != nil {
+			return nil, err
+		}
+		mac = hex.EncodeToString(ethCrypto.Keccak256(derivedKey[16:32], cipherText))
+	case CipherAES256GCM:
+		aead, err := newGCM(derivedKey)
+		if err != nil {
+			return nil, err
+		}
+		iv = make([]byte, aead.NonceSize())
+		if _, err = rand.Read(iv); err != nil {
+			return nil, err
+		}
+		cipherText = aead.Seal(nil, iv, key.Bytes(), nil)
+	default:
+		return nil, fmt.Errorf("unsupported cipher %q", cipherName)
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encryptedKey{
+		Address: Address(key),
+		KeyType: keyType,
+		Crypto: cryptoJSON{
+			Cipher:       cipherName,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          kdfScrypt,
+			KDFParams:    kdfParams,
+			MAC:          mac,
+		},
+		ID:      id,
+		Version: version,
+	})
+}
+
+// Decrypt decrypts a private key from V3 keystore JSON. ErrDecrypt is returned
+// if the passphrase is wrong. The key is a *crypto.Secp256k1PrivateKey or a
+// *crypto.Ed25519PrivateKey.
+func Decrypt(keyJSON []byte, passphrase string) (PrivateKey, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(keyJSON, &k); err != nil {
+		return nil, fmt.Errorf("invalid keystore JSON: %w", err)
+	}
+	if k.Version != version {
+		return nil, fmt.Errorf("unsupported keystore version %d", k.Version)
+	}
+
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iv: %w", err)
+	}
+
+	derivedKey, err := deriveKey(&k.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var plainText []byte
+	switch k.Crypto.Cipher {
+	case CipherAES128CTR:
+		mac, err := hex.DecodeString(k.Crypto.MAC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mac: %w", err)
+		}
+		if !bytes.Equal(ethCrypto.Keccak256(derivedKey[16:32], cipherText), mac) {
+			return nil, ErrDecrypt
+		}
+		if plainText, err = aesCTR(derivedKey[:16], iv, cipherText); err != nil {
+			return nil, err
+		}
+	case CipherAES256GCM:
+		aead, err := newGCM(derivedKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != aead.NonceSize() {
+			return nil, fmt.Errorf("invalid iv length %d", len(iv))
+		}
+		if plainText, err = aead.Open(nil, iv, cipherText, nil); err != nil {
+			return nil, ErrDecrypt
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cipher %q", k.Crypto.Cipher)
+	}
+
+	var key PrivateKey
+	switch k.KeyType {
+	case "", KeyTypeSecp256k1:
+		key, err = crypto.Secp256k1PrivateKeyFromHex(hex.EncodeToString(plainText))
+	case KeyTypeEd25519:
+		key, err = crypto.Ed25519PrivateKeyFromBytes(plainText)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return key, nil
+}
+
+// deriveKey derives the encryption key from the passphrase with the KDF.
+func deriveKey(c *cryptoJSON, passphrase string) ([]byte, error) {
+	var derivedKey []byte
+	switch c.KDF {
+	case kdfScrypt:
+		var params scryptParams
+		if err := json.Unmarshal(c.KDFParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid scrypt params: %w", err)
+		}
+		if params.N > maxScryptN || params.R > maxScryptR || params.P > maxScryptP || params.DKLen > maxKDFDKLen {
+			return nil, fmt.Errorf("scrypt params n=%d r=%d p=%d dklen=%d exceed the limits n=%d r=%d p=%d dklen=%d",
+				params.N, params.R, params.P, params.DKLen, maxScryptN, maxScryptR, maxScryptP, maxKDFDKLen)
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid salt: %w", err)
+		}
+		derivedKey, err = scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+		if err != nil {
+			return nil, err
+		}
+	case kdfPBKDF2:
+		var params pbkdf2Params
+		if err := json.Unmarshal(c.KDFParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 params: %w", err)
+		}
+		if params.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %q", params.PRF)
+		}
+		if params.C <= 0 || params.C > maxPBKDF2C || params.DKLen > maxKDFDKLen {
+			return nil, fmt.Errorf("pbkdf2 params c=%d dklen=%d exceed the limits c=%d dklen=%d",
+				params.C, params.DKLen, maxPBKDF2C, maxKDFDKLen)
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid salt: %w", err)
+		}
+		derivedKey = pbkdf2.Key([]byte(passphrase), salt, params.C, params.DKLen, sha256.New)
+	default:
+		return nil, fmt.Errorf("unsupported kdf %q", c.KDF)
+	}
+
+	if len(derivedKey) < 32 {
+		return nil, fmt.Errorf("derived key length %d is too short", len(derivedKey))
+	}
+
+	return derivedKey, nil
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid iv length %d", len(iv))
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newUUID returns a random (version 4) UUID string for the key's id field.
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
@@ -0,0 +1,133 @@
+package keystore
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+)
+
+func init() {
+	scryptN = 1 << 10 // fast tests
+}
+
+// The scrypt test vector from the Web3 Secret Storage Definition.
+const v3TestVector = `{
+	"crypto" : {
+		"cipher" : "aes-128-ctr",
+		"cipherparams" : {
+			"iv" : "83dbcc02d8ccb40e466191a123791e0e"
+		},
+		"ciphertext" : "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+		"kdf" : "scrypt",
+		"kdfparams" : {
+			"dklen" : 32,
+			"n" : 262144,
+			"p" : 8,
+			"r" : 1,
+			"salt" : "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"
+		},
+		"mac" : "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+	},
+	"id" : "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version" : 3
+}`
+
+func Test_DecryptV3(t *testing.T) {
+	key, err := Decrypt([]byte(v3TestVector), "testpassword")
+	require.NoError(t, err)
+	require.Equal(t, "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d", key.Hex())
+
+	_, err = Decrypt([]byte(v3TestVector), "wrong")
+	require.ErrorIs(t, err, ErrDecrypt)
+}
+
+func Test_DecryptKDFLimits(t *testing.T) {
+	for name, params := range map[string][2]string{
+		"scrypt n":     {`"n" : 262144`, `"n" : 1073741824`},
+		"scrypt r":     {`"r" : 1`, `"r" : 1024`},
+		"scrypt p":     {`"p" : 8`, `"p" : 1048576`},
+		"scrypt dklen": {`"dklen" : 32`, `"dklen" : 1048576`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			keyJSON := strings.Replace(v3TestVector, params[0], params[1], 1)
+			require.NotEqual(t, v3TestVector, keyJSON)
+
+			_, err := Decrypt([]byte(keyJSON), "testpassword")
+			require.ErrorContains(t, err, "exceed the limits")
+		})
+	}
+}
+
+func Test_EncryptDecrypt(t *testing.T) {
+	secpKey, err := crypto.GenerateSecp256k1Key()
+	require.NoError(t, err)
+	edKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+
+	for _, key := range []PrivateKey{secpKey, edKey} {
+		for _, cipherName := range []string{CipherAES128CTR, CipherAES256GCM} {
+			t.Run(KeyType(key)+"_"+cipherName, func(t *testing.T) {
+				keyJSON, err := Encrypt(key, "secret", cipherName)
+				require.NoError(t, err)
+
+				key2, err := Decrypt(keyJSON, "secret")
+				require.NoError(t, err)
+				require.IsType(t, key, key2)
+				require.Equal(t, key.Hex(), key2.Hex())
+
+				_, err = Decrypt(keyJSON, "not secret")
+				require.ErrorIs(t, err, ErrDecrypt)
+			})
+		}
+	}
+}
+
+func Test_Store(t *testing.T) {
+	store := NewStore(t.TempDir() + "/keystore")
+
+	keys, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	key, err := crypto.GenerateSecp256k1Key()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Import("alice", key, "pass"))
+	require.Error(t, store.Import("alice", key, "pass"))
+	require.Error(t, store.Import("Not/Valid", key, "pass"))
+	require.True(t, store.Has("alice"))
+
+	keys, err = store.List()
+	require.NoError(t, err)
+	require.Equal(t, []*KeyInfo{{Name: "alice", KeyType: KeyTypeSecp256k1, Address: Address(key)}}, keys)
+
+	// keys are stored in the standard V3 format
+	keyJSON, err := os.ReadFile(store.path("alice"))
+	require.NoError(t, err)
+	require.Contains(t, string(keyJSON), `"cipher":"aes-128-ctr"`)
+
+	key2, err := store.Load("alice", "pass")
+	require.NoError(t, err)
+	require.Equal(t, key.Hex(), key2.Hex())
+
+	_, err = store.Load("alice", "wrong")
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	edKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	require.NoError(t, store.Import("bob", edKey, "pass"))
+
+	keys, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, &KeyInfo{Name: "bob", KeyType: KeyTypeEd25519, Address: Address(edKey)}, keys[1])
+
+	require.NoError(t, store.Delete("alice"))
+	_, err = store.Load("alice", "pass")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.ErrorIs(t, store.Delete("alice"), ErrKeyNotFound)
+}
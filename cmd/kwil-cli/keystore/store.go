@@ -0,0 +1,174 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const keyFileExt = ".json"
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateName checks that a key or profile name is lower case letters,
+// digits, hyphens, and underscores, and at most 64 characters.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: must be lower case letters, digits, '-' or '_', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// ErrKeyNotFound is returned when a named key is not in the store.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Store is a directory of named, encrypted keys. Each key is stored in its own
+// file, named for the key.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store for the keys in dir, which is created when the
+// first key is imported.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// KeyInfo describes a key in the store without decrypting it.
+type KeyInfo struct {
+	Name    string `json:"name"`
+	KeyType string `json:"key_type"`
+	Address string `json:"address"`
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+keyFileExt)
+}
+
+// List returns the keys in the store, sorted by name.
+func (s *Store) List() ([]*KeyInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*KeyInfo
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), keyFileExt)
+		if !ok || entry.IsDir() || ValidateName(name) != nil {
+			continue
+		}
+		info, err := s.Info(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, info)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	return keys, nil
+}
+
+// Info returns the name, type, and address of the named key without
+// decrypting it.
+func (s *Store) Info(name string) (*KeyInfo, error) {
+	keyJSON, err := s.Export(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var k encryptedKey
+	if err = json.Unmarshal(keyJSON, &k); err != nil {
+		return nil, fmt.Errorf("invalid key file for %q: %w", name, err)
+	}
+
+	keyType := k.KeyType
+	if keyType == "" {
+		keyType = KeyTypeSecp256k1
+	}
+
+	return &KeyInfo{
+		Name:    name,
+		KeyType: keyType,
+		Address: k.Address,
+	}, nil
+}
+
+// Has reports whether a key with the name is in the store.
+func (s *Store) Has(name string) bool {
+	_, err := os.Stat(s.path(name))
+	return err == nil
+}
+
+// Import encrypts a key with the passphrase and stores it with the name. It is
+// an error if a key with the name already exists.
+func (s *Store) Import(name string, key PrivateKey, passphrase string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	keyJSON, err := Encrypt(key, passphrase, CipherAES128CTR)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("key %q already exists", name)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(keyJSON)
+	return err
+}
+
+// Load decrypts the named key with the passphrase.
+func (s *Store) Load(name, passphrase string) (PrivateKey, error) {
+	keyJSON, err := s.Export(name)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(keyJSON, passphrase)
+}
+
+// Export returns the named key's encrypted keystore JSON.
+func (s *Store) Export(name string) ([]byte, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	keyJSON, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, name)
+	}
+	return keyJSON, err
+}
+
+// Delete removes the named key from the store.
+func (s *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, name)
+	}
+	return err
+}
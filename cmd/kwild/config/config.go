@@ -210,6 +210,14 @@ func sanitizeCfgPaths(cfg *config.KwildConfig) error {
 	cfg.AppConfig.PrivateKeyPath = path
 	fmt.Println("Private key path:", cfg.AppConfig.PrivateKeyPath)
 
+	if cfg.AppConfig.AttesterKeyPath != "" {
+		path, err := config.CleanPath(cfg.AppConfig.AttesterKeyPath, rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to expand attester key path \"%v\": %v", cfg.AppConfig.AttesterKeyPath, err)
+		}
+		cfg.AppConfig.AttesterKeyPath = path
+	}
+
 	if cfg.AppConfig.GenesisState != "" {
 		path, err := config.CleanPath(cfg.AppConfig.GenesisState, rootDir)
 		if err != nil {
@@ -219,6 +227,14 @@ func sanitizeCfgPaths(cfg *config.KwildConfig) error {
 		fmt.Println("Snapshot file to initialize database from:", cfg.AppConfig.GenesisState)
 	}
 
+	if cfg.AppConfig.CDC.NDJSONFile != "" {
+		path, err := config.CleanPath(cfg.AppConfig.CDC.NDJSONFile, rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to expand change data capture file path \"%v\": %v", cfg.AppConfig.CDC.NDJSONFile, err)
+		}
+		cfg.AppConfig.CDC.NDJSONFile = path
+	}
+
 	return nil
 }
 
@@ -384,11 +400,12 @@ func DefaultEmptyConfig() *config.KwildConfig {
 			Extensions: make(map[string]map[string]string),
 		},
 		ChainConfig: &config.ChainConfig{
-			P2P:       &config.P2PConfig{},
-			RPC:       &config.ChainRPCConfig{},
-			Mempool:   &config.MempoolConfig{},
-			StateSync: &config.StateSyncConfig{},
-			Consensus: &config.ConsensusConfig{},
+			P2P:          &config.P2PConfig{},
+			RPC:          &config.ChainRPCConfig{},
+			Mempool:      &config.MempoolConfig{},
+			StateSync:    &config.StateSyncConfig{},
+			Consensus:    &config.ConsensusConfig{},
+			RemoteSigner: &config.RemoteSignerConfig{},
 		},
 		Logging:         &config.Logging{},
 		Instrumentation: &config.InstrumentationConfig{},
@@ -403,13 +420,18 @@ func EmptyConfig() *config.KwildConfig {
 		MigrationConfig: &config.MigrationConfig{},
 		AppConfig: &config.AppConfig{
 			ExtensionEndpoints: []string{},
+			DBReadReplicas:     []string{},
+			CDC: config.CDCConfig{
+				WebhookURLs: []string{},
+			},
 		},
 		ChainConfig: &config.ChainConfig{
-			P2P:       &config.P2PConfig{},
-			RPC:       &config.ChainRPCConfig{},
-			Mempool:   &config.MempoolConfig{},
-			StateSync: &config.StateSyncConfig{},
-			Consensus: &config.ConsensusConfig{},
+			P2P:          &config.P2PConfig{},
+			RPC:          &config.ChainRPCConfig{},
+			Mempool:      &config.MempoolConfig{},
+			StateSync:    &config.StateSyncConfig{},
+			Consensus:    &config.ConsensusConfig{},
+			RemoteSigner: &config.RemoteSignerConfig{},
 		},
 		Logging:         &config.Logging{},
 		Instrumentation: &config.InstrumentationConfig{},
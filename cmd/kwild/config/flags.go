@@ -33,6 +33,8 @@ func AddConfigFlags(flagSet *pflag.FlagSet, cfg *config.KwildConfig) {
 
 	// General APP flags:
 	flagSet.StringVar(&cfg.AppConfig.PrivateKeyPath, "app.private-key-path", cfg.AppConfig.PrivateKeyPath, "Path to the node private key file")
+	flagSet.StringVar(&cfg.AppConfig.AttesterKeyPath, "app.attester-key-path", cfg.AppConfig.AttesterKeyPath, "Path to the withdrawal attestation secp256k1 private key file")
+	flagSet.BoolVar(&cfg.AppConfig.DeriveAttesterKey, "app.derive-attester-key", cfg.AppConfig.DeriveAttesterKey, "Derive the withdrawal attestation key from the node private key if no attester key path is set")
 	flagSet.StringVar(&cfg.AppConfig.JSONRPCListenAddress, "app.jsonrpc-listen-addr", cfg.AppConfig.JSONRPCListenAddress, format("%s JSON-RPC listen address"))
 	flagSet.StringVar(&cfg.AppConfig.AdminListenAddress, "app.admin-listen-addr", cfg.AppConfig.AdminListenAddress, format("%s admin listen address (unix or tcp)"))
 	flagSet.StringVar(&cfg.AppConfig.AdminRPCPass, "app.admin-pass", cfg.AppConfig.AdminRPCPass, "password for the node's admin service (may be empty)")
@@ -41,6 +43,7 @@ func AddConfigFlags(flagSet *pflag.FlagSet, cfg *config.KwildConfig) {
 	flagSet.StringVar(&cfg.AppConfig.AdminTLSKeyFile, "app.admin-tls-key-file", cfg.AppConfig.AdminTLSKeyFile, "TLS key file path for the admin RPC server (optional)")
 	flagSet.StringVar(&cfg.AppConfig.Hostname, "app.hostname", cfg.AppConfig.Hostname, format("%s Server hostname"))
 	flagSet.BoolVar(&cfg.AppConfig.PrivateRPC, "app.private-rpc", cfg.AppConfig.PrivateRPC, "Enforce data privacy with authenticated call request, disabled ad hoc queries, and no raw transaction retrieval")
+	flagSet.BoolVar(&cfg.AppConfig.EnableExplain, "app.enable-explain", cfg.AppConfig.EnableExplain, "Enable the user.explain RPC, which reveals query plans and generated SQL (not recommended for public nodes)")
 	flagSet.Var(&cfg.AppConfig.ChallengeExpiry, "app.challenge-expiry", "Time after which a challenge expires")
 	flagSet.Float64Var(&cfg.AppConfig.ChallengeRateLimit, "app.challenge-rate-limit", cfg.AppConfig.ChallengeRateLimit, "challenge request rate limit per second per client IP")
 
@@ -49,12 +52,17 @@ func AddConfigFlags(flagSet *pflag.FlagSet, cfg *config.KwildConfig) {
 	flagSet.StringVar(&cfg.AppConfig.DBUser, "app.pg-db-user", cfg.AppConfig.DBUser, "PostgreSQL user name")
 	flagSet.StringVar(&cfg.AppConfig.DBPass, "app.pg-db-pass", cfg.AppConfig.DBPass, "PostgreSQL password name")
 	flagSet.StringVar(&cfg.AppConfig.DBName, "app.pg-db-name", cfg.AppConfig.DBName, "PostgreSQL database name")
+	flagSet.StringSliceVar(&cfg.AppConfig.DBReadReplicas, "app.pg-db-read-replicas", cfg.AppConfig.DBReadReplicas, "PostgreSQL read replica connection strings for read-only RPC requests")
 
 	flagSet.StringVar(&cfg.AppConfig.ProfileMode, "app.profile-mode", cfg.AppConfig.ProfileMode, format("%s profile mode (http, cpu, mem, mutex, or block)"))
 	flagSet.StringVar(&cfg.AppConfig.ProfileFile, "app.profile-file", cfg.AppConfig.ProfileFile, format("%s profile output file path (e.g. cpu.pprof)"))
 
 	flagSet.Var(&cfg.AppConfig.RPCTimeout, "app.rpc-timeout", "timeout for RPC requests (through reading the request, handling the request, and sending the response)")
 	flagSet.IntVar(&cfg.AppConfig.RPCMaxReqSize, "app.rpc-max-req-size", cfg.AppConfig.RPCMaxReqSize, "RPC request size limit")
+	flagSet.Int64Var(&cfg.AppConfig.RPCMaxRows, "app.rpc-max-rows", cfg.AppConfig.RPCMaxRows, "Maximum rows returned by a query or call, and maximum query page size (0 is unlimited)")
+	flagSet.IntVar(&cfg.AppConfig.RPCMaxCursors, "app.rpc-max-cursors", cfg.AppConfig.RPCMaxCursors, "Maximum number of open query_page cursors (0 is unlimited)")
+	flagSet.IntVar(&cfg.AppConfig.RPCMaxCursorsPerIP, "app.rpc-max-cursors-per-ip", cfg.AppConfig.RPCMaxCursorsPerIP, "Maximum number of open query_page cursors per client IP (0 is unlimited)")
+	flagSet.Var(&cfg.AppConfig.RPCCursorTimeout, "app.rpc-cursor-timeout", "idle timeout for query_page cursors")
 	flagSet.IntVar(&cfg.AppConfig.DEPRECATED_RPCReqLimit, "app.rpc-req-limit", cfg.AppConfig.DEPRECATED_RPCReqLimit, "RPC request size limit")
 	flagSet.MarkDeprecated("app.rpc-req-limit", "use --app.rpc-max-req-size instead")
 
@@ -71,6 +79,12 @@ func AddConfigFlags(flagSet *pflag.FlagSet, cfg *config.KwildConfig) {
 	flagSet.Uint64Var(&cfg.AppConfig.Snapshots.RecurringHeight, "app.snapshots.recurring-height", cfg.AppConfig.Snapshots.RecurringHeight, "Recurring heights to create snapshots")
 	flagSet.Uint64Var(&cfg.AppConfig.Snapshots.MaxSnapshots, "app.snapshots.max-snapshots", cfg.AppConfig.Snapshots.MaxSnapshots, "Maximum snapshots to store on disk. Default is 3. If max snapshots is reached, the oldest snapshot is deleted.")
 
+	// Change data capture flags
+	flagSet.BoolVar(&cfg.AppConfig.CDC.Enable, "app.cdc.enable", cfg.AppConfig.CDC.Enable, "Enable the change data capture feed of committed dataset changes")
+	flagSet.Int64Var(&cfg.AppConfig.CDC.RetainBlocks, "app.cdc.retain-blocks", cfg.AppConfig.CDC.RetainBlocks, "Number of recent blocks for which changes are kept (0 keeps all)")
+	flagSet.StringVar(&cfg.AppConfig.CDC.NDJSONFile, "app.cdc.ndjson-file", cfg.AppConfig.CDC.NDJSONFile, "File to append committed changes to as newline-delimited JSON")
+	flagSet.StringSliceVar(&cfg.AppConfig.CDC.WebhookURLs, "app.cdc.webhook-urls", cfg.AppConfig.CDC.WebhookURLs, "URLs to POST the committed changes of each block to")
+
 	// Basic Chain Config flags
 	flagSet.StringVar(&cfg.ChainConfig.Moniker, "chain.moniker", cfg.ChainConfig.Moniker, "Node moniker")
 
@@ -117,6 +131,12 @@ to instead run a dedicated seeder like https://github.com/kwilteam/cometseed.`))
 	flagSet.Var(&cfg.ChainConfig.StateSync.DiscoveryTime, "chain.statesync.discovery-time", "Chain state sync discovery time")
 	flagSet.Var(&cfg.ChainConfig.StateSync.ChunkRequestTimeout, "chain.statesync.chunk-request-timeout", "Chain state sync chunk request timeout")
 
+	// Remote signer flags
+	flagSet.StringVar(&cfg.ChainConfig.RemoteSigner.ListenAddress, "chain.remote-signer.listen-addr", cfg.ChainConfig.RemoteSigner.ListenAddress, "Address on which to listen for a remote signer of votes and proposals to connect (tcp://host:port or unix:///path)")
+	flagSet.StringVar(&cfg.ChainConfig.RemoteSigner.DialAddress, "chain.remote-signer.dial-addr", cfg.ChainConfig.RemoteSigner.DialAddress, "Address of a remote signer of votes and proposals to connect to (tcp://host:port or unix:///path)")
+	flagSet.Var(&cfg.ChainConfig.RemoteSigner.Timeout, "chain.remote-signer.timeout", "Remote signer request read and write timeout")
+	flagSet.Var(&cfg.ChainConfig.RemoteSigner.ConnTimeout, "chain.remote-signer.conn-timeout", "Time to wait at startup for a connection to the remote signer")
+
 	// Instrumentation flags
 	flagSet.BoolVar(&cfg.Instrumentation.Prometheus, "instrumentation.prometheus", cfg.Instrumentation.Prometheus, "collect and serve prometheus metrics")
 	flagSet.StringVar(&cfg.Instrumentation.PromListenAddr, "instrumentation.prometheus-listen-addr", cfg.Instrumentation.PromListenAddr, "listen address for prometheus metrics")
@@ -14,6 +14,7 @@ const (
 
 	configFileName    = "config.toml"
 	migrationsDirName = "migrations"
+	cdcDirName        = "cdc"
 
 	// receivedSnapshotsDirName is the directory where snapshots are received
 	receivedSnapshotsDirName = "received_snapshots"
@@ -62,3 +63,8 @@ func ConfigFilePath(rootDir string) string {
 func MigrationDir(rootDir string) string {
 	return filepath.Join(rootDir, migrationsDirName)
 }
+
+// CDCDir returns the directory where the change data capture log is stored
+func CDCDir(rootDir string) string {
+	return filepath.Join(rootDir, cdcDirName)
+}
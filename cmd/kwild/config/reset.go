@@ -50,6 +50,13 @@ func ResetAll(rootDir string) error {
 		fmt.Println("Error removing all migrations at directory", migrationDir, "err", err)
 	}
 
+	cdcDir := CDCDir(rootDir)
+	if err := os.RemoveAll(cdcDir); err == nil {
+		fmt.Println("Removed change data capture log at directory", cdcDir)
+	} else {
+		fmt.Println("Error removing change data capture log at directory", cdcDir, "err", err)
+	}
+
 	// The user-configurable paths
 
 	// TODO: support postgres database drop or schema drops
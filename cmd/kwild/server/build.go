@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -20,6 +22,7 @@ import (
 	abciTypes "github.com/cometbft/cometbft/abci/types"
 	cmtEd "github.com/cometbft/cometbft/crypto/ed25519"
 	cmtlocal "github.com/cometbft/cometbft/rpc/client/local"
+	cmttypes "github.com/cometbft/cometbft/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 
@@ -35,21 +38,28 @@ import (
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/internal/abci"
 	"github.com/kwilteam/kwil-db/internal/abci/cometbft"
+	"github.com/kwilteam/kwil-db/internal/abci/cometbft/privval"
 	"github.com/kwilteam/kwil-db/internal/abci/meta"
 	"github.com/kwilteam/kwil-db/internal/accounts"
+	"github.com/kwilteam/kwil-db/internal/cdc"
 	"github.com/kwilteam/kwil-db/internal/engine/execution"
 	"github.com/kwilteam/kwil-db/internal/kv/badger"
 	"github.com/kwilteam/kwil-db/internal/listeners"
 	"github.com/kwilteam/kwil-db/internal/migrations"
+	"github.com/kwilteam/kwil-db/internal/scheduler"
 	rpcserver "github.com/kwilteam/kwil-db/internal/services/jsonrpc"
 	"github.com/kwilteam/kwil-db/internal/services/jsonrpc/adminsvc"
 	"github.com/kwilteam/kwil-db/internal/services/jsonrpc/funcsvc"
 	usersvc "github.com/kwilteam/kwil-db/internal/services/jsonrpc/usersvc"
 	"github.com/kwilteam/kwil-db/internal/sql/pg"
+	"github.com/kwilteam/kwil-db/internal/sessions"
+	"github.com/kwilteam/kwil-db/internal/staking"
 	"github.com/kwilteam/kwil-db/internal/statesync"
 	"github.com/kwilteam/kwil-db/internal/txapp"
 	"github.com/kwilteam/kwil-db/internal/voting"
 	"github.com/kwilteam/kwil-db/internal/voting/broadcast"
+	"github.com/kwilteam/kwil-db/internal/wasm"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
 )
 
 var (
@@ -86,6 +96,21 @@ func initStores(d *coreDependencies, db *pg.DB) error {
 	// account store
 	initAccountRepository(d, initTx)
 
+	// session key store
+	initSessionStore(d, initTx)
+
+	// withdrawal store
+	initWithdrawalStore(d, initTx)
+
+	// staking store
+	initStakingStore(d, initTx)
+
+	// scheduler store
+	initSchedulerStore(d, initTx)
+
+	// WebAssembly module store, which must be loaded before the engine
+	initWasmStore(d, initTx)
+
 	if err = initTx.Commit(d.ctx); err != nil {
 		return fmt.Errorf("failed to commit the app initialization DB transaction: %w", err)
 	}
@@ -127,6 +152,12 @@ func buildServer(d *coreDependencies, closers *closeFuncs) *Server {
 	migrator := buildMigrator(d, db, txApp)
 	abciApp := buildAbci(d, db, txApp, snapshotter, statesyncer, p2p, migrator, closers)
 
+	// change data capture feed, which must be set before blocks are replayed
+	changeFeed := buildChangeFeed(d, closers)
+	if changeFeed != nil {
+		abciApp.SetChangeFeed(changeFeed)
+	}
+
 	// NOTE: buildCometNode immediately starts talking to the abciApp and
 	// replaying blocks (and using atomic db tx commits), i.e. calling
 	// FinalizeBlock+Commit. This is not just a constructor, sadly.
@@ -171,12 +202,26 @@ func buildServer(d *coreDependencies, closers *closeFuncs) *Server {
 	totalConsensusTimeouts := d.cfg.ChainConfig.Consensus.TimeoutCommit + d.cfg.ChainConfig.Consensus.TimeoutPrecommit +
 		d.cfg.ChainConfig.Consensus.TimeoutPrevote + d.cfg.ChainConfig.Consensus.TimeoutPropose
 
-	jsonRPCTxSvc := usersvc.NewService(db, e, wrappedCmtClient, txApp, abciApp, migrator,
-		*rpcSvcLogger, usersvc.WithReadTxTimeout(time.Duration(d.cfg.AppConfig.ReadTxTimeout)),
+	// read-only RPC requests may be served by read replicas
+	readDB := buildReadRouter(d, db, closers)
+
+	userSvcOpts := []usersvc.Opt{
+		usersvc.WithReadTxTimeout(time.Duration(d.cfg.AppConfig.ReadTxTimeout)),
 		usersvc.WithPrivateMode(d.cfg.AppConfig.PrivateRPC),
+		usersvc.WithExplain(d.cfg.AppConfig.EnableExplain),
+		usersvc.WithMaxRows(d.cfg.AppConfig.RPCMaxRows),
+		usersvc.WithMaxCursors(d.cfg.AppConfig.RPCMaxCursors, d.cfg.AppConfig.RPCMaxCursorsPerIP),
+		usersvc.WithCursorTimeout(time.Duration(d.cfg.AppConfig.RPCCursorTimeout)),
 		usersvc.WithChallengeExpiry(time.Duration(d.cfg.AppConfig.ChallengeExpiry)),
 		usersvc.WithChallengeRateLimit(d.cfg.AppConfig.ChallengeRateLimit),
-		usersvc.WithBlockAgeHealth(6*totalConsensusTimeouts.Dur()))
+		usersvc.WithBlockAgeHealth(6 * totalConsensusTimeouts.Dur()),
+	}
+	if changeFeed != nil {
+		userSvcOpts = append(userSvcOpts, usersvc.WithChangeFeed(changeFeed))
+	}
+
+	jsonRPCTxSvc := usersvc.NewService(readDB, e, wrappedCmtClient, txApp, abciApp, migrator,
+		*rpcSvcLogger, userSvcOpts...)
 
 	jsonRPCServer, err := rpcserver.NewServer(d.cfg.AppConfig.JSONRPCListenAddress,
 		*rpcServerLogger, rpcserver.WithTimeout(time.Duration(d.cfg.AppConfig.RPCTimeout)),
@@ -192,7 +237,7 @@ func buildServer(d *coreDependencies, closers *closeFuncs) *Server {
 	// admin service and server
 	signer := buildSigner(d)
 	jsonAdminSvc := adminsvc.NewService(db, wrappedCmtClient, txApp, abciApp, p2p, signer, d.cfg,
-		d.genesisCfg.ChainID, *d.log.Named("admin-json-svc"))
+		d.genesisCfg.ChainID, d.genesisCfg.ConsensusParams.Validator.MissedBlocksWindow, *d.log.Named("admin-json-svc"))
 	jsonRPCAdminServer := buildJRPCAdminServer(d)
 	jsonRPCAdminServer.RegisterSvc(jsonAdminSvc)
 	jsonRPCAdminServer.RegisterSvc(jsonRPCTxSvc)
@@ -321,7 +366,7 @@ func verifyDependencies(d *coreDependencies) {
 
 func buildTxApp(d *coreDependencies, db *pg.DB, engine *execution.GlobalContext, ev *voting.EventStore) *txapp.TxApp {
 
-	txApp, err := txapp.NewTxApp(d.ctx, db, engine, buildSigner(d), ev, d.service("txapp"))
+	txApp, err := txapp.NewTxApp(d.ctx, db, engine, buildSigner(d), buildAttesterKey(d), ev, d.service("txapp"))
 	if err != nil {
 		failBuild(err, "failed to build new TxApp")
 	}
@@ -509,8 +554,14 @@ func buildAbci(d *coreDependencies, db *pg.DB, txApp abci.TxApp, snapshotter *st
 		GasEnabled:         !d.genesisCfg.ConsensusParams.WithoutGasCosts,
 		ForkHeights:        d.genesisCfg.ForkHeights,
 		InitialHeight:      d.genesisCfg.InitialHeight,
+		GenesisIdentities:  make(map[string][]byte),
 		ABCIDir:            abciDir,
 	}
+	for _, v := range d.genesisCfg.Validators {
+		if len(v.ConsensusKey) > 0 {
+			cfg.GenesisIdentities[hex.EncodeToString(v.ConsensusKey)] = v.PubKey
+		}
+	}
 
 	app, err := abci.NewAbciApp(d.ctx, cfg, sh, ss, txApp,
 		d.genesisCfg.ConsensusParams, p2p, migrator, db, *d.log.Named("abci"))
@@ -552,6 +603,32 @@ func buildSigner(d *coreDependencies) *auth.Ed25519Signer {
 	return &auth.Ed25519Signer{Ed25519PrivateKey: *pk}
 }
 
+// buildAttesterKey loads the node's withdrawal attestation key. It is derived
+// from the node key only if the operator opted into it. If neither is
+// configured, it returns nil and the node does not attest to withdrawal epochs.
+func buildAttesterKey(d *coreDependencies) *ecdsa.PrivateKey {
+	if d.cfg.AppConfig.AttesterKeyPath != "" {
+		key, err := withdrawals.LoadAttesterKey(d.cfg.AppConfig.AttesterKeyPath)
+		if err != nil {
+			failBuild(err, "failed to load withdrawal attestation key")
+		}
+		return key
+	}
+
+	if d.cfg.AppConfig.DeriveAttesterKey {
+		key, err := withdrawals.DeriveAttesterKey(d.privKey.Bytes())
+		if err != nil {
+			failBuild(err, "failed to derive withdrawal attestation key")
+		}
+		return key
+	}
+
+	if d.genesisCfg.ConsensusParams.Withdrawals.Enabled() {
+		d.log.Warn("no withdrawal attestation key is configured, this node will not attest to withdrawal epochs")
+	}
+	return nil
+}
+
 func buildDB(d *coreDependencies, closer *closeFuncs) *pg.DB {
 	// Check if the database is supposed to be restored from the snapshot
 	// If yes, restore the database from the snapshot
@@ -584,6 +661,53 @@ func buildDB(d *coreDependencies, closer *closeFuncs) *pg.DB {
 	return db
 }
 
+// buildReadRouter opens the configured read replicas, returning a router that
+// creates read transactions on a replica that is caught up with the main DB.
+func buildReadRouter(d *coreDependencies, db *pg.DB, closer *closeFuncs) *pg.ReadRouter {
+	var replicas []sql.ReadTxMaker
+	for i, connStr := range d.cfg.AppConfig.DBReadReplicas {
+		replica, err := pg.NewReplicaPool(d.ctx, connStr, 24)
+		if err != nil {
+			failBuild(err, fmt.Sprintf("failed to open read replica %d", i))
+		}
+		closer.addCloser(replica.Close, fmt.Sprintf("closing read replica %d", i))
+		replicas = append(replicas, replica)
+	}
+
+	if len(replicas) > 0 {
+		d.log.Infof("Using %d read replicas for RPC reads", len(replicas))
+	}
+
+	return pg.NewReadRouter(db, replicas, func(ctx context.Context, tx sql.Executor) (int64, error) {
+		height, _, err := meta.GetChainState(ctx, tx)
+		return height, err
+	})
+}
+
+// buildChangeFeed opens the change data capture log and its sinks, if enabled.
+func buildChangeFeed(d *coreDependencies, closers *closeFuncs) *cdc.Feed {
+	cfg := d.cfg.AppConfig.CDC
+	if !cfg.Enable {
+		return nil
+	}
+
+	var sinks []cdc.Sink
+	if cfg.NDJSONFile != "" {
+		sinks = append(sinks, cdc.NewNDJSONSink(cfg.NDJSONFile))
+	}
+	for _, url := range cfg.WebhookURLs {
+		sinks = append(sinks, cdc.NewWebhookSink(url))
+	}
+
+	feed, err := cdc.NewFeed(kwildcfg.CDCDir(d.cfg.RootDir), cfg.RetainBlocks, sinks, *d.log.Named("cdc"))
+	if err != nil {
+		failBuild(err, "failed to open change data capture log")
+	}
+	closers.addCloser(feed.Close, "closing change data capture feed")
+
+	return feed
+}
+
 // restoreDB restores the database from a snapshot if the genesis apphash is specified.
 // Genesis apphash ensures that all the nodes in the network start from the same state.
 // Genesis apphash should match the hash of the snapshot file.
@@ -724,6 +848,46 @@ func initAccountRepository(d *coreDependencies, tx sql.Tx) {
 	}
 }
 
+func initSessionStore(d *coreDependencies, tx sql.Tx) {
+	err := sessions.InitializeSessionStore(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to initialize session store")
+	}
+}
+
+func initWithdrawalStore(d *coreDependencies, tx sql.Tx) {
+	err := withdrawals.InitializeWithdrawalStore(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to initialize withdrawal store")
+	}
+}
+
+func initStakingStore(d *coreDependencies, tx sql.Tx) {
+	err := staking.InitializeStakingStore(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to initialize staking store")
+	}
+}
+
+func initSchedulerStore(d *coreDependencies, tx sql.Tx) {
+	err := scheduler.InitializeSchedulerStore(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to initialize scheduler store")
+	}
+}
+
+func initWasmStore(d *coreDependencies, tx sql.Tx) {
+	err := wasm.InitializeWasmStore(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to initialize WebAssembly module store")
+	}
+
+	err = wasm.LoadModules(d.ctx, tx)
+	if err != nil {
+		failBuild(err, "failed to load WebAssembly modules")
+	}
+}
+
 func buildSnapshotter(d *coreDependencies) *statesync.SnapshotStore {
 	cfg := d.cfg.AppConfig
 	if !cfg.Snapshots.Enable {
@@ -1051,8 +1215,9 @@ func buildCometNode(d *coreDependencies, closer *closeFuncs, abciApp abciTypes.A
 	}
 
 	nodeLogger := increaseLogLevel("cometbft", &d.log, d.cfg.Logging.ConsensusLevel)
+	privValidator := buildPrivValidator(d, closer, genDoc.ChainID, readWriter, nodeLogger)
 	node, err := cometbft.NewCometBftNode(d.ctx, abciApp, nodeCfg, genDoc, d.privKey,
-		readWriter, nodeLogger)
+		privValidator, nodeLogger)
 	if err != nil {
 		failBuild(err, "failed to build comet node")
 	}
@@ -1060,6 +1225,44 @@ func buildCometNode(d *coreDependencies, closer *closeFuncs, abciApp abciTypes.A
 	return node
 }
 
+// buildPrivValidator creates the signer of the validator's votes and
+// proposals. This is the node's private key, unless a remote signer is
+// configured.
+func buildPrivValidator(d *coreDependencies, closer *closeFuncs, chainID string,
+	readWriter privval.AtomicReadWriter, logger *log.Logger) cmttypes.PrivValidator {
+	remoteCfg := d.cfg.ChainConfig.RemoteSigner
+	if !remoteCfg.Enabled() {
+		signer, err := privval.NewValidatorSigner(d.privKey, readWriter)
+		if err != nil {
+			failBuild(err, "failed to create private validator")
+		}
+		return signer
+	}
+
+	d.log.Info("Waiting for remote signer to connect.",
+		log.String("listen_addr", remoteCfg.ListenAddress), log.String("dial_addr", remoteCfg.DialAddress))
+	signer, err := privval.NewRemoteSigner(&privval.RemoteSignerConfig{
+		ListenAddr:  remoteCfg.ListenAddress,
+		DialAddr:    remoteCfg.DialAddress,
+		ConnKey:     d.privKey,
+		Timeout:     remoteCfg.Timeout.Dur(),
+		ConnTimeout: remoteCfg.ConnTimeout.Dur(),
+	}, chainID, readWriter, cometbft.NewLogWrapper(logger).With("module", "privval"))
+	if err != nil {
+		failBuild(err, "failed to connect to remote signer")
+	}
+	closer.addCloser(signer.Close, "closing remote signer connection")
+
+	// The node's key is still its identity for p2p and for the transactions it
+	// signs, such as resolution votes. The remote signer's key is registered
+	// as the validator's consensus key when it joins, or in the genesis
+	// validators.
+	pubKey, _ := signer.GetPubKey()
+	d.log.Info("Connected to remote signer.", log.String("consensus_key", hex.EncodeToString(pubKey.Bytes())))
+
+	return signer
+}
+
 // panicErr is the type given to panic from failBuild so that the wrapped error
 // may be type-inspected.
 type panicErr struct {
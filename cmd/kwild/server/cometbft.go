@@ -161,6 +161,12 @@ func extractGenesisDoc(g *chain.GenesisConfig) (*cmttypes.GenesisDoc, error) {
 			return nil, fmt.Errorf("pubkey is incorrect size: %v", v.PubKey.String())
 		}
 		pubKey := cmtEd.PubKey(v.PubKey)
+		if len(v.ConsensusKey) > 0 {
+			if len(v.ConsensusKey) != cmtEd.PubKeySize {
+				return nil, fmt.Errorf("consensus key is incorrect size: %v", v.ConsensusKey.String())
+			}
+			pubKey = cmtEd.PubKey(v.ConsensusKey)
+		}
 		genDoc.Validators = append(genDoc.Validators, cmttypes.GenesisValidator{
 			Address: pubKey.Address(),
 			PubKey:  pubKey,
@@ -202,9 +202,10 @@ func (m *migrationClient) downloadGenesisState(ctx context.Context) error {
 	if len(m.genesisCfg.Validators) == 0 {
 		for _, v := range metadata.GenesisInfo.Validators {
 			m.genesisCfg.Validators = append(m.genesisCfg.Validators, &chain.GenesisValidator{
-				Name:   v.Name,
-				PubKey: v.PubKey,
-				Power:  v.Power,
+				Name:         v.Name,
+				PubKey:       v.PubKey,
+				Power:        v.Power,
+				ConsensusKey: v.ConsensusKey,
 			})
 		}
 	} else {
@@ -66,6 +66,9 @@ type GenesisValidator struct {
 	PubKey HexBytes `json:"pub_key"`
 	Power  int64    `json:"power"`
 	Name   string   `json:"name"`
+	// ConsensusKey is the key that signs the validator's votes and proposals,
+	// if it is not PubKey, such as when they are signed by a remote signer.
+	ConsensusKey HexBytes `json:"consensus_key,omitempty"`
 }
 
 type BaseConsensusParams struct {
@@ -75,6 +78,8 @@ type BaseConsensusParams struct {
 	Votes     VoteParams      `json:"votes"`
 	ABCI      ABCIParams      `json:"abci"`
 	Migration MigrationParams `json:"migration"`
+
+	Withdrawals WithdrawalParams `json:"withdrawals"`
 }
 
 // ConsensusParams combines BaseConsensusParams with WithoutGasCosts.
@@ -111,6 +116,47 @@ type ValidatorParams struct {
 	// JoinExpiry is the number of blocks after which the validators join
 	// request expires if not approved.
 	JoinExpiry int64 `json:"join_expiry"`
+
+	// MissedBlocksWindow is the number of most recent blocks in which a
+	// validator's missed block signatures are counted. If zero, validator
+	// liveness is not tracked, and validators are never jailed.
+	MissedBlocksWindow int64 `json:"missed_blocks_window,omitempty"`
+
+	// MaxMissedBlocks is the number of blocks a validator may miss in the
+	// window before it is jailed, which removes its voting power.
+	MaxMissedBlocks int64 `json:"max_missed_blocks,omitempty"`
+
+	// JailPeriod is the number of blocks after being jailed before a
+	// validator may unjail itself.
+	JailPeriod int64 `json:"jail_period,omitempty"`
+
+	// RemoveOnMisbehavior removes a validator that is reported by the
+	// consensus engine for misbehavior, such as double signing. If false, its
+	// power is reduced by one.
+	RemoveOnMisbehavior bool `json:"remove_on_misbehavior,omitempty"`
+
+	// SlashAmount is the amount of tokens burned from a validator that is
+	// reported for misbehavior. With Staking, it is taken from the validator's
+	// stake. Otherwise, it is burned from the validator's liquid account
+	// balance, up to the balance. If nil or zero, validators are not slashed.
+	SlashAmount *big.Int `json:"slash_amount,omitempty"`
+
+	// Staking derives the power of validators from the tokens they have
+	// staked. Validators must still be approved to join the validator set.
+	Staking bool `json:"staking,omitempty"`
+
+	// MinStake is the minimum stake of a validator when Staking is enabled.
+	// A validator with less stake has no power.
+	MinStake *big.Int `json:"min_stake,omitempty"`
+
+	// StakePerPower is the amount of stake per unit of validator power. It is
+	// required with Staking, since token amounts would otherwise exceed the
+	// consensus engine's limit on the total power.
+	StakePerPower *big.Int `json:"stake_per_power,omitempty"`
+
+	// UnbondingPeriod is the number of blocks after unstaking before the
+	// tokens are returned to the staker's balance.
+	UnbondingPeriod int64 `json:"unbonding_period,omitempty"`
 }
 
 type VoteParams struct {
@@ -130,6 +176,23 @@ type MigrationParams struct {
 	EndHeight int64 `json:"end_height,omitempty"`
 }
 
+type WithdrawalParams struct {
+	// EpochLength is the number of blocks in a withdrawal epoch. Withdrawals
+	// in an epoch are attested to by the validators when it ends.
+	EpochLength int64 `json:"epoch_length"`
+
+	// Contract is the 20 byte address of the Ethereum bridge contract that
+	// releases withdrawn tokens. It is part of the message the validators
+	// sign, so attestations cannot be replayed to another contract. If it is
+	// not set, withdrawals are disabled.
+	Contract HexBytes `json:"contract,omitempty"`
+}
+
+// Enabled returns true if withdrawals are enabled.
+func (w *WithdrawalParams) Enabled() bool {
+	return len(w.Contract) != 0
+}
+
 // IsMigration returns true if the migration parameters are set.
 func (m *MigrationParams) IsMigration() bool {
 	return m.StartHeight != 0 && m.EndHeight != 0
@@ -153,6 +216,12 @@ func defaultConsensusParams() *ConsensusParams {
 			Validator: ValidatorParams{
 				PubKeyTypes: []string{abciPubKeyTypeEd25519},
 				JoinExpiry:  100800, // approx 1 week considering block rate of 6 sec/blk
+
+				MissedBlocksWindow: 10000,
+				MaxMissedBlocks:    9500,  // jailed if signing fewer than 5% of the window
+				JailPeriod:         14400, // approx 1 day considering block rate of 6 sec/blk
+
+				RemoveOnMisbehavior: true,
 			},
 			Votes: VoteParams{
 				VoteExpiry:    14400, // approx 1 day considering block rate of 6 sec/blk
@@ -161,6 +230,9 @@ func defaultConsensusParams() *ConsensusParams {
 			ABCI: ABCIParams{
 				VoteExtensionsEnableHeight: 0, // disabled, needs coordinated upgrade to enable
 			},
+			Withdrawals: WithdrawalParams{
+				EpochLength: 600, // approx 1 hour considering block rate of 6 sec/blk
+			},
 		},
 		WithoutGasCosts: true,
 	}
@@ -299,6 +371,39 @@ func (gc *GenesisConfig) SanityChecks() error {
 		return errors.New("join expiry should be greater than 0")
 	}
 
+	// liveness tracking is disabled with a zero window
+	valParams := gc.ConsensusParams.Validator
+	if valParams.MissedBlocksWindow < 0 || valParams.MaxMissedBlocks < 0 || valParams.JailPeriod < 0 {
+		return errors.New("validator liveness params should not be negative")
+	}
+	if valParams.MissedBlocksWindow > 0 && valParams.MaxMissedBlocks >= valParams.MissedBlocksWindow {
+		return errors.New("max missed blocks should be less than the missed blocks window")
+	}
+	if valParams.SlashAmount != nil && valParams.SlashAmount.Sign() < 0 {
+		return errors.New("slash amount should not be negative")
+	}
+	if valParams.UnbondingPeriod < 0 {
+		return errors.New("unbonding period should not be negative")
+	}
+	if (valParams.MinStake != nil && valParams.MinStake.Sign() < 0) ||
+		(valParams.StakePerPower != nil && valParams.StakePerPower.Sign() < 0) {
+		return errors.New("staking amounts should not be negative")
+	}
+	if valParams.Staking && (valParams.StakePerPower == nil || valParams.StakePerPower.Sign() == 0) {
+		return errors.New("stake per power should be set when staking is enabled")
+	}
+
+	// withdrawals are disabled without a contract
+	wdParams := gc.ConsensusParams.Withdrawals
+	if wdParams.Enabled() {
+		if len(wdParams.Contract) != 20 {
+			return errors.New("withdrawal contract should be a 20 byte address")
+		}
+		if wdParams.EpochLength <= 0 {
+			return errors.New("withdrawal epoch length should be greater than 0")
+		}
+	}
+
 	// Block params
 	if gc.ConsensusParams.Block.MaxBytes == 0 {
 		return errors.New("max bytes should be greater than 0")
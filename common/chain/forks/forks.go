@@ -162,6 +162,13 @@ func (fs *Forks) ForkHeight(fork string) *uint64 {
 	return fs.Extended[fork]
 }
 
+// IsActive returns true if the named fork's rule changes are in effect *as of*
+// the given height. A fork that never activates is never active.
+func (fs *Forks) IsActive(fork string, height uint64) bool {
+	h := fs.ForkHeight(fork)
+	return h != nil && height >= *h
+}
+
 // String displays a human readable summary of all defined forks and their
 // activation heights. For example:
 //
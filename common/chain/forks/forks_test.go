@@ -156,6 +156,11 @@ func TestForks_Height(t *testing.T) {
 
 	hp = fs.ForkHeight("unknown")
 	require.Nil(t, hp)
+
+	assert.False(t, fs.IsActive("extended", 5))
+	assert.True(t, fs.IsActive("extended", 6))
+	assert.True(t, fs.IsActive(forks.ForkHalt, 11))
+	assert.False(t, fs.IsActive("notdefined", 100))
 }
 
 func TestForks_FromMap(t *testing.T) {
@@ -122,6 +122,10 @@ type ExecutionData struct {
 	// Args are the arguments that were passed to the procedure.
 	// Currently these are all string or untyped nil values.
 	Args []any
+
+	// UsedGas is set by the engine to the gas used by the execution, including
+	// the gas used by built-in functions such as keccak256.
+	UsedGas uint64
 }
 
 func (e *ExecutionData) Clean() error {
@@ -48,6 +48,12 @@ type AppConfig struct {
 
 	PrivateKeyPath string `mapstructure:"private_key_path"`
 
+	// AttesterKeyPath is the path to the hex encoded secp256k1 key that the
+	// node attests to withdrawal epochs with. If it is empty, the key is
+	// derived from the node key only if DeriveAttesterKey is set.
+	AttesterKeyPath   string `mapstructure:"attester_key_path"`
+	DeriveAttesterKey bool   `mapstructure:"derive_attester_key"`
+
 	// PostgreSQL DB settings. DBName is the name if the PostgreSQL database to
 	// connect to. The different data stores (e.g. engine, acct store, event
 	// store, etc.) are all in the same database. Assuming "kwild" is the
@@ -66,9 +72,21 @@ type AppConfig struct {
 	DBPass string `mapstructure:"pg_db_pass"`
 	DBName string `mapstructure:"pg_db_name"`
 
+	// DBReadReplicas are connection strings, in either the DSN or URL format,
+	// for read replicas of the above database, such as hot standbys using
+	// streaming replication. Read-only RPC requests are served by a replica
+	// that has applied at least the last committed block, or by the primary
+	// database if none has.
+	DBReadReplicas []string `mapstructure:"pg_db_read_replicas"`
+
 	RPCTimeout         Duration                     `mapstructure:"rpc_timeout"`
 	RPCMaxReqSize      int                          `mapstructure:"rpc_max_req_size"`
+	RPCMaxRows         int64                        `mapstructure:"rpc_max_rows"`
+	RPCMaxCursors      int                          `mapstructure:"rpc_max_cursors"`
+	RPCMaxCursorsPerIP int                          `mapstructure:"rpc_max_cursors_per_ip"`
+	RPCCursorTimeout   Duration                     `mapstructure:"rpc_cursor_timeout"`
 	PrivateRPC         bool                         `mapstructure:"private_rpc"`
+	EnableExplain      bool                         `mapstructure:"enable_explain"`
 	ChallengeExpiry    Duration                     `mapstructure:"challenge_expiry"`
 	ChallengeRateLimit float64                      `mapstructure:"challenge_rate_limit"`
 	ReadTxTimeout      Duration                     `mapstructure:"db_read_timeout"`
@@ -89,6 +107,8 @@ type AppConfig struct {
 
 	Snapshots SnapshotConfig `mapstructure:"snapshots"`
 
+	CDC CDCConfig `mapstructure:"cdc"`
+
 	// GenesisState is the path to the snapshot file containing genesis state
 	// to be loaded on startup during network initialization. If genesis app_hash
 	// is not provided, this snapshot file is not used.
@@ -108,6 +128,20 @@ type SnapshotConfig struct {
 	MaxSnapshots       uint64 `mapstructure:"max_snapshots"`
 }
 
+// CDCConfig configures the change data capture feed of the dataset changes
+// committed in each block.
+type CDCConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// RetainBlocks is the number of most recent blocks for which changes are
+	// kept. Zero keeps all changes.
+	RetainBlocks int64 `mapstructure:"retain_blocks"`
+	// NDJSONFile is a file to which all changes are appended as
+	// newline-delimited JSON.
+	NDJSONFile string `mapstructure:"ndjson_file"`
+	// WebhookURLs are URLs to which the changes for each block are POSTed.
+	WebhookURLs []string `mapstructure:"webhook_urls"`
+}
+
 type ChainRPCConfig struct {
 	// TCP or UNIX socket address for the RPC server to listen on
 	ListenAddress string `mapstructure:"listen_addr"`
@@ -206,15 +240,41 @@ type StateSyncConfig struct {
 	ChunkRequestTimeout Duration `mapstructure:"chunk_request_timeout"`
 }
 
+// RemoteSignerConfig configures an external signing process, such as tmkms,
+// that holds the validator's consensus key and signs votes and proposals using
+// CometBFT's remote signer protocol. If neither address is set, votes and
+// proposals are signed with the node's private key.
+type RemoteSignerConfig struct {
+	// ListenAddress is the address (tcp://host:port or unix:///path) on which
+	// to listen for the remote signer to connect. This is how tmkms and most
+	// remote signers connect to a validator.
+	ListenAddress string `mapstructure:"listen_addr"`
+	// DialAddress is the address (tcp://host:port or unix:///path) of a remote
+	// signer that listens for the node to connect. Only one of ListenAddress
+	// and DialAddress may be set.
+	DialAddress string `mapstructure:"dial_addr"`
+	// Timeout is the read and write timeout for requests to the remote signer.
+	Timeout Duration `mapstructure:"timeout"`
+	// ConnTimeout is how long to wait at startup for a connection to the
+	// remote signer.
+	ConnTimeout Duration `mapstructure:"conn_timeout"`
+}
+
+// Enabled returns true if a remote signer is configured.
+func (c *RemoteSignerConfig) Enabled() bool {
+	return c != nil && (c.ListenAddress != "" || c.DialAddress != "")
+}
+
 type ChainConfig struct {
 	Moniker string `mapstructure:"moniker"`
 	// DBPath  string `mapstructure:"db_dir"` // internal/abci knows this
 
-	RPC       *ChainRPCConfig  `mapstructure:"rpc"`
-	P2P       *P2PConfig       `mapstructure:"p2p"`
-	Mempool   *MempoolConfig   `mapstructure:"mempool"`
-	StateSync *StateSyncConfig `mapstructure:"statesync"`
-	Consensus *ConsensusConfig `mapstructure:"consensus"`
+	RPC          *ChainRPCConfig     `mapstructure:"rpc"`
+	P2P          *P2PConfig          `mapstructure:"p2p"`
+	Mempool      *MempoolConfig      `mapstructure:"mempool"`
+	StateSync    *StateSyncConfig    `mapstructure:"statesync"`
+	Consensus    *ConsensusConfig    `mapstructure:"consensus"`
+	RemoteSigner *RemoteSignerConfig `mapstructure:"remote_signer"`
 }
 
 // toml package does not support time.Duration, since time is not part of TOML spec
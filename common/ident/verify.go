@@ -1,6 +1,9 @@
 package ident
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
 )
@@ -20,6 +23,16 @@ func verify(obj msgSerializer, identity []byte, sig *auth.Signature) error {
 // VerifyTransaction verifies a transaction's signature using the Authenticator
 // registry in this package.
 func VerifyTransaction(tx *transactions.Transaction) error {
+	if tx.Signature == nil {
+		return errors.New("transaction is not signed")
+	}
+	// The EIP-712 serialization is only signed with EIP-712 signatures, and
+	// EIP-712 signatures are only of that serialization. Otherwise a typed
+	// data message could be verified as signed text, or the reverse.
+	if (tx.Serialization == transactions.SignedMsgEip712) != (tx.Signature.Type == auth.EthEip712Auth) {
+		return fmt.Errorf("signature type %q may not be used with serialization %q",
+			tx.Signature.Type, tx.Serialization)
+	}
 	return verify(tx, tx.Sender, tx.Signature)
 }
 
@@ -10,8 +10,25 @@ import (
 var (
 	ErrNoTransaction = errors.New("no transaction")
 	ErrNoRows        = errors.New("no rows in result set")
+	ErrTooManyRows   = errors.New("too many rows in result set")
 )
 
+type maxRowsKey struct{}
+
+// WithMaxRows returns a context that limits the number of rows a query may
+// return. A query that would return more rows fails with ErrTooManyRows as soon
+// as the limit is exceeded, rather than after the entire result is read. Zero
+// is unlimited.
+func WithMaxRows(ctx context.Context, maxRows int64) context.Context {
+	return context.WithValue(ctx, maxRowsKey{}, maxRows)
+}
+
+// MaxRows returns the row limit set with WithMaxRows, or zero if there is none.
+func MaxRows(ctx context.Context) int64 {
+	maxRows, _ := ctx.Value(maxRowsKey{}).(int64)
+	return maxRows
+}
+
 // ResultSet is the result of a query or execution.
 // It contains the returned columns and the rows.
 type ResultSet struct {
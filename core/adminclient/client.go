@@ -58,6 +58,10 @@ type adminSvcClient interface {
 	GenesisSnapshotChunk(ctx context.Context, height uint64, chunkIdx uint32) ([]byte, error)
 	LoadChangeset(ctx context.Context, height int64, index int64) ([]byte, error)
 	ChangesetMetadata(ctx context.Context, height int64) (int64, []int64, error)
+
+	// resolution methods
+	ListResolutions(ctx context.Context, filter *types.ResolutionFilter, offset, limit int64) ([]*types.ResolutionInfo, int64, error)
+	Resolution(ctx context.Context, id *types.UUID) (*types.ResolutionInfo, error)
 }
 
 // defaultTransport constructs a new http.Transport that is equivalent to the
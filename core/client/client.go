@@ -188,6 +188,97 @@ func (c *Client) Transfer(ctx context.Context, to []byte, amount *big.Int, opts
 	return c.txClient.Broadcast(ctx, tx, syncBcastFlag(txOpts.SyncBcast))
 }
 
+// Withdraw withdraws balance to an Ethereum address. The amount is removed from
+// the account, and can be claimed from the bridge contract with the proof
+// returned by WithdrawalProof once the withdrawal's epoch is confirmed.
+func (c *Client) Withdraw(ctx context.Context, recipient []byte, amount *big.Int, opts ...clientType.TxOpt) (transactions.TxHash, error) {
+	if len(recipient) != 20 {
+		return nil, fmt.Errorf("recipient must be a 20 byte Ethereum address, got %d bytes", len(recipient))
+	}
+
+	acct, err := c.txClient.GetAccount(ctx, c.Signer.Identity(), types.AccountStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	nonceOpt := clientType.WithNonce(acct.Nonce + 1)
+	opts = append([]clientType.TxOpt{nonceOpt}, opts...) // prepend in case caller specified a nonce
+	txOpts := clientType.GetTxOpts(opts)
+
+	withdraw := &transactions.Withdraw{
+		Recipient: recipient,
+		Amount:    amount.String(),
+	}
+	tx, err := c.newTx(ctx, withdraw, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSpend := big.NewInt(0).Add(tx.Body.Fee, amount)
+	if totalSpend.Cmp(acct.Balance) > 0 {
+		return nil, fmt.Errorf("withdraw amount plus fees (%v) larger than balance (%v)", totalSpend, acct.Balance)
+	}
+
+	c.logger.Debug("withdraw", zap.String("recipient", hex.EncodeToString(recipient)),
+		zap.String("amount", amount.String()))
+
+	return c.txClient.Broadcast(ctx, tx, syncBcastFlag(txOpts.SyncBcast))
+}
+
+// Stake stakes balance on a network with staking enabled. The amount is removed
+// from the account, and determines the account's validator power if it is or
+// becomes a validator.
+func (c *Client) Stake(ctx context.Context, amount *big.Int, opts ...clientType.TxOpt) (transactions.TxHash, error) {
+	acct, err := c.txClient.GetAccount(ctx, c.Signer.Identity(), types.AccountStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	nonceOpt := clientType.WithNonce(acct.Nonce + 1)
+	opts = append([]clientType.TxOpt{nonceOpt}, opts...) // prepend in case caller specified a nonce
+	txOpts := clientType.GetTxOpts(opts)
+
+	stake := &transactions.Stake{
+		Amount: amount.String(),
+	}
+	tx, err := c.newTx(ctx, stake, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSpend := big.NewInt(0).Add(tx.Body.Fee, amount)
+	if totalSpend.Cmp(acct.Balance) > 0 {
+		return nil, fmt.Errorf("stake amount plus fees (%v) larger than balance (%v)", totalSpend, acct.Balance)
+	}
+
+	c.logger.Debug("stake", zap.String("amount", amount.String()))
+
+	return c.txClient.Broadcast(ctx, tx, syncBcastFlag(txOpts.SyncBcast))
+}
+
+// Unstake unstakes balance on a network with staking enabled. The amount is
+// returned to the account once the network's unbonding period is over.
+func (c *Client) Unstake(ctx context.Context, amount *big.Int, opts ...clientType.TxOpt) (transactions.TxHash, error) {
+	txOpts := clientType.GetTxOpts(opts)
+	unstake := &transactions.Unstake{
+		Amount: amount.String(),
+	}
+	tx, err := c.newTx(ctx, unstake, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug("unstake", zap.String("amount", amount.String()))
+
+	return c.txClient.Broadcast(ctx, tx, syncBcastFlag(txOpts.SyncBcast))
+}
+
+// WithdrawalProof returns the proof of a withdrawal, given the hash of the
+// withdraw transaction. The proof's Root and Signatures are set once the
+// withdrawal's epoch has ended, and it may be submitted to the bridge contract
+// once Confirmed.
+func (c *Client) WithdrawalProof(ctx context.Context, txHash []byte) (*types.WithdrawalProof, error) {
+	return c.txClient.WithdrawalProof(ctx, txHash)
+}
+
 // ChainInfo get the current blockchain information like chain ID and best block
 // height/hash.
 func (c *Client) ChainInfo(ctx context.Context) (*types.ChainInfo, error) {
@@ -355,6 +446,37 @@ func (c *Client) Query(ctx context.Context, dbid string, query string) (*clientT
 	return clientType.NewRecordsFromMaps(res), nil
 }
 
+// ExplainQuery returns the Kwil logical plan and the PostgreSQL query plan for
+// an ad-hoc query. The node must have explain enabled.
+func (c *Client) ExplainQuery(ctx context.Context, dbid string, query string) (*types.StatementPlan, error) {
+	plans, err := c.txClient.Explain(ctx, dbid, query, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) != 1 {
+		return nil, fmt.Errorf("expected 1 plan, got %d", len(plans))
+	}
+
+	return plans[0], nil
+}
+
+// ExplainProcedure returns the query plans for each SQL statement in a
+// procedure. The node must have explain enabled.
+func (c *Client) ExplainProcedure(ctx context.Context, dbid string, procedure string) ([]*types.StatementPlan, error) {
+	return c.txClient.Explain(ctx, dbid, "", procedure)
+}
+
+// Changes returns the changes to a dataset, or one of its tables if table is
+// not empty, committed in blocks starting at fromHeight. Changes are returned
+// for whole blocks, stopping before the block that would exceed limit, unless
+// it is the first. Use the returned height as fromHeight to get the following
+// changes, even if no changes were returned. The node must have change data
+// capture enabled.
+func (c *Client) Changes(ctx context.Context, dbid, table string, fromHeight, limit int64) ([]*types.RowChange, int64, error) {
+	changes, next, _, err := c.txClient.Changes(ctx, dbid, table, fromHeight, limit)
+	return changes, next, err
+}
+
 // ListDatabases lists databases belonging to an owner.
 // If no owner is passed, it will list all databases.
 func (c *Client) ListDatabases(ctx context.Context, owner []byte) ([]*types.DatasetIdentifier, error) {
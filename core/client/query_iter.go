@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+
+	clientType "github.com/kwilteam/kwil-db/core/types/client"
+)
+
+// QueryIterator iterates over the results of an ad-hoc query, transparently
+// fetching pages from the node as they are needed. All records are read from
+// the same database snapshot. Use Next to advance to each record, and check Err
+// when Next returns false.
+//
+// The node closes the query's cursor once the last page is fetched, or if the
+// cursor is idle for too long, so there is nothing to close if iteration is
+// abandoned.
+type QueryIterator struct {
+	ctx      context.Context
+	client   *Client
+	dbid     string
+	query    string
+	pageSize int64
+
+	started bool
+	cursor  string
+	height  int64
+	page    []map[string]any
+	index   int
+	err     error
+}
+
+// QueryIter executes an ad-hoc query, returning an iterator over the results
+// that fetches up to pageSize records at a time. If pageSize is zero, the
+// node's default page size is used. No request is made until the first call to
+// Next.
+func (c *Client) QueryIter(ctx context.Context, dbid string, query string, pageSize int64) *QueryIterator {
+	return &QueryIterator{
+		ctx:      ctx,
+		client:   c,
+		dbid:     dbid,
+		query:    query,
+		pageSize: pageSize,
+		index:    -1,
+	}
+}
+
+// Next advances to the next record, fetching the next page if needed. It
+// returns false when there are no more records or if a request fails, in which
+// case Err returns the error.
+func (it *QueryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.page) {
+		// The first page is requested without a cursor. Subsequent pages
+		// require the cursor returned with the previous page.
+		if it.started && it.cursor == "" {
+			return false
+		}
+
+		page, cursor, height, err := it.client.txClient.QueryPage(it.ctx, it.dbid, it.query, it.cursor, it.pageSize)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.started = true
+		it.page, it.cursor, it.height = page, cursor, height
+		it.index = 0
+	}
+
+	return true
+}
+
+// Record returns the current record. Use Next to advance the iterator.
+func (it *QueryIterator) Record() clientType.Record {
+	if it.index < 0 || it.index >= len(it.page) {
+		return clientType.Record{}
+	}
+
+	return it.page[it.index]
+}
+
+// Height returns the height of the block following which the database
+// snapshot was taken. It is zero until the first page is fetched.
+func (it *QueryIterator) Height() int64 {
+	return it.height
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *QueryIterator) Err() error {
+	return it.err
+}
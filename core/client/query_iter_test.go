@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/core/rpc/client/user"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedTxSvc serves pages of a fixed set of rows through QueryPage.
+type pagedTxSvc struct {
+	user.TxSvcClient // only QueryPage is used
+
+	rows     []map[string]any
+	failAt   int // fail the page request starting at this row, if > 0
+	requests int
+}
+
+func (p *pagedTxSvc) QueryPage(_ context.Context, _, _, cursor string, limit int64) ([]map[string]any, string, int64, error) {
+	p.requests++
+
+	start := 0
+	if cursor != "" {
+		start = int(cursor[0] - '0')
+	}
+	if p.failAt > 0 && start >= p.failAt {
+		return nil, "", 0, errors.New("cursor expired")
+	}
+
+	end := min(start+int(limit), len(p.rows))
+	var next string
+	if end-start == int(limit) {
+		next = string(rune('0' + end))
+	}
+
+	return p.rows[start:end], next, 42, nil
+}
+
+func Test_QueryIterator(t *testing.T) {
+	rows := make([]map[string]any, 5)
+	for i := range rows {
+		rows[i] = map[string]any{"id": int64(i)}
+	}
+
+	tests := []struct {
+		name     string
+		rows     []map[string]any
+		pageSize int64
+		failAt   int
+		want     int
+		requests int
+		wantErr  bool
+	}{
+		{"partial last page", rows, 2, 0, 5, 3, false},
+		{"empty last page", rows[:4], 2, 0, 4, 3, false},
+		{"single page", rows, 10, 0, 5, 1, false},
+		{"no rows", nil, 2, 0, 0, 1, false},
+		{"error on second page", rows, 2, 2, 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &pagedTxSvc{rows: tt.rows, failAt: tt.failAt}
+			c := &Client{txClient: svc}
+
+			it := c.QueryIter(context.Background(), "dbid", "SELECT * FROM t", tt.pageSize)
+			require.Zero(t, it.Height())
+
+			var got int
+			for it.Next() {
+				require.Equal(t, int64(got), it.Record()["id"])
+				got++
+			}
+
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.requests, svc.requests)
+			if tt.wantErr {
+				require.Error(t, it.Err())
+				require.False(t, it.Next())
+			} else {
+				require.NoError(t, it.Err())
+				require.Equal(t, int64(42), it.Height())
+			}
+		})
+	}
+}
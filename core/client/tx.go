@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/types"
 	clientType "github.com/kwilteam/kwil-db/core/types/client"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
@@ -20,6 +21,16 @@ func (c *Client) NewSignedTx(ctx context.Context, data transactions.Payload, txO
 	return c.newTx(ctx, data, txOpts)
 }
 
+// Broadcast broadcasts a signed transaction, such as one signed offline or by
+// several signers. Only the SyncBcast TxOption is used.
+func (c *Client) Broadcast(ctx context.Context, tx *transactions.Transaction, opts ...clientType.TxOpt) (transactions.TxHash, error) {
+	if tx.Body == nil || tx.Signature == nil {
+		return nil, fmt.Errorf("transaction must be signed to broadcast")
+	}
+	txOpts := clientType.GetTxOpts(opts)
+	return c.txClient.Broadcast(ctx, tx, syncBcastFlag(txOpts.SyncBcast))
+}
+
 // newTx creates a new Transaction signed by the Client's Signer
 func (c *Client) newTx(ctx context.Context, data transactions.Payload, txOpts *clientType.TxOptions) (*transactions.Transaction, error) {
 	if c.Signer == nil {
@@ -66,6 +77,11 @@ func (c *Client) newTx(ctx context.Context, data transactions.Payload, txOpts *c
 	// set fee
 	tx.Body.Fee = price
 
+	// EIP-712 signers sign the typed data serialization
+	if c.Signer.AuthType() == auth.EthEip712Auth {
+		tx.Serialization = transactions.SignedMsgEip712
+	}
+
 	// sign transaction
 	err = tx.Sign(c.Signer)
 	if err != nil {
@@ -78,3 +78,31 @@ func newEd25519Signer(pkey string) *auth.Ed25519Signer {
 
 	return &auth.Ed25519Signer{Ed25519PrivateKey: *edKey}
 }
+
+func Test_Eip712Hash(t *testing.T) {
+	// Messages that are not typed data are hashed as with personal_sign.
+	msg := []byte("foo")
+	hash, err := auth.Eip712Hash(msg)
+	assert.NoError(t, err)
+
+	ethSig, err := newEthSigner(secp256k1Key).Sign(msg)
+	assert.NoError(t, err)
+	eip712Sig, err := newEip712Signer(secp256k1Key).Sign(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, ethSig.Signature, eip712Sig.Signature)
+	assert.Equal(t, auth.EthEip712Auth, eip712Sig.Type)
+	assert.Len(t, hash, 32)
+
+	// Invalid typed data is an error rather than text.
+	_, err = auth.Eip712Hash([]byte(`{"primaryType":"Mail","types":{},"domain":{},"message":{}}`))
+	assert.Error(t, err)
+}
+
+func newEip712Signer(pkey string) *auth.Eip712Signer {
+	secpKey, err := crypto.Secp256k1PrivateKeyFromHex(pkey)
+	if err != nil {
+		panic(err)
+	}
+
+	return &auth.Eip712Signer{Key: *secpKey}
+}
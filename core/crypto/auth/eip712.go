@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+
+	ethAccounts "github.com/ethereum/go-ethereum/accounts"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EthEip712Auth is the Ethereum EIP-712 typed data authentication type, which
+// uses the secp256k1 signature scheme with the EIP-712 hash of a message that
+// is JSON typed data, as signed by a wallet's eth_signTypedData_v4 method. This
+// is intended as the authenticator for the SDK-provided Eip712Signer, and must
+// be registered with that name.
+const EthEip712Auth = "secp256k1_eip712"
+
+// Eip712Hash returns the digest that is signed for a message by an Eip712Signer.
+// If the message is JSON EIP-712 typed data, such as a transaction serialized
+// with the "eip712" serialization, it is the EIP-712 hash of the typed data.
+// Other messages, such as the text of a call message, are not typed data, and
+// are hashed as with EIP-191 personal_sign. Any JSON object with a primaryType
+// is treated as typed data, so that a typed data signature may not be used as
+// the signature of text.
+func Eip712Hash(msg []byte) ([]byte, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(msg, &typedData); err != nil || typedData.PrimaryType == "" {
+		return ethAccounts.TextHash(msg), nil
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EIP-712 typed data: %w", err)
+	}
+	return hash, nil
+}
+
+// Eip712Signer is a signer that signs messages using the secp256k1 curve,
+// using ethereum's EIP-712 typed data signature scheme. See Eip712Hash.
+type Eip712Signer struct {
+	Key crypto.Secp256k1PrivateKey
+}
+
+var _ Signer = (*Eip712Signer)(nil)
+
+// Sign signs the EIP-712 hash of the message, which should be JSON typed data.
+// The signature is in [R || S || V] format, 65 bytes.
+func (e *Eip712Signer) Sign(msg []byte) (*Signature, error) {
+	hash, err := Eip712Hash(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureBts, err := e.Key.SignWithRecoveryID(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{
+		Signature: signatureBts,
+		Type:      EthEip712Auth,
+	}, nil
+}
+
+// Identity returns the identity of the signer (ETH address for this signer).
+func (e *Eip712Signer) Identity() []byte {
+	pub, err := ethCrypto.UnmarshalPubkey(e.Key.PubKey().Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	return ethCrypto.PubkeyToAddress(*pub).Bytes()
+}
+
+func (e *Eip712Signer) AuthType() string {
+	return EthEip712Auth
+}
@@ -0,0 +1,122 @@
+/*
+Package external provides an auth.Signer that delegates signing to an external
+process or service, such as an HSM or custody service, so that private keys need
+not be available to the application.
+
+The protocol is a single JSON request and response. The request is one of:
+
+	{"method": "identity"}
+	{"method": "sign", "message": "<base64 message>"}
+
+The response to an identity request contains the signer's identity (e.g. an
+address or public key) and the authenticator type of its signatures:
+
+	{"identity": "<base64 identity>", "auth_type": "secp256k1_ep"}
+
+The response to a sign request contains the signature of the message, which
+must be of the signer's authenticator type:
+
+	{"signature": {"sig": "<base64 signature>", "type": "secp256k1_ep"}}
+
+Failures are indicated by a response with an error message:
+
+	{"error": "signing request denied"}
+
+The request may be sent to a command on its standard input, with the response
+read from its standard output, or POSTed to an HTTP endpoint, which may be
+served on a unix socket. ServeStdio and NewHandler implement the protocol for
+an auth.Signer, for signer programs written in Go.
+*/
+package external
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+const (
+	// MethodIdentity requests the signer's identity and authenticator type.
+	MethodIdentity = "identity"
+	// MethodSign requests a signature of the message.
+	MethodSign = "sign"
+)
+
+// Request is a request to an external signer.
+type Request struct {
+	Method  string `json:"method"`
+	Message []byte `json:"message,omitempty"`
+}
+
+// Response is an external signer's response to a Request.
+type Response struct {
+	Identity  []byte          `json:"identity,omitempty"`
+	AuthType  string          `json:"auth_type,omitempty"`
+	Signature *auth.Signature `json:"signature,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Handle handles a request with a signer, returning the response.
+func Handle(signer auth.Signer, req *Request) *Response {
+	switch req.Method {
+	case MethodIdentity:
+		return &Response{
+			Identity: signer.Identity(),
+			AuthType: signer.AuthType(),
+		}
+	case MethodSign:
+		sig, err := signer.Sign(req.Message)
+		if err != nil {
+			return &Response{Error: err.Error()}
+		}
+		return &Response{Signature: sig}
+	default:
+		return &Response{Error: fmt.Sprintf("unknown method %q", req.Method)}
+	}
+}
+
+// ServeStdio reads one request from r, handles it with the signer, and writes
+// the response to w. This is used by a signer command.
+func ServeStdio(signer auth.Signer, r io.Reader, w io.Writer) error {
+	var req Request
+	resp := &Response{}
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else {
+		resp = Handle(signer, &req)
+	}
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// maxMessageSize limits the size of requests and responses.
+const maxMessageSize = 1 << 22
+
+// NewHandler returns an http.Handler that handles POSTed requests with the
+// signer.
+func NewHandler(signer auth.Signer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req Request
+		resp := &Response{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			resp = Handle(signer, &req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// ErrSigner is returned when an external signer responds with an error.
+var ErrSigner = errors.New("external signer error")
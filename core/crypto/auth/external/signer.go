@@ -0,0 +1,229 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+// Transport sends a request to an external signer, returning its response.
+type Transport interface {
+	RoundTrip(ctx context.Context, req *Request) (*Response, error)
+}
+
+// DefaultTimeout is the default time allowed for an external signer to respond
+// to a signing request. It is long enough to allow for a signature to be
+// approved by a person.
+const DefaultTimeout = 2 * time.Minute
+
+// Signer is an auth.Signer that delegates signing to an external signer. The
+// identity and authenticator type are requested when it is created.
+type Signer struct {
+	transport Transport
+	identity  []byte
+	authType  string
+
+	// Timeout is the time allowed for the external signer to respond to a
+	// signing request.
+	Timeout time.Duration
+}
+
+var _ auth.Signer = (*Signer)(nil)
+
+// NewSigner creates a Signer that uses the transport, requesting the external
+// signer's identity and authenticator type.
+func NewSigner(ctx context.Context, transport Transport) (*Signer, error) {
+	resp, err := roundTrip(ctx, transport, &Request{Method: MethodIdentity})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Identity) == 0 {
+		return nil, fmt.Errorf("%w: no identity", ErrSigner)
+	}
+	if resp.AuthType == "" {
+		return nil, fmt.Errorf("%w: no auth type", ErrSigner)
+	}
+
+	return &Signer{
+		transport: transport,
+		identity:  resp.Identity,
+		authType:  resp.AuthType,
+		Timeout:   DefaultTimeout,
+	}, nil
+}
+
+// NewCommandSigner creates a Signer that runs a command for each request. See
+// CommandTransport.
+func NewCommandSigner(ctx context.Context, name string, args ...string) (*Signer, error) {
+	return NewSigner(ctx, &CommandTransport{Name: name, Args: args})
+}
+
+// NewHTTPSigner creates a Signer that POSTs requests to an HTTP endpoint. See
+// NewHTTPTransport.
+func NewHTTPSigner(ctx context.Context, endpoint string) (*Signer, error) {
+	transport, err := NewHTTPTransport(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(ctx, transport)
+}
+
+// Sign requests a signature of the message from the external signer.
+func (s *Signer) Sign(msg []byte) (*auth.Signature, error) {
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	resp, err := roundTrip(ctx, s.transport, &Request{
+		Method:  MethodSign,
+		Message: msg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Signature == nil || len(resp.Signature.Signature) == 0 {
+		return nil, fmt.Errorf("%w: no signature", ErrSigner)
+	}
+	if resp.Signature.Type != s.authType {
+		return nil, fmt.Errorf("%w: signature type %q, expected %q", ErrSigner, resp.Signature.Type, s.authType)
+	}
+
+	return resp.Signature, nil
+}
+
+// Identity returns the external signer's identity.
+func (s *Signer) Identity() []byte {
+	return s.identity
+}
+
+// AuthType returns the authenticator type of the external signer's signatures.
+func (s *Signer) AuthType() string {
+	return s.authType
+}
+
+func roundTrip(ctx context.Context, transport Transport, req *Request) (*Response, error) {
+	resp, err := transport.RoundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrSigner, resp.Error)
+	}
+	return resp, nil
+}
+
+// CommandTransport runs a command for each request, writing the request to its
+// standard input and reading the response from its standard output.
+type CommandTransport struct {
+	Name string
+	Args []string
+}
+
+func (c *CommandTransport) RoundTrip(ctx context.Context, req *Request) (*Response, error) {
+	reqBts, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = bytes.NewReader(reqBts)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("signer command failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("signer command failed: %w", err)
+	}
+
+	var resp Response
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid signer command response: %w", err)
+	}
+	return &resp, nil
+}
+
+// HTTPTransport POSTs requests to an HTTP endpoint.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPTransport creates an HTTPTransport for an http or https URL, or a
+// unix socket given as unix:///path/to/socket.
+func NewHTTPTransport(endpoint string) (*HTTPTransport, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &HTTPTransport{url: endpoint, client: &http.Client{}}, nil
+	case "unix":
+		sockPath := u.Path
+		if sockPath == "" {
+			sockPath = u.Opaque
+		}
+		dialer := &net.Dialer{}
+		return &HTTPTransport{
+			url: "http://unix/",
+			client: &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return dialer.DialContext(ctx, "unix", sockPath)
+					},
+				},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signer url scheme %q", u.Scheme)
+	}
+}
+
+func (h *HTTPTransport) RoundTrip(ctx context.Context, req *Request) (*Response, error) {
+	reqBts, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(reqBts))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("signer request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBts, err := io.ReadAll(io.LimitReader(httpResp.Body, maxMessageSize))
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signer request failed: %s: %s", httpResp.Status, strings.TrimSpace(string(respBts)))
+	}
+
+	var resp Response
+	if err = json.Unmarshal(respBts, &resp); err != nil {
+		return nil, fmt.Errorf("invalid signer response: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,100 @@
+package external
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+const testKeyHex = "f1aa5a7966c3863ccde3047f6a1e266cdc0c76b399e256b8fede92b1c69e4f4e"
+
+func testSigner(t *testing.T) *auth.EthPersonalSigner {
+	key, err := crypto.Secp256k1PrivateKeyFromHex(testKeyHex)
+	require.NoError(t, err)
+	return &auth.EthPersonalSigner{Key: *key}
+}
+
+// TestHelperProcess is not a real test. It is run as the signer command by
+// Test_CommandSigner.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("EXTERNAL_SIGNER_HELPER") != "1" {
+		return
+	}
+	if err := ServeStdio(testSigner(t), os.Stdin, os.Stdout); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func testSign(t *testing.T, signer *Signer) {
+	local := testSigner(t)
+	require.Equal(t, local.Identity(), signer.Identity())
+	require.Equal(t, auth.EthPersonalSignAuth, signer.AuthType())
+
+	msg := []byte("sign me")
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	require.Equal(t, auth.EthPersonalSignAuth, sig.Type)
+	require.NoError(t, auth.EthSecp256k1Authenticator{}.Verify(signer.Identity(), msg, sig.Signature))
+}
+
+func Test_CommandSigner(t *testing.T) {
+	t.Setenv("EXTERNAL_SIGNER_HELPER", "1")
+
+	signer, err := NewCommandSigner(context.Background(), os.Args[0], "-test.run=TestHelperProcess")
+	require.NoError(t, err)
+	testSign(t, signer)
+
+	_, err = NewCommandSigner(context.Background(), filepath.Join(t.TempDir(), "no-such-signer"))
+	require.Error(t, err)
+}
+
+func Test_HTTPSigner(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(testSigner(t)))
+	defer srv.Close()
+
+	signer, err := NewHTTPSigner(context.Background(), srv.URL)
+	require.NoError(t, err)
+	testSign(t, signer)
+}
+
+func Test_UnixSocketSigner(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "signer.sock")
+	ln, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: NewHandler(testSigner(t))}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	signer, err := NewHTTPSigner(context.Background(), "unix://"+sockPath)
+	require.NoError(t, err)
+	testSign(t, signer)
+}
+
+type errSigner struct {
+	auth.Signer
+}
+
+func (errSigner) Sign([]byte) (*auth.Signature, error) {
+	return nil, os.ErrPermission
+}
+
+func Test_SignerError(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(errSigner{testSigner(t)}))
+	defer srv.Close()
+
+	signer, err := NewHTTPSigner(context.Background(), srv.URL)
+	require.NoError(t, err)
+
+	_, err = signer.Sign([]byte("msg"))
+	require.ErrorIs(t, err, ErrSigner)
+}
@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+)
+
+// MultiSigAuth is the k-of-n multisig authentication type. The identity of a
+// multisig account is the sha256 hash of its MultiSig, which is the set of
+// member keys and the threshold number of them that must sign. Its signatures
+// are serialized MultiSigSignatures, which contain the MultiSig and at least
+// threshold signatures of the message by the member keys. This is the type of
+// signatures created by the MultiSigSigner, and the authenticator must be
+// registered with this name.
+const MultiSigAuth = "multisig"
+
+// MaxMultiSigKeys is the maximum number of member keys of a multisig account.
+const MaxMultiSigKeys = 32
+
+// MultiSigKey is a member key of a multisig account.
+type MultiSigKey struct {
+	// AuthType is the authenticator type of the key's signatures, which may
+	// be any registered type other than MultiSigAuth.
+	AuthType string `json:"auth_type"`
+	// Identity is the identity of the key for the authenticator type, such as
+	// an address or public key.
+	Identity types.HexBytes `json:"identity"`
+}
+
+func compareMultiSigKeys(a, b *MultiSigKey) int {
+	return cmp.Or(cmp.Compare(a.AuthType, b.AuthType), bytes.Compare(a.Identity, b.Identity))
+}
+
+// MultiSig defines a multisig account: a set of member keys, and the number of
+// them that must sign.
+type MultiSig struct {
+	Threshold uint16 `json:"threshold"`
+	// Keys are the member keys, sorted by authenticator type and identity so
+	// that a set of keys has one identity.
+	Keys []*MultiSigKey `json:"keys"`
+}
+
+// NewMultiSig creates a MultiSig with the keys, which are sorted.
+func NewMultiSig(threshold uint16, keys []*MultiSigKey) (*MultiSig, error) {
+	ms := &MultiSig{
+		Threshold: threshold,
+		Keys:      slices.Clone(keys),
+	}
+	slices.SortFunc(ms.Keys, compareMultiSigKeys)
+
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// Validate checks that the threshold is between one and the number of keys,
+// and that the keys are sorted and unique.
+func (ms *MultiSig) Validate() error {
+	if len(ms.Keys) == 0 {
+		return errors.New("multisig has no keys")
+	}
+	if len(ms.Keys) > MaxMultiSigKeys {
+		return fmt.Errorf("multisig has %d keys, more than the maximum of %d", len(ms.Keys), MaxMultiSigKeys)
+	}
+	if ms.Threshold == 0 || int(ms.Threshold) > len(ms.Keys) {
+		return fmt.Errorf("invalid multisig threshold %d of %d keys", ms.Threshold, len(ms.Keys))
+	}
+
+	for i, key := range ms.Keys {
+		if key == nil || key.AuthType == "" || len(key.Identity) == 0 {
+			return fmt.Errorf("invalid multisig key %d", i)
+		}
+		if key.AuthType == MultiSigAuth {
+			return errors.New("multisig keys may not be multisig accounts")
+		}
+		if i > 0 && compareMultiSigKeys(ms.Keys[i-1], key) >= 0 {
+			return errors.New("multisig keys are not sorted and unique")
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary serializes the MultiSig.
+func (ms *MultiSig) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(ms)
+}
+
+// UnmarshalBinary deserializes the MultiSig.
+func (ms *MultiSig) UnmarshalBinary(b []byte) error {
+	return serialize.Decode(b, ms)
+}
+
+// Identity returns the identity of the multisig account, which is the sha256
+// hash of the serialized MultiSig.
+func (ms *MultiSig) Identity() ([]byte, error) {
+	b, err := ms.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(b)
+	return hash[:], nil
+}
+
+// KeyIndex returns the index of the member key with the authenticator type and
+// identity, or -1 if it is not a member.
+func (ms *MultiSig) KeyIndex(authType string, identity []byte) int {
+	idx, found := slices.BinarySearchFunc(ms.Keys, &MultiSigKey{AuthType: authType, Identity: identity}, compareMultiSigKeys)
+	if !found {
+		return -1
+	}
+	return idx
+}
+
+// MultiSigPart is a signature by one member key of a multisig account.
+type MultiSigPart struct {
+	// Index is the index of the key in MultiSig.Keys.
+	Index     uint16
+	Signature []byte
+}
+
+// MultiSigSignature is the signature of a MultiSigAuth Signature.
+type MultiSigSignature struct {
+	MultiSig *MultiSig
+	// Parts are the member signatures, sorted by key index.
+	Parts []*MultiSigPart
+}
+
+// MarshalBinary serializes the MultiSigSignature.
+func (s *MultiSigSignature) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(s)
+}
+
+// UnmarshalBinary deserializes the MultiSigSignature.
+func (s *MultiSigSignature) UnmarshalBinary(b []byte) error {
+	return serialize.Decode(b, s)
+}
+
+// MultiSigSigner is a Signer for a multisig account. Member signatures of a
+// message may be collected offline, by different people and from different
+// MultiSigSigners, with SignWith, AddSignature, and Merge. Partial returns
+// the multisig signature with the signatures collected so far, so that it may
+// be passed to other members, and Sign returns it once there are at least
+// threshold signatures.
+type MultiSigSigner struct {
+	MultiSig *MultiSig
+
+	// Signers are member signers that are available to sign, such as in an
+	// automated treasury. Sign signs with each of them.
+	Signers []Signer
+
+	identity []byte
+	parts    map[uint16][]byte
+}
+
+var _ Signer = (*MultiSigSigner)(nil)
+
+// NewMultiSigSigner creates a MultiSigSigner for the multisig account with
+// the optional member signers.
+func NewMultiSigSigner(ms *MultiSig, signers ...Signer) (*MultiSigSigner, error) {
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	identity, err := ms.Identity()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultiSigSigner{
+		MultiSig: ms,
+		Signers:  signers,
+		identity: identity,
+		parts:    make(map[uint16][]byte),
+	}, nil
+}
+
+// SignWith signs the message with a member signer, adding the signature.
+func (s *MultiSigSigner) SignWith(signer Signer, msg []byte) error {
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return err
+	}
+	return s.AddSignature(signer.Identity(), sig)
+}
+
+// AddSignature adds the signature of a member key with the identity.
+func (s *MultiSigSigner) AddSignature(identity []byte, sig *Signature) error {
+	idx := s.MultiSig.KeyIndex(sig.Type, identity)
+	if idx == -1 {
+		return fmt.Errorf("%s key %x is not a member of the multisig", sig.Type, identity)
+	}
+	s.parts[uint16(idx)] = sig.Signature
+	return nil
+}
+
+// Merge adds the member signatures of a partial multisig signature of the same
+// multisig account, such as one returned by another MultiSigSigner's Partial.
+func (s *MultiSigSigner) Merge(sig *Signature) error {
+	if sig.Type != MultiSigAuth {
+		return fmt.Errorf("signature type %q is not %q", sig.Type, MultiSigAuth)
+	}
+
+	var msSig MultiSigSignature
+	if err := msSig.UnmarshalBinary(sig.Signature); err != nil {
+		return fmt.Errorf("invalid multisig signature: %w", err)
+	}
+	if msSig.MultiSig == nil {
+		return errors.New("invalid multisig signature: no multisig")
+	}
+	identity, err := msSig.MultiSig.Identity()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(identity, s.identity) {
+		return errors.New("signature is for a different multisig account")
+	}
+
+	for _, part := range msSig.Parts {
+		if int(part.Index) >= len(s.MultiSig.Keys) {
+			return fmt.Errorf("invalid multisig key index %d", part.Index)
+		}
+		s.parts[part.Index] = part.Signature
+	}
+	return nil
+}
+
+// Count returns the number of member signatures collected.
+func (s *MultiSigSigner) Count() int {
+	return len(s.parts)
+}
+
+// Partial returns the multisig signature with the member signatures collected
+// so far, which may be fewer than the threshold.
+func (s *MultiSigSigner) Partial() (*Signature, error) {
+	msSig := &MultiSigSignature{MultiSig: s.MultiSig}
+	for idx, sig := range s.parts {
+		msSig.Parts = append(msSig.Parts, &MultiSigPart{Index: idx, Signature: sig})
+	}
+	slices.SortFunc(msSig.Parts, func(a, b *MultiSigPart) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	sigBts, err := msSig.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{
+		Signature: sigBts,
+		Type:      MultiSigAuth,
+	}, nil
+}
+
+// Sign signs the message with any member Signers, and returns the multisig
+// signature if there are at least threshold member signatures. Collected
+// signatures must be of the same message, which is not checked.
+func (s *MultiSigSigner) Sign(msg []byte) (*Signature, error) {
+	for _, signer := range s.Signers {
+		if err := s.SignWith(signer, msg); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.parts) < int(s.MultiSig.Threshold) {
+		return nil, fmt.Errorf("have %d of %d required multisig signatures", len(s.parts), s.MultiSig.Threshold)
+	}
+	return s.Partial()
+}
+
+// Identity returns the identity of the multisig account.
+func (s *MultiSigSigner) Identity() []byte {
+	return s.identity
+}
+
+func (s *MultiSigSigner) AuthType() string {
+	return MultiSigAuth
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// WebAuthnAuth is the WebAuthn (passkey) authentication type, which verifies
+// ECDSA P-256 assertions by a device authenticator. The WebAuthn challenge is
+// the sha256 digest of the message. This is the type of signatures created by
+// the WebAuthnSigner, and the authenticator must be registered with this name.
+const WebAuthnAuth = "webauthn"
+
+// WebAuthnAssertion is the part of a WebAuthn assertion (the response of
+// navigator.credentials.get) that is needed to verify it. It is the signature
+// of a WebAuthnAuth Signature, serialized with MarshalBinary as:
+//
+//  1. uint16 length n of the authenticator data (big endian)
+//  2. the authenticator data (n bytes)
+//  3. uint16 length m of the client data JSON (big endian)
+//  4. the client data JSON (m bytes)
+//  5. the ASN.1 DER encoded ECDSA signature (the remaining bytes)
+type WebAuthnAssertion struct {
+	// AuthenticatorData is the authenticator data, which begins with the
+	// sha256 hash of the relying party ID and the flags.
+	AuthenticatorData []byte
+	// ClientDataJSON is the client data, which contains the challenge.
+	ClientDataJSON []byte
+	// Signature is the signature of AuthenticatorData || sha256(ClientDataJSON).
+	Signature []byte
+}
+
+// MarshalBinary serializes the assertion.
+func (a *WebAuthnAssertion) MarshalBinary() ([]byte, error) {
+	if len(a.AuthenticatorData) > math.MaxUint16 || len(a.ClientDataJSON) > math.MaxUint16 {
+		return nil, errors.New("webauthn assertion too large")
+	}
+
+	b := make([]byte, 0, 4+len(a.AuthenticatorData)+len(a.ClientDataJSON)+len(a.Signature))
+	b = binary.BigEndian.AppendUint16(b, uint16(len(a.AuthenticatorData)))
+	b = append(b, a.AuthenticatorData...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(a.ClientDataJSON)))
+	b = append(b, a.ClientDataJSON...)
+	return append(b, a.Signature...), nil
+}
+
+// UnmarshalBinary deserializes the assertion.
+func (a *WebAuthnAssertion) UnmarshalBinary(b []byte) error {
+	next := func() ([]byte, error) {
+		if len(b) < 2 {
+			return nil, errors.New("invalid webauthn assertion length")
+		}
+		n := int(binary.BigEndian.Uint16(b))
+		if len(b) < 2+n {
+			return nil, errors.New("invalid webauthn assertion length")
+		}
+		field := b[2 : 2+n]
+		b = b[2+n:]
+		return field, nil
+	}
+
+	authData, err := next()
+	if err != nil {
+		return err
+	}
+	clientData, err := next()
+	if err != nil {
+		return err
+	}
+
+	a.AuthenticatorData = authData
+	a.ClientDataJSON = clientData
+	a.Signature = b
+	return nil
+}
+
+// WebAuthnClientData is the client data of a WebAuthn assertion. Fields that
+// are not needed for verification are not included.
+type WebAuthnClientData struct {
+	// Type is "webauthn.get" for an assertion.
+	Type string `json:"type"`
+	// Challenge is the base64url (unpadded) encoded challenge.
+	Challenge string `json:"challenge"`
+	// Origin is the origin of the web app, e.g. https://app.example.com.
+	Origin string `json:"origin"`
+	// CrossOrigin is set when the request was from a cross-origin iframe.
+	CrossOrigin bool `json:"crossOrigin,omitempty"`
+}
+
+const (
+	// WebAuthnGetType is the client data type of an assertion.
+	WebAuthnGetType = "webauthn.get"
+
+	// WebAuthnFlagUserPresent and WebAuthnFlagUserVerified are the flags in
+	// the authenticator data indicating that the user was present, and that
+	// they were verified (e.g. with a PIN or biometric).
+	WebAuthnFlagUserPresent  byte = 0x01
+	WebAuthnFlagUserVerified byte = 0x04
+)
+
+// WebAuthnChallenge returns the WebAuthn challenge for a message, which is the
+// base64url encoding of the sha256 digest of the message.
+func WebAuthnChallenge(msg []byte) string {
+	digest := sha256.Sum256(msg)
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// WebAuthnSigner is a signer that creates WebAuthn assertions with a P-256 key
+// in the same manner as a passkey authenticator, without a browser or device.
+// It is intended for testing, since a real passkey's private key is not
+// available to an application, which instead gets assertions from the browser.
+type WebAuthnSigner struct {
+	Key *ecdsa.PrivateKey
+
+	// RPID is the relying party ID, e.g. example.com.
+	RPID string
+	// Origin is the origin of the web app, e.g. https://example.com.
+	Origin string
+}
+
+var _ Signer = (*WebAuthnSigner)(nil)
+
+// GenerateWebAuthnSigner creates a WebAuthnSigner with a new P-256 key.
+func GenerateWebAuthnSigner(rpID, origin string) (*WebAuthnSigner, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnSigner{Key: key, RPID: rpID, Origin: origin}, nil
+}
+
+// Sign creates a WebAuthn assertion with the digest of the message as the
+// challenge. The user present and user verified flags are set.
+func (w *WebAuthnSigner) Sign(msg []byte) (*Signature, error) {
+	clientData, err := json.Marshal(&WebAuthnClientData{
+		Type:      WebAuthnGetType,
+		Challenge: WebAuthnChallenge(msg),
+		Origin:    w.Origin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// rpIdHash || flags || signCount, with no attested credential data or
+	// extensions. The sign count is zero, as with most passkeys.
+	rpIDHash := sha256.Sum256([]byte(w.RPID))
+	authData := append(rpIDHash[:], WebAuthnFlagUserPresent|WebAuthnFlagUserVerified, 0, 0, 0, 0)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, w.Key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	if sig, err = NormalizeWebAuthnSignature(sig); err != nil {
+		return nil, err
+	}
+
+	assertion := &WebAuthnAssertion{
+		AuthenticatorData: authData,
+		ClientDataJSON:    clientData,
+		Signature:         sig,
+	}
+	sigBts, err := assertion.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{
+		Signature: sigBts,
+		Type:      WebAuthnAuth,
+	}, nil
+}
+
+// Identity returns the identity of the signer, which is the compressed P-256
+// credential public key.
+func (w *WebAuthnSigner) Identity() []byte {
+	return elliptic.MarshalCompressed(elliptic.P256(), w.Key.X, w.Key.Y)
+}
+
+func (w *WebAuthnSigner) AuthType() string {
+	return WebAuthnAuth
+}
+
+// ParseWebAuthnPublicKey parses a P-256 public key in compressed SEC 1 form,
+// which is the identity of a WebAuthnAuth signer. Only the compressed form is
+// accepted so that a credential has exactly one identity.
+func ParseWebAuthnPublicKey(pubKey []byte) (*ecdsa.PublicKey, error) {
+	curve := elliptic.P256()
+	x, y := elliptic.UnmarshalCompressed(curve, pubKey)
+	if x == nil {
+		return nil, fmt.Errorf("invalid P-256 public key of length %d", len(pubKey))
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// webauthnSignature is an ASN.1 DER encoded ECDSA signature.
+type webauthnSignature struct {
+	R, S *big.Int
+}
+
+// p256HalfOrder is half the order of the P-256 curve.
+var p256HalfOrder = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+
+// ParseWebAuthnSignature parses the ASN.1 DER encoded ECDSA signature of a
+// WebAuthn assertion, and reports whether it has a low S value (s <= n/2).
+func ParseWebAuthnSignature(sig []byte) (r, s *big.Int, lowS bool, err error) {
+	var parsed webauthnSignature
+	rest, err := asn1.Unmarshal(sig, &parsed)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("invalid webauthn signature: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, nil, false, errors.New("invalid webauthn signature: trailing data")
+	}
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 {
+		return nil, nil, false, errors.New("invalid webauthn signature: non-positive value")
+	}
+	return parsed.R, parsed.S, parsed.S.Cmp(p256HalfOrder) <= 0, nil
+}
+
+// NormalizeWebAuthnSignature returns the ASN.1 DER encoded P-256 signature
+// with a low S value. For any valid signature (r, s), (r, n-s) is also valid,
+// so the WebAuthnAuth authenticator only accepts the low S form to prevent a
+// transaction's signature, and thus its hash, from being changed by a third
+// party. Authenticators do not normalize their signatures, so an app must
+// normalize the signature of an assertion before using it.
+func NormalizeWebAuthnSignature(sig []byte) ([]byte, error) {
+	r, s, lowS, err := ParseWebAuthnSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	if lowS {
+		return sig, nil
+	}
+	s = new(big.Int).Sub(elliptic.P256().Params().N, s)
+	return asn1.Marshal(webauthnSignature{R: r, S: s})
+}
@@ -3,6 +3,7 @@ package admin
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/kwilteam/kwil-db/core/types"
 	adminTypes "github.com/kwilteam/kwil-db/core/types/admin"
@@ -11,14 +12,17 @@ import (
 type AdminClient interface {
 	Approve(ctx context.Context, publicKey []byte) ([]byte, error)
 	Join(ctx context.Context) ([]byte, error)
+	JoinWithStake(ctx context.Context, stake *big.Int) ([]byte, error)
 	JoinStatus(ctx context.Context, pubkey []byte) (*types.JoinRequest, error)
 	Leave(ctx context.Context) ([]byte, error)
+	Unjail(ctx context.Context) ([]byte, error)
 	ListValidators(ctx context.Context) ([]*types.Validator, error)
 	Peers(ctx context.Context) ([]*adminTypes.PeerInfo, error)
 	Remove(ctx context.Context, publicKey []byte) ([]byte, error)
 	Status(ctx context.Context) (*adminTypes.Status, error)
 	Version(ctx context.Context) (string, error)
 	ListPendingJoins(ctx context.Context) ([]*types.JoinRequest, error)
+	ListEvidence(ctx context.Context, pubkey []byte) ([]*types.ValidatorEvidence, error)
 
 	// GetConfig gets the current config from the node.
 	// It returns the config serialized as JSON.
@@ -2,6 +2,7 @@ package adminclient
 
 import (
 	"context"
+	"math/big"
 	"net/url"
 	"time"
 
@@ -57,7 +58,17 @@ func (cl *Client) Approve(ctx context.Context, publicKey []byte) ([]byte, error)
 // Join makes a validator join request for the node being administered. The
 // transaction hash for the broadcasted join transaction is returned.
 func (cl *Client) Join(ctx context.Context) ([]byte, error) {
+	return cl.JoinWithStake(ctx, nil)
+}
+
+// JoinWithStake stakes the given amount and makes a validator join request for
+// the node being administered, on a network with staking enabled. The
+// transaction hash for the broadcasted join transaction is returned.
+func (cl *Client) JoinWithStake(ctx context.Context, stake *big.Int) ([]byte, error) {
 	cmd := &adminjson.JoinRequest{}
+	if stake != nil {
+		cmd.Stake = stake.String()
+	}
 	res := &userjson.BroadcastResponse{}
 	err := cl.CallMethod(ctx, string(adminjson.MethodValJoin), cmd, res)
 	if err != nil {
@@ -80,6 +91,21 @@ func (cl *Client) JoinStatus(ctx context.Context, pubkey []byte) (*types.JoinReq
 	return res.JoinRequest, nil
 }
 
+// ListEvidence lists the recorded validator misbehavior and the punishment
+// applied. If pubkey is not nil, only the misbehavior of that validator is
+// listed.
+func (cl *Client) ListEvidence(ctx context.Context, pubkey []byte) ([]*types.ValidatorEvidence, error) {
+	cmd := &adminjson.ListEvidenceRequest{
+		PubKey: pubkey,
+	}
+	res := &adminjson.ListEvidenceResponse{}
+	err := cl.CallMethod(ctx, string(adminjson.MethodValEvidence), cmd, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Evidence, nil
+}
+
 // Leave makes a validator leave request for the node being administered. The
 // transaction hash for the broadcasted leave transaction is returned.
 func (cl *Client) Leave(ctx context.Context) ([]byte, error) {
@@ -92,6 +118,18 @@ func (cl *Client) Leave(ctx context.Context) ([]byte, error) {
 	return res.TxHash, err
 }
 
+// Unjail makes a validator unjail request for the node being administered. The
+// transaction hash for the broadcasted unjail transaction is returned.
+func (cl *Client) Unjail(ctx context.Context) ([]byte, error) {
+	cmd := &adminjson.UnjailRequest{}
+	res := &userjson.BroadcastResponse{}
+	err := cl.CallMethod(ctx, string(adminjson.MethodValUnjail), cmd, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.TxHash, err
+}
+
 // ListValidators gets the current validator set.
 func (cl *Client) ListValidators(ctx context.Context) ([]*types.Validator, error) {
 	cmd := &adminjson.ListValidatorsRequest{}
@@ -196,6 +196,96 @@ func (cl *Client) Query(ctx context.Context, dbid, query string) ([]map[string]a
 	return jsonUtil.UnmarshalMapWithoutFloat[[]map[string]any](res.Result)
 }
 
+func (cl *Client) QueryPage(ctx context.Context, dbid, query, cursor string, limit int64) ([]map[string]any, string, int64, error) {
+	cmd := &userjson.QueryPageRequest{
+		Cursor: cursor,
+		Limit:  limit,
+	}
+	if cursor == "" {
+		cmd.DBID = dbid
+		cmd.Query = query
+	}
+	res := &userjson.QueryPageResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodQueryPage), cmd, res)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	rows, err := jsonUtil.UnmarshalMapWithoutFloat[[]map[string]any](res.Result)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return rows, res.NextCursor, res.Height, nil
+}
+
+func (cl *Client) Explain(ctx context.Context, dbid, query, procedure string) ([]*types.StatementPlan, error) {
+	cmd := &userjson.ExplainRequest{
+		DBID:      dbid,
+		Query:     query,
+		Procedure: procedure,
+	}
+	res := &userjson.ExplainResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodExplain), cmd, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Plans, nil
+}
+
+func (cl *Client) Changes(ctx context.Context, dbid, table string, fromHeight, limit int64) ([]*types.RowChange, int64, int64, error) {
+	cmd := &userjson.ChangesRequest{
+		DBID:       dbid,
+		Table:      table,
+		FromHeight: fromHeight,
+		Limit:      limit,
+	}
+	res := &userjson.ChangesResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodChanges), cmd, res)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return res.Changes, res.NextHeight, res.LastHeight, nil
+}
+
+func (cl *Client) ListResolutions(ctx context.Context, filter *types.ResolutionFilter, offset, limit int64) ([]*types.ResolutionInfo, int64, error) {
+	cmd := &userjson.ListResolutionsRequest{
+		Offset: offset,
+		Limit:  limit,
+	}
+	if filter != nil {
+		cmd.ResolutionFilter = *filter
+	}
+	res := &userjson.ListResolutionsResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodListResolutions), cmd, res)
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Resolutions, res.NextOffset, nil
+}
+
+func (cl *Client) Resolution(ctx context.Context, id *types.UUID) (*types.ResolutionInfo, error) {
+	cmd := &userjson.ResolutionRequest{
+		ID: id,
+	}
+	res := &userjson.ResolutionResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodResolution), cmd, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (cl *Client) WithdrawalProof(ctx context.Context, txHash []byte) (*types.WithdrawalProof, error) {
+	cmd := &userjson.WithdrawalProofRequest{
+		TxHash: txHash,
+	}
+	res := &userjson.WithdrawalProofResponse{}
+	err := cl.CallMethod(ctx, string(userjson.MethodWithdrawalProof), cmd, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (cl *Client) TxQuery(ctx context.Context, txHash []byte) (*transactions.TcTxQueryResponse, error) {
 	cmd := &userjson.TxQueryRequest{
 		TxHash: txHash,
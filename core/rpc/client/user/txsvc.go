@@ -23,6 +23,11 @@ type TxSvcClient interface {
 	ListDatabases(ctx context.Context, ownerPubKey []byte) ([]*types.DatasetIdentifier, error)
 	Ping(ctx context.Context) (string, error)
 	Query(ctx context.Context, dbid string, query string) ([]map[string]any, error)
+	// QueryPage returns one page of the results of an ad-hoc query. The first
+	// page is requested with an empty cursor. The returned cursor is used to
+	// request the next page, and it is empty if there are no more rows. The
+	// returned height is that of the snapshot from which all pages are read.
+	QueryPage(ctx context.Context, dbid, query, cursor string, limit int64) (rows []map[string]any, nextCursor string, height int64, err error)
 	TxQuery(ctx context.Context, txHash []byte) (*transactions.TcTxQueryResponse, error)
 
 	// Migration methods
@@ -41,4 +46,26 @@ type TxSvcClient interface {
 	Challenge(ctx context.Context) ([]byte, error)
 
 	Health(ctx context.Context) (*types.Health, error)
+
+	// Explain returns the query plans for either an ad-hoc query or the SQL
+	// statements in a procedure.
+	Explain(ctx context.Context, dbid, query, procedure string) ([]*types.StatementPlan, error)
+
+	// Changes returns the changes to a dataset, or one of its tables if table
+	// is not empty, committed in blocks starting at fromHeight. The returned
+	// nextHeight is the height from which to request the following changes,
+	// and lastHeight is the last committed height known to the change feed.
+	Changes(ctx context.Context, dbid, table string, fromHeight, limit int64) (changes []*types.RowChange, nextHeight, lastHeight int64, err error)
+
+	// WithdrawalProof returns the Merkle proof of a withdrawal and the
+	// validator signatures of its epoch's root.
+	WithdrawalProof(ctx context.Context, txHash []byte) (*types.WithdrawalProof, error)
+
+	// ListResolutions lists pending, approved, and expired resolutions that
+	// match the filter, skipping the first offset resolutions. The returned
+	// nextOffset is the offset from which to request the next page, and it is
+	// zero if there are no more resolutions.
+	ListResolutions(ctx context.Context, filter *types.ResolutionFilter, offset, limit int64) (resolutions []*types.ResolutionInfo, nextOffset int64, err error)
+	// Resolution returns a resolution and its votes.
+	Resolution(ctx context.Context, id *types.UUID) (*types.ResolutionInfo, error)
 }
@@ -10,8 +10,15 @@ type GetConfigRequest struct{}
 type ApproveRequest struct {
 	PubKey []byte `json:"pubkey"`
 }
-type JoinRequest struct{}
+
+// JoinRequest requests to join the validator set. If Stake is set, that
+// amount is staked before requesting to join, on networks with staking
+// enabled.
+type JoinRequest struct {
+	Stake string `json:"stake,omitempty"`
+}
 type LeaveRequest struct{}
+type UnjailRequest struct{}
 type RemoveRequest struct {
 	PubKey []byte `json:"pubkey"`
 }
@@ -21,6 +28,12 @@ type JoinStatusRequest struct {
 type ListValidatorsRequest struct{}
 type ListJoinRequestsRequest struct{}
 
+// ListEvidenceRequest lists recorded validator misbehavior. If PubKey is set,
+// only the misbehavior of that validator is listed.
+type ListEvidenceRequest struct {
+	PubKey []byte `json:"pubkey,omitempty"`
+}
+
 type PeerRequest struct {
 	PeerID string `json:"peerid"`
 }
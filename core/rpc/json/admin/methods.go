@@ -14,9 +14,11 @@ const (
 	MethodValJoin           jsonrpc.Method = "admin.val_join"
 	MethodValRemove         jsonrpc.Method = "admin.val_remove"
 	MethodValLeave          jsonrpc.Method = "admin.val_leave"
+	MethodValUnjail         jsonrpc.Method = "admin.val_unjail"
 	MethodValJoinStatus     jsonrpc.Method = "admin.val_join_status"
 	MethodValList           jsonrpc.Method = "admin.val_list"
 	MethodValListJoins      jsonrpc.Method = "admin.val_list_joins"
+	MethodValEvidence       jsonrpc.Method = "admin.val_evidence"
 	MethodAddPeer           jsonrpc.Method = "admin.add_peer"
 	MethodRemovePeer        jsonrpc.Method = "admin.remove_peer"
 	MethodListPeers         jsonrpc.Method = "admin.list_peers"
@@ -80,6 +80,10 @@ type ListValidatorsResponse struct {
 	Validators []*Validator `json:"validators,omitempty"`
 }
 
+type ListEvidenceResponse struct {
+	Evidence []*types.ValidatorEvidence `json:"evidence"`
+}
+
 type ListJoinRequestsResponse struct {
 	JoinRequests []*PendingJoin `json:"join_requests,omitempty"`
 }
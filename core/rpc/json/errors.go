@@ -57,6 +57,14 @@ const (
 	ErrorMismatchCallAuthType  ErrorCode = -1005
 	ErrorTooFastChallengeReqs  ErrorCode = -1006
 	ErrorNoQueryWithPrivateRPC ErrorCode = -1007
+	ErrorExplainDisabled       ErrorCode = -1008
+	ErrorCursorNotFound        ErrorCode = -1009
+	ErrorTooManyCursors        ErrorCode = -1010
+	ErrorTooManyRows           ErrorCode = -1011
+	ErrorChangesDisabled       ErrorCode = -1012
+	ErrorChangesPruned         ErrorCode = -1013
+	ErrorWithdrawalNotFound    ErrorCode = -1014
+	ErrorResolutionNotFound    ErrorCode = -1015
 )
 
 // More detailed errors use a structured error type in the "data" field of the
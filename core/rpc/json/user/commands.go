@@ -93,11 +93,58 @@ type QueryRequest struct {
 	Query string `json:"query"`
 }
 
+// ExplainRequest contains the request parameters for MethodExplain. Exactly
+// one of Query or Procedure should be set.
+type ExplainRequest struct {
+	DBID      string `json:"dbid"`
+	Query     string `json:"query,omitempty" desc:"ad-hoc SQL query to explain"`
+	Procedure string `json:"procedure,omitempty" desc:"procedure whose SQL statements are explained"`
+}
+
+// QueryPageRequest contains the request parameters for MethodQueryPage. The
+// first page is requested with DBID and Query. Subsequent pages are requested
+// with only the Cursor returned with the previous page.
+type QueryPageRequest struct {
+	DBID   string `json:"dbid,omitempty"`
+	Query  string `json:"query,omitempty"`
+	Cursor string `json:"cursor,omitempty" desc:"continuation token from the previous page"`
+	Limit  int64  `json:"limit,omitempty" desc:"maximum number of rows in the page"`
+}
+
+// ChangesRequest contains the request parameters for MethodChanges. To follow
+// the changes to a dataset, request again from the NextHeight of each response.
+type ChangesRequest struct {
+	DBID       string `json:"dbid"`
+	Table      string `json:"table,omitempty" desc:"only return changes to this table"`
+	FromHeight int64  `json:"from_height" desc:"first block height to return changes from"`
+	Limit      int64  `json:"limit,omitempty" desc:"maximum number of changes, unless the first block has more"`
+}
+
 // TxQueryRequest contains the request parameters for MethodTxQuery.
 type TxQueryRequest struct {
 	TxHash types.HexBytes `json:"tx_hash"`
 }
 
+// WithdrawalProofRequest contains the request parameters for
+// MethodWithdrawalProof.
+type WithdrawalProofRequest struct {
+	TxHash types.HexBytes `json:"tx_hash" desc:"hash of the withdraw transaction"`
+}
+
+// ListResolutionsRequest contains the request parameters for
+// MethodListResolutions. Unset filters are not applied. To get the next page,
+// request again with the NextOffset of the response.
+type ListResolutionsRequest struct {
+	types.ResolutionFilter
+	Offset int64 `json:"offset,omitempty" desc:"number of resolutions to skip"`
+	Limit  int64 `json:"limit,omitempty" desc:"maximum number of resolutions to return"`
+}
+
+// ResolutionRequest contains the request parameters for MethodResolution.
+type ResolutionRequest struct {
+	ID *types.UUID `json:"id" desc:"resolution ID"`
+}
+
 // LoadChangesetsRequest contains the request parameters for MethodLoadChangesets.
 type ChangesetMetadataRequest struct {
 	Height int64 `json:"height"`
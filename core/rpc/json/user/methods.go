@@ -22,4 +22,10 @@ const (
 	MethodMigrationMetadata     jsonrpc.Method = "user.migration_metadata"
 	MethodMigrationGenesisChunk jsonrpc.Method = "user.migration_genesis_chunk"
 	MethodChallenge             jsonrpc.Method = "user.challenge"
+	MethodExplain               jsonrpc.Method = "user.explain"
+	MethodQueryPage             jsonrpc.Method = "user.query_page"
+	MethodChanges               jsonrpc.Method = "user.changes"
+	MethodWithdrawalProof       jsonrpc.Method = "user.withdrawal_proof"
+	MethodListResolutions       jsonrpc.Method = "user.list_resolutions"
+	MethodResolution            jsonrpc.Method = "user.resolution"
 )
@@ -42,6 +42,48 @@ type CallResponse struct {
 // QueryResponse contains the response object for MethodQuery.
 type QueryResponse Result
 
+// QueryPageResponse contains the response object for MethodQueryPage. All pages
+// for a cursor are read from the same database snapshot, which is the state
+// following the block at Height. If NextCursor is empty, there are no more
+// rows.
+type QueryPageResponse struct {
+	Result     []byte `json:"result,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Height     int64  `json:"height"`
+}
+
+// ChangesResponse contains the response object for MethodChanges. Changes are
+// returned for whole blocks, in order of height, and the response ends before
+// the block that would exceed the limit. The response may end before
+// LastHeight even if it has no changes, since the node reads a bounded number
+// of blocks per request. NextHeight is the height from which to request the
+// following changes, and LastHeight is the last committed block height known to
+// the change feed.
+type ChangesResponse struct {
+	Changes    []*types.RowChange `json:"changes"`
+	NextHeight int64              `json:"next_height"`
+	LastHeight int64              `json:"last_height"`
+}
+
+// WithdrawalProofResponse contains the response object for
+// MethodWithdrawalProof.
+type WithdrawalProofResponse = types.WithdrawalProof
+
+// ListResolutionsResponse contains the response object for
+// MethodListResolutions. If NextOffset is zero, there are no more resolutions.
+type ListResolutionsResponse struct {
+	Resolutions []*types.ResolutionInfo `json:"resolutions"`
+	NextOffset  int64                   `json:"next_offset,omitempty"`
+}
+
+// ResolutionResponse contains the response object for MethodResolution.
+type ResolutionResponse = types.ResolutionInfo
+
+// ExplainResponse contains the response object for MethodExplain.
+type ExplainResponse struct {
+	Plans []*types.StatementPlan `json:"plans"`
+}
+
 // ChainInfoResponse contains the response object for MethodChainInfo.
 type ChainInfoResponse = types.ChainInfo
 
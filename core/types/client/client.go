@@ -16,9 +16,11 @@ import (
 
 // Client defines methods are used to talk to a Kwil provider.
 type Client interface {
+	Broadcast(ctx context.Context, tx *transactions.Transaction, opts ...TxOpt) (transactions.TxHash, error)
 	// CallAction. Deprecated: Use Call instead.
 	CallAction(ctx context.Context, dbid string, action string, inputs []any) (*Records, error)
 	Call(ctx context.Context, dbid string, procedure string, inputs []any) (*CallResult, error)
+	Changes(ctx context.Context, dbid, table string, fromHeight, limit int64) (changes []*types.RowChange, nextHeight int64, err error)
 	ChainID() string
 	ChainInfo(ctx context.Context) (*types.ChainInfo, error)
 	DeployDatabase(ctx context.Context, payload *types.Schema, opts ...TxOpt) (transactions.TxHash, error)
@@ -27,6 +29,8 @@ type Client interface {
 	// DEPRECATED: Use Execute instead.
 	ExecuteAction(ctx context.Context, dbid string, action string, tuples [][]any, opts ...TxOpt) (transactions.TxHash, error)
 	Execute(ctx context.Context, dbid string, action string, tuples [][]any, opts ...TxOpt) (transactions.TxHash, error)
+	ExplainQuery(ctx context.Context, dbid string, query string) (*types.StatementPlan, error)
+	ExplainProcedure(ctx context.Context, dbid string, procedure string) ([]*types.StatementPlan, error)
 	GetAccount(ctx context.Context, pubKey []byte, status types.AccountStatus) (*types.Account, error)
 	GetSchema(ctx context.Context, dbid string) (*types.Schema, error)
 	ListDatabases(ctx context.Context, owner []byte) ([]*types.DatasetIdentifier, error)
@@ -35,6 +39,10 @@ type Client interface {
 	TxQuery(ctx context.Context, txHash []byte) (*transactions.TcTxQueryResponse, error)
 	WaitTx(ctx context.Context, txHash []byte, interval time.Duration) (*transactions.TcTxQueryResponse, error)
 	Transfer(ctx context.Context, to []byte, amount *big.Int, opts ...TxOpt) (transactions.TxHash, error)
+	Withdraw(ctx context.Context, recipient []byte, amount *big.Int, opts ...TxOpt) (transactions.TxHash, error)
+	WithdrawalProof(ctx context.Context, txHash []byte) (*types.WithdrawalProof, error)
+	Stake(ctx context.Context, amount *big.Int, opts ...TxOpt) (transactions.TxHash, error)
+	Unstake(ctx context.Context, amount *big.Int, opts ...TxOpt) (transactions.TxHash, error)
 }
 
 // CallResult is the result of a call to a procedure.
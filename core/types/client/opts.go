@@ -14,6 +14,8 @@ type Options struct {
 	Logger log.Logger
 
 	// Signer will be used to sign transactions and set the Sender field on call messages.
+	// To sign with a key held by an HSM or custody service, use an external
+	// signer from the core/crypto/auth/external package.
 	Signer auth.Signer
 
 	// The chain ID will be used in all transactions, which helps prevent replay attacks on
@@ -126,7 +126,8 @@ func inferPrecisionAndScale(s string) (precision, scale uint16) {
 
 	intPart := uint16(len(parts[0]))
 	if len(parts) == 1 {
-		return intPart, 0
+		// zero still has a precision of 1
+		return max(intPart, 1), 0
 	}
 
 	scale = uint16(len(parts[1]))
@@ -150,6 +150,18 @@ func Test_DecimalParsing(t *testing.T) {
 			prec:  1,
 			scale: 0,
 		},
+		{
+			name:  "zero",
+			in:    "0",
+			prec:  1,
+			scale: 0,
+		},
+		{
+			name:  "negative zero",
+			in:    "-000",
+			prec:  1,
+			scale: 0,
+		},
 		{
 			name:  "no int",
 			in:    "0.456",
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/core/types/decimal"
 	"github.com/kwilteam/kwil-db/core/types/serialize"
@@ -34,6 +36,12 @@ const (
 	PayloadTypeValidatorVoteBodies PayloadType = "validator_vote_bodies"
 	PayloadTypeCreateResolution    PayloadType = "create_resolution"
 	PayloadTypeApproveResolution   PayloadType = "approve_resolution"
+	PayloadTypeCreateSession       PayloadType = "create_session"
+	PayloadTypeRevokeSession       PayloadType = "revoke_session"
+	PayloadTypeWithdraw            PayloadType = "withdraw"
+	PayloadTypeValidatorUnjail     PayloadType = "validator_unjail"
+	PayloadTypeStake               PayloadType = "stake"
+	PayloadTypeUnstake             PayloadType = "unstake"
 	// PayloadTypeDeleteResolution    PayloadType = "delete_resolution"
 )
 
@@ -52,6 +60,12 @@ var payloadConcreteTypes = map[PayloadType]Payload{
 	PayloadTypeValidatorVoteBodies: &ValidatorVoteBodies{},
 	PayloadTypeCreateResolution:    &CreateResolution{},
 	PayloadTypeApproveResolution:   &ApproveResolution{},
+	PayloadTypeCreateSession:       &CreateSession{},
+	PayloadTypeRevokeSession:       &RevokeSession{},
+	PayloadTypeWithdraw:            &Withdraw{},
+	PayloadTypeValidatorUnjail:     &ValidatorUnjail{},
+	PayloadTypeStake:               &Stake{},
+	PayloadTypeUnstake:             &Unstake{},
 	// PayloadTypeDeleteResolution:    &DeleteResolution{},
 }
 
@@ -93,6 +107,12 @@ func (p PayloadType) Valid() bool {
 		PayloadTypeTransfer,
 		PayloadTypeCreateResolution,
 		PayloadTypeApproveResolution,
+		PayloadTypeCreateSession,
+		PayloadTypeRevokeSession,
+		PayloadTypeWithdraw,
+		PayloadTypeValidatorUnjail,
+		PayloadTypeStake,
+		PayloadTypeUnstake,
 		// PayloadTypeDeleteResolution,
 		// These should not come in user transactions, but they are not invalid
 		// payload types in general.
@@ -119,6 +139,12 @@ var payloadTypes = map[PayloadType]bool{
 	PayloadTypeValidatorVoteBodies: true,
 	PayloadTypeCreateResolution:    true,
 	PayloadTypeApproveResolution:   true,
+	PayloadTypeCreateSession:       true,
+	PayloadTypeRevokeSession:       true,
+	PayloadTypeWithdraw:            true,
+	PayloadTypeValidatorUnjail:     true,
+	PayloadTypeStake:               true,
+	PayloadTypeUnstake:             true,
 	// PayloadTypeDeleteResolution:    true,
 }
 
@@ -503,6 +529,10 @@ func (v *Transfer) MarshalBinary() ([]byte, error) {
 // a certain amount of power
 type ValidatorJoin struct {
 	Power uint64
+	// ConsensusKey is the ed25519 public key that will sign the validator's
+	// votes and proposals, if it is not the sender's key, such as when they
+	// are signed by a remote signer.
+	ConsensusKey []byte `rlp:"optional"`
 }
 
 func (v *ValidatorJoin) Type() PayloadType {
@@ -578,6 +608,25 @@ func (v *ValidatorLeave) MarshalBinary() ([]byte, error) {
 	return serialize.Encode(v)
 }
 
+// ValidatorUnjail is used by a jailed validator to restore its power after
+// the jail period has passed.
+type ValidatorUnjail struct{}
+
+func (v *ValidatorUnjail) Type() PayloadType {
+	return PayloadTypeValidatorUnjail
+}
+
+var _ encoding.BinaryUnmarshaler = (*ValidatorUnjail)(nil)
+var _ encoding.BinaryMarshaler = (*ValidatorUnjail)(nil)
+
+func (v *ValidatorUnjail) UnmarshalBinary(b []byte) error {
+	return serialize.Decode(b, v)
+}
+
+func (v *ValidatorUnjail) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(v)
+}
+
 // in the future, if/when we go to implement voting based on token weight (instead of validatorship),
 // we will create identical payloads as the VoteIDs and VoteBodies payloads, but with different types
 
@@ -666,6 +715,150 @@ func (v *ApproveResolution) UnmarshalBinary(p0 serialize.SerializedData) error {
 	return serialize.Decode(p0, v)
 }
 
+// SessionScope is a dataset in which a session key may execute procedures and
+// actions. If Procedures is empty, any of the dataset's procedures and actions
+// may be executed.
+type SessionScope struct {
+	DBID       string
+	Procedures []string
+}
+
+// CreateSession is a payload for authorizing a session key to execute
+// procedures and actions as the sender. Transactions signed by the session key
+// are limited to the Scopes, and are valid up to and including the Expiry
+// block height. The sender pays the fees of the session key's transactions, up
+// to a total of MaxFee. The session key consents to the session by signing the
+// ConsentMessage.
+type CreateSession struct {
+	SessionKey []byte
+	Scopes     []*SessionScope
+	MaxFee     string // big.Int
+	Expiry     uint64
+	Consent    *auth.Signature // by the session key
+}
+
+const sessionConsentTmpl = `Authorize this key to act for the delegator.
+
+Delegator: %x
+Scopes: %s
+MaxFee: %s
+Expiry: %d
+
+Kwil Chain ID: %s
+`
+
+// ConsentMessage is the message that the session key signs to consent to
+// acting for the delegator in the session. It includes the terms of the session
+// and the chain ID, so the signature cannot be used for other sessions.
+func (c *CreateSession) ConsentMessage(delegator []byte, chainID string) []byte {
+	scopes := make([]string, len(c.Scopes))
+	for i, scope := range c.Scopes {
+		scopes[i] = scope.DBID
+		if len(scope.Procedures) > 0 {
+			scopes[i] += ":" + strings.Join(scope.Procedures, ",")
+		}
+	}
+	return []byte(fmt.Sprintf(sessionConsentTmpl, delegator, strings.Join(scopes, " "),
+		c.MaxFee, c.Expiry, chainID))
+}
+
+var _ Payload = (*CreateSession)(nil)
+
+func (c *CreateSession) MarshalBinary() (serialize.SerializedData, error) {
+	return serialize.Encode(c)
+}
+
+func (c *CreateSession) Type() PayloadType {
+	return PayloadTypeCreateSession
+}
+
+func (c *CreateSession) UnmarshalBinary(p0 serialize.SerializedData) error {
+	return serialize.Decode(p0, c)
+}
+
+// RevokeSession is a payload for revoking a session key created by the
+// sender. The session key may not be used again.
+type RevokeSession struct {
+	SessionKey []byte
+}
+
+var _ Payload = (*RevokeSession)(nil)
+
+func (r *RevokeSession) MarshalBinary() (serialize.SerializedData, error) {
+	return serialize.Encode(r)
+}
+
+func (r *RevokeSession) Type() PayloadType {
+	return PayloadTypeRevokeSession
+}
+
+func (r *RevokeSession) UnmarshalBinary(p0 serialize.SerializedData) error {
+	return serialize.Decode(p0, r)
+}
+
+// Withdraw is a payload for withdrawing tokens from the sender's account back
+// to an Ethereum address. The amount is removed from the sender's balance, and
+// is released to the Recipient by the bridge contract once the validators
+// attest to the epoch that includes the withdrawal.
+type Withdraw struct {
+	Recipient []byte // 20 byte Ethereum address
+	Amount    string // big.Int
+}
+
+var _ Payload = (*Withdraw)(nil)
+
+func (w *Withdraw) MarshalBinary() (serialize.SerializedData, error) {
+	return serialize.Encode(w)
+}
+
+func (w *Withdraw) Type() PayloadType {
+	return PayloadTypeWithdraw
+}
+
+func (w *Withdraw) UnmarshalBinary(p0 serialize.SerializedData) error {
+	return serialize.Decode(p0, w)
+}
+
+// Stake is a payload for staking tokens from the sender's balance. On networks
+// with staking enabled, a validator's power is derived from its stake.
+type Stake struct {
+	Amount string // big.Int
+}
+
+var _ Payload = (*Stake)(nil)
+
+func (s *Stake) MarshalBinary() (serialize.SerializedData, error) {
+	return serialize.Encode(s)
+}
+
+func (s *Stake) Type() PayloadType {
+	return PayloadTypeStake
+}
+
+func (s *Stake) UnmarshalBinary(p0 serialize.SerializedData) error {
+	return serialize.Decode(p0, s)
+}
+
+// Unstake is a payload for unstaking tokens, which are returned to the
+// sender's balance after the network's unbonding period.
+type Unstake struct {
+	Amount string // big.Int
+}
+
+var _ Payload = (*Unstake)(nil)
+
+func (u *Unstake) MarshalBinary() (serialize.SerializedData, error) {
+	return serialize.Encode(u)
+}
+
+func (u *Unstake) Type() PayloadType {
+	return PayloadTypeUnstake
+}
+
+func (u *Unstake) UnmarshalBinary(p0 serialize.SerializedData) error {
+	return serialize.Decode(p0, u)
+}
+
 /* no delete resolution for now since it has never been tested and has no immediate use
 
 // DeleteResolution is a payload for deleting a resolution.
@@ -148,6 +148,13 @@ func Test_Types(t *testing.T) {
 				Power: 1,
 			},
 		},
+		{
+			name: "validator_join with consensus key",
+			obj: &transactions.ValidatorJoin{
+				Power:        1,
+				ConsensusKey: []byte("asdfadsf"),
+			},
+		},
 		{
 			name: "validator_leave",
 			obj:  &transactions.ValidatorLeave{},
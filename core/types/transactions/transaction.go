@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/kwilteam/kwil-db/core/crypto"
@@ -12,6 +13,8 @@ import (
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/core/types/serialize"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
 	gethTypes "github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
@@ -63,7 +66,8 @@ const (
 	// SignedMsgConcat is a human-readable serialization of the transaction body
 	// it needs a signer that signs
 	SignedMsgConcat SignedMsgSerializationType = "concat"
-	// SignedMsgEip712 is specific serialization for EIP712
+	// SignedMsgEip712 is the JSON EIP-712 typed data of the transaction body,
+	// which must be signed by an EIP-712 signer such as auth.Eip712Signer.
 	SignedMsgEip712 SignedMsgSerializationType = "eip712"
 
 	// DefaultSignedMsgSerType is the default serialization type
@@ -72,33 +76,48 @@ const (
 )
 
 const (
-	// EIP712DomainTypeName TODO: to test if we can change this
+	// EIP712DomainTypeName is the name of the EIP-712 domain type.
 	EIP712DomainTypeName = "EIP712Domain"
+	// EIP712PrimaryTypeName is the name of the EIP-712 primary type of a
+	// transaction body, which wallets display as the title of the message.
+	EIP712PrimaryTypeName = "Transaction"
+
+	// EIP712DomainName and EIP712DomainVersion identify the EIP-712 domain
+	// of Kwil transactions.
+	EIP712DomainName    = "Kwil"
+	EIP712DomainVersion = "1"
 )
 
-// EIP712TypedDomain represents the domain separator for EIP712
+// EIP712TypedDomain represents the domain separator for EIP712. A Kwil chain ID
+// is not numeric, so the domain has no chainId, which wallets would compare to
+// the connected EVM chain. Instead, the salt is the keccak256 hash of the Kwil
+// chain ID, which binds the signature to the chain. See EIP712Salt.
 var EIP712TypedDomain = []gethTypes.Type{
 	{Name: "name", Type: "string"},
 	{Name: "version", Type: "string"},
-	//{Name: "chainId", Type: "uint256"},
-	//{Name: "verifyingContract", Type: "address"},
-	// NOTE: Domain.Salt is different from TransactionBody.Salt,
-	// Domain.Salt is last resort to distinguish different Dapp
-	{Name: "salt", Type: "string"},
+	{Name: "salt", Type: "bytes32"},
 }
 
 // EIP712TypedDataMessage represents the primary type for EIP712 data
 var EIP712TypedDataMessage = []gethTypes.Type{
-	// type is the type of the payload
+	// description is the transaction's description
+	{Name: "description", Type: "string"},
+	// payload_type is the type of the payload
 	{Name: "payload_type", Type: "string"},
-	// data is the payload, which is a JSON string
-	{Name: "payload_digest", Type: "string"},
+	// payload_digest is the sha256 hash of the serialized payload
+	{Name: "payload_digest", Type: "bytes32"},
 	// fee is the fee for the transaction
-	{Name: "fee", Type: "string"},
+	{Name: "fee", Type: "uint256"},
 	// nonce is the nonce for the transaction
-	{Name: "nonce", Type: "string"},
-	// chainID is the chain for which the transaction is valid
-	{Name: "chainID", Type: "string"}, // ugh, string
+	{Name: "nonce", Type: "uint64"},
+	// chain_id is the chain for which the transaction is valid, which is also
+	// committed to by the domain salt, but is displayed here
+	{Name: "chain_id", Type: "string"},
+}
+
+// EIP712Salt returns the EIP-712 domain salt for a Kwil chain ID.
+func EIP712Salt(chainID string) []byte {
+	return ethCrypto.Keccak256([]byte(chainID))
 }
 
 // CreateTransaction creates a new unsigned transaction.
@@ -257,6 +276,37 @@ func (t *TransactionBody) MarshalBinary() ([]byte, error) {
 	return serialize.Encode(t)
 }
 
+// EIP712TypedData returns the EIP-712 typed data of the transaction body. This
+// is the message signed with the SignedMsgEip712 serialization.
+func (t *TransactionBody) EIP712TypedData() *gethTypes.TypedData {
+	fee := t.Fee
+	if fee == nil {
+		fee = big.NewInt(0)
+	}
+	return &gethTypes.TypedData{
+		Types: gethTypes.Types{
+			EIP712DomainTypeName:  EIP712TypedDomain,
+			EIP712PrimaryTypeName: EIP712TypedDataMessage,
+		},
+		PrimaryType: EIP712PrimaryTypeName,
+		Domain: gethTypes.TypedDataDomain{
+			Name:    EIP712DomainName,
+			Version: EIP712DomainVersion,
+			Salt:    hexutil.Encode(EIP712Salt(t.ChainID)),
+		},
+		// Integers are given as decimal strings, which survive a JSON round
+		// trip, unlike JSON numbers.
+		Message: gethTypes.TypedDataMessage{
+			"description":    t.Description,
+			"payload_type":   t.PayloadType.String(),
+			"payload_digest": hexutil.Encode(crypto.Sha256(t.Payload)),
+			"fee":            fee.String(),
+			"nonce":          strconv.FormatUint(t.Nonce, 10),
+			"chain_id":       t.ChainID,
+		},
+	}
+}
+
 // SerializeMsg prepares a message for signing or verification using a certain
 // message construction format. This is done since a Kwil transaction is foreign
 // to wallets, and it is signed as a message, not a transaction that is native
@@ -283,32 +333,11 @@ func (t *TransactionBody) SerializeMsg(mst SignedMsgSerializationType) ([]byte,
 			t.Nonce,
 			t.ChainID)
 		return []byte(msgStr), nil
-		//case SignedMsgEip712:
-		//	signerData := gethTypes.TypedData{
-		//		Types: gethTypes.Types{
-		//			EIP712DomainTypeName: EIP712TypedDomain,
-		//			"Message":            EIP712TypedDataMessage,
-		//		},
-		//		PrimaryType: "Message",
-		//		Domain: gethTypes.TypedDataDomain{
-		//			Name:    "Kwil", // TODO: should this be the name of the Dapp?
-		//			Version: "1",
-		//			// NOTE: not sure what should be treated as the Dapp on kwil
-		//			// either kwil itself or the Kuneiform
-		//			// if Kuneiform, DB_ID could be the salt?
-		//			Salt: hex.EncodeToString(t.Salt),
-		//		},
-		//		Message: gethTypes.TypedDataMessage{
-		//			"payload_type":   t.PayloadType.String(),
-		//			"payload_digest": string(t.Payload),
-		//			"fee":            t.Fee,
-		//			"nonce":          t.Nonce,
-		//			"salt":           hex.EncodeToString(t.Salt),
-		//		},
-		//	}
-		//
-		//	return eip712TypedDataAndHash(signerData)
-
+	case SignedMsgEip712:
+		// The message is the JSON typed data, as given to a wallet's
+		// eth_signTypedData_v4 method, so that it may be displayed field by
+		// field. The signer and authenticator hash it per EIP-712.
+		return json.Marshal(t.EIP712TypedData())
 	}
 	return nil, errors.New("invalid serialization type")
 }
@@ -319,22 +348,3 @@ type TxHash []byte
 func (h TxHash) Hex() string {
 	return strings.ToUpper(fmt.Sprintf("%x", h))
 }
-
-//// eip712TypedDataAndHash returns the `hashStruct(eip712Domain) || hashStruct(message)`
-//// of the given TypedData.
-//// It's different from the function `TypedDataAndHash` in go-ethereum signer
-//// package in that it only return `<version specific data> <data to sign>`.
-//func eip712TypedDataAndHash(typedData gethTypes.TypedData) ([]byte, error) {
-//	domainSeparator, err := typedData.HashStruct(EIP712DomainTypeName, typedData.Domain.Map())
-//	if err != nil {
-//		return nil, err
-//	}
-//
-//	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
-//	if err != nil {
-//		return nil, err
-//	}
-//
-//	rawData := fmt.Sprintf("%s%s", string(domainSeparator), string(typedDataHash))
-//	return []byte(rawData), nil
-//}
@@ -160,6 +160,7 @@ func TestTransaction_Sign(t *testing.T) {
 	require.NoError(t, err, "error parse private secp2561k1PvKeyHex")
 
 	ethPersonalSigner := auth.EthPersonalSigner{Key: *secp256k1PrivateKey}
+	eip712Signer := auth.Eip712Signer{Key: *secp256k1PrivateKey}
 
 	expectPersonalSignConcatSigHex := "e09459d0dc078f12bb176da6ec52764ac457e322644f4031a6c498979795eff16163edbfe2c68ba60e25d6a76a283f63662245555caecf68889fbfad786ae52801"
 	expectPersonalSignConcatSigBytes, _ := hex.DecodeString(expectPersonalSignConcatSigHex)
@@ -168,6 +169,13 @@ func TestTransaction_Sign(t *testing.T) {
 		Type:      auth.EthPersonalSignAuth,
 	}
 
+	expectEip712SigHex := "ad585d95ff7d29a0b3aa1bbdf2f379c01ae5b526c0b5f76cc7d19c2d9090613d654747117940bb592f59cd55d488d231b20427b2720a0faf03e26f9faf8ce99f00"
+	expectEip712SigBytes, _ := hex.DecodeString(expectEip712SigHex)
+	expectEip712Sig := &auth.Signature{
+		Signature: expectEip712SigBytes,
+		Type:      auth.EthEip712Auth,
+	}
+
 	rawPayload := transactions.ActionExecution{
 		DBID:   "xf617af1ca774ebbd6d23e8fe12c56d41d25a22d81e88f67c6c6ee0d4",
 		Action: "create_user",
@@ -211,6 +219,16 @@ func TestTransaction_Sign(t *testing.T) {
 			authenticator: &auth.EthSecp256k1Authenticator{},
 			wantSig:       expectPersonalSignConcatSig,
 		},
+		{
+			// The EIP-712 authenticator is an extension, tested with this
+			// signature in extensions/auth.
+			name: "eth eip712 typed data",
+			args: args{
+				mst:    transactions.SignedMsgEip712,
+				signer: &eip712Signer,
+			},
+			wantSig: expectEip712Sig,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t1 *testing.T) {
@@ -238,6 +256,10 @@ func TestTransaction_Sign(t *testing.T) {
 			require.Equal(t1, hex.EncodeToString(tt.wantSig.Signature),
 				hex.EncodeToString(tx.Signature.Signature), "mismatch signature")
 
+			if tt.authenticator == nil {
+				return
+			}
+
 			msgBts, err := tx.SerializeMsg()
 			require.NoError(t1, err, "error serializing message")
 
@@ -331,3 +353,57 @@ abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ
 		})
 	}
 }
+
+func TestTransactionBody_EIP712TypedData(t *testing.T) {
+	rawPayload := transactions.ActionExecution{
+		DBID:   "xf617af1ca774ebbd6d23e8fe12c56d41d25a22d81e88f67c6c6ee0d4",
+		Action: "create_user",
+		Arguments: [][]*transactions.EncodedValue{
+			{
+				mustDetect("foo"),
+				mustDetect(32),
+			},
+		},
+	}
+
+	payload, err := rawPayload.MarshalBinary()
+	require.NoError(t, err)
+
+	txBody := &transactions.TransactionBody{
+		Description: "By signing this message, you'll reveal your xxx to zzz",
+		Payload:     payload,
+		PayloadType: rawPayload.Type(),
+		Fee:         big.NewInt(100),
+		Nonce:       1,
+		ChainID:     "00000000000",
+	}
+
+	msg, err := txBody.SerializeMsg(transactions.SignedMsgEip712)
+	require.NoError(t, err)
+
+	// The message is the JSON typed data given to eth_signTypedData_v4.
+	var typedData map[string]any
+	require.NoError(t, json.Unmarshal(msg, &typedData))
+	assert.Equal(t, transactions.EIP712PrimaryTypeName, typedData["primaryType"])
+	assert.Equal(t, map[string]any{
+		"description":    txBody.Description,
+		"payload_type":   "execute",
+		"payload_digest": "0x208b88e13ec618f1867ed3546a148aec5c851f11915643bf96afc120456c01e9",
+		"fee":            "100",
+		"nonce":          "1",
+		"chain_id":       "00000000000",
+	}, typedData["message"])
+
+	// The hash that is signed is stable for this version of the typed data.
+	hash, err := auth.Eip712Hash(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "7535932f3ac0425fdbfb7fbba5075d7da0c5c72d278fb380eb9ad214bc9f5430", hex.EncodeToString(hash))
+
+	// A different chain ID changes the domain salt, and thus the hash.
+	txBody.ChainID = "kwil-testnet"
+	msg2, err := txBody.SerializeMsg(transactions.SignedMsgEip712)
+	require.NoError(t, err)
+	hash2, err := auth.Eip712Hash(msg2)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, hash2)
+}
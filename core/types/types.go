@@ -1,8 +1,11 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+
+	jsonUtil "github.com/kwilteam/kwil-db/core/utils/json"
 )
 
 // TODO: doc it all
@@ -42,6 +45,46 @@ type JoinRequest struct {
 type Validator struct {
 	PubKey []byte `json:"pubkey"`
 	Power  int64  `json:"power"`
+
+	// ConsensusKey is the key that signs the validator's votes and proposals,
+	// such as with a remote signer. It is only set if it is not PubKey, which
+	// identifies the validator and signs its transactions.
+	ConsensusKey []byte `json:"consensus_key,omitempty"`
+
+	// Uptime is the validator's recent block signing record. It is only set
+	// when listing validators on a network that tracks validator liveness.
+	Uptime *ValidatorUptime `json:"uptime,omitempty"`
+}
+
+// ValidatorUptime is a validator's block signing record in the most recent
+// blocks. A jailed validator has no power until it is unjailed.
+type ValidatorUptime struct {
+	Window       int64 `json:"window"`        // number of recent blocks in which missed blocks are counted
+	MissedBlocks int64 `json:"missed_blocks"` // number of blocks in the window the validator did not sign
+	Jailed       bool  `json:"jailed"`
+	JailedHeight int64 `json:"jailed_height,omitempty"` // the block height at which the validator was jailed
+}
+
+// Types of validator misbehavior evidence.
+const (
+	EvidenceTypeDuplicateVote     = "duplicate_vote"
+	EvidenceTypeLightClientAttack = "light_client_attack"
+)
+
+// ValidatorEvidence is a record of a validator's misbehavior reported by the
+// consensus engine, and the punishment applied to the validator.
+type ValidatorEvidence struct {
+	Validator HexBytes `json:"validator"`
+	Type      string   `json:"type"`   // e.g. EvidenceTypeDuplicateVote
+	Height    int64    `json:"height"` // the block height of the misbehavior
+	Time      int64    `json:"time"`   // the block time of the misbehavior, in unix seconds
+	// CommitHeight is the block height in which the evidence was committed
+	// and the validator punished.
+	CommitHeight int64 `json:"commit_height"`
+	PowerBefore  int64 `json:"power_before"`
+	PowerAfter   int64 `json:"power_after"`
+	// Slashed is the amount of tokens burned from the validator's account.
+	Slashed string `json:"slashed"`
 }
 
 // ValidatorRemoveProposal is a proposal from an existing validator (remover) to
@@ -81,6 +124,47 @@ type PendingResolution struct {
 	Approved     []bool   `json:"approved"`      // Approved is the list of bools indicating if the corresponding validator approved the resolution
 }
 
+// Resolution statuses. A pending resolution is awaiting votes. Approved and
+// expired resolutions are closed.
+const (
+	ResolutionStatusPending  = "pending"
+	ResolutionStatusApproved = "approved"
+	ResolutionStatusExpired  = "expired"
+)
+
+// ResolutionInfo describes a resolution and its votes.
+type ResolutionInfo struct {
+	ID        *UUID    `json:"id"`
+	Type      string   `json:"type"`
+	Proposer  HexBytes `json:"proposer,omitempty"` // the node that supplied the resolution body
+	ExpiresAt int64    `json:"expires_at"`         // the block height at which the resolution expires
+	Status    string   `json:"status"`             // e.g. ResolutionStatusPending
+	// Votes is the number of validators that approved the resolution.
+	Votes         int64 `json:"votes"`
+	ApprovedPower int64 `json:"approved_power"`
+	// RequiredPower is the power required to approve the resolution. For a
+	// pending resolution, it is based on the current validator set.
+	RequiredPower int64 `json:"required_power"`
+	// ClosedHeight is the block height at which a closed resolution was
+	// approved or expired.
+	ClosedHeight int64 `json:"closed_height,omitempty"`
+	// Body and Voters are only set when inspecting a pending resolution.
+	Body   HexBytes   `json:"body,omitempty"`
+	Voters []HexBytes `json:"voters,omitempty"`
+}
+
+// ResolutionFilter filters a listing of resolutions. Fields with the zero value
+// are not filtered on.
+type ResolutionFilter struct {
+	Type     string   `json:"type,omitempty"`
+	Proposer HexBytes `json:"proposer,omitempty"` // the node that supplied the resolution body
+	Status   string   `json:"status,omitempty"`   // e.g. ResolutionStatusPending
+	// MinExpiration and MaxExpiration are the inclusive range of expiration
+	// heights.
+	MinExpiration int64 `json:"min_expiration,omitempty"`
+	MaxExpiration int64 `json:"max_expiration,omitempty"`
+}
+
 // Migration is a migration resolution that is proposed by a validator
 // for initiating the migration process.
 type Migration struct {
@@ -184,3 +268,92 @@ type Health struct {
 	// can discern node state and the mode of interaction with one request.
 	Mode ServiceMode `json:"mode"` // e.g. "private"
 }
+
+// StatementPlan describes how a single SQL statement is planned, both by
+// Kwil's logical planner and by PostgreSQL.
+type StatementPlan struct {
+	// SQL is the PostgreSQL statement generated from the Kwil SQL. Any
+	// procedure variables are numbered parameters, named in Params.
+	SQL    string   `json:"sql"`
+	Params []string `json:"params,omitempty"`
+	// LogicalPlan is the formatted Kwil logical plan tree.
+	LogicalPlan string `json:"logical_plan,omitempty"`
+	// PostgresPlan is the output of PostgreSQL's EXPLAIN (FORMAT JSON).
+	PostgresPlan json.RawMessage `json:"postgres_plan,omitempty"`
+	// Error is set if either plan could not be created.
+	Error string `json:"error,omitempty"`
+}
+
+// RowChange is a change to a row of a dataset table that was committed in a
+// block, as recorded by a node's change data capture feed.
+type RowChange struct {
+	Height int64  `json:"height"`
+	DBID   string `json:"dbid"`
+	Table  string `json:"table"`
+	// Kind is one of "insert", "update", or "delete".
+	Kind string `json:"kind"`
+	// Old is the row before an update or delete, keyed by column name.
+	Old map[string]any `json:"old,omitempty"`
+	// New is the row after an insert or update, keyed by column name.
+	New map[string]any `json:"new,omitempty"`
+}
+
+// UnmarshalJSON decodes a RowChange, decoding integer values as int64 rather
+// than float64.
+func (rc *RowChange) UnmarshalJSON(b []byte) error {
+	type rowChange RowChange // no UnmarshalJSON method
+	var raw struct {
+		rowChange
+		Old json.RawMessage `json:"old"`
+		New json.RawMessage `json:"new"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*rc = RowChange(raw.rowChange)
+	var err error
+	if len(raw.Old) > 0 && string(raw.Old) != "null" {
+		if rc.Old, err = jsonUtil.UnmarshalMapWithoutFloat[map[string]any](raw.Old); err != nil {
+			return err
+		}
+	}
+	if len(raw.New) > 0 && string(raw.New) != "null" {
+		if rc.New, err = jsonUtil.UnmarshalMapWithoutFloat[map[string]any](raw.New); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithdrawalProof proves that a withdrawal was included in an epoch's Merkle
+// root, and carries the validator signatures attesting to the root. A bridge
+// contract releases the withdrawn tokens to the recipient given the proof.
+type WithdrawalProof struct {
+	TxHash    HexBytes `json:"tx_hash"`
+	Recipient HexBytes `json:"recipient"` // 20 byte Ethereum address
+	Amount    string   `json:"amount"`    // big.Int
+	// Epoch is the epoch that includes the withdrawal. The Root, Proof, and
+	// Signatures are only set once the epoch has ended.
+	Epoch int64 `json:"epoch"`
+	// Root is the Merkle root of the withdrawals in the epoch.
+	Root HexBytes `json:"root,omitempty"`
+	// Proof is the sibling hashes from the withdrawal's leaf to the Root.
+	Proof []HexBytes `json:"proof,omitempty"`
+	// Signatures are the validator signatures of the epoch's attestation.
+	Signatures []*WithdrawalSignature `json:"signatures,omitempty"`
+	// Confirmed is true once validators with at least two thirds of the
+	// voting power have signed the attestation.
+	Confirmed bool `json:"confirmed"`
+}
+
+// WithdrawalSignature is a validator's signature of an epoch's withdrawal
+// attestation. The Signature is a 65 byte secp256k1 signature by the
+// validator's attestation key, whose Ethereum address is the Attester, so that
+// it can be checked with ecrecover.
+type WithdrawalSignature struct {
+	Validator HexBytes `json:"validator"`
+	Attester  HexBytes `json:"attester"`
+	Signature HexBytes `json:"signature"`
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+	This implements Ethereum EIP-712 typed data signatures as a Kwil
+	authentication driver.
+
+	A transaction signed with this authenticator must use the "eip712"
+	serialization, which makes the signed message the JSON typed data of the
+	transaction body (see TransactionBody.EIP712TypedData), as given to a
+	wallet's eth_signTypedData_v4 method. The signature is of the EIP-712 hash:
+
+		keccak256(0x19 || 0x01 || domainSeparator || hashStruct(message))
+
+	Messages that are not typed data, such as the text of a call message, are
+	verified as EIP-191 personal_sign signatures so that the same signer may be
+	used for both. See auth.Eip712Hash.
+
+	This is not registered by default. It is added by the canonical "eip712"
+	hardfork (see the consensus extension package) when that is activated in
+	the genesis config.
+*/
+
+// eip712SignatureLength is the expected length of a signature
+const eip712SignatureLength = 65
+
+// Eip712Authenticator is the authenticator for the Ethereum EIP-712 typed data
+// signature type created by an auth.Eip712Signer. Identities are Ethereum
+// addresses.
+type Eip712Authenticator struct{}
+
+var _ auth.Authenticator = Eip712Authenticator{}
+
+// Identifier returns an ethereum address hex string from address bytes.
+// It will include the 0x prefix, and the address will be checksum-able.
+func (Eip712Authenticator) Identifier(ident []byte) (string, error) {
+	return ethCommon.BytesToAddress(ident).Hex(), nil
+}
+
+// Verify recovers the signer's address from the signature of the EIP-712 hash
+// of the message, and checks that it is the identity.
+func (Eip712Authenticator) Verify(identity []byte, msg []byte, signature []byte) error {
+	// signature is 65 bytes, [R || S || V] format
+	if len(signature) != eip712SignatureLength {
+		return fmt.Errorf("invalid signature length: expected %d, received %d",
+			eip712SignatureLength, len(signature))
+	}
+
+	// Transform yellow paper V from 27/28 to 0/1 without modifying the
+	// caller's signature.
+	sig := bytes.Clone(signature)
+	if sig[ethCrypto.RecoveryIDOffset] == 27 || sig[ethCrypto.RecoveryIDOffset] == 28 {
+		sig[ethCrypto.RecoveryIDOffset] -= 27
+	}
+
+	hash, err := auth.Eip712Hash(msg)
+	if err != nil {
+		return err
+	}
+
+	pubkeyBytes, err := ethCrypto.Ecrecover(hash, sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: recover public key failed: %w", err)
+	}
+
+	addr := ethCommon.BytesToAddress(ethCrypto.Keccak256(pubkeyBytes[1:])[12:])
+	if !bytes.Equal(addr.Bytes(), identity) {
+		return fmt.Errorf("invalid signature: expected address %x, received %x", identity, addr.Bytes())
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package auth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	coreauth "github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/auth"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Eip712(t *testing.T) {
+	key, err := crypto.Secp256k1PrivateKeyFromHex("f1aa5a7966c3863ccde3047f6a1e266cdc0c76b399e256b8fede92b1c69e4f4e")
+	require.NoError(t, err)
+	signer := &coreauth.Eip712Signer{Key: *key}
+	authenticator := auth.Eip712Authenticator{}
+
+	ident, err := authenticator.Identifier(signer.Identity())
+	require.NoError(t, err)
+	require.Equal(t, "0xc89D42189f0450C2b2c3c61f58Ec5d628176A1E7", ident)
+
+	tx := &transactions.Transaction{
+		Body: &transactions.TransactionBody{
+			Payload:     []byte("payload"),
+			PayloadType: transactions.PayloadTypeExecute,
+			Fee:         big.NewInt(100),
+			Nonce:       1,
+			ChainID:     "kwil-testnet",
+		},
+		Serialization: transactions.SignedMsgEip712,
+	}
+	require.NoError(t, tx.Sign(signer))
+
+	msg, err := tx.SerializeMsg()
+	require.NoError(t, err)
+	require.NoError(t, authenticator.Verify(tx.Sender, msg, tx.Signature.Signature))
+
+	// Changing any field of the body invalidates the signature.
+	tx.Body.Nonce++
+	msg, err = tx.SerializeMsg()
+	require.NoError(t, err)
+	require.Error(t, authenticator.Verify(tx.Sender, msg, tx.Signature.Signature))
+
+	// A personal_sign signature of the typed data text is not accepted.
+	tx.Body.Nonce--
+	msg, err = tx.SerializeMsg()
+	require.NoError(t, err)
+	ethSig, err := (&coreauth.EthPersonalSigner{Key: *key}).Sign(msg)
+	require.NoError(t, err)
+	require.Error(t, authenticator.Verify(tx.Sender, msg, ethSig.Signature))
+
+	// Plain text messages, like call messages, are signed as with personal_sign.
+	textSig, err := signer.Sign([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, authenticator.Verify(signer.Identity(), []byte("foo"), textSig.Signature))
+}
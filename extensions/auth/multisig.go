@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+/*
+	This implements k-of-n multisig accounts as a Kwil authentication driver.
+
+	The identity (sender) of a multisig account is the sha256 hash of the
+	serialized auth.MultiSig, which commits to the member keys and the
+	threshold. Member keys may be of any registered authenticator type other
+	than multisig.
+
+	The signature is a serialized auth.MultiSigSignature, which contains the
+	MultiSig and the member signatures of the message, each of which is
+	verified with the registered authenticator of the member key's type. It is
+	created with an auth.MultiSigSigner, which collects the member signatures.
+*/
+
+// MultiSigAuthenticator verifies multisig signatures. The member signatures
+// are verified with the authenticators registered with RegisterAuthenticator.
+type MultiSigAuthenticator struct {
+	// GetAuthenticator, if set, is used instead of the registry to get the
+	// authenticators of the member keys.
+	GetAuthenticator func(name string) (auth.Authenticator, error)
+}
+
+var _ auth.Authenticator = MultiSigAuthenticator{}
+
+// Identifier returns the hex encoded identity of the multisig account.
+func (MultiSigAuthenticator) Identifier(identity []byte) (string, error) {
+	if len(identity) != 32 {
+		return "", fmt.Errorf("invalid multisig identity length %d", len(identity))
+	}
+	return hex.EncodeToString(identity), nil
+}
+
+// Verify checks that the multisig signature is for the multisig account with
+// the identity, and that at least threshold member keys signed the message.
+func (m MultiSigAuthenticator) Verify(identity []byte, msg []byte, signature []byte) error {
+	var sig auth.MultiSigSignature
+	if err := sig.UnmarshalBinary(signature); err != nil {
+		return fmt.Errorf("invalid multisig signature: %w", err)
+	}
+
+	ms := sig.MultiSig
+	if ms == nil {
+		return errors.New("invalid multisig signature: no multisig")
+	}
+	if err := ms.Validate(); err != nil {
+		return err
+	}
+	msIdentity, err := ms.Identity()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(msIdentity, identity) {
+		return fmt.Errorf("multisig %x is not the sender %x", msIdentity, identity)
+	}
+
+	if len(sig.Parts) < int(ms.Threshold) {
+		return fmt.Errorf("have %d of %d required multisig signatures", len(sig.Parts), ms.Threshold)
+	}
+
+	getAuthenticator := m.GetAuthenticator
+	if getAuthenticator == nil {
+		getAuthenticator = GetAuthenticator
+	}
+
+	for i, part := range sig.Parts {
+		// The parts must be sorted by key index so there are no duplicates.
+		if i > 0 && part.Index <= sig.Parts[i-1].Index {
+			return errors.New("multisig signatures are not sorted and unique")
+		}
+		if int(part.Index) >= len(ms.Keys) {
+			return fmt.Errorf("invalid multisig key index %d", part.Index)
+		}
+		key := ms.Keys[part.Index]
+
+		authn, err := getAuthenticator(key.AuthType)
+		if err != nil {
+			return err
+		}
+		if err = authn.Verify(key.Identity, msg, part.Signature); err != nil {
+			return fmt.Errorf("multisig key %d: %w", part.Index, err)
+		}
+	}
+
+	return nil
+}
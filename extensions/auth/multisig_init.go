@@ -0,0 +1,12 @@
+//go:build auth_multisig || ext_test
+
+package auth
+
+import "github.com/kwilteam/kwil-db/core/crypto/auth"
+
+func init() {
+	err := RegisterAuthenticator(ModAdd, auth.MultiSigAuth, MultiSigAuthenticator{})
+	if err != nil {
+		panic(err)
+	}
+}
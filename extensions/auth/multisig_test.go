@@ -0,0 +1,100 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	coreauth "github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/extensions/auth"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MultiSig(t *testing.T) {
+	secpKey, err := crypto.GenerateSecp256k1Key()
+	require.NoError(t, err)
+	edKey, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	edKey2, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+
+	signers := []coreauth.Signer{
+		&coreauth.EthPersonalSigner{Key: *secpKey},
+		&coreauth.Ed25519Signer{Ed25519PrivateKey: *edKey},
+		&coreauth.Ed25519Signer{Ed25519PrivateKey: *edKey2},
+	}
+	var keys []*coreauth.MultiSigKey
+	for _, s := range signers {
+		keys = append(keys, &coreauth.MultiSigKey{AuthType: s.AuthType(), Identity: s.Identity()})
+	}
+
+	ms, err := coreauth.NewMultiSig(2, keys)
+	require.NoError(t, err)
+
+	authenticator := auth.MultiSigAuthenticator{
+		GetAuthenticator: func(name string) (coreauth.Authenticator, error) {
+			switch name {
+			case coreauth.EthPersonalSignAuth:
+				return coreauth.EthSecp256k1Authenticator{}, nil
+			case coreauth.Ed25519Auth:
+				return coreauth.Ed25519Authenticator{}, nil
+			}
+			return nil, auth.ErrAuthenticatorNotFound
+		},
+	}
+
+	msg := []byte("foo")
+
+	// Two members sign separately, offline.
+	signer1, err := coreauth.NewMultiSigSigner(ms)
+	require.NoError(t, err)
+	require.NoError(t, signer1.SignWith(signers[0], msg))
+	partial, err := signer1.Partial()
+	require.NoError(t, err)
+
+	_, err = signer1.Sign(msg)
+	require.Error(t, err) // 1 of 2
+	require.Error(t, authenticator.Verify(signer1.Identity(), msg, partial.Signature))
+
+	signer2, err := coreauth.NewMultiSigSigner(ms)
+	require.NoError(t, err)
+	require.NoError(t, signer2.Merge(partial))
+	require.NoError(t, signer2.SignWith(signers[2], msg))
+	sig, err := signer2.Sign(msg)
+	require.NoError(t, err)
+	require.Equal(t, coreauth.MultiSigAuth, sig.Type)
+
+	require.NoError(t, authenticator.Verify(signer2.Identity(), msg, sig.Signature))
+	require.Error(t, authenticator.Verify(signer2.Identity(), []byte("bar"), sig.Signature))
+
+	// The identity does not depend on the order of the keys.
+	ms2, err := coreauth.NewMultiSig(2, []*coreauth.MultiSigKey{keys[2], keys[0], keys[1]})
+	require.NoError(t, err)
+	id2, err := ms2.Identity()
+	require.NoError(t, err)
+	require.Equal(t, signer1.Identity(), id2)
+
+	// A different threshold is a different account.
+	ms3, err := coreauth.NewMultiSig(1, keys)
+	require.NoError(t, err)
+	signer3, err := coreauth.NewMultiSigSigner(ms3, signers[1])
+	require.NoError(t, err)
+	require.NotEqual(t, signer1.Identity(), signer3.Identity())
+	sig3, err := signer3.Sign(msg)
+	require.NoError(t, err)
+	require.NoError(t, authenticator.Verify(signer3.Identity(), msg, sig3.Signature))
+	require.Error(t, authenticator.Verify(signer1.Identity(), msg, sig3.Signature))
+
+	// Non-members may not sign.
+	outsider, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	require.Error(t, signer1.SignWith(&coreauth.Ed25519Signer{Ed25519PrivateKey: *outsider}, msg))
+
+	// Invalid multisigs
+	_, err = coreauth.NewMultiSig(4, keys)
+	require.Error(t, err)
+	_, err = coreauth.NewMultiSig(1, []*coreauth.MultiSigKey{keys[0], keys[0]})
+	require.Error(t, err)
+	_, err = coreauth.NewMultiSig(1, []*coreauth.MultiSigKey{{AuthType: coreauth.MultiSigAuth, Identity: signer3.Identity()}})
+	require.Error(t, err)
+}
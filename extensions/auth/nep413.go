@@ -1,29 +1,27 @@
-//go:build auth_nep413 || ext_test
-
 package auth
 
 import (
 	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
 
 	borsch "github.com/near/borsh-go"
 )
 
-func init() {
-	err := RegisterAuthenticator(ModAdd, nep413Name, Nep413Authenticator{
-		MsgEncoder: func(bts []byte) string {
-			return string(bts)
-		},
-	})
-	if err != nil {
-		panic(err)
-	}
-}
+const (
+	// Nep413Auth is the authenticator name
+	Nep413Auth = "nep413"
 
-const nep413Name = "nep413"
+	// nep413Tag is the NEP-413 payload tag, 2^31+413.
+	nep413Tag uint32 = 2147484061
+)
 
 /*
 
@@ -94,7 +92,7 @@ func (n Nep413Authenticator) Verify(sender []byte, msg []byte, signature []byte)
 	}
 
 	// add the tag and message to the payload
-	payload.Tag = 2147484061
+	payload.Tag = nep413Tag
 	payload.Message = n.MsgEncoder(msg)
 
 	// serialize the payload
@@ -163,3 +161,71 @@ func deserializeSignature(signatureBts []byte) (*Nep413Payload, []byte, error) {
 
 	return payload, signature, nil
 }
+
+// Nep413Signer is a signer that signs messages in the same manner as a NEAR
+// wallet's NEP-413 signMessage, producing signatures verified by the
+// Nep413Authenticator. Like the registered authenticator, the message is
+// signed as plain text.
+type Nep413Signer struct {
+	crypto.Ed25519PrivateKey
+
+	// Recipient is the recipient of the message, e.g. an app or chain name.
+	// It is part of the signed payload but is not checked by the
+	// authenticator.
+	Recipient string
+}
+
+var _ auth.Signer = (*Nep413Signer)(nil)
+
+// Sign signs the message with a random nonce, returning the serialized
+// payload and signature in the format expected by the Nep413Authenticator.
+func (n *Nep413Signer) Sign(msg []byte) (*auth.Signature, error) {
+	payload := Nep413Payload{
+		Recipient: n.Recipient,
+	}
+	if _, err := rand.Read(payload.Nonce[:]); err != nil {
+		return nil, err
+	}
+
+	// The serialized payload in the signature omits the tag and message.
+	payloadBts, err := borsch.Serialize(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(payloadBts) > math.MaxUint16 {
+		return nil, fmt.Errorf("nep413 payload too large: %d bytes", len(payloadBts))
+	}
+
+	payload.Tag = nep413Tag
+	payload.Message = string(msg)
+	signedBts, err := borsch.Serialize(payload)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(signedBts)
+
+	sig, err := n.Ed25519PrivateKey.Sign(hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 0, 2+len(payloadBts)+len(sig))
+	signature = append(signature, byte(len(payloadBts)>>8), byte(len(payloadBts))) // big endian
+	signature = append(signature, payloadBts...)
+	signature = append(signature, sig...)
+
+	return &auth.Signature{
+		Signature: signature,
+		Type:      Nep413Auth,
+	}, nil
+}
+
+// Identity returns the ed25519 public key, which is also the NEAR implicit
+// account ID when hex encoded.
+func (n *Nep413Signer) Identity() []byte {
+	return n.Ed25519PrivateKey.PubKey().Bytes()
+}
+
+func (n *Nep413Signer) AuthType() string {
+	return Nep413Auth
+}
@@ -0,0 +1,14 @@
+//go:build auth_nep413 || ext_test
+
+package auth
+
+func init() {
+	err := RegisterAuthenticator(ModAdd, Nep413Auth, Nep413Authenticator{
+		MsgEncoder: func(bts []byte) string {
+			return string(bts)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/kwilteam/kwil-db/core/crypto"
 	"github.com/kwilteam/kwil-db/extensions/auth"
 	borsch "github.com/near/borsh-go"
 	"github.com/stretchr/testify/assert"
@@ -99,6 +100,29 @@ func Test_Nep413(t *testing.T) {
 	}
 }
 
+func Test_Nep413Signer(t *testing.T) {
+	key, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+
+	signer := &auth.Nep413Signer{
+		Ed25519PrivateKey: *key,
+		Recipient:         "kwil-chain",
+	}
+	msg := []byte("Kwil 🖋\n")
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	require.Equal(t, auth.Nep413Auth, sig.Type)
+
+	authenticator := auth.Nep413Authenticator{MsgEncoder: strEncode}
+	require.NoError(t, authenticator.Verify(signer.Identity(), msg, sig.Signature))
+	require.Error(t, authenticator.Verify(signer.Identity(), []byte("other"), sig.Signature))
+
+	ident, err := authenticator.Identifier(signer.Identity())
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(key.PubKey().Bytes()), ident)
+}
+
 func strEncode(bts []byte) string {
 	return string(bts)
 }
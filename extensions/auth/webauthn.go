@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+/*
+	This implements WebAuthn (passkey) assertions as a Kwil authentication
+	driver, so that users may sign with a device authenticator such as a phone
+	or security key.
+
+	The app requests an assertion with navigator.credentials.get, giving the
+	base64url encoded sha256 digest of the serialized transaction (or call
+	message) as the challenge (see auth.WebAuthnChallenge). The authenticator
+	signs the authenticator data and the hash of the client data JSON, which
+	contains the challenge, with the credential's P-256 key. The assertion is
+	the signature of the transaction, serialized as an auth.WebAuthnAssertion.
+
+	The identity (sender) is the credential's P-256 public key in compressed
+	SEC 1 form, which the app must save when the credential is created, since
+	assertions do not contain it.
+
+	Only ES256 credentials are supported, which is the default algorithm for
+	platform authenticators. Signatures must have a low S value, so the app
+	must normalize the assertion's signature (see
+	auth.NormalizeWebAuthnSignature).
+*/
+
+// webauthnMinAuthDataLength is the length of the authenticator data without
+// attested credential data or extensions: rpIdHash, flags, and signCount.
+const webauthnMinAuthDataLength = 37
+
+// WebAuthnAuthenticator verifies WebAuthn assertions with P-256 keys. The
+// relying party ID and the allowed origins are required, since an assertion
+// from any other site, which may have been phished, must not be accepted.
+type WebAuthnAuthenticator struct {
+	// RPID is the required relying party ID.
+	RPID string
+	// Origins are the allowed origins of the client data.
+	Origins []string
+	// RequireUserVerification requires the user verified flag, indicating
+	// that the user was verified with a PIN or biometric, in addition to the
+	// user present flag that is always required.
+	RequireUserVerification bool
+}
+
+var _ auth.Authenticator = WebAuthnAuthenticator{}
+
+// Identifier returns the identifier of a credential public key, which is the
+// hex encoded last 20 bytes of the sha256 hash of the compressed public key.
+func (WebAuthnAuthenticator) Identifier(pubKey []byte) (string, error) {
+	if _, err := auth.ParseWebAuthnPublicKey(pubKey); err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(pubKey)
+	return hex.EncodeToString(hash[12:]), nil
+}
+
+// Verify verifies a WebAuthn assertion of the message by the credential with
+// the public key.
+func (w WebAuthnAuthenticator) Verify(pubKey []byte, msg []byte, signature []byte) error {
+	if w.RPID == "" || len(w.Origins) == 0 {
+		return errors.New("webauthn relying party ID and origins are not configured")
+	}
+
+	pub, err := auth.ParseWebAuthnPublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+
+	var assertion auth.WebAuthnAssertion
+	if err = assertion.UnmarshalBinary(signature); err != nil {
+		return err
+	}
+
+	// The client data must be for an assertion of the message's challenge.
+	var clientData auth.WebAuthnClientData
+	if err = json.Unmarshal(assertion.ClientDataJSON, &clientData); err != nil {
+		return fmt.Errorf("invalid webauthn client data: %w", err)
+	}
+	if clientData.Type != auth.WebAuthnGetType {
+		return fmt.Errorf("invalid webauthn client data type %q", clientData.Type)
+	}
+	if clientData.Challenge != auth.WebAuthnChallenge(msg) {
+		return errors.New("webauthn challenge is not the message digest")
+	}
+	if !slices.Contains(w.Origins, clientData.Origin) {
+		return fmt.Errorf("webauthn origin %q is not allowed", clientData.Origin)
+	}
+
+	authData := assertion.AuthenticatorData
+	if len(authData) < webauthnMinAuthDataLength {
+		return fmt.Errorf("invalid webauthn authenticator data length %d", len(authData))
+	}
+	rpIDHash := sha256.Sum256([]byte(w.RPID))
+	if [32]byte(authData[:32]) != rpIDHash {
+		return errors.New("webauthn relying party ID mismatch")
+	}
+	flags := authData[32]
+	if flags&auth.WebAuthnFlagUserPresent == 0 {
+		return errors.New("webauthn user not present")
+	}
+	if w.RequireUserVerification && flags&auth.WebAuthnFlagUserVerified == 0 {
+		return errors.New("webauthn user not verified")
+	}
+
+	_, _, lowS, err := auth.ParseWebAuthnSignature(assertion.Signature)
+	if err != nil {
+		return err
+	}
+	if !lowS {
+		return errors.New("webauthn signature is not normalized to low S")
+	}
+
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	digest := sha256.Sum256(append(slices.Clip(authData), clientDataHash[:]...))
+	if !ecdsa.VerifyASN1(pub, digest[:], assertion.Signature) {
+		return errors.New("invalid webauthn signature")
+	}
+
+	return nil
+}
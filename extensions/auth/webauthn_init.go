@@ -0,0 +1,36 @@
+//go:build auth_webauthn || ext_test
+
+package auth
+
+import (
+	"strings"
+
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+)
+
+// The relying party ID and the comma separated allowed origins of the WebAuthn
+// authenticator. They must be the same on every node of a network, so they are
+// set when building kwild, e.g.:
+//
+//	go build -tags auth_webauthn -ldflags "-X github.com/kwilteam/kwil-db/extensions/auth.webauthnRPID=example.com -X github.com/kwilteam/kwil-db/extensions/auth.webauthnOrigins=https://example.com"
+//
+// If they are not set, all WebAuthn signatures are rejected.
+var (
+	webauthnRPID    string
+	webauthnOrigins string
+)
+
+func init() {
+	var origins []string
+	if webauthnOrigins != "" {
+		origins = strings.Split(webauthnOrigins, ",")
+	}
+
+	err := RegisterAuthenticator(ModAdd, auth.WebAuthnAuth, WebAuthnAuthenticator{
+		RPID:    webauthnRPID,
+		Origins: origins,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,101 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	coreauth "github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/extensions/auth"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WebAuthn(t *testing.T) {
+	signer, err := coreauth.GenerateWebAuthnSigner("example.com", "https://example.com")
+	require.NoError(t, err)
+
+	msg := []byte("foo")
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+	require.Equal(t, coreauth.WebAuthnAuth, sig.Type)
+
+	authenticator := auth.WebAuthnAuthenticator{
+		RPID:                    "example.com",
+		Origins:                 []string{"https://example.com"},
+		RequireUserVerification: true,
+	}
+	require.NoError(t, authenticator.Verify(signer.Identity(), msg, sig.Signature))
+
+	// The identifier is a stable 20 byte hex string.
+	ident, err := authenticator.Identifier(signer.Identity())
+	require.NoError(t, err)
+	require.Len(t, ident, 40)
+	ident2, err := auth.WebAuthnAuthenticator{}.Identifier(signer.Identity())
+	require.NoError(t, err)
+	require.Equal(t, ident, ident2)
+
+	// wrong message
+	require.Error(t, authenticator.Verify(signer.Identity(), []byte("bar"), sig.Signature))
+
+	// wrong key
+	other, err := coreauth.GenerateWebAuthnSigner("example.com", "https://example.com")
+	require.NoError(t, err)
+	require.Error(t, authenticator.Verify(other.Identity(), msg, sig.Signature))
+
+	// wrong relying party or origin
+	require.ErrorContains(t, auth.WebAuthnAuthenticator{RPID: "evil.com", Origins: authenticator.Origins}.
+		Verify(signer.Identity(), msg, sig.Signature), "relying party")
+	require.ErrorContains(t, auth.WebAuthnAuthenticator{RPID: "example.com", Origins: []string{"https://evil.com"}}.
+		Verify(signer.Identity(), msg, sig.Signature), "origin")
+
+	// the relying party and origins are required
+	require.ErrorContains(t, auth.WebAuthnAuthenticator{}.Verify(signer.Identity(), msg, sig.Signature), "not configured")
+	require.ErrorContains(t, auth.WebAuthnAuthenticator{RPID: "example.com"}.Verify(signer.Identity(), msg, sig.Signature), "not configured")
+
+	// tampered client data
+	var assertion coreauth.WebAuthnAssertion
+	require.NoError(t, assertion.UnmarshalBinary(sig.Signature))
+	var clientData coreauth.WebAuthnClientData
+	require.NoError(t, json.Unmarshal(assertion.ClientDataJSON, &clientData))
+	clientData.Origin = "https://evil.com"
+	assertion.ClientDataJSON, err = json.Marshal(&clientData)
+	require.NoError(t, err)
+	tampered, err := assertion.MarshalBinary()
+	require.NoError(t, err)
+	require.Error(t, authenticator.Verify(signer.Identity(), msg, tampered))
+
+	// user not present
+	require.NoError(t, assertion.UnmarshalBinary(sig.Signature))
+	assertion.AuthenticatorData[32] = 0
+	tampered, err = assertion.MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorContains(t, authenticator.Verify(signer.Identity(), msg, tampered), "not present")
+
+	// high S signature, which is also a valid ECDSA signature
+	sig, err = signer.Sign(msg) // the assertion was modified in place
+	require.NoError(t, err)
+	require.NoError(t, assertion.UnmarshalBinary(bytes.Clone(sig.Signature)))
+	r, sVal, lowS, err := coreauth.ParseWebAuthnSignature(assertion.Signature)
+	require.NoError(t, err)
+	require.True(t, lowS)
+	assertion.Signature, err = asn1.Marshal(struct{ R, S *big.Int }{r, new(big.Int).Sub(elliptic.P256().Params().N, sVal)})
+	require.NoError(t, err)
+	highS, err := assertion.MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorContains(t, authenticator.Verify(signer.Identity(), msg, highS), "low S")
+
+	// which is accepted once normalized
+	assertion.Signature, err = coreauth.NormalizeWebAuthnSignature(assertion.Signature)
+	require.NoError(t, err)
+	normalized, err := assertion.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, sig.Signature, normalized)
+	require.NoError(t, authenticator.Verify(signer.Identity(), msg, normalized))
+
+	// truncated signature
+	require.Error(t, authenticator.Verify(signer.Identity(), msg, sig.Signature[:10]))
+}
@@ -2,8 +2,44 @@ package consensus
 
 import (
 	"github.com/kwilteam/kwil-db/common/chain/forks"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	authExt "github.com/kwilteam/kwil-db/extensions/auth"
 )
 
+// ForkEip712 is the name of the canonical hard fork that enables EIP-712 typed
+// data transaction signing.
+const ForkEip712 = "eip712"
+
+// ForkCryptoFuncs is the name of the canonical hard fork that allows schemas
+// to use the keccak256, ecrecover, ed25519_verify, and eth_address functions.
+const ForkCryptoFuncs = "crypto_funcs"
+
+// ForkValidatorEvidence is the name of the canonical hard fork that records
+// validator misbehavior evidence and applies the punishment set by the
+// genesis validator params.
+const ForkValidatorEvidence = "validator_evidence"
+
+// ForkResolutionResults is the name of the canonical hard fork that records
+// the outcome of approved and expired resolutions.
+const ForkResolutionResults = "resolution_results"
+
+// ForkScheduledProcedures is the name of the canonical hard fork that runs
+// procedures scheduled with the @schedule annotation.
+const ForkScheduledProcedures = "scheduled_procedures"
+
+// ForkSessions is the name of the canonical hard fork that allows accounts to
+// authorize session keys to transact on their behalf.
+const ForkSessions = "sessions"
+
+// ForkConsensusKeys is the name of the canonical hard fork that allows join
+// requests to set a consensus key, which signs the validator's votes and
+// proposals in place of its node key, such as one held by a remote signer.
+const ForkConsensusKeys = "consensus_keys"
+
+// ForkWasm is the name of the canonical hard fork that allows validators to
+// deploy WebAssembly modules, and schemas to use them with the wasm precompile.
+const ForkWasm = "wasm"
+
 // Register the canonical (non-extension) hard forks that are baked into kwild.
 func init() {
 	RegisterHardfork(&Hardfork{
@@ -15,4 +51,65 @@ func init() {
 		// NOTE: canonical forks can define any of the standardized updates, but
 		// this one does not.
 	})
+
+	RegisterHardfork(&Hardfork{
+		// "eip712" adds the authenticator for EIP-712 typed data signatures,
+		// which are used with the "eip712" transaction serialization. It is
+		// activated like any other fork, by including it in the genesis forks.
+		Name: ForkEip712,
+
+		AuthUpdates: []*AuthMod{
+			{
+				Name:      auth.EthEip712Auth,
+				Operation: authExt.ModAdd,
+				Authn:     authExt.Eip712Authenticator{},
+			},
+		},
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "crypto_funcs" has no standard updates. Before activation, the
+		// deploy schema route rejects schemas that use the functions.
+		Name: ForkCryptoFuncs,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "validator_evidence" has no standard updates. Before activation, a
+		// misbehaving validator's power is reduced by one from the power
+		// reported by CometBFT, and the evidence is not recorded.
+		Name: ForkValidatorEvidence,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "resolution_results" has no standard updates. Before activation,
+		// closed resolutions are deleted without recording their outcome.
+		Name: ForkResolutionResults,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "scheduled_procedures" has no standard updates. Before activation,
+		// @schedule annotations are not parsed when a schema is deployed, and
+		// no scheduled procedures are run.
+		Name: ForkScheduledProcedures,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "sessions" has no standard updates. Before activation, the create
+		// and revoke session routes reject their transactions, and senders are
+		// not looked up as session keys.
+		Name: ForkSessions,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "consensus_keys" has no standard updates. Before activation, the
+		// validator join route rejects join requests with a consensus key.
+		Name: ForkConsensusKeys,
+	})
+
+	RegisterHardfork(&Hardfork{
+		// "wasm" has no standard updates. Before activation, wasm_module
+		// resolutions are rejected and not resolved, and schemas may not use
+		// the wasm precompile.
+		Name: ForkWasm,
+	})
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
 	"github.com/kwilteam/kwil-db/core/utils/order"
 )
 
@@ -98,7 +99,109 @@ func ListEndBlockHooks() []struct {
 	return hooks
 }
 
+// BeginBlockHook is a function that is run at the start of each block,
+// before any of the transactions in the block have been processed. It is
+// meant to be used to prepare state for the block, such as resetting
+// per-block counters. An error returned will halt the local node. All state
+// changes and errors should be deterministic, as all nodes will run the same
+// BeginBlockHooks in the same order.
+type BeginBlockHook func(ctx context.Context, app *common.App, block *common.BlockContext) error
+
+// RegisterBeginBlockHook registers a BeginBlockHook to be run at the start of each block.
+// The name can be anything, as long as it is unique. It is used to deterministically
+// order the hooks.
+func RegisterBeginBlockHook(name string, hook BeginBlockHook) error {
+	_, ok := beginBlockHooks[name]
+	if ok {
+		return fmt.Errorf("begin block hook with name %s already exists", name)
+	}
+
+	beginBlockHooks[name] = hook
+	return nil
+}
+
+var beginBlockHooks map[string]BeginBlockHook
+
+// ListBeginBlockHooks deterministically returns a list of all registered BeginBlockHooks.
+func ListBeginBlockHooks() []struct {
+	Name string
+	Hook BeginBlockHook
+} {
+	var hooks []struct {
+		Name string
+		Hook BeginBlockHook
+	}
+	for _, hook := range order.OrderMap(beginBlockHooks) {
+		hooks = append(hooks, struct {
+			Name string
+			Hook BeginBlockHook
+		}{
+			Name: hook.Key,
+			Hook: hook.Value,
+		})
+	}
+
+	return hooks
+}
+
+// TxResult is the result of a transaction that has been executed in a block.
+type TxResult struct {
+	// Code is the response code of the transaction.
+	Code transactions.TxCode
+	// Spend is the amount of tokens spent by the transaction.
+	Spend int64
+	// Error is the error returned by the transaction, if it failed.
+	Error error
+}
+
+// TxResultHook is a function that is run after each transaction in a block
+// has been executed, whether or not the transaction succeeded. It is given
+// the transaction and its result, and can be used for custom indexing or to
+// alter state, such as to rebate fees. An error returned will halt the local
+// node. All state changes and errors should be deterministic, as all nodes
+// will run the same TxResultHooks in the same order.
+type TxResultHook func(ctx *common.TxContext, app *common.App, tx *transactions.Transaction, result *TxResult) error
+
+// RegisterTxResultHook registers a TxResultHook to be run after each transaction is executed.
+// The name can be anything, as long as it is unique. It is used to deterministically
+// order the hooks.
+func RegisterTxResultHook(name string, hook TxResultHook) error {
+	_, ok := txResultHooks[name]
+	if ok {
+		return fmt.Errorf("tx result hook with name %s already exists", name)
+	}
+
+	txResultHooks[name] = hook
+	return nil
+}
+
+var txResultHooks map[string]TxResultHook
+
+// ListTxResultHooks deterministically returns a list of all registered TxResultHooks.
+func ListTxResultHooks() []struct {
+	Name string
+	Hook TxResultHook
+} {
+	var hooks []struct {
+		Name string
+		Hook TxResultHook
+	}
+	for _, hook := range order.OrderMap(txResultHooks) {
+		hooks = append(hooks, struct {
+			Name string
+			Hook TxResultHook
+		}{
+			Name: hook.Key,
+			Hook: hook.Value,
+		})
+	}
+
+	return hooks
+}
+
 func init() {
 	genesisHooks = make(map[string]GenesisHook)
 	endBlockHooks = make(map[string]EndBlockHook)
+	beginBlockHooks = make(map[string]BeginBlockHook)
+	txResultHooks = make(map[string]TxResultHook)
 }
@@ -0,0 +1,245 @@
+package evmevents
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	kwiltypes "github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/decimal"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/resolutions/procedure"
+)
+
+// file contains functionality for decoding EVM events into procedure arguments
+
+// parseEvent parses an event from a JSON ABI. The ABI may be a single event
+// fragment, or an array of fragments. If the ABI contains more than one event,
+// name selects the event.
+func parseEvent(abiJSON, name string) (*abi.Event, error) {
+	abiJSON = strings.TrimSpace(abiJSON)
+	if strings.HasPrefix(abiJSON, "{") {
+		abiJSON = "[" + abiJSON + "]"
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	var event abi.Event
+	switch {
+	case name != "":
+		var ok bool
+		event, ok = parsed.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("event %s not found", name)
+		}
+	case len(parsed.Events) == 1:
+		for _, e := range parsed.Events {
+			event = e
+		}
+	case len(parsed.Events) == 0:
+		return nil, errors.New("no event found")
+	default:
+		return nil, errors.New("more than one event found, the event must be configured")
+	}
+
+	// anonymous events do not have their signature as a topic, so we cannot
+	// filter for them
+	if event.Anonymous {
+		return nil, fmt.Errorf("anonymous event %s is not supported", event.Name)
+	}
+
+	for _, input := range event.Inputs {
+		if _, err = kwilValue(input.Type, input.Indexed, reflect.Zero(input.Type.GetType()).Interface()); err != nil {
+			return nil, fmt.Errorf("field %s: %w", input.Name, err)
+		}
+	}
+
+	return &event, nil
+}
+
+// hasInput returns true if the event has a field with the given name.
+func hasInput(event *abi.Event, name string) bool {
+	for _, input := range event.Inputs {
+		if input.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// procedureCall decodes an event log into the procedure call it triggers.
+func (e *EVMEventConfig) procedureCall(l *types.Log) (*procedure.ProcedureCallResolution, error) {
+	if len(l.Topics) == 0 || l.Topics[0] != e.event.ID {
+		return nil, fmt.Errorf("log is not a %s event", e.event.Name)
+	}
+
+	values := make(map[string]any)
+	err := e.event.Inputs.UnpackIntoMap(values, l.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexed abi.Arguments
+	for _, input := range e.event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	err = abi.ParseTopicsIntoMap(values, indexed, l.Topics[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	names := e.Args
+	if len(names) == 0 {
+		for _, input := range e.event.Inputs {
+			names = append(names, input.Name)
+		}
+	}
+
+	args := make([]*transactions.EncodedValue, len(names))
+	for i, name := range names {
+		var input abi.Argument
+		for _, in := range e.event.Inputs {
+			if in.Name == name {
+				input = in
+				break
+			}
+		}
+
+		var err error
+		args[i], err = encodeValue(input, values[name])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+	}
+
+	// the transaction hash and log index uniquely identify the event
+	source := make([]byte, 0, ethcommon.HashLength+8)
+	source = append(source, l.TxHash.Bytes()...)
+	source = binary.BigEndian.AppendUint64(source, uint64(l.Index))
+
+	return &procedure.ProcedureCallResolution{
+		DBID:      e.DBID,
+		Procedure: e.Procedure,
+		Args:      args,
+		Source:    source,
+	}, nil
+}
+
+// encodeValue encodes the value of an event field for a procedure call.
+func encodeValue(input abi.Argument, v any) (*transactions.EncodedValue, error) {
+	kv, err := kwilValue(input.Type, input.Indexed, v)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := transactions.EncodeValue(kv)
+	if err != nil {
+		return nil, err
+	}
+
+	// the type of an empty array cannot be inferred from its elements, so it
+	// is the type of the zero value of an element
+	if arr, ok := kv.([]any); ok && len(arr) == 0 {
+		elem, err := kwilValue(*input.Type.Elem, false, reflect.Zero(input.Type.Elem.GetType()).Interface())
+		if err != nil {
+			return nil, err
+		}
+		elemEnc, err := transactions.EncodeValue(elem)
+		if err != nil {
+			return nil, err
+		}
+		enc.Type = elemEnc.Type
+		enc.Type.IsArray = true
+	}
+
+	return enc, nil
+}
+
+// kwilValue converts a value decoded by the abi package into a value that can
+// be passed to a procedure:
+//   - address: text, as a checksummed hex string like Ethereum callers
+//   - intN and uintN up to 64 bits: int
+//   - uint64 and larger: uint256
+//   - larger intN: decimal(78,0)
+//   - bool: bool
+//   - string: text
+//   - bytes, bytesN: blob
+//   - arrays and slices of the above: arrays
+//
+// Indexed strings, bytes, and arrays are only stored as their keccak256 hash,
+// which is passed as a blob.
+func kwilValue(typ abi.Type, indexed bool, v any) (any, error) {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy:
+		if indexed {
+			hash, _ := v.(ethcommon.Hash)
+			return hash.Bytes(), nil
+		}
+	}
+
+	switch typ.T {
+	case abi.AddressTy:
+		return v.(ethcommon.Address).Hex(), nil
+	case abi.IntTy:
+		if typ.Size <= 64 {
+			return reflect.ValueOf(v).Int(), nil
+		}
+		b := v.(*big.Int)
+		if b == nil {
+			b = new(big.Int)
+		}
+		return decimal.NewExplicit(b.String(), 78, 0)
+	case abi.UintTy:
+		if typ.Size < 64 {
+			return int64(reflect.ValueOf(v).Uint()), nil
+		}
+		if typ.Size == 64 {
+			return kwiltypes.Uint256FromInt(reflect.ValueOf(v).Uint()), nil
+		}
+		b := v.(*big.Int)
+		if b == nil {
+			b = new(big.Int)
+		}
+		return kwiltypes.Uint256FromBig(b)
+	case abi.BoolTy:
+		return v.(bool), nil
+	case abi.StringTy:
+		return v.(string), nil
+	case abi.BytesTy:
+		return v.([]byte), nil
+	case abi.FixedBytesTy, abi.HashTy:
+		rv := reflect.ValueOf(v)
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return b, nil
+	case abi.SliceTy, abi.ArrayTy:
+		switch typ.Elem.T {
+		case abi.SliceTy, abi.ArrayTy, abi.BytesTy, abi.FixedBytesTy, abi.TupleTy, abi.FunctionTy:
+			return nil, fmt.Errorf("unsupported array element type %s", typ.Elem)
+		}
+		rv := reflect.ValueOf(v)
+		arr := make([]any, rv.Len())
+		for i := range arr {
+			var err error
+			arr[i], err = kwilValue(*typ.Elem, false, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
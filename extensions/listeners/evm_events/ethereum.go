@@ -0,0 +1,192 @@
+package evmevents
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jpillora/backoff"
+
+	"github.com/kwilteam/kwil-db/core/log"
+)
+
+// file contains functionality for subscribing to an EVM chain and reading logs
+
+// evmClient is the subset of the go-ethereum client used by the listener. It
+// is satisfied by both *ethclient.Client and the client of go-ethereum's
+// simulated backend.
+type evmClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// ethClient is a client for interacting with an EVM chain. It handles retries
+// and resubscribing to the chain in case of transient errors.
+type ethClient struct {
+	maxRetries int64
+	logger     log.SugaredLogger
+	client     evmClient
+	// close closes the connection, if the client has one.
+	close func()
+}
+
+// newEthClient dials the RPC provider and creates a new ethereum client.
+func newEthClient(ctx context.Context, rpcurl string, maxRetries int64, logger log.SugaredLogger) (*ethClient, error) {
+	var client *ethclient.Client
+
+	// this only gets run on startup, so if we fail 3 times here, it is likely
+	// a permanent error
+	err := retry(ctx, 3, func() error {
+		var innerErr error
+		client, innerErr = ethclient.DialContext(ctx, rpcurl)
+		return innerErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ethClient{
+		maxRetries: maxRetries,
+		logger:     logger,
+		client:     client,
+		close:      client.Close,
+	}, nil
+}
+
+// GetLatestBlock gets the latest block number from the chain.
+func (ec *ethClient) GetLatestBlock(ctx context.Context) (int64, error) {
+	var blockNumber int64
+	err := retry(ctx, ec.maxRetries, func() error {
+		header, err := ec.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			ec.logger.Error("Failed to get latest block", "error", err)
+			return err
+		}
+		blockNumber = header.Number.Int64()
+		return nil
+	})
+	return blockNumber, err
+}
+
+// GetEventLogs gets the logs for an event emitted by a contract, in the given
+// range of block heights.
+func (ec *ethClient) GetEventLogs(ctx context.Context, contract ethcommon.Address, event *abi.Event, fromBlock, toBlock int64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		ToBlock:   big.NewInt(toBlock),
+		FromBlock: big.NewInt(fromBlock),
+		Addresses: []ethcommon.Address{contract},
+		Topics:    [][]ethcommon.Hash{{event.ID}},
+	}
+
+	var logs []types.Log
+	err := retry(ctx, ec.maxRetries, func() error {
+		var err error
+		logs, err = ec.client.FilterLogs(ctx, query)
+		if err != nil {
+			ec.logger.Error("Failed to get event logs", "error", err)
+		}
+
+		return err
+	})
+	return logs, err
+}
+
+// ListenToBlocks subscribes to new blocks on the chain. It takes a
+// reconnectInterval, which is the amount of time it will wait to resubscribe
+// if no new blocks are received. It takes a callback function that is called
+// with the new block number. It can send duplicates, if that is received from
+// the RPC provider. It will block until the context is cancelled, or until an
+// error is returned from the callback function.
+func (ec *ethClient) ListenToBlocks(ctx context.Context, reconnectInterval time.Duration, cb func(int64) error) error {
+	headers := make(chan *types.Header, 1)
+	sub, err := ec.client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		return err
+	}
+	defer func() { sub.Unsubscribe() }()
+
+	resubscribe := func() error {
+		var retryCount int
+		ec.logger.Warn("Resubscribing to EVM node", "attempt", retryCount) // anomalous
+		sub.Unsubscribe()
+
+		return retry(ctx, ec.maxRetries, func() error {
+			retryCount++
+			sub, err = ec.client.SubscribeNewHead(ctx, headers)
+			return err
+		})
+	}
+
+	reconn := time.NewTicker(reconnectInterval)
+	defer reconn.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ec.logger.Debug("Context cancelled, stopping EVM client")
+			return nil
+		case header := <-headers:
+			ec.logger.Debug("New block", "height", header.Number.Int64())
+			err := cb(header.Number.Int64())
+			if err != nil {
+				return err
+			}
+
+			reconn.Reset(reconnectInterval)
+		case err := <-sub.Err():
+			ec.logger.Error("EVM subscription error", "error", err)
+			err = resubscribe()
+			if err != nil {
+				return err
+			}
+			reconn.Reset(reconnectInterval)
+		case <-reconn.C:
+			ec.logger.Warn("No new blocks received, resubscribing")
+			err := resubscribe()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close closes the client's connection.
+func (ec *ethClient) Close() {
+	if ec.close != nil {
+		ec.close()
+	}
+}
+
+// retry will retry the function until it is successful, or reached the max retries
+func retry(ctx context.Context, maxRetries int64, fn func() error) error {
+	retrier := &backoff.Backoff{
+		Min:    1 * time.Second,
+		Max:    10 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		// fail after maxRetries retries
+		if retrier.Attempt() > float64(maxRetries) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retrier.Duration()):
+		}
+	}
+}
@@ -0,0 +1,415 @@
+// package evmevents implements a generic listener that listens to an event
+// emitted by an EVM smart contract, and calls a procedure in a Kwil dataset
+// with the event's fields once the network has agreed on the event. It is
+// configured entirely from the node's local extension configuration, so new
+// bridges and oracles do not need a bespoke listener.
+package evmevents
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/extensions/listeners"
+	"github.com/kwilteam/kwil-db/extensions/resolutions/procedure"
+)
+
+const ListenerName = "evm_events"
+
+func init() {
+	err := listeners.RegisterListener(ListenerName, Start)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Start starts the evm_events listener. It starts an instance for the local
+// extension configuration named "evm_events", and for every configuration
+// named "evm_events_<name>", so that a node can listen to several contracts.
+// Each instance listens for a single event of a single contract. When it sees
+// the event in a block with enough confirmations, it creates a procedure_call
+// resolution, defined in extensions/resolutions/procedure, that calls the
+// configured procedure with the event's fields as arguments.
+func Start(ctx context.Context, service *common.Service, eventStore listeners.EventStore) error {
+	configs := make(map[string]*EVMEventConfig)
+	for name, m := range service.LocalConfig.AppConfig.Extensions {
+		if name != ListenerName && !strings.HasPrefix(name, ListenerName+"_") {
+			continue
+		}
+
+		config := &EVMEventConfig{}
+		err := config.setConfig(m)
+		if err != nil {
+			return fmt.Errorf("failed to set %s configuration: %w", name, err)
+		}
+		configs[name] = config
+	}
+	if len(configs) == 0 {
+		service.Logger.Warn("no evm_events configuration found, evm_events listener will not start")
+		return nil // no configuration, so we don't start the listener
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for name, config := range configs {
+		logger := service.Logger
+		if name != ListenerName {
+			logger = *logger.Named(strings.TrimPrefix(name, ListenerName+"_"))
+		}
+		g.Go(func() error {
+			client, err := newEthClient(ctx, config.RPCProvider, config.MaxRetries, logger)
+			if err != nil {
+				return fmt.Errorf("%s: failed to create ethereum client: %w", name, err)
+			}
+			defer client.Close()
+
+			l := &listener{
+				name:       name,
+				config:     config,
+				client:     client,
+				eventStore: eventStore,
+				logger:     logger,
+			}
+			if err = l.listen(ctx); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// listener is a single configured instance of the evm_events listener.
+type listener struct {
+	// name is the name of the configuration, which scopes the last processed
+	// height in the KV store.
+	name       string
+	config     *EVMEventConfig
+	client     *ethClient
+	eventStore listeners.EventStore
+	logger     log.SugaredLogger
+}
+
+// listen catches up with the chain, and then processes new blocks as they
+// receive enough confirmations. It only returns when the context is cancelled,
+// or when the client cannot recover from an error after the max retries.
+func (l *listener) listen(ctx context.Context) error {
+	// we will either start after the last processed height, or from the
+	// configured starting height, whichever is greater
+	lastHeight, err := l.getLastStoredHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get last stored height: %w", err)
+	}
+
+	if l.config.StartingHeight > lastHeight {
+		lastHeight = l.config.StartingHeight - 1
+	}
+
+	currentHeight, err := l.client.GetLatestBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block height: %w", err)
+	}
+	l.logger.S.Infof("EVM best block: %v", currentHeight)
+
+	if lastHeight > currentHeight-l.config.RequiredConfirmations {
+		return fmt.Errorf("starting height is greater than the last confirmed block height")
+	}
+
+	// sync all logs from the starting height to the current height, in chunks
+	// of config.BlockSyncChunkSize
+	for lastHeight < currentHeight-l.config.RequiredConfirmations {
+		toBlock := min(lastHeight+l.config.BlockSyncChunkSize, currentHeight-l.config.RequiredConfirmations)
+
+		// lastheight + 1 because we have already processed the last height
+		err = l.processEvents(ctx, lastHeight+1, toBlock)
+		if err != nil {
+			return fmt.Errorf("failed to process events: %w", err)
+		}
+
+		lastHeight = toBlock
+	}
+
+	err = l.client.ListenToBlocks(ctx, time.Duration(l.config.ReconnectionInterval)*time.Second, func(newHeight int64) error {
+		newHeight = newHeight - l.config.RequiredConfirmations // account for required confirmations
+
+		// it is possible to receive the same height twice
+		if newHeight <= lastHeight {
+			l.logger.Info("received duplicate block height", "height", newHeight)
+			return nil
+		}
+
+		l.logger.Info("received new block height", "height", newHeight)
+
+		// lastheight + 1 because we have already processed the last height
+		err := l.processEvents(ctx, lastHeight+1, newHeight)
+		if err != nil {
+			return fmt.Errorf("failed to process events: %w", err)
+		}
+
+		lastHeight = newHeight
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ListenToBlocks failure: %w", err)
+	}
+
+	return nil
+}
+
+// processEvents flags every configured event in the given height range for
+// broadcast in a Kwil vote ID / approval transaction, and then stores the
+// processed height.
+func (l *listener) processEvents(ctx context.Context, from, to int64) error {
+	logs, err := l.client.GetEventLogs(ctx, l.config.contractAddress, l.config.event, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get event logs: %w", err)
+	}
+
+	for _, log := range logs {
+		call, err := l.config.procedureCall(&log)
+		if err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+
+		bts, err := call.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		l.logger.Info("Flagging new EVM event for approval (to broadcast)", "event", l.config.event.Name,
+			"txHash", log.TxHash.Hex(), "logIndex", log.Index, "procedure", l.config.Procedure)
+		err = l.eventStore.Broadcast(ctx, procedure.ProcedureCallEventType, bts)
+		if err != nil {
+			return fmt.Errorf("failed to mark new event for broadcast: %w", err)
+		}
+	}
+
+	l.logger.Info("processed events", "from", from, "to", to, "events", len(logs))
+
+	return l.setLastStoredHeight(ctx, to)
+}
+
+// EVMEventConfig is the configuration for an instance of the evm_events
+// listener. It can be read in from a map[string]string, which is passed from
+// the node's local configuration.
+type EVMEventConfig struct {
+	// StartingHeight is the block height it will start listening from. Any
+	// events emitted before this height will be ignored. If not configured, it
+	// will start from block 0.
+	StartingHeight int64
+	// ContractAddress is the address of the smart contract it will listen to.
+	// It is a required configuration.
+	ContractAddress string
+	// EventABI is the JSON ABI of the event it will listen for. It may be a
+	// single ABI fragment, e.g. {"type":"event","name":"Transfer",...}, or a
+	// contract ABI, in which case Event selects the event. It is a required
+	// configuration.
+	EventABI string
+	// Event is the name of the event in EventABI. It is only required if
+	// EventABI contains more than one event.
+	Event string
+	// RequiredConfirmations is the number of blocks that must be mined before
+	// the listener will create a resolution for an event. If not configured,
+	// it will default to 12.
+	RequiredConfirmations int64
+	// DBID is the ID of the dataset that contains Procedure. It is a required
+	// configuration.
+	DBID string
+	// Procedure is the procedure that is called with the fields of each event.
+	// It is called as the owner of the dataset. It is a required
+	// configuration.
+	Procedure string
+	// Args is a comma separated list of the names of the event's fields that
+	// are passed to the procedure, in order. Unnamed fields are named arg0,
+	// arg1, etc. If not configured, all of the event's fields are passed in
+	// the order that they are declared.
+	Args []string
+	// RPCProvider is the websocket URL of the RPC endpoint it will connect to.
+	// It is a required configuration.
+	RPCProvider string
+	// ReconnectionInterval is the amount of time in seconds that the listener
+	// will wait for a new block before resubscribing. If not configured, it
+	// will default to 60s.
+	ReconnectionInterval int64
+	// MaxRetries is the total number of times the listener will attempt an RPC
+	// with the provider before giving up. If not configured, it will default
+	// to 10.
+	MaxRetries int64
+	// BlockSyncChunkSize is the number of blocks the listener will request
+	// logs for at a time while catching up to the network. If not configured,
+	// it will default to 1,000,000.
+	BlockSyncChunkSize int64
+
+	// contractAddress and event are parsed from ContractAddress and EventABI.
+	contractAddress ethcommon.Address
+	event           *abi.Event
+}
+
+// setConfig sets the configuration for the evm_events listener. If it doesn't
+// find a required configuration, or if it finds an invalid configuration, it
+// returns an error.
+func (e *EVMEventConfig) setConfig(m map[string]string) error {
+	var err error
+	e.StartingHeight, err = parseInt(m, "starting_height", "0", 0)
+	if err != nil {
+		return err
+	}
+
+	contractAddress, ok := m["contract_address"]
+	if !ok {
+		return fmt.Errorf("no contract_address provided")
+	}
+	if !ethcommon.IsHexAddress(contractAddress) {
+		return fmt.Errorf("invalid contract_address: %s", contractAddress)
+	}
+	e.ContractAddress = contractAddress
+	e.contractAddress = ethcommon.HexToAddress(contractAddress)
+
+	eventABI, ok := m["event_abi"]
+	if !ok {
+		return fmt.Errorf("no event_abi provided")
+	}
+	e.EventABI = eventABI
+	e.Event = m["event"]
+	e.event, err = parseEvent(e.EventABI, e.Event)
+	if err != nil {
+		return fmt.Errorf("invalid event_abi: %w", err)
+	}
+
+	e.RequiredConfirmations, err = parseInt(m, "required_confirmations", "12", 0)
+	if err != nil {
+		return err
+	}
+
+	e.DBID, ok = m["dbid"]
+	if !ok || e.DBID == "" {
+		return fmt.Errorf("no dbid provided")
+	}
+
+	e.Procedure, ok = m["procedure"]
+	if !ok || e.Procedure == "" {
+		return fmt.Errorf("no procedure provided")
+	}
+
+	e.Args = nil
+	if args := m["args"]; args != "" {
+		for _, arg := range strings.Split(args, ",") {
+			e.Args = append(e.Args, strings.TrimSpace(arg))
+		}
+	}
+	for _, arg := range e.Args {
+		if !hasInput(e.event, arg) {
+			return fmt.Errorf("event %s has no field %s", e.event.Name, arg)
+		}
+	}
+
+	rpc, ok := m["rpc_provider"]
+	if !ok {
+		return fmt.Errorf("no rpc_provider provided")
+	}
+	if !strings.HasPrefix(rpc, "ws") {
+		return fmt.Errorf("rpc_provider must be a websocket URL")
+	}
+	e.RPCProvider = rpc
+
+	e.ReconnectionInterval, err = parseInt(m, "reconnection_interval", "60", 5)
+	if err != nil {
+		return err
+	}
+
+	e.MaxRetries, err = parseInt(m, "max_retries", "10", 0)
+	if err != nil {
+		return err
+	}
+
+	e.BlockSyncChunkSize, err = parseInt(m, "block_sync_chunk_size", "1000000", 1)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseInt parses the integer value of key in m, or def if it is not set. It
+// returns an error if the value is less than minimum.
+func parseInt(m map[string]string, key, def string, minimum int64) (int64, error) {
+	str, ok := m[key]
+	if !ok {
+		str = def
+	}
+
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", key, str)
+	}
+	if i < minimum {
+		return 0, fmt.Errorf("%s must be greater than or equal to %d", key, minimum)
+	}
+	return i, nil
+}
+
+// Map returns the configuration as a map[string]string.
+// This is used for testing
+func (e *EVMEventConfig) Map() map[string]string {
+	m := map[string]string{
+		"starting_height":        strconv.FormatInt(e.StartingHeight, 10),
+		"contract_address":       e.ContractAddress,
+		"event_abi":              e.EventABI,
+		"required_confirmations": strconv.FormatInt(e.RequiredConfirmations, 10),
+		"dbid":                   e.DBID,
+		"procedure":              e.Procedure,
+		"rpc_provider":           e.RPCProvider,
+		"reconnection_interval":  strconv.FormatInt(e.ReconnectionInterval, 10),
+		"max_retries":            strconv.FormatInt(e.MaxRetries, 10),
+		"block_sync_chunk_size":  strconv.FormatInt(e.BlockSyncChunkSize, 10),
+	}
+	if e.Event != "" {
+		m["event"] = e.Event
+	}
+	if len(e.Args) > 0 {
+		m["args"] = strings.Join(e.Args, ",")
+	}
+	return m
+}
+
+// lastHeightKey is the key used to store the last height processed by the
+// listener instance.
+func (l *listener) lastHeightKey() []byte {
+	return []byte("lh " + l.name)
+}
+
+// getLastStoredHeight gets the last height stored by the KV store
+func (l *listener) getLastStoredHeight(ctx context.Context) (int64, error) {
+	lastHeight, err := l.eventStore.Get(ctx, l.lastHeightKey())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last block height: %w", err)
+	}
+
+	if len(lastHeight) == 0 {
+		return 0, nil
+	}
+
+	return int64(binary.LittleEndian.Uint64(lastHeight)), nil
+}
+
+// setLastStoredHeight sets the last height stored by the KV store
+func (l *listener) setLastStoredHeight(ctx context.Context, height int64) error {
+	heightBts := make([]byte, 8)
+	binary.LittleEndian.PutUint64(heightBts, uint64(height))
+
+	err := l.eventStore.Set(ctx, l.lastHeightKey(), heightBts)
+	if err != nil {
+		return fmt.Errorf("failed to set last block height: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package evmevents
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	kwiltypes "github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/decimal"
+	"github.com/kwilteam/kwil-db/extensions/resolutions/procedure"
+)
+
+const testEventABI = `{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"memo","type":"string"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"delta","type":"int256"},{"indexed":false,"name":"ids","type":"uint32[]"},{"indexed":false,"name":"data","type":"bytes4"}],"name":"Deposit","type":"event"}`
+
+// emitterCode is the runtime bytecode of a contract that emits a log with
+// three topics, which are the first 96 bytes of the calldata, and the rest of
+// the calldata as the log's data.
+var emitterCode = ethcommon.FromHex("0x366000600037604051602051600051606036036060a300")
+
+func Test_Config(t *testing.T) {
+	cfg := &EVMEventConfig{
+		StartingHeight:        45,
+		ContractAddress:       "0x1234567890123456789012345678901234567890",
+		EventABI:              testEventABI,
+		RequiredConfirmations: 10,
+		DBID:                  "xdbid",
+		Procedure:             "deposit",
+		Args:                  []string{"from", "amount"},
+		RPCProvider:           "ws://localhost:8545",
+		ReconnectionInterval:  10,
+		MaxRetries:            5,
+		BlockSyncChunkSize:    100,
+	}
+	m := cfg.Map()
+
+	cfg2 := &EVMEventConfig{}
+	err := cfg2.setConfig(m)
+	require.NoError(t, err)
+	assert.Equal(t, "Deposit", cfg2.event.Name)
+	cfg2.contractAddress, cfg2.event = ethcommon.Address{}, nil
+	require.Equal(t, cfg, cfg2)
+
+	// a contract ABI with several events requires the event name
+	contractABI := "[" + testEventABI + `,{"inputs":[],"name":"Other","type":"event"}]`
+	m["event_abi"] = contractABI
+	require.Error(t, cfg2.setConfig(m))
+	m["event"] = "Deposit"
+	require.NoError(t, cfg2.setConfig(m))
+
+	m["args"] = "from,unknown"
+	require.Error(t, cfg2.setConfig(m))
+	delete(m, "args")
+
+	m["event_abi"] = `{"inputs":[{"name":"t","type":"tuple","components":[{"name":"a","type":"uint256"}]}],"name":"Tuple","type":"event"}`
+	delete(m, "event")
+	require.Error(t, cfg2.setConfig(m))
+
+	m["event_abi"] = `{"anonymous":true,"inputs":[],"name":"Anon","type":"event"}`
+	require.Error(t, cfg2.setConfig(m))
+}
+
+// testEvent is a Deposit event emitted by the emitter contract.
+type testEvent struct {
+	from   ethcommon.Address
+	memo   string
+	amount *big.Int
+	delta  *big.Int
+	ids    []uint32
+	data   [4]byte
+}
+
+func (e *testEvent) calldata(t *testing.T, event *abi.Event) []byte {
+	data, err := event.Inputs.NonIndexed().Pack(e.amount, e.delta, e.ids, e.data)
+	require.NoError(t, err)
+
+	calldata := append([]byte{}, event.ID.Bytes()...)
+	calldata = append(calldata, ethcommon.LeftPadBytes(e.from.Bytes(), 32)...)
+	calldata = append(calldata, crypto.Keccak256([]byte(e.memo))...)
+	return append(calldata, data...)
+}
+
+// testChain is a simulated chain with an emitter contract.
+type testChain struct {
+	t        *testing.T
+	backend  *simulated.Backend
+	key      *ecdsa.PrivateKey
+	contract ethcommon.Address
+	chainID  *big.Int
+	nonce    uint64
+}
+
+func newTestChain(t *testing.T) *testChain {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	contract := ethcommon.HexToAddress("0x00000000000000000000000000000000000e1717")
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(1e18)},
+		contract:                              {Code: emitterCode, Balance: new(big.Int)},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	require.NoError(t, err)
+
+	return &testChain{t: t, backend: backend, key: key, contract: contract, chainID: chainID}
+}
+
+// emit sends a transaction that emits the event, and commits a block.
+func (c *testChain) emit(event *abi.Event, e *testEvent) {
+	ctx := context.Background()
+	gasPrice, err := c.backend.Client().SuggestGasPrice(ctx)
+	require.NoError(c.t, err)
+
+	tx, err := types.SignTx(types.NewTransaction(c.nonce, c.contract, new(big.Int), 200000, gasPrice, e.calldata(c.t, event)),
+		types.LatestSignerForChainID(c.chainID), c.key)
+	require.NoError(c.t, err)
+	c.nonce++
+
+	require.NoError(c.t, c.backend.Client().SendTransaction(ctx, tx))
+	c.backend.Commit()
+}
+
+// commit commits n empty blocks.
+func (c *testChain) commit(n int) {
+	for range n {
+		c.backend.Commit()
+	}
+}
+
+func Test_EVMEvents(t *testing.T) {
+	chain := newTestChain(t)
+
+	cfg := &EVMEventConfig{}
+	err := cfg.setConfig(map[string]string{
+		"contract_address":       chain.contract.Hex(),
+		"event_abi":              testEventABI,
+		"required_confirmations": "2",
+		"dbid":                   "xdbid",
+		"procedure":              "deposit",
+		"rpc_provider":           "ws://unused",
+		"block_sync_chunk_size":  "2",
+	})
+	require.NoError(t, err)
+
+	events := []*testEvent{
+		{
+			from:   ethcommon.HexToAddress("0x00000000000000000000000000000000000000a1"),
+			memo:   "first",
+			amount: new(big.Int).Lsh(big.NewInt(1), 255),
+			delta:  big.NewInt(-5),
+			ids:    []uint32{1, 2, 3},
+			data:   [4]byte{1, 2, 3, 4},
+		},
+		{
+			from:   ethcommon.HexToAddress("0x00000000000000000000000000000000000000a2"),
+			memo:   "second",
+			amount: big.NewInt(100),
+			delta:  big.NewInt(7),
+			ids:    []uint32{},
+			data:   [4]byte{5, 6, 7, 8},
+		},
+		{
+			from:   ethcommon.HexToAddress("0x00000000000000000000000000000000000000a3"),
+			memo:   "third",
+			amount: big.NewInt(1),
+			delta:  big.NewInt(0),
+			ids:    []uint32{9},
+			data:   [4]byte{},
+		},
+	}
+
+	// the first events are emitted before the listener starts, and the last
+	// one while it is listening to new blocks
+	chain.emit(cfg.event, events[0])
+	chain.commit(3)
+	chain.emit(cfg.event, events[1])
+	chain.commit(2)
+
+	store := newMockEventStore()
+	l := &listener{
+		name:   ListenerName,
+		config: cfg,
+		client: &ethClient{
+			maxRetries: 1,
+			logger:     log.NewNoOp().Sugar(),
+			client:     chain.backend.Client(),
+		},
+		eventStore: store,
+		logger:     log.NewNoOp().Sugar(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() { errs <- l.listen(ctx) }()
+
+	require.Eventually(t, func() bool { return store.count() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	chain.emit(cfg.event, events[2])
+	chain.commit(1)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, store.count()) // not enough confirmations
+
+	chain.commit(1)
+	require.Eventually(t, func() bool { return store.count() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-errs)
+
+	lastHeight, err := l.getLastStoredHeight(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), lastHeight)
+
+	sources := make(map[string]bool)
+	for i, bts := range store.broadcasts() {
+		var call procedure.ProcedureCallResolution
+		require.NoError(t, call.UnmarshalBinary(bts))
+		assert.Equal(t, "xdbid", call.DBID)
+		assert.Equal(t, "deposit", call.Procedure)
+		assert.False(t, sources[string(call.Source)])
+		sources[string(call.Source)] = true
+
+		args := make([]any, len(call.Args))
+		for j, arg := range call.Args {
+			args[j], err = arg.Decode()
+			require.NoError(t, err)
+		}
+
+		e := events[i]
+		require.Len(t, args, 6)
+		assert.Equal(t, e.from.Hex(), args[0])
+		assert.Equal(t, crypto.Keccak256([]byte(e.memo)), args[1])
+
+		amount, err := kwiltypes.Uint256FromBig(e.amount)
+		require.NoError(t, err)
+		assert.Equal(t, amount.String(), args[2].(*kwiltypes.Uint256).String())
+
+		assert.Equal(t, e.delta.String(), args[3].(*decimal.Decimal).String())
+
+		ids := args[4]
+		if len(e.ids) == 0 {
+			assert.Empty(t, ids)
+		} else {
+			expected := make([]int64, len(e.ids))
+			for j, id := range e.ids {
+				expected[j] = int64(id)
+			}
+			assert.Equal(t, expected, ids)
+		}
+
+		assert.Equal(t, e.data[:], args[5])
+	}
+}
+
+func Test_ProcedureCallArgs(t *testing.T) {
+	cfg := &EVMEventConfig{}
+	err := cfg.setConfig(map[string]string{
+		"contract_address": "0x1234567890123456789012345678901234567890",
+		"event_abi":        testEventABI,
+		"dbid":             "xdbid",
+		"procedure":        "deposit",
+		"args":             "amount, from",
+		"rpc_provider":     "ws://unused",
+	})
+	require.NoError(t, err)
+
+	e := &testEvent{
+		from:   ethcommon.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		amount: big.NewInt(42),
+		delta:  big.NewInt(0),
+		data:   [4]byte{},
+	}
+	calldata := e.calldata(t, cfg.event)
+	l := &types.Log{
+		Topics: []ethcommon.Hash{
+			ethcommon.BytesToHash(calldata[:32]),
+			ethcommon.BytesToHash(calldata[32:64]),
+			ethcommon.BytesToHash(calldata[64:96]),
+		},
+		Data:   calldata[96:],
+		TxHash: ethcommon.HexToHash("0x01"),
+		Index:  3,
+	}
+
+	call, err := cfg.procedureCall(l)
+	require.NoError(t, err)
+	require.Len(t, call.Args, 2)
+
+	amount, err := call.Args[0].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "42", amount.(*kwiltypes.Uint256).String())
+
+	from, err := call.Args[1].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, e.from.Hex(), from)
+
+	// a log of another event is an error
+	l.Topics[0] = ethcommon.HexToHash("0x02")
+	_, err = cfg.procedureCall(l)
+	require.Error(t, err)
+}
+
+// mockEventStore is an in-memory listeners.EventStore.
+type mockEventStore struct {
+	mtx    sync.Mutex
+	events [][]byte
+	kv     map[string][]byte
+}
+
+func newMockEventStore() *mockEventStore {
+	return &mockEventStore{kv: make(map[string][]byte)}
+}
+
+func (m *mockEventStore) Broadcast(_ context.Context, eventType string, data []byte) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if !strings.EqualFold(eventType, procedure.ProcedureCallEventType) {
+		panic("unexpected event type " + eventType)
+	}
+	m.events = append(m.events, data)
+	return nil
+}
+
+func (m *mockEventStore) count() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return len(m.events)
+}
+
+func (m *mockEventStore) broadcasts() [][]byte {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.events
+}
+
+func (m *mockEventStore) Set(_ context.Context, key []byte, value []byte) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.kv[string(key)] = value
+	return nil
+}
+
+func (m *mockEventStore) Get(_ context.Context, key []byte) ([]byte, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.kv[string(key)], nil
+}
+
+func (m *mockEventStore) Delete(_ context.Context, key []byte) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.kv, string(key))
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build listener_evm_events || ext_test
+
+package extensions
+
+// The EVM event listener registers the procedure_call resolution, which changes
+// the consensus rules of the network. It is therefore only compiled in when
+// building kwild with the listener's build tag, e.g.:
+//
+//	go build -tags listener_evm_events ./cmd/kwild
+//
+// Every node of a network must be built with the same tags.
+
+import (
+	_ "github.com/kwilteam/kwil-db/extensions/listeners/evm_events"
+)
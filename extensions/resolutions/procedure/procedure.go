@@ -0,0 +1,105 @@
+// package procedure implements a resolution that calls a procedure in a
+// dataset once the network has agreed on the procedure's arguments. It allows
+// listeners to feed external data, such as EVM events, into Kuneiform
+// procedures without writing a bespoke resolution for each of them.
+package procedure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+)
+
+const ProcedureCallEventType = "procedure_call"
+
+// The resolution is registered by importing this package, which only the EVM
+// event listener does. See extensions/register_evm_events.go.
+func init() {
+	err := resolutions.RegisterResolution(ProcedureCallEventType, resolutions.ModAdd, resolutionConfig)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ProcedureCallResolution is a resolution that calls a procedure with the
+// given arguments. It can be serialized and deserialized to be passed around
+// the network.
+type ProcedureCallResolution struct {
+	// DBID is the ID of the dataset that contains the procedure.
+	DBID string
+	// Procedure is the name of the procedure to call.
+	Procedure string
+	// Args are the arguments passed to the procedure, in order.
+	Args []*transactions.EncodedValue
+	// Source uniquely identifies the external event that triggered the call,
+	// such as an EVM chain ID, transaction hash, and log index. Like the
+	// TxHash of the credit_account resolution, it ensures that two identical
+	// calls triggered by different events are different resolutions, since
+	// resolutions are idempotent for the lifetime of the network.
+	Source []byte
+}
+
+// MarshalBinary marshals the ProcedureCallResolution to binary.
+func (p *ProcedureCallResolution) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(p)
+}
+
+// UnmarshalBinary unmarshals the ProcedureCallResolution from binary.
+func (p *ProcedureCallResolution) UnmarshalBinary(data []byte) error {
+	return serialize.Decode(data, p)
+}
+
+// resolutionConfig defines the rules for the procedure_call resolution. The
+// thresholds and expiration match those of the credit_account resolution.
+var resolutionConfig = resolutions.ResolutionConfig{
+	RefundThreshold:       big.NewRat(1, 3),
+	ConfirmationThreshold: big.NewRat(2, 3),
+	ExpirationPeriod:      600,
+	// ResolveFunc calls the procedure as the owner of the dataset, so that
+	// procedures meant to receive external data can use the owner modifier to
+	// prevent users from calling them directly. The owner did not sign
+	// anything, so @caller is empty. The ID of the resolution is used as the
+	// @txid. If the procedure fails, the resolution has no effect.
+	ResolveFunc: func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error {
+		var call ProcedureCallResolution
+		err := call.UnmarshalBinary(resolution.Body)
+		if err != nil {
+			return err
+		}
+
+		if call.DBID == "" || call.Procedure == "" {
+			return errors.New("procedure call requires a dbid and procedure")
+		}
+
+		schema, err := app.Engine.GetSchema(call.DBID)
+		if err != nil {
+			return fmt.Errorf("failed to get schema %s: %w", call.DBID, err)
+		}
+
+		args := make([]any, len(call.Args))
+		for i, arg := range call.Args {
+			args[i], err = arg.Decode()
+			if err != nil {
+				return fmt.Errorf("failed to decode argument %d: %w", i, err)
+			}
+		}
+
+		_, err = app.Engine.Procedure(&common.TxContext{
+			Ctx:          ctx,
+			BlockContext: block,
+			TxID:         resolution.ID.String(),
+			Signer:       schema.Owner,
+		}, app.DB, &common.ExecutionData{
+			Dataset:   call.DBID,
+			Procedure: call.Procedure,
+			Args:      args,
+		})
+		return err
+	},
+}
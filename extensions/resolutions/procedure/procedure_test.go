@@ -0,0 +1,82 @@
+package procedure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+)
+
+// mockEngine records the procedures it executes.
+type mockEngine struct {
+	common.Engine
+	owner []byte
+	ctx   *common.TxContext
+	data  *common.ExecutionData
+}
+
+func (m *mockEngine) GetSchema(dbid string) (*types.Schema, error) {
+	if dbid != "xdbid" {
+		return nil, errors.New("dataset not found")
+	}
+	return &types.Schema{Owner: m.owner}, nil
+}
+
+func (m *mockEngine) Procedure(ctx *common.TxContext, tx sql.DB, options *common.ExecutionData) (*sql.ResultSet, error) {
+	m.ctx, m.data = ctx, options
+	return &sql.ResultSet{}, nil
+}
+
+func Test_ProcedureCall(t *testing.T) {
+	arg1, err := transactions.EncodeValue("0xabc")
+	require.NoError(t, err)
+	arg2, err := transactions.EncodeValue(int64(5))
+	require.NoError(t, err)
+
+	call := &ProcedureCallResolution{
+		DBID:      "xdbid",
+		Procedure: "deposit",
+		Args:      []*transactions.EncodedValue{arg1, arg2},
+		Source:    []byte("source"),
+	}
+	body, err := call.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded ProcedureCallResolution
+	require.NoError(t, decoded.UnmarshalBinary(body))
+	require.Equal(t, call, &decoded)
+
+	engine := &mockEngine{owner: []byte("owner")}
+	resolution := &resolutions.Resolution{
+		ID:   types.NewUUIDV5(body),
+		Body: body,
+		Type: ProcedureCallEventType,
+	}
+	block := &common.BlockContext{Height: 10}
+
+	err = resolutionConfig.ResolveFunc(context.Background(), &common.App{Engine: engine}, resolution, block)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("owner"), engine.ctx.Signer)
+	assert.Equal(t, resolution.ID.String(), engine.ctx.TxID)
+	assert.Equal(t, block, engine.ctx.BlockContext)
+	assert.Equal(t, "xdbid", engine.data.Dataset)
+	assert.Equal(t, "deposit", engine.data.Procedure)
+	assert.Equal(t, []any{"0xabc", int64(5)}, engine.data.Args)
+
+	// the dataset must exist
+	call.DBID = "other"
+	body, err = call.MarshalBinary()
+	require.NoError(t, err)
+	resolution.Body = body
+	err = resolutionConfig.ResolveFunc(context.Background(), &common.App{Engine: engine}, resolution, block)
+	require.Error(t, err)
+}
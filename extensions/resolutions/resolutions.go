@@ -118,6 +118,38 @@ type ResolutionConfig struct {
 	// block execution. It is therefore expected that the function is
 	// deterministic, regardless of a node's local configuration.
 	ResolveFunc func(ctx context.Context, app *common.App, resolution *Resolution, block *common.BlockContext) error
+	// ExpireFunc is an optional function that is called once a
+	// resolution has expired without receiving the required number
+	// of votes. It can be used to react to a rejected resolution,
+	// such as to unlock funds or to retry. Like the ResolveFunc, it
+	// is called by all nodes as a part of block execution, and must
+	// be deterministic. If it returns an error, its changes are
+	// rolled back, but the resolution still expires.
+	ExpireFunc func(ctx context.Context, app *common.App, resolution *Resolution, block *common.BlockContext) error
+	// OnVote is an optional function that is called each time a
+	// validator votes for a resolution, including the implicit vote
+	// of the validator that proposed the resolution body. The voter
+	// is the validator's identifier, and the resolution includes the
+	// vote. It must be deterministic. If it returns an error, its
+	// changes are rolled back, but the vote is still counted.
+	OnVote func(ctx context.Context, app *common.App, resolution *Resolution, voter []byte, block *common.BlockContext) error
+	// ExpirationPeriodFunc optionally determines the expiration
+	// period of a single resolution from its body, allowing each
+	// resolution of a type to expire after a different number of
+	// blocks. If it is nil, or it returns a value <1, the
+	// ExpirationPeriod is used.
+	ExpirationPeriodFunc func(body []byte) int64
+}
+
+// ExpirationHeight returns the block height at which a resolution with the
+// given body, proposed at the given block height, will expire.
+func (r ResolutionConfig) ExpirationHeight(body []byte, height int64) int64 {
+	if r.ExpirationPeriodFunc != nil {
+		if period := r.ExpirationPeriodFunc(body); period > 0 {
+			return height + period
+		}
+	}
+	return height + r.ExpirationPeriod
 }
 
 // Resolution contains information for a resolution that can be voted
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -38,13 +39,14 @@ import (
 
 	abciTypes "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/crypto/tmhash"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	"go.uber.org/zap"
 )
 
 var (
 	ABCIPeerFilterPath       = "/p2p/filter/"
 	ABCIPeerFilterPathLen    = len(ABCIPeerFilterPath)
-	statesyncSnapshotSchemas = []string{"kwild_voting", "kwild_internal", "kwild_chain", "kwild_accts", "kwild_migrations", "ds_*"}
+	statesyncSnapshotSchemas = []string{"kwild_voting", "kwild_internal", "kwild_chain", "kwild_accts", "kwild_migrations", "kwild_sessions", "kwild_withdrawals", "kwild_staking", "kwild_scheduler", "kwild_wasm", "ds_*"}
 	statsyncExcludedTables   = []string{"kwild_internal.sentry"}
 	lastCommitInfoFile       = "last_commit_info.json"
 )
@@ -60,6 +62,9 @@ type AbciConfig struct {
 	GasEnabled         bool
 	ForkHeights        map[string]*uint64
 	InitialHeight      int64
+	// GenesisIdentities maps the hex encoded consensus keys of the genesis
+	// validators that have one to the validators' identifiers.
+	GenesisIdentities map[string][]byte
 
 	ABCIDir string
 }
@@ -103,9 +108,10 @@ type AbciApp struct {
 
 	broadcastFn EventBroadcaster
 
-	// validatorAddressToPubKey is a map of validator addresses to their public
-	// keys. It should only be accessed from consensus connection methods, which
-	// are not called concurrently, or the constructor.
+	// validatorAddressToPubKey is a map of validator addresses, which are
+	// derived from their consensus keys, to their public keys. It should only
+	// be accessed from consensus connection methods, which are not called
+	// concurrently, or the constructor.
 	validatorAddressToPubKey map[string][]byte
 
 	// verifiedTxns stores hashes of all the transactions currently in the
@@ -125,6 +131,9 @@ type AbciApp struct {
 	// Migrator is the migrator module that handles migrations
 	migrator MigratorModule
 
+	// changeFeed records the changes committed in each block, if set.
+	changeFeed ChangeFeedModule
+
 	// lastCommitInfoFileName is the file name of the last commit info file
 	// which stores the app hash and height at the end of FinalizeBlock.
 	lastCommitInfoFileName string
@@ -162,7 +171,7 @@ func NewAbciApp(ctx context.Context, cfg *AbciConfig, snapshotter SnapshotModule
 		return nil, fmt.Errorf("failed to get validators: %w", err)
 	}
 	for _, val := range validators {
-		addr, err := cometbft.PubkeyToAddr(val.PubKey)
+		addr, err := cometbft.PubkeyToAddr(consensusKey(val))
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert pubkey to address: %w", err)
 		}
@@ -311,6 +320,34 @@ func NewAbciApp(ctx context.Context, cfg *AbciConfig, snapshotter SnapshotModule
 	return app, nil
 }
 
+// evidenceType returns the type of validator misbehavior evidence.
+func evidenceType(t abciTypes.MisbehaviorType) string {
+	switch t {
+	case abciTypes.MisbehaviorType_DUPLICATE_VOTE:
+		return types.EvidenceTypeDuplicateVote
+	case abciTypes.MisbehaviorType_LIGHT_CLIENT_ATTACK:
+		return types.EvidenceTypeLightClientAttack
+	default:
+		return strings.ToLower(t.String())
+	}
+}
+
+// misbehaviorEvent returns the block event that reports a validator's
+// punishment for misbehavior.
+func misbehaviorEvent(ev *types.ValidatorEvidence) abciTypes.Event {
+	return abciTypes.Event{
+		Type: "validator_misbehavior",
+		Attributes: []abciTypes.EventAttribute{
+			{Key: "validator", Value: hex.EncodeToString(ev.Validator), Index: true},
+			{Key: "type", Value: ev.Type, Index: true},
+			{Key: "height", Value: strconv.FormatInt(ev.Height, 10)},
+			{Key: "power_before", Value: strconv.FormatInt(ev.PowerBefore, 10)},
+			{Key: "power_after", Value: strconv.FormatInt(ev.PowerAfter, 10)},
+			{Key: "slashed", Value: ev.Slashed},
+		},
+	}
+}
+
 // proposerAddrToString converts a proposer address to a string.
 // This follows the semantics of comet's ed25519.Pubkey.Address() method,
 // which hex encodes and upper cases the address
@@ -506,14 +543,31 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 		}
 	}
 
-	err := a.txApp.Begin(ctx, req.Height)
+	a.chainContextMtx.Lock()
+	defer a.chainContextMtx.Unlock()
+
+	addr := proposerAddrToString(req.ProposerAddress)
+	proposerPubKey, ok := a.validatorAddressToPubKey[addr]
+	if !ok && len(req.Txs) > 0 {
+		// ProcessProposal allows block proposals for untracked validators, but
+		// only if the block has no transactions.
+		return nil, fmt.Errorf("failed to find proposer pubkey corresponding to address %v", addr)
+	}
+	// Note that in the !ok case, empty Txs is required, and the proposerPubKey
+	// may be empty!
+
+	blockCtx := common.BlockContext{
+		ChainContext: a.chainContext,
+		Height:       req.Height,
+		Timestamp:    req.Time.Unix(),
+		Proposer:     proposerPubKey,
+	}
+
+	err := a.txApp.Begin(ctx, a.consensusTx, &blockCtx)
 	if err != nil {
 		return nil, fmt.Errorf("begin tx commit failed: %w", err)
 	}
 
-	a.chainContextMtx.Lock()
-	defer a.chainContextMtx.Unlock()
-
 	// we copy the Kwil consensus params to ensure we persist any changes
 	// made during the block execution
 	networkParams := &common.NetworkParameters{
@@ -532,9 +586,10 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 	}
 
 	// Punish bad validators.
+	var misbehaviorEvents []abciTypes.Event
 	for _, ev := range req.Misbehavior {
 		addr := proposerAddrToString(ev.Validator.Address) // comet example app confirms this conversion... weird
-		logger.Info("punish validator", zap.String("addr", addr))
+		logger.Info("punish validator", zap.String("addr", addr), zap.String("type", ev.Type.String()))
 		// FORKSITE: could alter punishment system (consider misbehavior Type)
 
 		// CometBFT gives the address, not public key, so we have to remember them.
@@ -547,30 +602,48 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 			continue
 		}
 
-		const punishDelta = 1
-		newPower := ev.Validator.Power - punishDelta
-		if err = a.txApp.UpdateValidator(ctx, a.consensusTx, pubkey, newPower); err != nil {
+		if !a.forks.IsActive(consensus.ForkValidatorEvidence, uint64(req.Height)) {
+			const punishDelta = 1
+			newPower := ev.Validator.Power - punishDelta
+			if err = a.txApp.UpdateValidator(ctx, a.consensusTx, pubkey, newPower); err != nil {
+				return nil, fmt.Errorf("failed to punish validator: %w", err)
+			}
+			continue
+		}
+
+		evidence := &types.ValidatorEvidence{
+			Validator:    pubkey,
+			Type:         evidenceType(ev.Type),
+			Height:       ev.Height,
+			Time:         ev.Time.Unix(),
+			CommitHeight: req.Height,
+		}
+		if err = a.txApp.Punish(ctx, a.consensusTx, evidence); err != nil {
 			return nil, fmt.Errorf("failed to punish validator: %w", err)
 		}
+		misbehaviorEvents = append(misbehaviorEvents, misbehaviorEvent(evidence))
 	}
 
-	addr := proposerAddrToString(req.ProposerAddress)
-	proposerPubKey, ok := a.validatorAddressToPubKey[addr]
-	if !ok && len(req.Txs) > 0 {
-		// ProcessProposal allows block proposals for untracked validators, but
-		// only if the block has no transactions.
-		return nil, fmt.Errorf("failed to find proposer pubkey corresponding to address %v", addr)
+	// Track which validators signed the previous block, jailing any that have
+	// missed too many blocks.
+	var signed, missed [][]byte
+	for _, vote := range req.DecidedLastCommit.Votes {
+		pubkey, ok := a.validatorAddressToPubKey[proposerAddrToString(vote.Validator.Address)]
+		if !ok {
+			continue // e.g. removed in the previous block
+		}
+		if vote.BlockIdFlag == cmtproto.BlockIDFlagAbsent {
+			missed = append(missed, pubkey)
+		} else {
+			signed = append(signed, pubkey)
+		}
+	}
+	if err = a.txApp.TrackLiveness(ctx, a.consensusTx, req.Height-1, signed, missed); err != nil {
+		return nil, fmt.Errorf("failed to track validator liveness: %w", err)
 	}
-	// Note that in the !ok case, empty Txs is required, and the proposerPubKey
-	// may be empty!
-
-	res := &abciTypes.ResponseFinalizeBlock{}
 
-	blockCtx := common.BlockContext{
-		ChainContext: a.chainContext,
-		Height:       req.Height,
-		Timestamp:    req.Time.Unix(),
-		Proposer:     proposerPubKey,
+	res := &abciTypes.ResponseFinalizeBlock{
+		Events: misbehaviorEvents,
 	}
 
 	inMigration := blockCtx.ChainContext.NetworkParameters.MigrationStatus == types.MigrationInProgress
@@ -665,14 +738,21 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 			return nil, fmt.Errorf("failed to get identifier: %w", err)
 		}
 
-		txRes := a.txApp.Execute(&common.TxContext{
+		txCtx := &common.TxContext{
 			Ctx:           ctx,
 			TxID:          hex.EncodeToString(txHash[:]), // tmhash.Sum(tx), // use cometbft TmHash to get the same hash as is indexed
 			BlockContext:  &blockCtx,
 			Signer:        decoded.Sender,
 			Authenticator: decoded.Signature.Type,
 			Caller:        ident,
-		}, a.consensusTx, decoded)
+		}
+		txRes := a.txApp.Execute(txCtx, a.consensusTx, decoded)
+
+		// let extensions observe the executed transaction and its result
+		err = a.txApp.TxResult(txCtx, a.consensusTx, decoded, txRes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process transaction result: %w", err)
+		}
 
 		abciRes := &abciTypes.ExecTxResult{}
 		if txRes.Error != nil {
@@ -779,6 +859,21 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 		}()
 	}
 
+	// "cdc" module subscribes to the changeset processor to record the block's
+	// changes for the change data capture feed
+	cdcErrChan := make(chan error, 1)
+	defer close(cdcErrChan)
+
+	if a.changeFeed != nil {
+		csChanCDC, err := csp.Subscribe(ctx, "cdc")
+		if err != nil {
+			return nil, fmt.Errorf("failed to subscribe to changeset processor: %w", err)
+		}
+		go func() {
+			cdcErrChan <- a.changeFeed.StoreChangesets(req.Height, csChanCDC)
+		}()
+	}
+
 	// statistics module can subscribe to the changeset processor to listen for changesets for updating statistics
 	// statsChan := csp.Subscribe(ctx, "statistics")
 
@@ -812,28 +907,33 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 
 	res.ValidatorUpdates = make([]abciTypes.ValidatorUpdate, len(valUpdates))
 	for i, up := range valUpdates {
-		addr, err := cometbft.PubkeyToAddr(up.PubKey)
+		addr, err := cometbft.PubkeyToAddr(consensusKey(up))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert pubkey to address: %w", err)
+		}
+		// the node's peer ID is derived from its node key, not its consensus key
+		nodeID, err := cometbft.PubkeyToAddr(up.PubKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert pubkey to address: %w", err)
 		}
 		if up.Power == 0 {
 			delete(a.validatorAddressToPubKey, addr)
-			if err = a.p2p.RemovePeer(ctx, addr); err != nil {
+			if err = a.p2p.RemovePeer(ctx, nodeID); err != nil {
 				if !errors.Is(err, cometbft.ErrPeerNotWhitelisted) {
-					a.log.Warn("failed to remove demoted validator from peer list", log.String("address", addr), log.Error(err))
+					a.log.Warn("failed to remove demoted validator from peer list", log.String("address", nodeID), log.Error(err))
 				}
 			}
 		} else {
 			a.validatorAddressToPubKey[addr] = up.PubKey // there may be new validators we need to add
 			// Add the validator to the peer list
-			if err = a.p2p.AddPeer(ctx, addr); err != nil {
+			if err = a.p2p.AddPeer(ctx, nodeID); err != nil {
 				if !errors.Is(err, cometbft.ErrPeerAlreadyWhitelisted) {
-					a.log.Warn("failed to whitelist promoted validator", log.String("address", addr), log.Error(err))
+					a.log.Warn("failed to whitelist promoted validator", log.String("address", nodeID), log.Error(err))
 				}
 			}
 		}
 
-		res.ValidatorUpdates[i] = abciTypes.Ed25519ValidatorUpdate(up.PubKey, up.Power)
+		res.ValidatorUpdates[i] = abciTypes.Ed25519ValidatorUpdate(consensusKey(up), up.Power)
 	}
 
 	// Join requests approved by this node are added to the peer list.
@@ -891,6 +991,14 @@ func (a *AbciApp) FinalizeBlock(ctx context.Context, req *abciTypes.RequestFinal
 		}
 	}
 
+	if a.changeFeed != nil {
+		// The change feed is not part of consensus, so failing to record the
+		// changes is not fatal.
+		if err = <-cdcErrChan; err != nil {
+			a.log.Error("failed to store changes for change data capture", log.Error(err))
+		}
+	}
+
 	// Persist app hash and height to the disk for recovery purposes.
 	lc := &lastCommitInfo{
 		Height:  req.Height,
@@ -934,8 +1042,9 @@ func validatorUpdates(initial, final []*types.Validator) []*types.Validator {
 		if _, ok := finalVals[key]; !ok {
 			// Validator is in the initial set, but not in the final set
 			updates = append(updates, &types.Validator{
-				PubKey: val.PubKey,
-				Power:  0,
+				PubKey:       val.PubKey,
+				Power:        0,
+				ConsensusKey: val.ConsensusKey,
 			})
 		}
 	}
@@ -943,6 +1052,15 @@ func validatorUpdates(initial, final []*types.Validator) []*types.Validator {
 	return updates
 }
 
+// consensusKey returns the key that CometBFT identifies a validator by, which
+// signs its votes and proposals.
+func consensusKey(val *types.Validator) []byte {
+	if len(val.ConsensusKey) > 0 {
+		return val.ConsensusKey
+	}
+	return val.PubKey
+}
+
 // Commit persists the state changes. This is called under mempool lock in
 // cometbft, unlike FinalizeBlock.
 func (a *AbciApp) Commit(ctx context.Context, _ *abciTypes.RequestCommit) (*abciTypes.ResponseCommit, error) {
@@ -989,6 +1107,12 @@ func (a *AbciApp) Commit(ctx context.Context, _ *abciTypes.RequestCommit) (*abci
 
 	a.txApp.Commit(ctx)
 
+	if a.changeFeed != nil {
+		if err = a.changeFeed.Commit(height); err != nil {
+			a.log.Error("failed to commit changes for change data capture", log.Error(err))
+		}
+	}
+
 	// Snapshots are to be taken if:
 	// - the block height is a multiple of the snapshot interval
 	// - there are no snapshots in the store (This is to support the new nodes joining the network using
@@ -1146,13 +1270,17 @@ func (a *AbciApp) InitChain(ctx context.Context, req *abciTypes.RequestInitChain
 			PubKey: pk,
 			Power:  vi.Power,
 		}
+		if id, ok := a.cfg.GenesisIdentities[hex.EncodeToString(pk)]; ok {
+			vldtrs[i].PubKey = id
+			vldtrs[i].ConsensusKey = pk
+		}
 
 		addr, err := cometbft.PubkeyToAddr(pk)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert pubkey to address: %w", err)
 		}
 
-		a.validatorAddressToPubKey[addr] = pk
+		a.validatorAddressToPubKey[addr] = vldtrs[i].PubKey
 	}
 
 	// With the genesisTx not being committed until the first FinalizeBlock, we
@@ -1184,7 +1312,7 @@ func (a *AbciApp) InitChain(ctx context.Context, req *abciTypes.RequestInitChain
 
 	valUpdates := make([]abciTypes.ValidatorUpdate, len(vldtrs))
 	for i, validator := range vldtrs {
-		valUpdates[i] = abciTypes.Ed25519ValidatorUpdate(validator.PubKey, validator.Power)
+		valUpdates[i] = abciTypes.Ed25519ValidatorUpdate(consensusKey(validator), validator.Power)
 	}
 
 	logger.Info("initialized chain", zap.String("app hash", fmt.Sprintf("%x", a.cfg.GenesisAppHash)))
@@ -1236,7 +1364,7 @@ func (a *AbciApp) ApplySnapshotChunk(ctx context.Context, req *abciTypes.Request
 			return nil, fmt.Errorf("failed to get current validators: %w", err)
 		}
 		for _, val := range validators {
-			addr, err := cometbft.PubkeyToAddr(val.PubKey)
+			addr, err := cometbft.PubkeyToAddr(consensusKey(val))
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert pubkey to address: %w", err)
 			}
@@ -1832,6 +1960,12 @@ func (a *AbciApp) TxInMempool(txHash []byte) bool {
 	return ok
 }
 
+// SetChangeFeed sets the module that records the changes committed in each
+// block for change data capture.
+func (a *AbciApp) SetChangeFeed(feed ChangeFeedModule) {
+	a.changeFeed = feed
+}
+
 // SetReplayStatusChecker sets the function to check if the node is in replay mode.
 // This has to be set here because it is a CometBFT node function. Since ABCI is
 // a dependency to CometBFT, this is a circular dependency, so we have to set it
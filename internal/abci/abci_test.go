@@ -400,7 +400,7 @@ func (m *mockTxApp) ApplyMempool(ctx *common.TxContext, db sql.DB, tx *transacti
 	return nil
 }
 
-func (m *mockTxApp) Begin(ctx context.Context, height int64) error {
+func (m *mockTxApp) Begin(ctx context.Context, db sql.DB, block *common.BlockContext) error {
 	return nil
 }
 
@@ -431,6 +431,18 @@ func (m *mockTxApp) UpdateValidator(ctx context.Context, db sql.DB, validator []
 	return nil
 }
 
+func (m *mockTxApp) Punish(ctx context.Context, db sql.DB, ev *types.ValidatorEvidence) error {
+	return nil
+}
+
+func (m *mockTxApp) TrackLiveness(ctx context.Context, db sql.DB, height int64, signed, missed [][]byte) error {
+	return nil
+}
+
+func (m *mockTxApp) TxResult(ctx *common.TxContext, db sql.DB, tx *transactions.Transaction, res *txapp.TxResponse) error {
+	return nil
+}
+
 func (m *mockTxApp) Reload(ctx context.Context, db sql.DB) error {
 	return nil
 }
@@ -488,3 +500,25 @@ func (m *mockTx) BeginTx(ctx context.Context) (sql.Tx, error) {
 func (m *mockTx) Precommit(ctx context.Context, changes chan<- any) ([]byte, error) {
 	return nil, nil
 }
+
+func Test_validatorUpdates(t *testing.T) {
+	initial := []*types.Validator{
+		{PubKey: []byte("a"), Power: 1},
+		{PubKey: []byte("b"), Power: 1, ConsensusKey: []byte("bk")},
+		{PubKey: []byte("c"), Power: 1, ConsensusKey: []byte("ck")},
+	}
+	final := []*types.Validator{
+		{PubKey: []byte("a"), Power: 1},
+		{PubKey: []byte("b"), Power: 2, ConsensusKey: []byte("bk")},
+		{PubKey: []byte("d"), Power: 1, ConsensusKey: []byte("dk")},
+	}
+
+	updates := validatorUpdates(initial, final)
+	byKey := make(map[string]int64)
+	for _, up := range updates {
+		byKey[string(consensusKey(up))] = up.Power
+	}
+
+	// CometBFT is given the consensus keys, including of removed validators
+	assert.Equal(t, map[string]int64{"bk": 2, "ck": 0, "dk": 1}, byKey)
+}
@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 
 	"github.com/kwilteam/kwil-db/core/log"
-	"github.com/kwilteam/kwil-db/internal/abci/cometbft/privval"
 
 	abciTypes "github.com/cometbft/cometbft/abci/types"
 	cometConfig "github.com/cometbft/cometbft/config"
@@ -168,9 +167,12 @@ WARNING: These files are overwritten on kwild startup.`
 	return nil
 }
 
-// NewCometBftNode creates a new CometBFT node.
+// NewCometBftNode creates a new CometBFT node. The private key is the node's
+// p2p identity, and the private validator signs votes and proposals, which may
+// be done with the same key by a privval.ValidatorSigner, or by a remote signer
+// with a privval.RemoteSigner.
 func NewCometBftNode(ctx context.Context, app abciTypes.Application, conf *cometConfig.Config,
-	genDoc *types.GenesisDoc, privateKey cometEd25519.PrivKey, atomicStore privval.AtomicReadWriter,
+	genDoc *types.GenesisDoc, privateKey cometEd25519.PrivKey, privateValidator types.PrivValidator,
 	logger *log.Logger) (*CometBftNode, error) {
 	if err := writeCometBFTConfigs(conf, genDoc); err != nil {
 		return nil, fmt.Errorf("failed to write the effective cometbft config files: %w", err)
@@ -183,11 +185,6 @@ func NewCometBftNode(ctx context.Context, app abciTypes.Application, conf *comet
 		return nil, fmt.Errorf("invalid node config: %w", err)
 	}
 
-	privateValidator, err := privval.NewValidatorSigner(privateKey, atomicStore)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create private validator: %v", err)
-	}
-
 	node, err := cometNodes.NewNodeWithContext(
 		ctx,
 		conf,
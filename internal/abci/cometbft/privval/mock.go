@@ -0,0 +1,103 @@
+package privval
+
+import (
+	"net"
+	"sync"
+
+	cometEd25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	cometLog "github.com/cometbft/cometbft/libs/log"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	p2pconn "github.com/cometbft/cometbft/p2p/conn"
+	cmtPrivval "github.com/cometbft/cometbft/privval"
+)
+
+// MockRemoteSigner is a remote signer for tests. It signs votes and proposals
+// for an ed25519 key using CometBFT's remote signer protocol, with the same
+// double sign protection as ValidatorSigner, but its state is only kept in
+// memory.
+type MockRemoteSigner struct {
+	server *cmtPrivval.SignerServer
+
+	// listener is set if the mock listens for the node to connect
+	listener net.Listener
+	addr     string
+}
+
+// NewMockRemoteSigner creates a MockRemoteSigner. If listen is false, it dials
+// the node at addr, like tmkms. Otherwise, it listens at addr for the node to
+// connect. Tcp connections are authenticated with privKey. The signer does not
+// connect until it is started.
+func NewMockRemoteSigner(addr, chainID string, privKey cometEd25519.PrivKey, listen bool, logger cometLog.Logger) (*MockRemoteSigner, error) {
+	signer, err := NewValidatorSigner(privKey, &memStore{})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MockRemoteSigner{addr: addr}
+
+	var dial cmtPrivval.SocketDialer
+	if listen {
+		protocol, address := cmtnet.ProtocolAndAddress(addr)
+		m.listener, err = net.Listen(protocol, address)
+		if err != nil {
+			return nil, err
+		}
+		m.addr = protocol + "://" + m.listener.Addr().String()
+
+		dial = func() (net.Conn, error) {
+			conn, err := m.listener.Accept()
+			if err != nil || protocol != "tcp" {
+				return conn, err
+			}
+			return p2pconn.MakeSecretConnection(conn, privKey)
+		}
+	} else {
+		dial, err = socketDialer(addr, privKey, defaultRemoteSignerTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := cmtPrivval.NewSignerDialerEndpoint(logger, dial)
+	m.server = cmtPrivval.NewSignerServer(endpoint, chainID, signer)
+
+	return m, nil
+}
+
+// Addr returns the address of the node, or if the mock listens, the address on
+// which it listens.
+func (m *MockRemoteSigner) Addr() string {
+	return m.addr
+}
+
+// Start starts serving sign requests.
+func (m *MockRemoteSigner) Start() error {
+	return m.server.Start()
+}
+
+// Stop stops serving sign requests and closes the connection.
+func (m *MockRemoteSigner) Stop() error {
+	if m.listener != nil {
+		m.listener.Close()
+	}
+	return m.server.Stop()
+}
+
+// memStore is an in-memory AtomicReadWriter.
+type memStore struct {
+	mtx sync.Mutex
+	bts []byte
+}
+
+func (m *memStore) Read() ([]byte, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.bts, nil
+}
+
+func (m *memStore) Write(bts []byte) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.bts = bts
+	return nil
+}
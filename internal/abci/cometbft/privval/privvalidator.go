@@ -150,7 +150,7 @@ func (v *ValidatorSigner) SignVote(chainID string, vote *tendermintTypes.Vote) e
 			vote.Timestamp = timestamp
 			vote.Signature = v.lastSignedState.Signature
 		} else {
-			err = fmt.Errorf("conflicting data")
+			err = ErrConflictingData
 		}
 
 		vote.ExtensionSignature = extSig
@@ -179,12 +179,7 @@ func (v *ValidatorSigner) signAndPersist(height int64, round int32, step int8, s
 		return nil, err
 	}
 
-	v.lastSignedState.Height = height
-	v.lastSignedState.Round = round
-	v.lastSignedState.Step = step
-	v.lastSignedState.SignBytes = signBytes
-	v.lastSignedState.Signature = signature
-	err = v.lastSignedState.store()
+	err = v.lastSignedState.save(height, round, step, signBytes, signature)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +223,16 @@ func (l *LastSignState) store() error {
 	return l.storer.Write(bts)
 }
 
+// save updates the lastSignState with a new signature and stores it.
+func (l *LastSignState) save(height int64, round int32, step int8, signBytes, signature []byte) error {
+	l.Height = height
+	l.Round = round
+	l.Step = step
+	l.SignBytes = signBytes
+	l.Signature = signature
+	return l.store()
+}
+
 func newLastSignState(storer AtomicReadWriter) (*LastSignState, error) {
 	l := &LastSignState{storer: storer}
 
@@ -359,4 +364,6 @@ var (
 	ErrStepRegression   = errors.New("step regression")
 	ErrNilSignature     = errors.New("signature is nil")
 	ErrUnknownVoteType  = errors.New("unknown vote type")
+	ErrConflictingData  = errors.New("conflicting data")
+	ErrInvalidSignature = errors.New("invalid signature from remote signer")
 )
@@ -0,0 +1,334 @@
+package privval
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto"
+	cometEd25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	cometLog "github.com/cometbft/cometbft/libs/log"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	p2pconn "github.com/cometbft/cometbft/p2p/conn"
+	cmtPrivval "github.com/cometbft/cometbft/privval"
+	tendermintTypes "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+const (
+	defaultRemoteSignerTimeout     = 5 * time.Second
+	defaultRemoteSignerConnTimeout = time.Minute
+)
+
+// RemoteSignerConfig configures the connection to a remote signer.
+type RemoteSignerConfig struct {
+	// ListenAddr is the address (tcp://host:port or unix:///path) on which to
+	// listen for the remote signer to connect.
+	ListenAddr string
+	// DialAddr is the address (tcp://host:port or unix:///path) of a remote
+	// signer that listens for the node to connect. Only one of ListenAddr and
+	// DialAddr may be set.
+	DialAddr string
+	// ConnKey authenticates the node to the remote signer on tcp connections.
+	ConnKey cometEd25519.PrivKey
+	// Timeout is the read and write timeout for requests. If zero, 5 seconds
+	// is used.
+	Timeout time.Duration
+	// ConnTimeout is how long NewRemoteSigner waits for a connection to the
+	// remote signer. If zero, 1 minute is used.
+	ConnTimeout time.Duration
+}
+
+// NewRemoteSigner connects to a remote signer and returns a RemoteSigner that
+// uses it. It blocks until the remote signer is connected, or the connection
+// timeout elapses. The storer is used in the same way as by NewValidatorSigner.
+func NewRemoteSigner(cfg *RemoteSignerConfig, chainID string, storer AtomicReadWriter, logger cometLog.Logger) (*RemoteSigner, error) {
+	timeout, connTimeout := cfg.Timeout, cfg.ConnTimeout
+	if timeout <= 0 {
+		timeout = defaultRemoteSignerTimeout
+	}
+	if connTimeout <= 0 {
+		connTimeout = defaultRemoteSignerConnTimeout
+	}
+
+	lss, err := newLastSignState(storer)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := newSignerListener(cfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := cmtPrivval.NewSignerListenerEndpoint(logger, listener,
+		cmtPrivval.SignerListenerEndpointTimeoutReadWrite(timeout))
+	client, err := cmtPrivval.NewSignerClient(endpoint, chainID) // starts the endpoint
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	if err = client.WaitForConnection(connTimeout); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+
+	pubKey, err := client.GetPubKey()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to get public key from remote signer: %w", err)
+	}
+
+	return &RemoteSigner{
+		client:          client,
+		pubKey:          pubKey,
+		lastSignedState: lss,
+	}, nil
+}
+
+// RemoteSigner implements CometBFT's cometTypes.PrivValidator by forwarding
+// sign requests to a remote signer, such as tmkms, using CometBFT's remote
+// signer protocol. The remote signer should have its own double sign
+// protection, but like ValidatorSigner, RemoteSigner also checks the height,
+// round, and step of every request against its most recent signature, which it
+// persists. Every signature from the remote signer is verified before it is
+// used.
+type RemoteSigner struct {
+	client *cmtPrivval.SignerClient
+
+	// pubKey is the public key of the remote signer's validator key
+	pubKey crypto.PubKey
+
+	// lastSignedState is the most recent signature made by the remote signer
+	lastSignedState *LastSignState
+}
+
+var _ types.PrivValidator = (*RemoteSigner)(nil)
+
+// GetPubKey returns the public key of the validator
+// It is part of the cometTypes.PrivValidator interface
+func (r *RemoteSigner) GetPubKey() (crypto.PubKey, error) {
+	return r.pubKey, nil
+}
+
+// SignProposal signs a proposal message with the remote signer
+// It is part of the cometTypes.PrivValidator interface
+func (r *RemoteSigner) SignProposal(chainID string, proposal *tendermintTypes.Proposal) error {
+	height, round, step := proposal.Height, proposal.Round, stepPropose
+
+	sameHRS, err := r.lastSignedState.checkHRS(height, round, step)
+	if err != nil {
+		return err
+	}
+
+	// If we already have a signature for this HRS, the remote signer should
+	// return the same signature, so it may only differ by timestamp.
+	reqSignBytes := types.ProposalSignBytes(chainID, proposal)
+	if sameHRS {
+		if _, ok := checkProposalsOnlyDifferByTimestamp(r.lastSignedState.SignBytes, reqSignBytes); !ok {
+			return fmt.Errorf("%w: proposal sign bytes differ from last sign bytes", ErrConflictingData)
+		}
+	}
+
+	if err = r.client.SignProposal(chainID, proposal); err != nil {
+		return err
+	}
+
+	// The remote signer may use the timestamp of its own last signature, but
+	// must otherwise sign the requested proposal.
+	signBytes := types.ProposalSignBytes(chainID, proposal)
+	if _, ok := checkProposalsOnlyDifferByTimestamp(reqSignBytes, signBytes); !ok {
+		return fmt.Errorf("%w: remote signer signed a different proposal", ErrConflictingData)
+	}
+	if !r.pubKey.VerifySignature(signBytes, proposal.Signature) {
+		return fmt.Errorf("%w: proposal at height %d round %d", ErrInvalidSignature, height, round)
+	}
+
+	return r.lastSignedState.save(height, round, step, signBytes, proposal.Signature)
+}
+
+// SignVote signs a vote message with the remote signer
+// It is part of the cometTypes.PrivValidator interface
+func (r *RemoteSigner) SignVote(chainID string, vote *tendermintTypes.Vote) error {
+	step, err := voteToStep(vote)
+	if err != nil {
+		return err
+	}
+	height, round := vote.Height, vote.Round
+
+	sameHRS, err := r.lastSignedState.checkHRS(height, round, step)
+	if err != nil {
+		return err
+	}
+
+	// If we already have a signature for this HRS, the remote signer should
+	// return the same signature, so it may only differ by timestamp. The
+	// remote signer is still asked to sign the vote extension.
+	reqSignBytes := types.VoteSignBytes(chainID, vote)
+	if sameHRS {
+		if _, ok := checkVotesOnlyDifferByTimestamp(r.lastSignedState.SignBytes, reqSignBytes); !ok {
+			return ErrConflictingData
+		}
+	}
+
+	if err = r.client.SignVote(chainID, vote); err != nil {
+		return err
+	}
+
+	signBytes := types.VoteSignBytes(chainID, vote)
+	if _, ok := checkVotesOnlyDifferByTimestamp(reqSignBytes, signBytes); !ok {
+		return fmt.Errorf("%w: remote signer signed a different vote", ErrConflictingData)
+	}
+	if !r.pubKey.VerifySignature(signBytes, vote.Signature) {
+		return fmt.Errorf("%w: vote at height %d round %d", ErrInvalidSignature, height, round)
+	}
+	if vote.Type == tendermintTypes.PrecommitType && !types.ProtoBlockIDIsNil(&vote.BlockID) {
+		extSignBytes := types.VoteExtensionSignBytes(chainID, vote)
+		if !r.pubKey.VerifySignature(extSignBytes, vote.ExtensionSignature) {
+			return fmt.Errorf("%w: vote extension at height %d round %d", ErrInvalidSignature, height, round)
+		}
+	}
+
+	return r.lastSignedState.save(height, round, step, signBytes, vote.Signature)
+}
+
+// Close closes the connection to the remote signer.
+func (r *RemoteSigner) Close() error {
+	return r.client.Close()
+}
+
+// newSignerListener returns a listener for the remote signer's connection. If
+// the remote signer listens for the node to connect, the listener dials it.
+func newSignerListener(cfg *RemoteSignerConfig, timeout time.Duration) (net.Listener, error) {
+	switch {
+	case cfg.ListenAddr != "" && cfg.DialAddr != "":
+		return nil, errors.New("only one of the remote signer listen and dial addresses may be set")
+	case cfg.DialAddr != "":
+		dial, err := socketDialer(cfg.DialAddr, cfg.ConnKey, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return &dialListener{
+			dial:      dial,
+			addr:      signerAddr(cfg.DialAddr),
+			retryWait: time.Second,
+			closed:    make(chan struct{}),
+		}, nil
+	case cfg.ListenAddr != "":
+	default:
+		return nil, errors.New("no remote signer address")
+	}
+
+	protocol, address := cmtnet.ProtocolAndAddress(cfg.ListenAddr)
+	switch protocol {
+	case "unix":
+		ln, err := net.Listen(protocol, address)
+		if err != nil {
+			return nil, err
+		}
+		ul := cmtPrivval.NewUnixListener(ln)
+		cmtPrivval.UnixListenerTimeoutReadWrite(timeout)(ul)
+		return ul, nil
+	case "tcp":
+		ln, err := net.Listen(protocol, address)
+		if err != nil {
+			return nil, err
+		}
+		tl := cmtPrivval.NewTCPListener(ln, cfg.ConnKey)
+		cmtPrivval.TCPListenerTimeoutReadWrite(timeout)(tl)
+		return tl, nil
+	default:
+		return nil, fmt.Errorf("invalid remote signer address %q: expected tcp or unix protocol", cfg.ListenAddr)
+	}
+}
+
+// socketDialer returns a function that dials a tcp or unix address. Tcp
+// connections are authenticated and encrypted with connKey.
+func socketDialer(addr string, connKey cometEd25519.PrivKey, timeout time.Duration) (cmtPrivval.SocketDialer, error) {
+	protocol, address := cmtnet.ProtocolAndAddress(addr)
+	switch protocol {
+	case "unix":
+		return func() (net.Conn, error) {
+			return net.DialTimeout(protocol, address, timeout)
+		}, nil
+	case "tcp":
+		return func() (net.Conn, error) {
+			conn, err := net.DialTimeout(protocol, address, timeout)
+			if err != nil {
+				return nil, err
+			}
+			// The deadline is for the handshake. The endpoint sets new
+			// deadlines for each request.
+			if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			sc, err := p2pconn.MakeSecretConnection(conn, connKey)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return sc, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid remote signer address %q: expected tcp or unix protocol", addr)
+	}
+}
+
+// dialListener is a net.Listener that dials a remote signer instead of
+// accepting connections, so that CometBFT's SignerListenerEndpoint may be used
+// with a remote signer that listens for the node to connect.
+type dialListener struct {
+	dial      cmtPrivval.SocketDialer
+	addr      net.Addr
+	retryWait time.Duration
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ net.Listener = (*dialListener)(nil)
+
+func (l *dialListener) Accept() (net.Conn, error) {
+	select {
+	case <-l.closed:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		// The endpoint tries again right away, so wait before returning.
+		select {
+		case <-time.After(l.retryWait):
+		case <-l.closed:
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (l *dialListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *dialListener) Addr() net.Addr {
+	return l.addr
+}
+
+// signerAddr is the net.Addr of a remote signer.
+type signerAddr string
+
+func (a signerAddr) Network() string {
+	protocol, _ := cmtnet.ProtocolAndAddress(string(a))
+	return protocol
+}
+
+func (a signerAddr) String() string {
+	_, address := cmtnet.ProtocolAndAddress(string(a))
+	return address
+}
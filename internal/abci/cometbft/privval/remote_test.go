@@ -0,0 +1,129 @@
+package privval
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cometEd25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	cometLog "github.com/cometbft/cometbft/libs/log"
+	cmtPrivval "github.com/cometbft/cometbft/privval"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RemoteSigner(t *testing.T) {
+	privKeyBts, err := hex.DecodeString(defaultPrivateKey)
+	require.NoError(t, err)
+	privKey := cometEd25519.PrivKey(privKeyBts)
+
+	tests := []struct {
+		name string
+		// addr is the address of the node, or the mock signer if listen is true
+		addr   string
+		listen bool
+	}{
+		{
+			name: "node listens on tcp",
+			addr: "tcp://" + cmtPrivval.GetFreeLocalhostAddrPort(),
+		},
+		{
+			name: "node listens on unix socket",
+			addr: "unix://" + filepath.Join(t.TempDir(), "node.sock"),
+		},
+		{
+			name:   "node dials tcp",
+			addr:   "tcp://127.0.0.1:0",
+			listen: true,
+		},
+		{
+			name:   "node dials unix socket",
+			addr:   "unix://" + filepath.Join(t.TempDir(), "signer.sock"),
+			listen: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := cometLog.NewNopLogger()
+
+			mock, err := NewMockRemoteSigner(tc.addr, defaultChainID, privKey, tc.listen, logger)
+			require.NoError(t, err)
+			require.NoError(t, mock.Start())
+			defer mock.Stop()
+
+			cfg := &RemoteSignerConfig{
+				ConnKey:     cometEd25519.GenPrivKey(),
+				ConnTimeout: 10 * time.Second,
+			}
+			if tc.listen {
+				cfg.DialAddr = mock.Addr()
+			} else {
+				cfg.ListenAddr = tc.addr
+			}
+
+			store := newMockStore()
+			signer, err := NewRemoteSigner(cfg, defaultChainID, store, logger)
+			require.NoError(t, err)
+			defer signer.Close()
+
+			pubKey, err := signer.GetPubKey()
+			require.NoError(t, err)
+			assert.Equal(t, privKey.PubKey(), pubKey)
+
+			proposal := testProposal()
+			require.NoError(t, signer.SignProposal(defaultChainID, proposal))
+			assert.True(t, pubKey.VerifySignature(types.ProposalSignBytes(defaultChainID, proposal), proposal.Signature))
+
+			vote := testVote(step(cmtproto.PrecommitType))
+			require.NoError(t, signer.SignVote(defaultChainID, vote))
+			assert.True(t, pubKey.VerifySignature(types.VoteSignBytes(defaultChainID, vote), vote.Signature))
+			assert.True(t, pubKey.VerifySignature(types.VoteExtensionSignBytes(defaultChainID, vote), vote.ExtensionSignature))
+
+			// the last signature is persisted by the node
+			lss, err := newLastSignState(store)
+			require.NoError(t, err)
+			assert.Equal(t, vote.Height, lss.Height)
+			assert.Equal(t, stepPrecommit, lss.Step)
+			assert.Equal(t, vote.Signature, lss.Signature)
+
+			// signing the same vote again with a new timestamp returns the
+			// same signature and timestamp
+			again := testVote(step(cmtproto.PrecommitType), timestamped(1000))
+			require.NoError(t, signer.SignVote(defaultChainID, again))
+			assert.Equal(t, vote.Signature, again.Signature)
+			assert.Equal(t, vote.Timestamp.UTC(), again.Timestamp.UTC())
+
+			// a different vote for the same height, round, and step is refused
+			// by the node
+			conflict := testVote(step(cmtproto.PrecommitType))
+			conflict.BlockID.Hash = hash("hash2")
+			err = signer.SignVote(defaultChainID, conflict)
+			assert.ErrorIs(t, err, ErrConflictingData)
+
+			err = signer.SignVote(defaultChainID, testVote(height(9)))
+			assert.ErrorIs(t, err, ErrHeightRegression)
+
+			require.NoError(t, signer.SignVote(defaultChainID, testVote(height(11))))
+		})
+	}
+}
+
+func Test_RemoteSignerAddress(t *testing.T) {
+	_, err := NewRemoteSigner(&RemoteSignerConfig{
+		ListenAddr: "tcp://127.0.0.1:0",
+		DialAddr:   "tcp://127.0.0.1:0",
+	}, defaultChainID, newMockStore(), cometLog.NewNopLogger())
+	assert.Error(t, err)
+
+	_, err = NewRemoteSigner(&RemoteSignerConfig{
+		ListenAddr: "udp://127.0.0.1:0",
+	}, defaultChainID, newMockStore(), cometLog.NewNopLogger())
+	assert.Error(t, err)
+
+	_, err = NewRemoteSigner(&RemoteSignerConfig{}, defaultChainID, newMockStore(), cometLog.NewNopLogger())
+	assert.Error(t, err)
+}
@@ -48,14 +48,17 @@ type StateSyncModule interface {
 type TxApp interface {
 	AccountInfo(ctx context.Context, db sql.DB, acctID []byte, getUnconfirmed bool) (balance *big.Int, nonce int64, err error)
 	ApplyMempool(ctx *common.TxContext, db sql.DB, tx *transactions.Transaction) error
-	Begin(ctx context.Context, height int64) error
+	Begin(ctx context.Context, db sql.DB, block *common.BlockContext) error
 	Commit(ctx context.Context)
 	Execute(ctx *common.TxContext, db sql.DB, tx *transactions.Transaction) *txapp.TxResponse
 	Finalize(ctx context.Context, db sql.DB, block *common.BlockContext) (finalValidators []*types.Validator, approvedJoins, expiredJoins [][]byte, err error)
 	GenesisInit(ctx context.Context, db sql.DB, validators []*types.Validator, genesisAccounts []*types.Account, initialHeight int64, chain *common.ChainContext) error
 	GetValidators(ctx context.Context, db sql.DB) ([]*types.Validator, error)
 	ProposerTxs(ctx context.Context, db sql.DB, txNonce uint64, maxTxsSize int64, block *common.BlockContext) ([][]byte, error)
+	Punish(ctx context.Context, db sql.DB, ev *types.ValidatorEvidence) error
 	Reload(ctx context.Context, db sql.DB) error
+	TrackLiveness(ctx context.Context, db sql.DB, height int64, signed, missed [][]byte) error
+	TxResult(ctx *common.TxContext, db sql.DB, tx *transactions.Transaction, res *txapp.TxResponse) error
 	UpdateValidator(ctx context.Context, db sql.DB, validator []byte, power int64) error
 	Price(ctx context.Context, db sql.DB, tx *transactions.Transaction, chainCtx *common.ChainContext) (*big.Int, error)
 }
@@ -97,3 +100,13 @@ type MigratorModule interface {
 	PersistLastChangesetHeight(ctx context.Context, tx sql.Executor) error
 	GetMigrationMetadata(ctx context.Context, status types.MigrationStatus) (*types.MigrationMetadata, error)
 }
+
+// ChangeFeedModule records the changes committed in each block for change data
+// capture.
+type ChangeFeedModule interface {
+	// StoreChangesets stores a block's changesets, which are not yet
+	// committed. It must drain the changes channel.
+	StoreChangesets(height int64, changes <-chan any) error
+	// Commit is called after the block's changes are committed.
+	Commit(height int64) error
+}
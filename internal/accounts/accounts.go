@@ -165,3 +165,27 @@ func Transfer(ctx context.Context, db sql.TxMaker, from, to []byte, amt *big.Int
 
 	return tx.Commit(ctx)
 }
+
+// Burn removes an amount from an account's balance, such as when the tokens
+// are withdrawn from the network. If the account does not have enough funds,
+// it will fail. The amount may not be negative.
+func Burn(ctx context.Context, tx sql.Executor, account []byte, amt *big.Int) error {
+	if amt.Sign() < 0 {
+		return ErrNegativeTransfer
+	}
+
+	acct, err := getAccount(ctx, tx, account)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return errInsufficientFunds(account, amt, big.NewInt(0))
+		}
+		return err
+	}
+
+	newBal := new(big.Int).Sub(acct.Balance, amt)
+	if newBal.Sign() < 0 {
+		return errInsufficientFunds(account, amt, acct.Balance)
+	}
+
+	return updateAccount(ctx, tx, account, newBal, acct.Nonce)
+}
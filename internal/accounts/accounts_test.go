@@ -218,6 +218,31 @@ var acctsTestCases = []acctsTestCase{
 			require.ErrorIs(t, err, ErrInsufficientFunds)
 		},
 	},
+	{
+		name: "burn",
+		fn: func(t *testing.T, db sql.DB) {
+			ctx := context.Background()
+
+			err := Burn(ctx, db, account1, big.NewInt(10))
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+
+			err = Credit(ctx, db, account1, big.NewInt(100))
+			require.NoError(t, err)
+
+			err = Burn(ctx, db, account1, big.NewInt(150))
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+
+			err = Burn(ctx, db, account1, big.NewInt(-50))
+			require.ErrorIs(t, err, ErrNegativeTransfer)
+
+			err = Burn(ctx, db, account1, big.NewInt(60))
+			require.NoError(t, err)
+
+			acc, err := GetAccount(ctx, db, account1)
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(40), acc.Balance)
+		},
+	},
 	{
 		name: "get non existent account",
 		fn: func(t *testing.T, db sql.DB) {
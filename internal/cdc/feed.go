@@ -0,0 +1,419 @@
+// Package cdc implements a change data capture feed of the dataset row changes
+// committed in each block. The changes are kept in a per-block log on disk,
+// which may be read by height (e.g. by the user.changes RPC), and are delivered
+// to any configured sinks after each block is committed.
+package cdc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/internal/sql/pg"
+)
+
+// ErrPruned is returned when changes are requested for blocks that are no
+// longer (or were never) in the log.
+var ErrPruned = errors.New("changes are not available")
+
+const (
+	blocksDirName = "blocks"
+	sinksDirName  = "sinks"
+	metaFileName  = "feed.json"
+
+	blockFileExt   = ".ndjson"
+	pendingFileExt = ".tmp"
+)
+
+// feedMeta is the persisted range of committed heights in the log.
+type feedMeta struct {
+	// First is the lowest height for which changes may be read.
+	First int64 `json:"first"`
+	// Last is the last committed height.
+	Last int64 `json:"last"`
+}
+
+// Feed is the change data capture log. Changes for a block are written by
+// StoreChangesets during block execution, and become readable when the block
+// is committed with Commit.
+type Feed struct {
+	dir    string
+	retain int64
+	log    log.Logger
+
+	mtx     sync.RWMutex
+	meta    feedMeta
+	heights []int64 // committed heights with any changes, ascending
+	failed  int64   // height for which StoreChangesets failed
+
+	sinks []*sinkRunner
+}
+
+// NewFeed opens or creates a change log in dir. If retainBlocks is positive,
+// changes for blocks older than that many blocks are pruned. The sinks are
+// delivered all committed changes, starting from where each left off, until
+// Close is called.
+func NewFeed(dir string, retainBlocks int64, sinks []Sink, logger log.Logger) (*Feed, error) {
+	if err := os.MkdirAll(filepath.Join(dir, blocksDirName), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, sinksDirName), 0755); err != nil {
+		return nil, err
+	}
+
+	f := &Feed{
+		dir:    dir,
+		retain: retainBlocks,
+		log:    logger,
+	}
+
+	bts, err := os.ReadFile(filepath.Join(dir, metaFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(bts, &f.meta); err != nil {
+			return nil, fmt.Errorf("invalid change feed metadata: %w", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, blocksDirName))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, pendingFileExt) { // never committed
+			_ = os.Remove(filepath.Join(dir, blocksDirName, name))
+			continue
+		}
+		height, err := strconv.ParseInt(strings.TrimSuffix(name, blockFileExt), 10, 64)
+		if err != nil || !strings.HasSuffix(name, blockFileExt) {
+			continue
+		}
+		if height > f.meta.Last { // renamed, but metadata not saved
+			_ = os.Remove(f.blockFile(height))
+			continue
+		}
+		f.heights = append(f.heights, height)
+	}
+	slices.Sort(f.heights)
+
+	for _, sink := range sinks {
+		f.sinks = append(f.sinks, newSinkRunner(f, sink, *logger.Named("sink-" + sink.Name())))
+	}
+	for _, s := range f.sinks {
+		go s.run()
+	}
+
+	return f, nil
+}
+
+// Close stops delivering changes to the sinks.
+func (f *Feed) Close() error {
+	for _, s := range f.sinks {
+		s.stop()
+	}
+	return nil
+}
+
+func (f *Feed) blockFile(height int64) string {
+	return filepath.Join(f.dir, blocksDirName, fmt.Sprintf("%020d%s", height, blockFileExt))
+}
+
+func (f *Feed) pendingFile(height int64) string {
+	return f.blockFile(height) + pendingFileExt
+}
+
+// StoreChangesets decodes the changesets of a block from the changes channel
+// and writes them to the log. They are not readable until Commit is called
+// for the height. The channel is always drained, even if there is an error.
+func (f *Feed) StoreChangesets(height int64, changes <-chan any) error {
+	var relations []*pg.Relation
+	var rows []*types.RowChange
+	var err error
+	for ch := range changes {
+		if err != nil {
+			continue // keep draining so the broadcaster is not blocked
+		}
+		switch ct := ch.(type) {
+		case *pg.Relation:
+			relations = append(relations, ct)
+		case *pg.ChangesetEntry:
+			var row *types.RowChange
+			row, err = rowChange(height, relations, ct)
+			if row != nil {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if err == nil {
+		err = f.writePending(height, rows)
+	}
+	if err != nil {
+		f.mtx.Lock()
+		f.failed = height
+		f.mtx.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// rowChange converts a changeset entry to a RowChange. Changes to tables that
+// are not in a dataset schema are ignored.
+func rowChange(height int64, relations []*pg.Relation, ce *pg.ChangesetEntry) (*types.RowChange, error) {
+	if int(ce.RelationIdx) >= len(relations) {
+		return nil, fmt.Errorf("changeset entry references unknown relation %d", ce.RelationIdx)
+	}
+	rel := relations[ce.RelationIdx]
+
+	dbid, ok := strings.CutPrefix(rel.Schema, pg.DefaultSchemaFilterPrefix)
+	if !ok {
+		return nil, nil
+	}
+
+	oldVals, newVals, err := ce.DecodeTuples(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &types.RowChange{
+		Height: height,
+		DBID:   dbid,
+		Table:  rel.Table,
+		Kind:   ce.Kind(),
+	}
+	if oldVals != nil {
+		row.Old = make(map[string]any, len(oldVals))
+		for i, val := range oldVals {
+			row.Old[rel.Columns[i].Name] = val
+		}
+	}
+	if newVals != nil {
+		row.New = make(map[string]any, len(newVals))
+		for i, val := range newVals {
+			if pg.IsUnchanged(val) && i < len(oldVals) {
+				val = oldVals[i]
+			}
+			row.New[rel.Columns[i].Name] = val
+		}
+	}
+
+	return row, nil
+}
+
+// writePending writes the changes for a block to its pending file.
+func (f *Feed) writePending(height int64, rows []*types.RowChange) error {
+	path := f.pendingFile(height)
+	if len(rows) == 0 {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		if err = enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// Commit makes the changes stored for a block readable, prunes old blocks, and
+// notifies the sinks. It must be called after the block's changes are
+// committed to the database.
+func (f *Feed) Commit(height int64) error {
+	f.mtx.Lock()
+	defer f.notifySinks()
+	defer f.mtx.Unlock()
+
+	pending := f.pendingFile(height)
+	failed := f.failed == height
+	f.failed = 0
+	if failed {
+		_ = os.Remove(pending) // possibly incomplete
+	}
+
+	_, err := os.Stat(pending)
+	hasChanges := err == nil
+	if hasChanges {
+		if err = os.Rename(pending, f.blockFile(height)); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case failed:
+		// Changes for this block are missing, so the log cannot be read
+		// across it. Start over from the next block.
+		f.log.Warnf("Change feed is missing changes for block %d. Earlier changes are no longer available.", height)
+		f.pruneBefore(height + 1)
+		f.meta.First = height + 1
+	case f.meta.Last == 0:
+		f.meta.First = height
+	case height > f.meta.Last+1:
+		f.log.Warnf("Change feed resuming at block %d after last block %d. Earlier changes are no longer available.",
+			height, f.meta.Last)
+		f.pruneBefore(height)
+		f.meta.First = height
+	}
+
+	if hasChanges {
+		if idx, found := slices.BinarySearch(f.heights, height); !found {
+			f.heights = slices.Insert(f.heights, idx, height)
+		}
+	}
+	f.meta.Last = max(f.meta.Last, height)
+
+	if f.retain > 0 && height-f.retain+1 > f.meta.First {
+		f.meta.First = height - f.retain + 1
+		f.pruneBefore(f.meta.First)
+	}
+
+	return f.saveMeta()
+}
+
+// pruneBefore deletes the block files for heights less than height. The mutex
+// must be locked.
+func (f *Feed) pruneBefore(height int64) {
+	idx, _ := slices.BinarySearch(f.heights, height)
+	for _, h := range f.heights[:idx] {
+		if err := os.Remove(f.blockFile(h)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			f.log.Warnf("Failed to prune changes for block %d: %v", h, err)
+		}
+	}
+	f.heights = slices.Delete(f.heights, 0, idx)
+}
+
+// saveMeta atomically writes the feed metadata. The mutex must be locked.
+func (f *Feed) saveMeta() error {
+	bts, err := json.Marshal(f.meta)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(f.dir, metaFileName)
+	if err = os.WriteFile(path+pendingFileExt, bts, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+pendingFileExt, path)
+}
+
+func (f *Feed) notifySinks() {
+	for _, s := range f.sinks {
+		s.notify()
+	}
+}
+
+// Range returns the lowest height for which changes may be read, and the last
+// committed height. Both are zero if no block has been committed.
+func (f *Feed) Range() (first, last int64) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.meta.First, f.meta.Last
+}
+
+// Changes reads the committed changes to a dataset, optionally to a single
+// table, from blocks starting at fromHeight. Changes are returned for whole
+// blocks, so a response ends before the block that would take it over limit
+// changes, except that the first block with changes is always returned. At
+// most maxBlocks blocks with changes are read. The returned height is the
+// height from which to read the next changes. ErrPruned is returned if
+// fromHeight is before the first height in the log. A limit or maxBlocks of
+// zero is unlimited.
+func (f *Feed) Changes(dbid, table string, fromHeight int64, limit, maxBlocks int) ([]*types.RowChange, int64, error) {
+	f.mtx.RLock()
+	first, last := f.meta.First, f.meta.Last
+	idx, _ := slices.BinarySearch(f.heights, fromHeight)
+	heights := slices.Clone(f.heights[idx:])
+	f.mtx.RUnlock()
+
+	if last == 0 || fromHeight < first {
+		return nil, 0, fmt.Errorf("%w before height %d", ErrPruned, first)
+	}
+
+	var changes []*types.RowChange
+	for i, height := range heights {
+		if maxBlocks > 0 && i == maxBlocks {
+			return changes, height, nil
+		}
+
+		rows, err := f.readBlock(height)
+		if errors.Is(err, os.ErrNotExist) { // pruned since the heights were copied
+			return nil, 0, fmt.Errorf("%w for height %d", ErrPruned, height)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var blockChanges []*types.RowChange
+		for _, row := range rows {
+			if row.DBID == dbid && (table == "" || strings.EqualFold(row.Table, table)) {
+				blockChanges = append(blockChanges, row)
+			}
+		}
+		if limit > 0 && len(changes) > 0 && len(changes)+len(blockChanges) > limit {
+			return changes, height, nil
+		}
+		changes = append(changes, blockChanges...)
+		if limit > 0 && len(changes) >= limit {
+			return changes, height + 1, nil
+		}
+	}
+
+	return changes, max(fromHeight, last+1), nil
+}
+
+// readBlock reads all of the changes committed in a block.
+func (f *Feed) readBlock(height int64) ([]*types.RowChange, error) {
+	file, err := os.Open(f.blockFile(height))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []*types.RowChange
+	dec := json.NewDecoder(bufio.NewReader(file))
+	for dec.More() {
+		var row types.RowChange
+		if err = dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("invalid changes for block %d: %w", height, err)
+		}
+		rows = append(rows, &row)
+	}
+
+	return rows, nil
+}
+
+// committedBlocks returns the committed heights with changes in (after, last],
+// along with the range of the log.
+func (f *Feed) committedBlocks(after int64) (heights []int64, first, last int64) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	idx, _ := slices.BinarySearch(f.heights, after+1)
+	return slices.Clone(f.heights[idx:]), f.meta.First, f.meta.Last
+}
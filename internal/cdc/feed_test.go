@@ -0,0 +1,231 @@
+package cdc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/internal/sql/pg"
+)
+
+// storeBlock stores and commits the changes for a block.
+func storeBlock(t *testing.T, f *Feed, height int64, rows ...*types.RowChange) {
+	t.Helper()
+	for _, row := range rows {
+		row.Height = height
+	}
+	require.NoError(t, f.writePending(height, rows))
+	require.NoError(t, f.Commit(height))
+}
+
+func insert(dbid, table string, id int64) *types.RowChange {
+	return &types.RowChange{
+		DBID:  dbid,
+		Table: table,
+		Kind:  "insert",
+		New:   map[string]any{"id": id},
+	}
+}
+
+func Test_FeedChanges(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewFeed(dir, 0, nil, log.NewNoOp())
+	require.NoError(t, err)
+
+	storeBlock(t, f, 5, insert("db1", "users", 1), insert("db2", "users", 2))
+	storeBlock(t, f, 6)
+	storeBlock(t, f, 7, insert("db1", "posts", 3), insert("db1", "users", 4))
+	storeBlock(t, f, 8, insert("db1", "users", 5))
+
+	first, last := f.Range()
+	require.Equal(t, int64(5), first)
+	require.Equal(t, int64(8), last)
+
+	changes, next, err := f.Changes("db1", "", 5, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	require.Equal(t, int64(9), next)
+	require.Equal(t, &types.RowChange{
+		Height: 5,
+		DBID:   "db1",
+		Table:  "users",
+		Kind:   "insert",
+		New:    map[string]any{"id": int64(1)}, // not float64
+	}, changes[0])
+
+	changes, next, err = f.Changes("db1", "USERS", 6, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, int64(7), changes[0].Height)
+	require.Equal(t, int64(8), changes[1].Height)
+	require.Equal(t, int64(9), next)
+
+	// the response ends before a block that would exceed the limit
+	changes, next, err = f.Changes("db1", "", 5, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(7), next)
+
+	// but the first block is returned whole
+	changes, next, err = f.Changes("db1", "", 7, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, int64(8), next)
+
+	// scanning stops after the maximum blocks
+	changes, next, err = f.Changes("db1", "", 5, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(7), next)
+
+	// nothing new yet
+	changes, next, err = f.Changes("db1", "", 9, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, int64(9), next)
+
+	_, _, err = f.Changes("db1", "", 4, 0, 0)
+	require.ErrorIs(t, err, ErrPruned)
+
+	// reopen, with an uncommitted block that must be discarded
+	require.NoError(t, f.writePending(9, []*types.RowChange{insert("db1", "users", 6)}))
+	require.NoError(t, f.Close())
+
+	f, err = NewFeed(dir, 2, nil, log.NewNoOp())
+	require.NoError(t, err)
+	defer f.Close()
+
+	changes, _, err = f.Changes("db1", "", 5, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+
+	// retention prunes all but the last two blocks
+	storeBlock(t, f, 9)
+	first, last = f.Range()
+	require.Equal(t, int64(8), first)
+	require.Equal(t, int64(9), last)
+
+	_, _, err = f.Changes("db1", "", 7, 0, 0)
+	require.ErrorIs(t, err, ErrPruned)
+
+	changes, _, err = f.Changes("db1", "", 8, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, int64(8), changes[0].Height)
+
+	// a gap in the committed blocks restarts the log
+	storeBlock(t, f, 12, insert("db1", "users", 7))
+	first, _ = f.Range()
+	require.Equal(t, int64(12), first)
+}
+
+func Test_FeedStoreFailure(t *testing.T) {
+	f, err := NewFeed(t.TempDir(), 0, nil, log.NewNoOp())
+	require.NoError(t, err)
+	defer f.Close()
+
+	storeBlock(t, f, 1, insert("db1", "users", 1))
+
+	// A changeset entry for an unknown relation fails, but the channel must
+	// still be drained.
+	ch := make(chan any)
+	go func() {
+		ch <- &pg.ChangesetEntry{RelationIdx: 3}
+		ch <- &pg.Relation{Schema: "ds_db1", Table: "users"}
+		close(ch)
+	}()
+	require.Error(t, f.StoreChangesets(2, ch))
+	require.NoError(t, f.Commit(2))
+
+	// changes before the failed block are no longer available
+	_, _, err = f.Changes("db1", "", 1, 0, 0)
+	require.ErrorIs(t, err, ErrPruned)
+
+	changes, next, err := f.Changes("db1", "", 3, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, int64(3), next)
+}
+
+func Test_rowChange(t *testing.T) {
+	relations := []*pg.Relation{
+		{Schema: "kwild_internal", Table: "kv"},
+		{Schema: "ds_abc", Table: "users", Columns: []*pg.Column{
+			{Name: "id", Type: types.IntType},
+			{Name: "name", Type: types.TextType},
+		}},
+	}
+
+	row, err := rowChange(4, relations, &pg.ChangesetEntry{RelationIdx: 0})
+	require.NoError(t, err)
+	require.Nil(t, row)
+
+	row, err = rowChange(4, relations, &pg.ChangesetEntry{
+		RelationIdx: 1,
+		OldTuple:    []*pg.TupleColumn{{ValueType: pg.NullValue}, {ValueType: pg.NullValue}},
+		NewTuple:    []*pg.TupleColumn{{ValueType: pg.UnchangedUpdate}, {ValueType: pg.NullValue}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &types.RowChange{
+		Height: 4,
+		DBID:   "abc",
+		Table:  "users",
+		Kind:   "update",
+		Old:    map[string]any{"id": nil, "name": nil},
+		New:    map[string]any{"id": nil, "name": nil},
+	}, row)
+}
+
+type memSink struct {
+	mtx     sync.Mutex
+	heights []int64
+}
+
+func (s *memSink) Name() string { return "mem" }
+
+func (s *memSink) Deliver(_ context.Context, height int64, _ []*types.RowChange) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.heights = append(s.heights, height)
+	return nil
+}
+
+func (s *memSink) delivered() []int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]int64{}, s.heights...)
+}
+
+func Test_FeedSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := &memSink{}
+	f, err := NewFeed(dir, 0, []Sink{sink}, log.NewNoOp())
+	require.NoError(t, err)
+
+	storeBlock(t, f, 1, insert("db1", "users", 1))
+	storeBlock(t, f, 2)
+	storeBlock(t, f, 3, insert("db1", "users", 2))
+
+	require.Eventually(t, func() bool {
+		return len(sink.delivered()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{1, 3}, sink.delivered())
+	require.NoError(t, f.Close())
+
+	// a restarted sink resumes after the last delivered block
+	sink2 := &memSink{}
+	f, err = NewFeed(dir, 0, []Sink{sink2}, log.NewNoOp())
+	require.NoError(t, err)
+	defer f.Close()
+
+	storeBlock(t, f, 4, insert("db1", "users", 3))
+	require.Eventually(t, func() bool {
+		return len(sink2.delivered()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{4}, sink2.delivered())
+}
@@ -0,0 +1,262 @@
+package cdc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+)
+
+// sinkRetryInterval is how long to wait before retrying a failed delivery.
+const sinkRetryInterval = 5 * time.Second
+
+// Sink receives the changes committed in each block. Delivery is at least
+// once: after a failure or an unclean shutdown, the changes for a block may be
+// delivered again.
+type Sink interface {
+	// Name uniquely identifies the sink. It is used to persist the last
+	// delivered height, so it must not change between restarts.
+	Name() string
+	// Deliver sends the changes committed in a block. It is only called for
+	// blocks with changes, in order of height.
+	Deliver(ctx context.Context, height int64, changes []*types.RowChange) error
+}
+
+// sinkRunner delivers the committed changes in the log to a sink, persisting
+// the last delivered height.
+type sinkRunner struct {
+	feed *Feed
+	sink Sink
+	log  log.Logger
+	file string // last delivered height
+
+	notifyChan chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func newSinkRunner(feed *Feed, sink Sink, logger log.Logger) *sinkRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sinkRunner{
+		feed:       feed,
+		sink:       sink,
+		log:        logger,
+		file:       filepath.Join(feed.dir, sinksDirName, sink.Name()),
+		notifyChan: make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// notify signals that another block was committed. It does not block.
+func (s *sinkRunner) notify() {
+	select {
+	case s.notifyChan <- struct{}{}:
+	default:
+	}
+}
+
+func (s *sinkRunner) stop() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *sinkRunner) run() {
+	defer close(s.done)
+
+	delivered, err := s.loadHeight()
+	if err != nil {
+		s.log.Errorf("Not delivering changes to sink: %v", err)
+		return
+	}
+
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.notifyChan:
+		case <-retry.C:
+		}
+
+		delivered, err = s.catchUp(delivered)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.log.Warnf("Failed to deliver changes, retrying in %v: %v", sinkRetryInterval, err)
+			retry.Reset(sinkRetryInterval)
+		}
+	}
+}
+
+// catchUp delivers the changes for committed blocks after height delivered,
+// returning the new delivered height.
+func (s *sinkRunner) catchUp(delivered int64) (int64, error) {
+	heights, first, last := s.feed.committedBlocks(delivered)
+	if last == 0 || delivered >= last {
+		return delivered, nil
+	}
+	if delivered > 0 && delivered < first-1 {
+		s.log.Warnf("Changes for blocks %d to %d were pruned before delivery", delivered+1, first-1)
+	}
+
+	for _, height := range heights {
+		changes, err := s.feed.readBlock(height)
+		if err != nil {
+			return delivered, err
+		}
+		if err = s.sink.Deliver(s.ctx, height, changes); err != nil {
+			return delivered, err
+		}
+		delivered = height
+		if err = s.saveHeight(delivered); err != nil {
+			return delivered, err
+		}
+	}
+
+	if delivered < last {
+		delivered = last
+		if err := s.saveHeight(delivered); err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s *sinkRunner) loadHeight() (int64, error) {
+	bts, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(bts)), 10, 64)
+}
+
+func (s *sinkRunner) saveHeight(height int64) error {
+	return os.WriteFile(s.file, []byte(strconv.FormatInt(height, 10)), 0644)
+}
+
+// NDJSONSink appends changes to a file as newline-delimited JSON, with one
+// line per row change.
+type NDJSONSink struct {
+	path string
+	mtx  sync.Mutex
+}
+
+var _ Sink = (*NDJSONSink)(nil)
+
+// NewNDJSONSink creates a sink that appends changes to the file at path,
+// creating it if it does not exist.
+func NewNDJSONSink(path string) *NDJSONSink {
+	return &NDJSONSink{path: path}
+}
+
+func (s *NDJSONSink) Name() string {
+	return "ndjson"
+}
+
+func (s *NDJSONSink) Deliver(_ context.Context, _ int64, changes []*types.RowChange) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, change := range changes {
+		if err = enc.Encode(change); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// WebhookSink POSTs the changes for each block to a URL as a JSON object with
+// "height" and "changes" fields. Any response status other than 2xx is a
+// failed delivery, which is retried.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+var _ Sink = (*WebhookSink)(nil)
+
+// NewWebhookSink creates a sink that POSTs changes to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name is derived from the URL so that each webhook's progress is tracked
+// separately.
+func (s *WebhookSink) Name() string {
+	h := sha256.Sum256([]byte(s.url))
+	return "webhook-" + hex.EncodeToString(h[:8])
+}
+
+type webhookPayload struct {
+	Height  int64              `json:"height"`
+	Changes []*types.RowChange `json:"changes"`
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, height int64, changes []*types.RowChange) error {
+	body, err := json.Marshal(&webhookPayload{
+		Height:  height,
+		Changes: changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/internal/engine/generate"
+	"github.com/kwilteam/kwil-db/internal/sql/pg"
+	"github.com/kwilteam/kwil-db/parse"
+)
+
+// DeclareCursor declares a PostgreSQL cursor for an ad-hoc, read-only SQL
+// query. Rows are then fetched from the cursor with FetchCursor, which must use
+// the same transaction. The cursor is closed when the transaction ends. The
+// name must be a valid, unquoted PostgreSQL identifier chosen by the caller.
+func (g *GlobalContext) DeclareCursor(ctx *common.TxContext, tx sql.DB, dbid, query, name string) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	dataset, ok := g.datasets[dbid]
+	if !ok {
+		return ErrDatasetNotFound
+	}
+
+	res, err := parse.ParseSQL(query, dataset.schema, false)
+	if err != nil {
+		return err
+	}
+
+	if res.ParseErrs.Err() != nil {
+		return res.ParseErrs.Err()
+	}
+
+	if res.Mutative {
+		return errors.New("cannot declare a cursor for a mutative query")
+	}
+
+	sqlStmt, params, err := generate.WriteSQL(res.AST, true, dbidSchema(dbid))
+	if err != nil {
+		return err
+	}
+
+	// Ad-hoc queries have no bound values, so any parameter would be null.
+	// Rather than bind nulls to a utility statement, reject it.
+	if len(params) > 0 {
+		return fmt.Errorf("cursor query may not reference variables: %v", params)
+	}
+
+	// all execution data is empty, but things like @caller can still be used
+	err = setContextualVars(ctx, tx, &common.ExecutionData{})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Execute(ctx.Ctx, fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", name, sqlStmt), pg.QueryModeExec)
+	if err != nil {
+		return decorateExecuteErr(err, query)
+	}
+
+	return nil
+}
+
+// FetchCursor fetches up to limit rows from a cursor declared with
+// DeclareCursor. Fewer than limit rows are returned once the cursor is
+// exhausted.
+func (g *GlobalContext) FetchCursor(ctx *common.TxContext, tx sql.DB, name string, limit int64) (*sql.ResultSet, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid fetch limit %d", limit)
+	}
+
+	return tx.Execute(ctx.Ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", limit, name), pg.QueryModeExec)
+}
@@ -465,6 +465,11 @@ func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.Re
 		}, nil
 	case sqlDeleteKwilSchema:
 		delete(m.dbs, args[0].(string))
+	case sqlGetUsedGas:
+		return &sql.ResultSet{
+			Columns: []string{"current_setting"},
+			Rows:    [][]any{{"0"}},
+		}, nil
 	default:
 		m.executedStmts = append(m.executedStmts, stmt)
 
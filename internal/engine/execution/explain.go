@@ -0,0 +1,226 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/internal/engine/generate"
+	"github.com/kwilteam/kwil-db/internal/sql/pg"
+	"github.com/kwilteam/kwil-db/parse"
+	"github.com/kwilteam/kwil-db/parse/planner/logical"
+)
+
+// Explain returns the Kwil logical plan and the PostgreSQL query plan for an
+// ad-hoc SQL statement. The statement is not executed, so the tx may be
+// read-only even if the statement is mutative.
+func (g *GlobalContext) Explain(ctx *common.TxContext, tx sql.DB, dbid, query string) (*types.StatementPlan, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	dataset, ok := g.datasets[dbid]
+	if !ok {
+		return nil, ErrDatasetNotFound
+	}
+
+	res, err := parse.ParseSQL(query, dataset.schema, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ParseErrs.Err() != nil {
+		return nil, res.ParseErrs.Err()
+	}
+
+	return explainStatement(ctx, tx, dbid, dataset.schema, res.AST, nil)
+}
+
+// ExplainProcedure returns the plans for each SQL statement in a procedure, in
+// the order they appear in the procedure body. Procedure variables are left as
+// parameters, so PostgreSQL creates a generic plan for each statement. A
+// statement that cannot be planned has the Error field of its plan set.
+func (g *GlobalContext) ExplainProcedure(ctx *common.TxContext, tx sql.DB, dbid, procedure string) ([]*types.StatementPlan, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	dataset, ok := g.datasets[dbid]
+	if !ok {
+		return nil, ErrDatasetNotFound
+	}
+
+	proc, ok := dataset.schema.FindProcedure(procedure)
+	if !ok {
+		return nil, fmt.Errorf(`procedure "%s" not found`, procedure)
+	}
+
+	res, err := parse.ParseProcedure(proc, dataset.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ParseErrs.Err() != nil {
+		return nil, res.ParseErrs.Err()
+	}
+
+	var plans []*types.StatementPlan
+	for _, stmt := range procedureSQLStatements(res.AST) {
+		plan, err := explainStatement(ctx, tx, dbid, dataset.schema, stmt, res.Variables)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+// explainStatement creates the logical plan and PostgreSQL plan for a parsed
+// statement. Errors from either planner are recorded in the returned plan,
+// since they are a result of the user's query rather than a failure of the
+// node. Only errors in SQL generation are returned.
+func explainStatement(ctx *common.TxContext, tx sql.DB, dbid string, schema *types.Schema, stmt *parse.SQLStatement,
+	vars map[string]*types.DataType) (*types.StatementPlan, error) {
+	sqlStmt, params, err := generate.WriteSQL(stmt, true, dbidSchema(dbid))
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &types.StatementPlan{
+		SQL:    sqlStmt,
+		Params: params,
+	}
+
+	var errs []string
+
+	allVars := make(map[string]*types.DataType, len(vars)+len(parse.SessionVars))
+	for name, dt := range parse.SessionVars {
+		allVars["@"+name] = dt
+	}
+	for name, dt := range vars {
+		allVars[name] = dt
+	}
+
+	analyzed, err := logical.CreateLogicalPlan(stmt, schema, allVars, nil)
+	if err != nil {
+		errs = append(errs, "logical plan: "+err.Error())
+	} else {
+		plan.LogicalPlan = analyzed.Format()
+	}
+
+	// parameters are typed from the procedure variables, and inferred by
+	// PostgreSQL if their type is not known
+	paramTypes := make([]string, len(params))
+	for i, param := range params {
+		paramTypes[i] = "unknown"
+		if dt, ok := allVars[param]; ok {
+			if pgType, err := dt.PGString(); err == nil {
+				paramTypes[i] = pgType
+			}
+		}
+	}
+
+	pgPlan, err := explainPostgres(ctx, tx, sqlStmt, paramTypes)
+	if err != nil {
+		errs = append(errs, "postgres plan: "+err.Error())
+	} else {
+		plan.PostgresPlan = pgPlan
+	}
+
+	plan.Error = strings.Join(errs, "; ")
+
+	return plan, nil
+}
+
+// explainStmtSeq numbers the prepared statements used to explain statements
+// with parameters. Each call prepares a uniquely named statement, so that a
+// statement that failed to be deallocated cannot break later calls on the
+// same connection.
+var explainStmtSeq atomic.Uint64
+
+// explainPostgres returns the JSON PostgreSQL plan for a statement with
+// parameters of the given types. A statement with parameters is prepared, and
+// its generic plan is explained by executing it with NULL arguments, since
+// EXPLAIN cannot be given unbound parameters. The statement is explained in a
+// nested transaction so that a failure does not abort the caller's
+// transaction, which may be used to explain subsequent statements.
+func explainPostgres(ctx *common.TxContext, tx sql.DB, stmt string, paramTypes []string) (json.RawMessage, error) {
+	tx2, err := tx.BeginTx(ctx.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx2.Rollback(ctx.Ctx)
+
+	if len(paramTypes) == 0 {
+		return explainJSON(ctx, tx2, "EXPLAIN (FORMAT JSON) "+stmt)
+	}
+
+	// The extended protocol is used since the simple protocol requires
+	// arguments for the numbered parameters.
+	stmtName := fmt.Sprintf("kwil_explain_%d", explainStmtSeq.Add(1))
+	_, err = tx2.Execute(ctx.Ctx, fmt.Sprintf("PREPARE %s (%s) AS %s", stmtName,
+		strings.Join(paramTypes, ", "), stmt), pg.QueryModeExec)
+	if err != nil {
+		return nil, err
+	}
+	// A prepared statement is not transactional, so it is deallocated in the
+	// caller's transaction once the nested transaction is rolled back. The
+	// request context may already be cancelled, so it is not used.
+	defer func() {
+		tx2.Rollback(context.Background())
+		tx.Execute(context.Background(), "DEALLOCATE "+stmtName, pg.QueryModeExec)
+	}()
+
+	// Without a generic plan, the plan would be for NULL arguments.
+	_, err = tx2.Execute(ctx.Ctx, "SET LOCAL plan_cache_mode = force_generic_plan", pg.QueryModeExec)
+	if err != nil {
+		return nil, err
+	}
+
+	nulls := strings.TrimSuffix(strings.Repeat("NULL, ", len(paramTypes)), ", ")
+	return explainJSON(ctx, tx2, fmt.Sprintf("EXPLAIN (FORMAT JSON) EXECUTE %s (%s)", stmtName, nulls))
+}
+
+// explainJSON runs an EXPLAIN statement and returns the JSON plan.
+func explainJSON(ctx *common.TxContext, tx sql.Executor, explainStmt string) (json.RawMessage, error) {
+	result, err := tx.Execute(ctx.Ctx, explainStmt, pg.QueryModeExec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Rows) != 1 || len(result.Rows[0]) != 1 {
+		return nil, fmt.Errorf("unexpected EXPLAIN result shape")
+	}
+
+	return json.Marshal(result.Rows[0][0])
+}
+
+// procedureSQLStatements returns all SQL statements in a procedure body,
+// including those in loops and conditionals, in the order they appear.
+func procedureSQLStatements(stmts []parse.ProcedureStmt) []*parse.SQLStatement {
+	var res []*parse.SQLStatement
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *parse.ProcedureStmtSQL:
+			res = append(res, s.SQL)
+		case *parse.ProcedureStmtReturn:
+			if s.SQL != nil {
+				res = append(res, s.SQL)
+			}
+		case *parse.ProcedureStmtForLoop:
+			if loopSQL, ok := s.LoopTerm.(*parse.LoopTermSQL); ok {
+				res = append(res, loopSQL.Statement)
+			}
+			res = append(res, procedureSQLStatements(s.Body)...)
+		case *parse.ProcedureStmtIf:
+			for _, ifThen := range s.IfThens {
+				res = append(res, procedureSQLStatements(ifThen.Then)...)
+			}
+			res = append(res, procedureSQLStatements(s.Else)...)
+		}
+	}
+
+	return res
+}
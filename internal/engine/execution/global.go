@@ -282,6 +282,12 @@ func (g *GlobalContext) Procedure(ctx *common.TxContext, tx sql.DB, options *com
 		return nil, err
 	}
 
+	sqlGas, err := sqlUsedGas(ctx.Ctx, tx2)
+	if err != nil {
+		return nil, err
+	}
+	options.UsedGas = procedureCtx.UsedGas + sqlGas
+
 	return procedureCtx.Result, tx2.Commit(ctx.Ctx)
 }
 
@@ -252,7 +252,7 @@ func (e *callMethod) execute(scope *precompiles.ProcedureContext, global *Global
 	var err error
 
 	scope.UsedGas += 10
-	if scope.UsedGas >= 10000000 {
+	if scope.UsedGas >= pg.MaxGas {
 		return fmt.Errorf("out of gas")
 	}
 
@@ -280,6 +280,10 @@ func (e *callMethod) execute(scope *precompiles.ProcedureContext, global *Global
 			DB:      db,
 			Engine:  global,
 		}, e.Method, inputs)
+
+		// gas used by a metered precompile is charged to the caller, but gas
+		// used by local procedure calls is not
+		scope.UsedGas = newScope.UsedGas
 	}
 	if err != nil {
 		return err
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 
 	_ "embed"
 
@@ -35,6 +37,7 @@ var (
 	sqlListSchemaContent = fmt.Sprintf(`SELECT schema_content FROM %s.kwil_schemas;`, pg.InternalSchemaName)
 	sqlDropSchema        = `DROP SCHEMA "%s" CASCADE;`
 	sqlDeleteKwilSchema  = fmt.Sprintf(`DELETE FROM %s.kwil_schemas WHERE dbid = $1;`, pg.InternalSchemaName)
+	sqlGetUsedGas        = fmt.Sprintf(`SELECT current_setting('%s', true);`, pg.GasUsedSetting)
 
 	// v1 upgrades the schema to be:
 	// TABLE kwil_schemas (
@@ -370,5 +373,29 @@ func setContextualVars(ctx *common.TxContext, db sql.DB, data *common.ExecutionD
 		return err
 	}
 
+	// the gas used by built-in functions is counted from zero for each execution
+	_, err = db.Execute(ctx.Ctx, fmt.Sprintf(`SET LOCAL %s = 0;`, pg.GasUsedSetting))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// sqlUsedGas returns the gas used by built-in functions, such as keccak256,
+// since the contextual variables were set.
+func sqlUsedGas(ctx context.Context, db sql.Executor) (uint64, error) {
+	res, err := db.Execute(ctx, sqlGetUsedGas, pg.QueryModeExec)
+	if err != nil {
+		return 0, err
+	}
+	if len(res.Rows) != 1 || len(res.Rows[0]) != 1 {
+		return 0, errors.New("unexpected gas used result shape")
+	}
+
+	used, _ := res.Rows[0][0].(string)
+	if used == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(used, 10, 64)
+}
@@ -0,0 +1,53 @@
+//go:build pglive
+
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ExplainProcedure tests that a Postgres plan is returned for statements
+// that use procedure parameters and variables.
+func Test_ExplainProcedure(t *testing.T) {
+	global, db, err := setup(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup(t, db)
+
+	ctx := context.Background()
+
+	tx, err := db.BeginPreparedTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	dbid := deployAndSeed(t, global, tx, `procedure posts_by_name($name text, $limit int) public view returns table(content text) {
+		$min int := 1;
+		for $row in SELECT u.user_num FROM users u WHERE u.name = $name AND u.user_num >= $min {
+			notice($row.user_num::text);
+		}
+		return SELECT p.content FROM posts p INNER JOIN users u ON p.user_id = u.id
+			WHERE u.name = $name ORDER BY p.id LIMIT $limit;
+	}`)
+
+	plans, err := global.ExplainProcedure(txData(), tx, dbid, "posts_by_name")
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+
+	for _, plan := range plans {
+		assert.Empty(t, plan.Error)
+		assert.NotEmpty(t, plan.Params)
+		assert.NotEmpty(t, plan.PostgresPlan)
+		assert.NotEmpty(t, plan.LogicalPlan)
+	}
+
+	// the transaction is still usable after explaining
+	plans, err = global.ExplainProcedure(txData(), tx, dbid, "posts_by_name")
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Empty(t, plans[0].Error)
+}
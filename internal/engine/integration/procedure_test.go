@@ -36,6 +36,7 @@ func Test_Procedures(t *testing.T) {
 		caller    string   // can be empty, if set it will override the default caller in the transaction data
 		readOnly  bool     // if true, the procedure will be executed in a read-only transaction
 		notices   []string // expected notices, if any
+		gas       uint64   // expected gas used, if not zero
 	}
 
 	tests := []testcase{
@@ -152,6 +153,39 @@ func Test_Procedures(t *testing.T) {
 			inputs:  []any{hex.EncodeToString([]byte("hello"))},
 			outputs: [][]any{{base64.StdEncoding.EncodeToString([]byte("hello")), []byte("hello"), crypto.Sha256([]byte("hello"))}},
 		},
+		{
+			name: "cryptographic functions",
+			procedure: `procedure crypto_funcs($msg blob, $sig blob, $pubkey blob, $ed_pubkey blob, $ed_sig blob) public view returns (hash blob, signer blob, addr blob, ok bool, bad bool) {
+				$hash := keccak256($msg);
+				return $hash, ecrecover($hash, $sig), eth_address($pubkey), ed25519_verify($ed_pubkey, $msg, $ed_sig), ed25519_verify($ed_pubkey, $hash, $ed_sig);
+			}`,
+			inputs: []any{[]byte("kwil"),
+				mustHex("5107a282a48aafe8cec3e5916e70624e4931e877d9fc3cea32f63d2ed75b5e045643febf85c8df0220ce2695b7e3af608bac3aad2a8eb7c979906a63a41428071c"),
+				mustHex("02812bef44f6e7b2a19c0b01c2dca5e54ba1935a1890ffdcb93abd0c534b209c21"),
+				mustHex("3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29"),
+				mustHex("eb4b8397e2b48b5e4b30e15d9a4d8665dc190d4d18a272642cb663cbf5683440813eee401b2c2e085f7b0c21c95228c36391e89eb1e62175be61cecb795c370c"),
+			},
+			outputs: [][]any{{
+				mustHex("9a733fdd001cc1fd86d2c39cd6f2a815768e3ade659a604d9a512fff7acf524e"),
+				mustHex("c89d42189f0450c2b2c3c61f58ec5d628176a1e7"),
+				mustHex("c89d42189f0450c2b2c3c61f58ec5d628176a1e7"),
+				true,
+				false,
+			}},
+			// keccak256 of 4 bytes: 200, ecrecover: 5000, eth_address: 2000,
+			// ed25519_verify of 4 and 32 bytes: 5000 each
+			gas: 17200,
+		},
+		{
+			name: "cryptographic function gas by input size",
+			procedure: `procedure keccak_sizes($short blob, $long blob) public view returns (a blob, b blob) {
+				return keccak256($short), keccak256($long);
+			}`,
+			inputs:  []any{make([]byte, 135), make([]byte, 300)},
+			outputs: [][]any{{mustHex("29e3704feeca7fb9ba229f0fa04d9b36449cf3ad6e1d85d9cfff3a10df9abc3e"), mustHex("347b017cb0632f78c0c51dfedd8e31b8d2c31e5bf282c1e8c370e45ef8b0f7f0")}},
+			// 100 + 100 per 136 byte block: 200 and 400
+			gas: 600,
+		},
 		{
 			name: "join on subquery",
 			procedure: `procedure join_on_subquery() public view returns table(name text, content text) {
@@ -476,11 +510,12 @@ func Test_Procedures(t *testing.T) {
 			}()
 
 			// execute test procedure
-			res, err := global.Procedure(d, execTx, &common.ExecutionData{
+			execData := &common.ExecutionData{
 				Dataset:   dbid,
 				Procedure: procedureName,
 				Args:      test.inputs,
-			})
+			}
+			res, err := global.Procedure(d, execTx, execData)
 			if test.err != nil {
 				require.Error(t, err)
 				require.ErrorIs(t, err, test.err)
@@ -508,6 +543,10 @@ func Test_Procedures(t *testing.T) {
 
 			// check notices
 			require.Equal(t, test.notices, rec)
+
+			if test.gas != 0 {
+				require.Equal(t, test.gas, execData.UsedGas)
+			}
 		})
 	}
 }
@@ -521,6 +560,14 @@ func mustDecimal(val string, precision, scale uint16) *decimal.Decimal {
 	return d
 }
 
+func mustHex(val string) []byte {
+	bts, err := hex.DecodeString(val)
+	if err != nil {
+		panic(err)
+	}
+	return bts
+}
+
 func Test_ForeignProcedures(t *testing.T) {
 	type testcase struct {
 		name string
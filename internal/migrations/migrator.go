@@ -228,9 +228,10 @@ func (m *Migrator) NotifyHeight(ctx context.Context, block *common.BlockContext,
 		genesisVals := make([]*chain.GenesisValidator, len(vals))
 		for i, v := range vals {
 			genesisVals[i] = &chain.GenesisValidator{
-				PubKey: v.PubKey,
-				Power:  v.Power,
-				Name:   fmt.Sprintf("validator-%d", i),
+				PubKey:       v.PubKey,
+				Power:        v.Power,
+				Name:         fmt.Sprintf("validator-%d", i),
+				ConsensusKey: v.ConsensusKey,
 			}
 		}
 
@@ -277,8 +278,9 @@ func (m *Migrator) generateGenesisConfig(ctx context.Context, snapshotHash []byt
 		genVals = append(genVals, &types.NamedValidator{
 			Name: v.Name,
 			Validator: types.Validator{
-				PubKey: v.PubKey,
-				Power:  v.Power,
+				PubKey:       v.PubKey,
+				Power:        v.Power,
+				ConsensusKey: v.ConsensusKey,
 			},
 		})
 	}
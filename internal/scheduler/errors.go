@@ -0,0 +1,8 @@
+package scheduler
+
+import "errors"
+
+var (
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrGasDisabled     = errors.New("scheduled procedures require gas costs to be enabled")
+)
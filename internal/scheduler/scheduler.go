@@ -0,0 +1,283 @@
+// Package scheduler runs procedures that a schema schedules to run
+// automatically, either every N blocks or once at a specific height. A
+// procedure is scheduled with an annotation, such as @schedule(every=100) or
+// @schedule(height=5000). Kuneiform annotations take key=value arguments, so a
+// schedule written as @schedule(every: 100) must be written as
+// @schedule(every=100). Scheduled procedures are executed at the end of the
+// block as the dataset owner, who is charged for the gas each run uses.
+//
+// Schedules are only parsed and run once the scheduled_procedures hard fork is
+// active. Before it, the annotation is ignored like any other. Since runs are
+// paid for with gas, schedules are rejected, and not run, while gas costs are
+// disabled.
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/kwilteam/kwil-db/common"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/extensions/hooks"
+	"github.com/kwilteam/kwil-db/internal/accounts"
+	"github.com/kwilteam/kwil-db/internal/sql/versioning"
+)
+
+const (
+	// scheduleAnnotation is the name of the annotation that schedules a
+	// procedure.
+	scheduleAnnotation = "@schedule"
+
+	schedulerEndBlockHook = "scheduled_procedures"
+)
+
+const (
+	// MaxSchedules is the maximum number of scheduled procedures in a schema.
+	MaxSchedules = 10
+
+	// MaxRunsPerBlock is the maximum number of scheduled procedures run in a
+	// block. Schedules are run oldest first, and the runs of the others are
+	// skipped for the block.
+	MaxRunsPerBlock = 100
+)
+
+// GasPrice is the amount charged to the dataset owner for each unit of gas used
+// by a run of a scheduled procedure.
+var GasPrice = big.NewInt(1000000000000)
+
+// burn is overridden in tests.
+var burn = accounts.Burn
+
+func init() {
+	err := hooks.RegisterEndBlockHook(schedulerEndBlockHook, runSchedules)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Schedule is a procedure that is run automatically.
+type Schedule struct {
+	DBID      string
+	Procedure string
+	// Owner is the signer that deployed the dataset, who is charged for each
+	// run.
+	Owner []byte
+	// Caller and Authenticator identify the owner when running the
+	// procedure, such as for @caller.
+	Caller        string
+	Authenticator string
+	// Every is the interval in blocks between runs. It is zero if the
+	// procedure runs once, at Height.
+	Every int64
+	// Height is the height at which the procedure runs once. It is zero if
+	// the procedure runs every Every blocks.
+	Height int64
+	// StartHeight is the height at which the schedule was created. A
+	// procedure run every N blocks first runs N blocks after it.
+	StartHeight int64
+}
+
+// InitializeSchedulerStore initializes the scheduler store schema and tables.
+func InitializeSchedulerStore(ctx context.Context, db sql.DB) error {
+	upgradeFns := map[int64]versioning.UpgradeFunc{
+		0: initTables,
+	}
+
+	err := versioning.Upgrade(ctx, db, schemaName, upgradeFns, schedulerStoreVersion)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Schedules returns the schedules declared by the @schedule annotations of a
+// schema's procedures. A scheduled procedure may not have parameters, and may
+// only have one schedule. Only the procedure, interval and height of the
+// returned schedules are set.
+func Schedules(schema *types.Schema) ([]*Schedule, error) {
+	var schedules []*Schedule
+	for _, proc := range schema.Procedures {
+		var schedule *Schedule
+		for _, annotation := range proc.Annotations {
+			s, err := parseSchedule(annotation)
+			if err != nil {
+				return nil, fmt.Errorf("procedure %s: %w", proc.Name, err)
+			}
+			if s == nil {
+				continue // not a schedule
+			}
+			if schedule != nil {
+				return nil, fmt.Errorf("%w: procedure %s has more than one schedule", ErrInvalidSchedule, proc.Name)
+			}
+			schedule = s
+		}
+		if schedule == nil {
+			continue
+		}
+
+		if len(proc.Parameters) > 0 {
+			return nil, fmt.Errorf("%w: scheduled procedure %s may not have parameters", ErrInvalidSchedule, proc.Name)
+		}
+
+		schedule.Procedure = proc.Name
+		schedules = append(schedules, schedule)
+	}
+
+	if len(schedules) > MaxSchedules {
+		return nil, fmt.Errorf("%w: a schema may have at most %d scheduled procedures", ErrInvalidSchedule, MaxSchedules)
+	}
+
+	return schedules, nil
+}
+
+// parseSchedule parses a @schedule annotation, which must have either an
+// every or a height argument, such as @schedule(every=100). It returns nil if
+// the annotation is not a schedule.
+func parseSchedule(annotation string) (*Schedule, error) {
+	args, ok := strings.CutPrefix(annotation, scheduleAnnotation+"(")
+	if !ok {
+		return nil, nil
+	}
+	args, ok = strings.CutSuffix(args, ")")
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, annotation)
+	}
+
+	s := &Schedule{}
+	for _, arg := range strings.Split(args, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(arg), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, annotation)
+		}
+
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidSchedule, key)
+		}
+
+		switch key {
+		case "every":
+			s.Every = n
+		case "height":
+			s.Height = n
+		default:
+			return nil, fmt.Errorf("%w: unknown argument %s", ErrInvalidSchedule, key)
+		}
+	}
+
+	if (s.Every == 0) == (s.Height == 0) {
+		return nil, fmt.Errorf("%w: exactly one of every or height must be set", ErrInvalidSchedule)
+	}
+
+	return s, nil
+}
+
+// AddSchedule stores a schedule for a procedure. A procedure scheduled to run
+// once must run after the start height.
+func AddSchedule(ctx context.Context, db sql.Executor, s *Schedule) error {
+	if s.Height != 0 && s.Height <= s.StartHeight {
+		return fmt.Errorf("%w: procedure %s is scheduled at height %d, which is not after %d",
+			ErrInvalidSchedule, s.Procedure, s.Height, s.StartHeight)
+	}
+
+	_, err := db.Execute(ctx, sqlInsertSchedule, s.DBID, s.Procedure, s.Owner, s.Caller, s.Authenticator,
+		s.Every, s.Height, s.StartHeight)
+	return err
+}
+
+// DeleteSchedules deletes the schedules of a dataset, such as when it is
+// dropped.
+func DeleteSchedules(ctx context.Context, db sql.Executor, dbid string) error {
+	_, err := db.Execute(ctx, sqlDeleteSchedules, dbid)
+	return err
+}
+
+// runSchedules is an end block hook that runs the procedures scheduled for the
+// block. The owner of the dataset is charged for the gas used by each run, and
+// the run is reverted if they cannot pay. A procedure that fails is logged, and
+// does not halt the network. Nothing is run while gas costs are disabled.
+func runSchedules(ctx context.Context, app *common.App, block *common.BlockContext) error {
+	if !app.Service.GenesisConfig.Forks().IsActive(consensus.ForkScheduledProcedures, uint64(block.Height)) {
+		return nil
+	}
+	if block.ChainContext.NetworkParameters.DisabledGasCosts {
+		return nil
+	}
+
+	schedules, err := listDueSchedules(ctx, app.DB, block.Height, MaxRunsPerBlock+1)
+	if err != nil {
+		return err
+	}
+	if len(schedules) > MaxRunsPerBlock {
+		app.Service.Logger.Warn("too many scheduled procedures are due, skipping the newest",
+			log.Int("height", block.Height), log.Int("max", MaxRunsPerBlock))
+		schedules = schedules[:MaxRunsPerBlock]
+	}
+
+	for _, s := range schedules {
+		if err := runSchedule(ctx, app, block, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runSchedule runs a scheduled procedure in a nested transaction, which is
+// committed only if the owner can pay for the gas it used. It only returns an
+// error if the block cannot be processed.
+func runSchedule(ctx context.Context, app *common.App, block *common.BlockContext, s *Schedule) error {
+	tx, err := app.DB.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// scheduled runs have no transaction, so a deterministic ID is made
+	// for the run
+	txID := sha256.Sum256([]byte(fmt.Sprintf("%s.%s@%d", s.DBID, s.Procedure, block.Height)))
+
+	exec := &common.ExecutionData{
+		Dataset:   s.DBID,
+		Procedure: s.Procedure,
+	}
+	_, err = app.Engine.Procedure(&common.TxContext{
+		Ctx:           ctx,
+		BlockContext:  block,
+		TxID:          hex.EncodeToString(txID[:]),
+		Signer:        s.Owner,
+		Caller:        s.Caller,
+		Authenticator: s.Authenticator,
+	}, tx, exec)
+	if err != nil {
+		app.Service.Logger.Warn("scheduled procedure failed", log.String("dbid", s.DBID),
+			log.String("procedure", s.Procedure), log.Error(err))
+		return nil
+	}
+
+	cost := new(big.Int).Mul(GasPrice, new(big.Int).SetUint64(exec.UsedGas))
+	err = burn(ctx, tx, s.Owner, cost)
+	if errors.Is(err, accounts.ErrInsufficientFunds) {
+		app.Service.Logger.Warn("reverting scheduled procedure, the owner has insufficient funds",
+			log.String("dbid", s.DBID), log.String("procedure", s.Procedure), log.Uint("gas", exec.UsedGas))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	app.Service.Logger.Debug("ran scheduled procedure", log.String("dbid", s.DBID),
+		log.String("procedure", s.Procedure), log.Uint("gas", exec.UsedGas))
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,312 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/internal/accounts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Schedules(t *testing.T) {
+	type testcase struct {
+		name        string
+		annotations []string
+		params      bool
+		want        *Schedule // nil if not scheduled
+		err         bool
+	}
+
+	tests := []testcase{
+		{
+			name:        "every",
+			annotations: []string{"@kgw(authn='true')", "@schedule(every=100)"},
+			want:        &Schedule{Procedure: "proc", Every: 100},
+		},
+		{
+			name:        "height",
+			annotations: []string{"@schedule(height=5000)"},
+			want:        &Schedule{Procedure: "proc", Height: 5000},
+		},
+		{
+			name:        "not scheduled",
+			annotations: []string{"@kgw(authn='true')"},
+		},
+		{
+			name:        "every and height",
+			annotations: []string{"@schedule(every=100, height=5000)"},
+			err:         true,
+		},
+		{
+			name:        "no arguments",
+			annotations: []string{"@schedule()"},
+			err:         true,
+		},
+		{
+			name:        "not an integer",
+			annotations: []string{"@schedule(every='100')"},
+			err:         true,
+		},
+		{
+			name:        "zero interval",
+			annotations: []string{"@schedule(every=0)"},
+			err:         true,
+		},
+		{
+			name:        "unknown argument",
+			annotations: []string{"@schedule(cron=100)"},
+			err:         true,
+		},
+		{
+			name:        "two schedules",
+			annotations: []string{"@schedule(every=100)", "@schedule(height=5000)"},
+			err:         true,
+		},
+		{
+			name:        "parameters",
+			annotations: []string{"@schedule(every=100)"},
+			params:      true,
+			err:         true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proc := &types.Procedure{Name: "proc", Annotations: tc.annotations}
+			if tc.params {
+				proc.Parameters = []*types.ProcedureParameter{{Name: "$id", Type: types.IntType}}
+			}
+
+			schedules, err := Schedules(&types.Schema{Procedures: []*types.Procedure{proc}})
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidSchedule)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.want == nil {
+				assert.Empty(t, schedules)
+				return
+			}
+			require.Len(t, schedules, 1)
+			assert.Equal(t, tc.want, schedules[0])
+		})
+	}
+}
+
+func Test_SchedulesLimit(t *testing.T) {
+	schema := &types.Schema{}
+	for i := range MaxSchedules + 1 {
+		schema.Procedures = append(schema.Procedures, &types.Procedure{
+			Name:        fmt.Sprintf("proc%d", i),
+			Annotations: []string{"@schedule(every=100)"},
+		})
+	}
+
+	_, err := Schedules(schema)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	schema.Procedures = schema.Procedures[:MaxSchedules]
+	schedules, err := Schedules(schema)
+	require.NoError(t, err)
+	assert.Len(t, schedules, MaxSchedules)
+}
+
+func Test_AddSchedule(t *testing.T) {
+	ctx := context.Background()
+	db := &mockDB{}
+
+	require.NoError(t, AddSchedule(ctx, db, &Schedule{Procedure: "a", Every: 10, StartHeight: 5}))
+	require.NoError(t, AddSchedule(ctx, db, &Schedule{Procedure: "b", Height: 6, StartHeight: 5}))
+	require.ErrorIs(t, AddSchedule(ctx, db, &Schedule{Procedure: "c", Height: 5, StartHeight: 5}), ErrInvalidSchedule)
+	assert.Len(t, db.schedules, 2)
+}
+
+// mockDB stores schedules in memory, handling the queries used by this
+// package.
+type mockDB struct {
+	schedules []*Schedule
+}
+
+func (m *mockDB) BeginTx(ctx context.Context) (sql.Tx, error) {
+	return &mockTx{m}, nil
+}
+
+func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	switch stmt {
+	case sqlInsertSchedule:
+		m.schedules = append(m.schedules, &Schedule{
+			DBID:          args[0].(string),
+			Procedure:     args[1].(string),
+			Owner:         args[2].([]byte),
+			Caller:        args[3].(string),
+			Authenticator: args[4].(string),
+			Every:         args[5].(int64),
+			Height:        args[6].(int64),
+			StartHeight:   args[7].(int64),
+		})
+		return &sql.ResultSet{}, nil
+	case sqlListDueSchedules:
+		// schedules are added in the order of their start heights
+		height, limit := args[0].(int64), args[1].(int64)
+		var rows [][]any
+		for _, s := range m.schedules {
+			if int64(len(rows)) == limit {
+				break
+			}
+			if (s.Every > 0 && height > s.StartHeight && (height-s.StartHeight)%s.Every == 0) || s.Height == height {
+				rows = append(rows, []any{s.DBID, s.Procedure, s.Owner, s.Caller, s.Authenticator, s.Every, s.Height, s.StartHeight})
+			}
+		}
+		return &sql.ResultSet{Rows: rows}, nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %s", stmt)
+	}
+}
+
+type mockTx struct {
+	*mockDB
+}
+
+func (m *mockTx) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+// mockEngine records the procedures that are executed, each of which uses
+// mockGas gas.
+type mockEngine struct {
+	common.Engine
+	called []string
+}
+
+const mockGas = 100
+
+func (m *mockEngine) Procedure(ctx *common.TxContext, tx sql.DB, options *common.ExecutionData) (*sql.ResultSet, error) {
+	m.called = append(m.called, fmt.Sprintf("%s.%s by %s", options.Dataset, options.Procedure, ctx.Caller))
+	if options.Procedure == "fails" {
+		return nil, errors.New("procedure failed")
+	}
+	options.UsedGas = mockGas
+	return &sql.ResultSet{}, nil
+}
+
+func Test_RunSchedules(t *testing.T) {
+	ctx := context.Background()
+	db := &mockDB{}
+	engine := &mockEngine{}
+	activation := uint64(16)
+	genesis := chain.DefaultGenesisConfig()
+	genesis.ForkHeights = map[string]*uint64{consensus.ForkScheduledProcedures: &activation}
+	app := &common.App{
+		Service: &common.Service{Logger: log.New(log.Config{}).Sugar(), GenesisConfig: genesis},
+		DB:      db,
+		Engine:  engine,
+	}
+
+	runCost := new(big.Int).Mul(GasPrice, big.NewInt(mockGas))
+	// alice can pay for one and a half runs
+	balances := map[string]*big.Int{"alice": new(big.Int).Div(new(big.Int).Mul(runCost, big.NewInt(3)), big.NewInt(2))}
+	burn = func(_ context.Context, _ sql.Executor, account []byte, amt *big.Int) error {
+		bal, ok := balances[string(account)]
+		if !ok || bal.Cmp(amt) < 0 {
+			return accounts.ErrInsufficientFunds
+		}
+		balances[string(account)] = bal.Sub(bal, amt)
+		return nil
+	}
+	defer func() { burn = accounts.Burn }()
+
+	for _, s := range []*Schedule{
+		{DBID: "db1", Procedure: "settle", Owner: []byte("alice"), Caller: "alice", Every: 10, StartHeight: 5},
+		{DBID: "db1", Procedure: "fails", Owner: []byte("alice"), Caller: "alice", Height: 15, StartHeight: 5},
+		{DBID: "db2", Procedure: "expire", Owner: []byte("bob"), Caller: "bob", Every: 10, StartHeight: 5},
+	} {
+		require.NoError(t, AddSchedule(ctx, db, s))
+	}
+
+	block := &common.BlockContext{
+		Height: 15,
+		ChainContext: &common.ChainContext{
+			NetworkParameters: &common.NetworkParameters{},
+		},
+	}
+
+	// nothing runs before the fork activates
+	require.NoError(t, runSchedules(ctx, app, block))
+	assert.Empty(t, engine.called)
+
+	// alice is charged for the gas used by the run that succeeded, and bob
+	// cannot pay
+	activation = 15
+	require.NoError(t, runSchedules(ctx, app, block))
+	assert.Equal(t, []string{"db1.settle by alice", "db1.fails by alice", "db2.expire by bob"}, engine.called)
+	assert.Equal(t, new(big.Int).Div(runCost, big.NewInt(2)), balances["alice"])
+
+	// nothing is scheduled for the next block
+	engine.called = nil
+	block.Height = 16
+	require.NoError(t, runSchedules(ctx, app, block))
+	assert.Empty(t, engine.called)
+
+	// without gas costs, nothing runs
+	block.Height = 25
+	block.ChainContext.NetworkParameters.DisabledGasCosts = true
+	require.NoError(t, runSchedules(ctx, app, block))
+	assert.Empty(t, engine.called)
+
+	// alice can no longer pay, so her run is reverted
+	block.ChainContext.NetworkParameters.DisabledGasCosts = false
+	require.NoError(t, runSchedules(ctx, app, block))
+	assert.Equal(t, []string{"db1.settle by alice", "db2.expire by bob"}, engine.called)
+	assert.Equal(t, new(big.Int).Div(runCost, big.NewInt(2)), balances["alice"])
+}
+
+func Test_RunSchedulesLimit(t *testing.T) {
+	ctx := context.Background()
+	db := &mockDB{}
+	engine := &mockEngine{}
+	activation := uint64(0)
+	genesis := chain.DefaultGenesisConfig()
+	genesis.ForkHeights = map[string]*uint64{consensus.ForkScheduledProcedures: &activation}
+	app := &common.App{
+		Service: &common.Service{Logger: log.New(log.Config{}).Sugar(), GenesisConfig: genesis},
+		DB:      db,
+		Engine:  engine,
+	}
+
+	burn = func(context.Context, sql.Executor, []byte, *big.Int) error { return nil }
+	defer func() { burn = accounts.Burn }()
+
+	for i := range MaxRunsPerBlock + 1 {
+		require.NoError(t, AddSchedule(ctx, db, &Schedule{DBID: fmt.Sprintf("db%d", i), Procedure: "run",
+			Owner: []byte("alice"), Caller: "alice", Every: 1, StartHeight: int64(i)}))
+	}
+
+	block := &common.BlockContext{
+		Height: MaxRunsPerBlock + 1,
+		ChainContext: &common.ChainContext{
+			NetworkParameters: &common.NetworkParameters{},
+		},
+	}
+
+	// the newest schedule is skipped
+	require.NoError(t, runSchedules(ctx, app, block))
+	require.Len(t, engine.called, MaxRunsPerBlock)
+	assert.Equal(t, "db0.run by alice", engine.called[0])
+	assert.Equal(t, fmt.Sprintf("db%d.run by alice", MaxRunsPerBlock-1), engine.called[MaxRunsPerBlock-1])
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+const (
+	schemaName = `kwild_scheduler`
+
+	schedulerStoreVersion = 0
+
+	sqlInitSchedulesTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.schedules (
+		dbid TEXT NOT NULL,
+		procedure TEXT NOT NULL,
+		owner BYTEA NOT NULL, -- the signer that deployed the dataset
+		caller TEXT NOT NULL, -- the identifier of the owner, used as @caller
+		authenticator TEXT NOT NULL, -- the authenticator of the owner
+		every INT8 NOT NULL, -- the interval in blocks, or 0 for a single run
+		at_height INT8 NOT NULL, -- the height of a single run, or 0 for an interval
+		start_height INT8 NOT NULL, -- the height at which the schedule was created
+		PRIMARY KEY (dbid, procedure)
+	);`
+
+	sqlInsertSchedule = `INSERT INTO ` + schemaName + `.schedules (dbid, procedure, owner, caller, authenticator, every, at_height, start_height)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	sqlDeleteSchedules = `DELETE FROM ` + schemaName + `.schedules WHERE dbid = $1`
+
+	// sqlListDueSchedules lists the schedules that should run at the height
+	// given as $1, oldest first, up to the limit given as $2.
+	sqlListDueSchedules = `SELECT dbid, procedure, owner, caller, authenticator, every, at_height, start_height
+		FROM ` + schemaName + `.schedules
+		WHERE (every > 0 AND $1 > start_height AND ($1 - start_height) % every = 0) OR at_height = $1
+		ORDER BY start_height, dbid, procedure
+		LIMIT $2`
+)
+
+func initTables(ctx context.Context, tx sql.DB) error {
+	_, err := tx.Execute(ctx, sqlInitSchedulesTable)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	return nil
+}
+
+// listDueSchedules lists at most limit schedules that should run at the given
+// height, ordered by start height, dataset and procedure.
+func listDueSchedules(ctx context.Context, db sql.Executor, height, limit int64) ([]*Schedule, error) {
+	res, err := db.Execute(ctx, sqlListDueSchedules, height, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*Schedule, len(res.Rows))
+	for i, row := range res.Rows {
+		if len(row) != 8 {
+			// this should never happen, just for safety
+			return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
+		}
+
+		s := &Schedule{}
+		var ok bool
+		if s.DBID, ok = row[0].(string); !ok {
+			return nil, fmt.Errorf("invalid type for dbid (%T)", row[0])
+		}
+		if s.Procedure, ok = row[1].(string); !ok {
+			return nil, fmt.Errorf("invalid type for procedure (%T)", row[1])
+		}
+		if s.Owner, ok = row[2].([]byte); !ok {
+			return nil, fmt.Errorf("invalid type for owner (%T)", row[2])
+		}
+		if s.Caller, ok = row[3].(string); !ok {
+			return nil, fmt.Errorf("invalid type for caller (%T)", row[3])
+		}
+		if s.Authenticator, ok = row[4].(string); !ok {
+			return nil, fmt.Errorf("invalid type for authenticator (%T)", row[4])
+		}
+		for j, dst := range []*int64{&s.Every, &s.Height, &s.StartHeight} {
+			if *dst, ok = sql.Int64(row[5+j]); !ok {
+				return nil, fmt.Errorf("invalid type for schedule column %d (%T)", 5+j, row[5+j])
+			}
+		}
+
+		schedules[i] = s
+	}
+
+	return schedules, nil
+}
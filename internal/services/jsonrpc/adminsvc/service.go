@@ -1,11 +1,13 @@
 package adminsvc
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"slices"
 
 	cmtCoreTypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/kwilteam/kwil-db/common/config"
@@ -65,6 +67,10 @@ type Service struct {
 	cfg     *config.KwildConfig
 	chainID string
 	signer  auth.Signer // ed25519 signer derived from the node's private key
+
+	// missedBlocksWindow is the number of recent blocks in which validators'
+	// missed blocks are counted. It is zero if liveness is not tracked.
+	missedBlocksWindow int64
 }
 
 const (
@@ -174,9 +180,15 @@ func (svc *Service) Methods() map[jsonrpc.Method]rpcserver.MethodDef {
 		adminjson.MethodValList: rpcserver.MakeMethodDef(svc.ListValidators,
 			"list the current validators",
 			"the list of current validators and their power"),
+		adminjson.MethodValEvidence: rpcserver.MakeMethodDef(svc.ListEvidence,
+			"list recorded validator misbehavior",
+			"the misbehavior reported by the consensus engine and the punishment applied to each validator"),
 		adminjson.MethodValLeave: rpcserver.MakeMethodDef(svc.Leave,
 			"leave the validator set",
 			"the hash of the broadcasted validator leave transaction"),
+		adminjson.MethodValUnjail: rpcserver.MakeMethodDef(svc.Unjail,
+			"unjail the validator",
+			"the hash of the broadcasted validator unjail transaction"),
 		adminjson.MethodValRemove: rpcserver.MakeMethodDef(svc.Remove,
 			"vote to remote a validator",
 			"the hash of the broadcasted validator remove transaction"),
@@ -221,7 +233,7 @@ func (svc *Service) Handlers() map[jsonrpc.Method]rpcserver.MethodHandler {
 // NewService constructs a new Service.
 func NewService(db sql.DelayedReadTxMaker, blockchain BlockchainTransactor, txApp TxApp,
 	pricer Pricer, p2p P2P, signer auth.Signer, cfg *config.KwildConfig,
-	chainID string, logger log.Logger) *Service {
+	chainID string, missedBlocksWindow int64, logger log.Logger) *Service {
 	return &Service{
 		blockchain: blockchain,
 		TxApp:      txApp,
@@ -232,6 +244,8 @@ func NewService(db sql.DelayedReadTxMaker, blockchain BlockchainTransactor, txAp
 		cfg:        cfg,
 		log:        logger,
 		db:         db,
+
+		missedBlocksWindow: missedBlocksWindow,
 	}
 }
 
@@ -345,9 +359,33 @@ func (svc *Service) Approve(ctx context.Context, req *adminjson.ApproveRequest)
 }
 
 func (svc *Service) Join(ctx context.Context, req *adminjson.JoinRequest) (*userjson.BroadcastResponse, *jsonrpc.Error) {
-	return svc.sendTx(ctx, &transactions.ValidatorJoin{
+	// With a remote signer, the validator's votes and proposals are signed
+	// with its consensus key rather than the node's key.
+	status, err := svc.blockchain.Status(ctx)
+	if err != nil {
+		svc.log.Error("chain status error", log.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorNodeInternal, "status failure", nil)
+	}
+	join := &transactions.ValidatorJoin{
 		Power: 1,
-	})
+	}
+	if !bytes.Equal(status.Validator.PubKey, svc.signer.Identity()) {
+		join.ConsensusKey = status.Validator.PubKey
+	}
+
+	if req.Stake != "" {
+		// stake first, so that the join request is executed with the stake
+		// included in the validator power
+		amt, ok := new(big.Int).SetString(req.Stake, 10)
+		if !ok || amt.Sign() <= 0 {
+			return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "invalid stake amount", nil)
+		}
+		if _, jsonErr := svc.sendTx(ctx, &transactions.Stake{Amount: amt.String()}); jsonErr != nil {
+			return nil, jsonErr
+		}
+	}
+
+	return svc.sendTx(ctx, join)
 }
 
 func (svc *Service) Remove(ctx context.Context, req *adminjson.RemoveRequest) (*userjson.BroadcastResponse, *jsonrpc.Error) {
@@ -389,6 +427,10 @@ func (svc *Service) Leave(ctx context.Context, req *adminjson.LeaveRequest) (*us
 	return svc.sendTx(ctx, &transactions.ValidatorLeave{})
 }
 
+func (svc *Service) Unjail(ctx context.Context, req *adminjson.UnjailRequest) (*userjson.BroadcastResponse, *jsonrpc.Error) {
+	return svc.sendTx(ctx, &transactions.ValidatorUnjail{})
+}
+
 func (svc *Service) ListValidators(ctx context.Context, req *adminjson.ListValidatorsRequest) (*adminjson.ListValidatorsResponse, *jsonrpc.Error) {
 	readTx := svc.db.BeginDelayedReadTx()
 	defer readTx.Rollback(ctx)
@@ -406,11 +448,56 @@ func (svc *Service) ListValidators(ctx context.Context, req *adminjson.ListValid
 		}
 	}
 
+	if svc.missedBlocksWindow > 0 {
+		liveness, err := voting.ListLiveness(ctx, readTx)
+		if err != nil {
+			svc.log.Error("failed to retrieve validator liveness", zap.Error(err))
+			return nil, jsonrpc.NewError(jsonrpc.ErrorDBInternal, "failed to retrieve validator liveness", nil)
+		}
+
+		uptimes := make(map[string]*coretypes.ValidatorUptime, len(liveness))
+		for _, l := range liveness {
+			uptimes[string(l.Validator)] = &coretypes.ValidatorUptime{
+				Window:       svc.missedBlocksWindow,
+				MissedBlocks: l.MissedBlocks,
+				Jailed:       l.Jailed,
+				JailedHeight: l.JailedHeight,
+			}
+		}
+		for _, v := range pbValidators {
+			v.Uptime = uptimes[string(v.PubKey)]
+		}
+
+		// jailed validators are listed with no power
+		for _, l := range liveness {
+			if l.Jailed && !slices.ContainsFunc(vals, func(v *coretypes.Validator) bool { return bytes.Equal(v.PubKey, l.Validator) }) {
+				pbValidators = append(pbValidators, &adminjson.Validator{
+					PubKey: l.Validator,
+					Uptime: uptimes[string(l.Validator)],
+				})
+			}
+		}
+	}
+
 	return &adminjson.ListValidatorsResponse{
 		Validators: pbValidators,
 	}, nil
 }
 
+func (svc *Service) ListEvidence(ctx context.Context, req *adminjson.ListEvidenceRequest) (*adminjson.ListEvidenceResponse, *jsonrpc.Error) {
+	readTx := svc.db.BeginDelayedReadTx()
+	defer readTx.Rollback(ctx)
+	evidence, err := voting.ListEvidence(ctx, readTx, req.PubKey)
+	if err != nil {
+		svc.log.Error("failed to retrieve validator evidence", zap.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorDBInternal, "failed to retrieve validator evidence", nil)
+	}
+
+	return &adminjson.ListEvidenceResponse{
+		Evidence: evidence,
+	}, nil
+}
+
 func (svc *Service) ListPendingJoins(ctx context.Context, req *adminjson.ListJoinRequestsRequest) (*adminjson.ListJoinRequestsResponse, *jsonrpc.Error) {
 	readTx := svc.db.BeginDelayedReadTx()
 	defer readTx.Rollback(ctx)
@@ -244,6 +244,12 @@ func reflectTypeInfo(t reflect.Type, knownSchemas map[reflect.Type]Schema) Schem
 		schema.Items = &ti
 		return schema
 
+	case reflect.Map:
+		// Map keys are the object's property names, which cannot be known.
+		schema.Type = "object"
+		schema.AdditionalProperties = true
+		return schema
+
 	case reflect.Interface:
 		// Represent interfaces as a generic object, assuming no specific
 		// properties can be inferred.
@@ -0,0 +1,81 @@
+package usersvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	jsonrpc "github.com/kwilteam/kwil-db/core/rpc/json"
+	userjson "github.com/kwilteam/kwil-db/core/rpc/json/user"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/internal/cdc"
+)
+
+const (
+	// defaultChangesLimit is the maximum number of changes in a changes
+	// response, if the request does not specify a limit and there is no
+	// configured max rows. Changes are returned for whole blocks, so a single
+	// block with more changes is returned whole.
+	defaultChangesLimit = 1000
+
+	// maxChangesBlocks is the maximum number of blocks with changes that are
+	// read for a changes response, so that a request for a dataset with few
+	// changes does not read the whole log.
+	maxChangesBlocks = 1000
+)
+
+// ChangeFeed is the change data capture log of the dataset changes committed in
+// each block.
+type ChangeFeed interface {
+	Changes(dbid, table string, fromHeight int64, limit, maxBlocks int) ([]*types.RowChange, int64, error)
+	Range() (first, last int64)
+}
+
+// Changes is the handler for the user.changes RPC. It returns the changes to a
+// dataset, or one of its tables, committed in blocks starting at the requested
+// height.
+func (svc *Service) Changes(ctx context.Context, req *userjson.ChangesRequest) (*userjson.ChangesResponse, *jsonrpc.Error) {
+	if svc.changeFeed == nil {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorChangesDisabled, "change data capture is disabled on this node", nil)
+	}
+
+	if svc.privateMode {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorNoQueryWithPrivateRPC,
+			"changes are prohibited when authenticated calls are enforced (private mode)", nil)
+	}
+
+	if req.DBID == "" {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "dbid is required", nil)
+	}
+	if req.Limit < 0 {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "negative limit", nil)
+	}
+
+	limit := int64(defaultChangesLimit)
+	if svc.maxRows > 0 {
+		limit = svc.maxRows
+	}
+	if req.Limit > 0 && req.Limit < limit {
+		limit = req.Limit
+	}
+
+	_, last := svc.changeFeed.Range()
+	changes, next, err := svc.changeFeed.Changes(req.DBID, req.Table, req.FromHeight, int(limit), maxChangesBlocks)
+	if err != nil {
+		if errors.Is(err, cdc.ErrPruned) {
+			return nil, jsonrpc.NewError(jsonrpc.ErrorChangesPruned, err.Error(), nil)
+		}
+		svc.log.Error("failed to read changes", log.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorNodeInternal, "failed to read changes", nil)
+	}
+
+	if changes == nil {
+		changes = []*types.RowChange{}
+	}
+
+	return &userjson.ChangesResponse{
+		Changes:    changes,
+		NextHeight: next,
+		LastHeight: max(last, next-1),
+	}, nil
+}
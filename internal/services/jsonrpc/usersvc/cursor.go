@@ -0,0 +1,255 @@
+package usersvc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	jsonrpc "github.com/kwilteam/kwil-db/core/rpc/json"
+	userjson "github.com/kwilteam/kwil-db/core/rpc/json/user"
+	"github.com/kwilteam/kwil-db/internal/abci/meta"
+	rpcserver "github.com/kwilteam/kwil-db/internal/services/jsonrpc"
+)
+
+const (
+	// defaultPageSize is the number of rows in a page if the request does
+	// not specify a limit and there is no configured max rows.
+	defaultPageSize = 1000
+
+	// pgCursorName is the name of the PostgreSQL cursor. There is one cursor
+	// per transaction, so it need not be unique.
+	pgCursorName = "kwil_query_page"
+)
+
+// queryCursor is an open server-side cursor for an ad-hoc query. It holds a
+// repeatable read transaction so that every page comes from the same snapshot.
+type queryCursor struct {
+	tx      sql.OuterReadTx
+	txCtx   *common.TxContext
+	height  int64
+	limit   int64
+	ip      string // client that opened the cursor, counted in ipCursors
+	expires time.Time
+}
+
+func (c *queryCursor) close() {
+	_ = c.tx.Rollback(context.Background())
+}
+
+// QueryPage is the handler for the user.query_page RPC. It executes an ad-hoc
+// query with a server-side cursor, returning up to the requested limit of rows
+// and a cursor to request the next page. All pages of a query are read from
+// the same database snapshot.
+func (svc *Service) QueryPage(ctx context.Context, req *userjson.QueryPageRequest) (*userjson.QueryPageResponse, *jsonrpc.Error) {
+	if svc.privateMode {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorNoQueryWithPrivateRPC,
+			"query is prohibited when authenticated calls are enforced (private mode)", nil)
+	}
+
+	if req.Limit < 0 {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "negative limit", nil)
+	}
+
+	if req.Cursor == "" && req.Query == "" {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "query or cursor is required", nil)
+	}
+
+	var id string
+	var cursor *queryCursor
+	var jsonErr *jsonrpc.Error
+	if req.Cursor == "" {
+		clientIP, _ := ctx.Value(rpcserver.RequestIPCtx).(string)
+		id, cursor, jsonErr = svc.openCursor(ctx, req, clientIP)
+	} else {
+		id = req.Cursor
+		cursor, jsonErr = svc.takeCursor(id)
+	}
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	limit := cursor.limit
+	if req.Limit > 0 && req.Limit < limit {
+		limit = req.Limit
+	}
+
+	ctxExec, cancel := context.WithTimeout(ctx, svc.readTxTimeout)
+	defer cancel()
+	cursor.txCtx.Ctx = ctxExec
+
+	result, err := svc.engine.FetchCursor(cursor.txCtx, cursor.tx, pgCursorName, limit)
+	if err != nil {
+		svc.releaseCursor(id, cursor, true)
+		return nil, engineError(err)
+	}
+
+	bts, err := json.Marshal(resultMap(result))
+	if err != nil {
+		svc.releaseCursor(id, cursor, true)
+		return nil, jsonrpc.NewError(jsonrpc.ErrorResultEncoding, "failed to marshal query result", nil)
+	}
+
+	resp := &userjson.QueryPageResponse{
+		Result: bts,
+		Height: cursor.height,
+	}
+
+	// A short page means the cursor is exhausted. Otherwise there may be more
+	// rows, and the next page may be empty.
+	done := int64(len(result.Rows)) < limit
+	if !done {
+		resp.NextCursor = id
+	}
+	svc.releaseCursor(id, cursor, done)
+
+	return resp, nil
+}
+
+// openCursor starts a read transaction and declares a cursor for the query. The
+// cursor is counted as open, in total and for the client IP, but it is not
+// added to the cursors map until it is released.
+func (svc *Service) openCursor(ctx context.Context, req *userjson.QueryPageRequest, ip string) (string, *queryCursor, *jsonrpc.Error) {
+	svc.cursorMtx.Lock()
+	if svc.maxCursors > 0 && svc.openCursors >= svc.maxCursors {
+		svc.cursorMtx.Unlock()
+		return "", nil, jsonrpc.NewError(jsonrpc.ErrorTooManyCursors, "too many open query cursors", nil)
+	}
+	if svc.maxCursorsPerIP > 0 && svc.ipCursors[ip] >= svc.maxCursorsPerIP {
+		svc.cursorMtx.Unlock()
+		return "", nil, jsonrpc.NewError(jsonrpc.ErrorTooManyCursors, "too many open query cursors for this client", nil)
+	}
+	svc.openCursors++
+	svc.ipCursors[ip]++
+	svc.cursorMtx.Unlock()
+
+	id, cursor, jsonErr := svc.declareCursor(ctx, req)
+	if jsonErr != nil {
+		svc.cursorMtx.Lock()
+		svc.uncountCursor(ip)
+		svc.cursorMtx.Unlock()
+		return "", nil, jsonErr
+	}
+	cursor.ip = ip
+
+	return id, cursor, nil
+}
+
+// uncountCursor removes a closed cursor from the open cursor counts. The
+// cursorMtx must be held.
+func (svc *Service) uncountCursor(ip string) {
+	svc.openCursors--
+	svc.ipCursors[ip]--
+	if svc.ipCursors[ip] <= 0 {
+		delete(svc.ipCursors, ip)
+	}
+}
+
+func (svc *Service) declareCursor(ctx context.Context, req *userjson.QueryPageRequest) (string, *queryCursor, *jsonrpc.Error) {
+	var idBts [16]byte
+	if _, err := rand.Read(idBts[:]); err != nil {
+		return "", nil, jsonrpc.NewError(jsonrpc.ErrorInternal, err.Error(), nil)
+	}
+
+	limit := int64(defaultPageSize)
+	if svc.maxRows > 0 {
+		limit = svc.maxRows
+	}
+	if req.Limit > 0 && req.Limit < limit {
+		limit = req.Limit
+	}
+
+	ctxExec, cancel := context.WithTimeout(ctx, svc.readTxTimeout)
+	defer cancel()
+
+	// The transaction outlives this request, so it must not use its context.
+	readTx, err := svc.db.BeginReadTx(context.Background())
+	if err != nil {
+		return "", nil, jsonrpc.NewError(jsonrpc.ErrorNodeInternal, "failed to start read tx", nil)
+	}
+
+	height, _, err := meta.GetChainState(ctxExec, readTx)
+	if err != nil {
+		readTx.Rollback(ctx)
+		svc.log.Error("failed to get chain state", log.Error(err))
+		return "", nil, jsonrpc.NewError(jsonrpc.ErrorNodeInternal, "failed to get snapshot height", nil)
+	}
+
+	txCtx := &common.TxContext{
+		Ctx: ctxExec,
+		BlockContext: &common.BlockContext{
+			Height: -1, // cannot know the height here.
+		},
+	}
+
+	err = svc.engine.DeclareCursor(txCtx, readTx, req.DBID, req.Query, pgCursorName)
+	if err != nil {
+		readTx.Rollback(ctx)
+		return "", nil, engineError(err)
+	}
+
+	return hex.EncodeToString(idBts[:]), &queryCursor{
+		tx:     readTx,
+		txCtx:  txCtx,
+		height: height,
+		limit:  limit,
+	}, nil
+}
+
+// takeCursor removes an idle cursor from the cursors map so that it is not
+// used concurrently or expired while a page is fetched. It must be returned
+// with releaseCursor.
+func (svc *Service) takeCursor(id string) (*queryCursor, *jsonrpc.Error) {
+	svc.cursorMtx.Lock()
+	defer svc.cursorMtx.Unlock()
+
+	cursor, ok := svc.cursors[id]
+	if !ok {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorCursorNotFound,
+			fmt.Sprintf("cursor %q not found, expired, or in use", id), nil)
+	}
+	delete(svc.cursors, id)
+
+	return cursor, nil
+}
+
+// releaseCursor returns a cursor to the cursors map, or closes it if done.
+func (svc *Service) releaseCursor(id string, cursor *queryCursor, done bool) {
+	if done {
+		cursor.close()
+		svc.cursorMtx.Lock()
+		svc.uncountCursor(cursor.ip)
+		svc.cursorMtx.Unlock()
+		return
+	}
+
+	cursor.expires = time.Now().Add(svc.cursorTimeout)
+
+	svc.cursorMtx.Lock()
+	svc.cursors[id] = cursor
+	svc.cursorMtx.Unlock()
+}
+
+func (svc *Service) expireCursors() {
+	now := time.Now()
+	var expired []*queryCursor
+
+	svc.cursorMtx.Lock()
+	for id, cursor := range svc.cursors {
+		if now.After(cursor.expires) {
+			delete(svc.cursors, id)
+			svc.uncountCursor(cursor.ip)
+			expired = append(expired, cursor)
+		}
+	}
+	svc.cursorMtx.Unlock()
+
+	for _, cursor := range expired {
+		cursor.close()
+	}
+}
@@ -0,0 +1,136 @@
+package usersvc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	jsonrpc "github.com/kwilteam/kwil-db/core/rpc/json"
+	userjson "github.com/kwilteam/kwil-db/core/rpc/json/user"
+	rpcserver "github.com/kwilteam/kwil-db/internal/services/jsonrpc"
+)
+
+func Test_QueryPageLimits(t *testing.T) {
+	db := &cursorDB{}
+	svc := NewService(db, &cursorEngine{rows: 8}, nil, nil, nil, nil, log.NewNoOp(),
+		WithMaxCursors(2, 1))
+
+	req := &userjson.QueryPageRequest{DBID: "x", Query: "SELECT 1", Limit: 5}
+
+	// a full page leaves the cursor open
+	res, jsonErr := svc.QueryPage(ipCtx("1.1.1.1"), req)
+	require.Nil(t, jsonErr)
+	require.NotEmpty(t, res.NextCursor)
+
+	// the client may not open another cursor
+	_, jsonErr = svc.QueryPage(ipCtx("1.1.1.1"), req)
+	require.NotNil(t, jsonErr)
+	require.Equal(t, jsonrpc.ErrorTooManyCursors, jsonErr.Code)
+
+	// another client may
+	_, jsonErr = svc.QueryPage(ipCtx("2.2.2.2"), req)
+	require.Nil(t, jsonErr)
+
+	// but not once the total limit is reached
+	_, jsonErr = svc.QueryPage(ipCtx("3.3.3.3"), req)
+	require.NotNil(t, jsonErr)
+	require.Equal(t, jsonrpc.ErrorTooManyCursors, jsonErr.Code)
+
+	// rejected requests do not start transactions
+	require.EqualValues(t, 2, db.begun.Load())
+	require.EqualValues(t, 0, db.rolledBack.Load())
+
+	// exhausting a cursor closes it, and the client may open another
+	res, jsonErr = svc.QueryPage(ipCtx("1.1.1.1"), &userjson.QueryPageRequest{Cursor: res.NextCursor})
+	require.Nil(t, jsonErr)
+	require.Empty(t, res.NextCursor)
+	require.EqualValues(t, 1, db.rolledBack.Load())
+
+	_, jsonErr = svc.QueryPage(ipCtx("1.1.1.1"), req)
+	require.Nil(t, jsonErr)
+}
+
+func Test_QueryPageExpiry(t *testing.T) {
+	db := &cursorDB{}
+	svc := NewService(db, &cursorEngine{rows: 10}, nil, nil, nil, nil, log.NewNoOp(),
+		WithMaxCursors(1, 1), WithCursorTimeout(time.Millisecond))
+
+	req := &userjson.QueryPageRequest{DBID: "x", Query: "SELECT 1", Limit: 5}
+
+	res, jsonErr := svc.QueryPage(ipCtx("1.1.1.1"), req)
+	require.Nil(t, jsonErr)
+	require.NotEmpty(t, res.NextCursor)
+
+	time.Sleep(5 * time.Millisecond)
+	svc.expireCursors()
+
+	// the expired cursor's transaction is released
+	require.EqualValues(t, 1, db.rolledBack.Load())
+
+	_, jsonErr = svc.QueryPage(ipCtx("1.1.1.1"), &userjson.QueryPageRequest{Cursor: res.NextCursor})
+	require.NotNil(t, jsonErr)
+	require.Equal(t, jsonrpc.ErrorCursorNotFound, jsonErr.Code)
+
+	// and it no longer counts against the limits
+	_, jsonErr = svc.QueryPage(ipCtx("2.2.2.2"), req)
+	require.Nil(t, jsonErr)
+}
+
+func ipCtx(ip string) context.Context {
+	return context.WithValue(context.Background(), rpcserver.RequestIPCtx, ip)
+}
+
+// cursorEngine is an EngineReader whose cursors have a fixed number of rows.
+type cursorEngine struct {
+	EngineReader
+	rows int64
+}
+
+func (e *cursorEngine) DeclareCursor(ctx *common.TxContext, tx sql.DB, dbid, query, name string) error {
+	tx.(*cursorTx).remaining = e.rows
+	return nil
+}
+
+func (e *cursorEngine) FetchCursor(ctx *common.TxContext, tx sql.DB, name string, limit int64) (*sql.ResultSet, error) {
+	cur := tx.(*cursorTx)
+	n := min(limit, cur.remaining)
+	cur.remaining -= n
+
+	res := &sql.ResultSet{Columns: []string{"n"}}
+	for i := int64(0); i < n; i++ {
+		res.Rows = append(res.Rows, []any{i})
+	}
+	return res, nil
+}
+
+// cursorDB counts the read transactions that are started and rolled back.
+type cursorDB struct {
+	DB
+	begun, rolledBack atomic.Int64
+}
+
+func (db *cursorDB) BeginReadTx(ctx context.Context) (sql.OuterReadTx, error) {
+	db.begun.Add(1)
+	return &cursorTx{db: db}, nil
+}
+
+type cursorTx struct {
+	sql.OuterReadTx
+	db        *cursorDB
+	remaining int64
+}
+
+func (tx *cursorTx) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	return &sql.ResultSet{}, nil // no chain state
+}
+
+func (tx *cursorTx) Rollback(ctx context.Context) error {
+	tx.db.rolledBack.Add(1)
+	return nil
+}
@@ -0,0 +1,79 @@
+package usersvc
+
+import (
+	"context"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	jsonrpc "github.com/kwilteam/kwil-db/core/rpc/json"
+	userjson "github.com/kwilteam/kwil-db/core/rpc/json/user"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/internal/voting"
+)
+
+const (
+	defaultResolutionsLimit = 100
+	maxResolutionsLimit     = 1000
+)
+
+// ListResolutions is the handler for the user.list_resolutions RPC. It lists
+// pending resolutions and the results of approved and expired resolutions,
+// with their votes and the power required to approve them.
+func (svc *Service) ListResolutions(ctx context.Context, req *userjson.ListResolutionsRequest) (*userjson.ListResolutionsResponse, *jsonrpc.Error) {
+	switch req.Status {
+	case "", types.ResolutionStatusPending, types.ResolutionStatusApproved, types.ResolutionStatusExpired:
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "invalid resolution status: "+req.Status, nil)
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "offset and limit may not be negative", nil)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultResolutionsLimit
+	}
+	limit = min(limit, maxResolutionsLimit)
+
+	readTx := svc.db.BeginDelayedReadTx()
+	defer readTx.Rollback(ctx)
+
+	// get one more than the limit to know if there is another page
+	infos, err := voting.ListResolutions(ctx, readTx, &req.ResolutionFilter, req.Offset, limit+1)
+	if err != nil {
+		svc.log.Error("failed to list resolutions", log.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorDBInternal, "failed to list resolutions", nil)
+	}
+
+	resp := &userjson.ListResolutionsResponse{
+		Resolutions: infos,
+	}
+	if int64(len(infos)) > limit {
+		resp.Resolutions = infos[:limit]
+		resp.NextOffset = req.Offset + limit
+	}
+
+	return resp, nil
+}
+
+// Resolution is the handler for the user.resolution RPC. It returns a
+// resolution and its votes. A pending resolution includes its body and the
+// validators that approved it.
+func (svc *Service) Resolution(ctx context.Context, req *userjson.ResolutionRequest) (*userjson.ResolutionResponse, *jsonrpc.Error) {
+	if req.ID == nil {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "missing resolution ID", nil)
+	}
+
+	readTx := svc.db.BeginDelayedReadTx()
+	defer readTx.Rollback(ctx)
+
+	info, err := voting.GetResolution(ctx, readTx, req.ID)
+	if err != nil {
+		svc.log.Error("failed to get resolution", log.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorDBInternal, "failed to get resolution", nil)
+	}
+	if info == nil {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorResolutionNotFound, "resolution not found", nil)
+	}
+
+	return info, nil
+}
@@ -39,6 +39,8 @@ type Service struct {
 	readTxTimeout   time.Duration
 	blockAgeThresh  time.Duration
 	privateMode     bool
+	enableExplain   bool
+	maxRows         int64
 	challengeExpiry time.Duration
 
 	engine      EngineReader
@@ -47,11 +49,23 @@ type Service struct {
 	chainClient BlockchainTransactor
 	abci        ABCI // handles pricing, migration status etc.
 	migrator    Migrator
+	changeFeed  ChangeFeed // nil if change data capture is disabled
 
 	// challenges issued to the clients
 	challengeMtx     sync.Mutex
 	challenges       map[[32]byte]time.Time
 	challengeLimiter *ratelimit.IPRateLimiter
+
+	// open query cursors, keyed by continuation token. A cursor in use by a
+	// request is removed from the map, but still counted in openCursors and
+	// ipCursors.
+	maxCursors      int
+	maxCursorsPerIP int
+	cursorTimeout   time.Duration
+	cursorMtx       sync.Mutex
+	cursors         map[string]*queryCursor
+	openCursors     int
+	ipCursors       map[string]int
 }
 
 type DB interface {
@@ -62,6 +76,12 @@ type DB interface {
 type serviceCfg struct {
 	readTxTimeout      time.Duration
 	privateMode        bool
+	enableExplain      bool
+	maxRows            int64
+	maxCursors         int
+	maxCursorsPerIP    int
+	cursorTimeout      time.Duration
+	changeFeed         ChangeFeed
 	challengeExpiry    time.Duration
 	challengeRateLimit float64 // challenge requests/sec, sustained
 	blockAgeThresh     int64   // milliseconds
@@ -84,6 +104,50 @@ func WithPrivateMode(privateMode bool) Opt {
 	}
 }
 
+// WithExplain enables the explain method, which reveals query plans and the
+// generated SQL for ad-hoc queries and procedures.
+func WithExplain(enable bool) Opt {
+	return func(cfg *serviceCfg) {
+		cfg.enableExplain = enable
+	}
+}
+
+// WithMaxRows limits the number of rows returned by the Query and Call methods,
+// and the size of a page returned by the QueryPage method. Zero is unlimited,
+// except that pages use a default size. The limit is enforced as the rows are
+// read from the database, not after the entire result is read.
+func WithMaxRows(maxRows int64) Opt {
+	return func(cfg *serviceCfg) {
+		cfg.maxRows = maxRows
+	}
+}
+
+// WithMaxCursors limits the number of query cursors open at once, in total and
+// for each client IP address. Each cursor holds a connection from the reader
+// pool until it is exhausted or expires. Zero is unlimited.
+func WithMaxCursors(total, perIP int) Opt {
+	return func(cfg *serviceCfg) {
+		cfg.maxCursors = total
+		cfg.maxCursorsPerIP = perIP
+	}
+}
+
+// WithCursorTimeout sets how long a query cursor may go without a page request
+// before it is closed.
+func WithCursorTimeout(timeout time.Duration) Opt {
+	return func(cfg *serviceCfg) {
+		cfg.cursorTimeout = timeout
+	}
+}
+
+// WithChangeFeed enables the changes method, which reads the dataset changes
+// committed in each block from the change data capture feed.
+func WithChangeFeed(feed ChangeFeed) Opt {
+	return func(cfg *serviceCfg) {
+		cfg.changeFeed = feed
+	}
+}
+
 func WithChallengeExpiry(expiry time.Duration) Opt {
 	return func(cfg *serviceCfg) {
 		cfg.challengeExpiry = expiry
@@ -107,6 +171,9 @@ const (
 	defaultChallengeExpiry    = 10 * time.Second // TODO: or maybe more?
 	defaultChallengeRateLimit = 10.0
 	defaultAgeThreshMilli     = 129_000 // two minutes
+	defaultMaxCursors         = 8
+	defaultMaxCursorsPerIP    = 2
+	defaultCursorTimeout      = 30 * time.Second
 )
 
 // NewService creates a new instance of the user RPC service.
@@ -116,6 +183,9 @@ func NewService(db DB, engine EngineReader, chainClient BlockchainTransactor,
 		readTxTimeout:      defaultReadTxTimeout,
 		challengeExpiry:    defaultChallengeExpiry,
 		challengeRateLimit: defaultChallengeRateLimit,
+		maxCursors:         defaultMaxCursors,
+		maxCursorsPerIP:    defaultMaxCursorsPerIP,
+		cursorTimeout:      defaultCursorTimeout,
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -131,9 +201,17 @@ func NewService(db DB, engine EngineReader, chainClient BlockchainTransactor,
 		db:               db,
 		migrator:         migrator,
 		privateMode:      cfg.privateMode,
+		enableExplain:    cfg.enableExplain,
+		maxRows:          cfg.maxRows,
+		changeFeed:       cfg.changeFeed,
 		challengeExpiry:  cfg.challengeExpiry,
 		challenges:       make(map[[32]byte]time.Time),
 		challengeLimiter: ratelimit.NewIPRateLimiter(cfg.challengeRateLimit, int(6*defaultChallengeRateLimit)), // allow many calls at start of block
+		maxCursors:       cfg.maxCursors,
+		maxCursorsPerIP:  cfg.maxCursorsPerIP,
+		cursorTimeout:    cfg.cursorTimeout,
+		cursors:          make(map[string]*queryCursor),
+		ipCursors:        make(map[string]int),
 	}
 
 	// Start the expiry goroutine, unsupervised for now since services don't
@@ -146,6 +224,14 @@ func NewService(db DB, engine EngineReader, chainClient BlockchainTransactor,
 				svc.expireChallenges()
 			}
 		}()
+	} else { // query cursors are not available in private mode
+		go func() {
+			ticker := time.NewTicker(5 * time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				svc.expireCursors()
+			}
+		}()
 	}
 
 	return svc
@@ -158,7 +244,7 @@ func NewService(db DB, engine EngineReader, chainClient BlockchainTransactor,
 // or any other breaking changes.
 const (
 	apiVerMajor = 0
-	apiVerMinor = 2
+	apiVerMinor = 4
 	apiVerPatch = 0
 
 	serviceName = "user"
@@ -168,6 +254,10 @@ const (
 //
 // apiVerMinor = 2 indicates the presence of the migration, challenge, and
 // health methods added in Kwil v0.9
+//
+// apiVerMinor = 3 indicates the presence of the explain and query_page methods
+//
+// apiVerMinor = 4 indicates the presence of the changes method
 
 var (
 	apiVerSemver = fmt.Sprintf("%d.%d.%d", apiVerMajor, apiVerMinor, apiVerPatch)
@@ -296,6 +386,36 @@ func (svc *Service) Methods() map[jsonrpc.Method]rpcserver.MethodDef {
 			"perform an ad-hoc SQL query",
 			"the result of the query as a encoded records",
 		),
+		userjson.MethodQueryPage: rpcserver.MakeMethodDef(
+			svc.QueryPage,
+			"perform an ad-hoc SQL query, returning one page of the results",
+			"a page of the query results and a cursor for the next page",
+		),
+		userjson.MethodExplain: rpcserver.MakeMethodDef(
+			svc.Explain,
+			"explain an ad-hoc SQL query or the statements of a procedure",
+			"the Kwil logical plan and PostgreSQL query plan of each statement",
+		),
+		userjson.MethodChanges: rpcserver.MakeMethodDef(
+			svc.Changes,
+			"get the committed changes to a database's tables from a block height",
+			"the row changes and the height from which to request the next changes",
+		),
+		userjson.MethodWithdrawalProof: rpcserver.MakeMethodDef(
+			svc.WithdrawalProof,
+			"get the proof of a withdrawal to Ethereum",
+			"the withdrawal's Merkle proof and the validator signatures of its epoch",
+		),
+		userjson.MethodListResolutions: rpcserver.MakeMethodDef(
+			svc.ListResolutions,
+			"list resolutions and their votes",
+			"the resolutions, and the offset from which to request the next page",
+		),
+		userjson.MethodResolution: rpcserver.MakeMethodDef(
+			svc.Resolution,
+			"get a resolution and its votes",
+			"the resolution, with its body and voters if it is pending",
+		),
 		userjson.MethodSchema: rpcserver.MakeMethodDef(
 			svc.Schema,
 			"get a deployed database's kuneiform schema definition",
@@ -370,6 +490,10 @@ type EngineReader interface {
 	GetSchema(dbid string) (*types.Schema, error)
 	ListDatasets(owner []byte) ([]*types.DatasetIdentifier, error)
 	Execute(ctx *common.TxContext, tx sql.DB, dbid string, query string, values map[string]any) (*sql.ResultSet, error)
+	Explain(ctx *common.TxContext, tx sql.DB, dbid, query string) (*types.StatementPlan, error)
+	ExplainProcedure(ctx *common.TxContext, tx sql.DB, dbid, procedure string) ([]*types.StatementPlan, error)
+	DeclareCursor(ctx *common.TxContext, tx sql.DB, dbid, query, name string) error
+	FetchCursor(ctx *common.TxContext, tx sql.DB, name string, limit int64) (*sql.ResultSet, error)
 }
 
 // NOTE:
@@ -529,17 +653,24 @@ func (svc *Service) Query(ctx context.Context, req *userjson.QueryRequest) (*use
 	defer readTx.Rollback(ctx)
 
 	result, err := svc.engine.Execute(&common.TxContext{
-		Ctx: ctxExec,
+		Ctx: sql.WithMaxRows(ctxExec, svc.maxRows),
 		BlockContext: &common.BlockContext{
 			Height: -1, // cannot know the height here.
 		},
 	}, readTx, req.DBID, req.Query, nil)
+	if errors.Is(err, sql.ErrTooManyRows) {
+		return nil, svc.tooManyRows()
+	}
 	if err != nil {
 		// We don't know for sure that it's an invalid argument, but an invalid
 		// user-provided query isn't an internal server error.
 		return nil, engineError(err)
 	}
 
+	if jsonErr := svc.checkMaxRows(result); jsonErr != nil {
+		return nil, jsonErr
+	}
+
 	bts, err := json.Marshal(resultMap(result)) // marshalling the map is less efficient, but necessary for backwards compatibility
 	if err != nil {
 		return nil, jsonrpc.NewError(jsonrpc.ErrorResultEncoding, "failed to marshal call result", nil)
@@ -550,6 +681,55 @@ func (svc *Service) Query(ctx context.Context, req *userjson.QueryRequest) (*use
 	}, nil
 }
 
+// Explain is the handler for the user.explain RPC. It plans, but does not
+// execute, either an ad-hoc query or each SQL statement in a procedure.
+func (svc *Service) Explain(ctx context.Context, req *userjson.ExplainRequest) (*userjson.ExplainResponse, *jsonrpc.Error) {
+	if !svc.enableExplain {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorExplainDisabled, "explain is disabled on this node", nil)
+	}
+
+	if svc.privateMode { // the plans reveal the data distribution via row estimates
+		return nil, jsonrpc.NewError(jsonrpc.ErrorNoQueryWithPrivateRPC,
+			"explain is prohibited when authenticated calls are enforced (private mode)", nil)
+	}
+
+	if (req.Query == "") == (req.Procedure == "") {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "exactly one of query or procedure is required", nil)
+	}
+
+	ctxExec, cancel := context.WithTimeout(ctx, svc.readTxTimeout)
+	defer cancel()
+
+	readTx := svc.db.BeginDelayedReadTx()
+	defer readTx.Rollback(ctx)
+
+	txCtx := &common.TxContext{
+		Ctx: ctxExec,
+		BlockContext: &common.BlockContext{
+			Height: -1, // cannot know the height here.
+		},
+	}
+
+	if req.Query != "" {
+		plan, err := svc.engine.Explain(txCtx, readTx, req.DBID, req.Query)
+		if err != nil {
+			return nil, engineError(err)
+		}
+		return &userjson.ExplainResponse{
+			Plans: []*types.StatementPlan{plan},
+		}, nil
+	}
+
+	plans, err := svc.engine.ExplainProcedure(txCtx, readTx, req.DBID, req.Procedure)
+	if err != nil {
+		return nil, engineError(err)
+	}
+
+	return &userjson.ExplainResponse{
+		Plans: plans,
+	}, nil
+}
+
 func (svc *Service) Account(ctx context.Context, req *userjson.AccountRequest) (*userjson.AccountResponse, *jsonrpc.Error) {
 	// Status is presently just 0 for confirmed and 1 for pending, but there may
 	// be others such as finalized and safe.
@@ -666,6 +846,22 @@ func unmarshalActionCall(req *userjson.CallRequest) (*transactions.ActionCall, *
 	return &actionPayload, &cm, nil
 }
 
+// checkMaxRows returns an error if the result has more than the configured
+// maximum number of rows. Rows are limited as they are read from the database,
+// so this only catches results that were not read with the limit.
+func (svc *Service) checkMaxRows(r *sql.ResultSet) *jsonrpc.Error {
+	if svc.maxRows > 0 && int64(len(r.Rows)) > svc.maxRows {
+		return svc.tooManyRows()
+	}
+	return nil
+}
+
+func (svc *Service) tooManyRows() *jsonrpc.Error {
+	return jsonrpc.NewError(jsonrpc.ErrorTooManyRows,
+		fmt.Sprintf("result exceeds the limit of %d rows (use %s for ad-hoc queries)",
+			svc.maxRows, userjson.MethodQueryPage), nil)
+}
+
 func resultMap(r *sql.ResultSet) []map[string]any {
 	m := make([]map[string]any, len(r.Rows))
 	for i, row := range r.Rows {
@@ -796,7 +992,7 @@ func (svc *Service) Call(ctx context.Context, req *userjson.CallRequest) (*userj
 	}()
 
 	executeResult, err := svc.engine.Procedure(&common.TxContext{
-		Ctx:    ctxExec,
+		Ctx:    sql.WithMaxRows(ctxExec, svc.maxRows),
 		Signer: signer,
 		Caller: caller,
 		BlockContext: &common.BlockContext{
@@ -808,10 +1004,17 @@ func (svc *Service) Call(ctx context.Context, req *userjson.CallRequest) (*userj
 		Procedure: body.Action,
 		Args:      args,
 	})
+	if errors.Is(err, sql.ErrTooManyRows) {
+		return nil, svc.tooManyRows()
+	}
 	if err != nil {
 		return nil, engineError(err)
 	}
 
+	if jsonErr := svc.checkMaxRows(executeResult); jsonErr != nil {
+		return nil, jsonErr
+	}
+
 	err = done(ctx)
 	if err != nil {
 		return nil, jsonrpc.NewError(jsonrpc.ErrorNodeInternal, "failed to unsubscribe from notices", nil)
@@ -0,0 +1,34 @@
+package usersvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kwilteam/kwil-db/core/log"
+	jsonrpc "github.com/kwilteam/kwil-db/core/rpc/json"
+	userjson "github.com/kwilteam/kwil-db/core/rpc/json/user"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
+)
+
+// WithdrawalProof is the handler for the user.withdrawal_proof RPC. It returns
+// the Merkle proof of a withdrawal and the validator signatures of its epoch's
+// root, which are given to the bridge contract to release the tokens.
+func (svc *Service) WithdrawalProof(ctx context.Context, req *userjson.WithdrawalProofRequest) (*userjson.WithdrawalProofResponse, *jsonrpc.Error) {
+	if len(req.TxHash) == 0 {
+		return nil, jsonrpc.NewError(jsonrpc.ErrorInvalidParams, "missing tx hash", nil)
+	}
+
+	readTx := svc.db.BeginDelayedReadTx()
+	defer readTx.Rollback(ctx)
+
+	proof, err := withdrawals.GetProof(ctx, readTx, req.TxHash)
+	if err != nil {
+		if errors.Is(err, withdrawals.ErrWithdrawalNotFound) {
+			return nil, jsonrpc.NewError(jsonrpc.ErrorWithdrawalNotFound, err.Error(), nil)
+		}
+		svc.log.Error("failed to get withdrawal proof", log.Error(err))
+		return nil, jsonrpc.NewError(jsonrpc.ErrorDBInternal, "failed to get withdrawal proof", nil)
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,15 @@
+package sessions
+
+import "errors"
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExists   = errors.New("session key already registered")
+	ErrSessionRevoked  = errors.New("session revoked")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrNotInScope      = errors.New("not permitted by session")
+	ErrNotDelegator    = errors.New("sender did not create the session")
+	ErrBudgetExceeded  = errors.New("session fee budget exceeded")
+	ErrKeyType         = errors.New("transaction not signed with the session key's type")
+	ErrConvertToBigInt = errors.New("could not convert to big int")
+)
@@ -0,0 +1,170 @@
+// Package sessions stores session keys. A session key is authorized by an
+// account to execute procedures and actions on its behalf, within a limited
+// scope, fee budget, and lifetime.
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/internal/sql/versioning"
+)
+
+// Session is a session key's authorization.
+type Session struct {
+	// Key is the identity of the session key, which is the sender of its
+	// transactions.
+	Key []byte
+	// Delegator is the identity of the account that created the session, and
+	// that the session key's transactions execute as.
+	Delegator []byte
+	// DelegatorType is the authenticator type of the delegator.
+	DelegatorType string
+	// KeyType is the authenticator type of the session key. Its transactions
+	// must be signed with this type, since an identity may be valid for more
+	// than one authenticator.
+	KeyType string
+	// Scopes are the datasets, and optionally procedures, that the session key
+	// may execute.
+	Scopes []*transactions.SessionScope
+	// MaxFee is the total fees that the delegator will pay for the session
+	// key's transactions.
+	MaxFee *big.Int
+	// Spent is the total fees paid for the session key's transactions.
+	Spent *big.Int
+	// Expiry is the last block height at which the session is valid.
+	Expiry int64
+	// Revoked is true if the delegator revoked the session.
+	Revoked bool
+}
+
+// InitializeSessionStore initializes the session store schema and tables.
+func InitializeSessionStore(ctx context.Context, db sql.DB) error {
+	upgradeFns := map[int64]versioning.UpgradeFunc{
+		0: initTables,
+	}
+
+	err := versioning.Upgrade(ctx, db, schemaName, upgradeFns, sessionStoreVersion)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateSession stores a new session. A session key may only be used for one
+// session, so if the key has ever been registered, ErrSessionExists is
+// returned.
+func CreateSession(ctx context.Context, tx sql.Executor, s *Session) error {
+	_, err := getSession(ctx, tx, s.Key)
+	if err == nil {
+		return ErrSessionExists
+	}
+	if !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+
+	return createSession(ctx, tx, s)
+}
+
+// GetSession retrieves the session for a session key. If the key is not a
+// session key, ErrSessionNotFound is returned.
+func GetSession(ctx context.Context, tx sql.Executor, key []byte) (*Session, error) {
+	return getSession(ctx, tx, key)
+}
+
+// RevokeSession revokes a session. Only the delegator may revoke it.
+func RevokeSession(ctx context.Context, tx sql.Executor, delegator, key []byte) error {
+	s, err := getSession(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+
+	if string(s.Delegator) != string(delegator) {
+		return ErrNotDelegator
+	}
+
+	return revokeSession(ctx, tx, key)
+}
+
+// Spend records fees paid for a session key's transaction. If the fees would
+// exceed the session's budget, ErrBudgetExceeded is returned and nothing is
+// recorded.
+func Spend(ctx context.Context, tx sql.Executor, key []byte, amount *big.Int) error {
+	s, err := getSession(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+
+	if amount.Cmp(s.Remaining()) > 0 {
+		return fmt.Errorf("%w: session has %s remaining, but tried to spend %s",
+			ErrBudgetExceeded, s.Remaining(), amount)
+	}
+
+	return updateSessionSpent(ctx, tx, key, new(big.Int).Add(s.Spent, amount))
+}
+
+// Remaining returns the fees that the delegator will still pay for the session
+// key's transactions.
+func (s *Session) Remaining() *big.Int {
+	remaining := new(big.Int).Sub(s.MaxFee, s.Spent)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// Authorize checks that the session permits a transaction signed by the
+// session key at the given block height. Session keys may only execute
+// procedures and actions in the session's scopes, and only with signatures of
+// the type that consented to the session.
+func (s *Session) Authorize(height int64, tx *transactions.Transaction) error {
+	if tx.Signature == nil || tx.Signature.Type != s.KeyType {
+		return fmt.Errorf("%w: session key is a %s key", ErrKeyType, s.KeyType)
+	}
+	if s.Revoked {
+		return ErrSessionRevoked
+	}
+	if height > s.Expiry {
+		return fmt.Errorf("%w at height %d", ErrSessionExpired, s.Expiry)
+	}
+
+	if tx.Body.PayloadType != transactions.PayloadTypeExecute {
+		return fmt.Errorf("%w: session keys cannot send %s transactions", ErrNotInScope, tx.Body.PayloadType)
+	}
+
+	action := &transactions.ActionExecution{}
+	if err := action.UnmarshalBinary(tx.Body.Payload); err != nil {
+		return err
+	}
+
+	if !s.allows(action.DBID, action.Action) {
+		return fmt.Errorf("%w: %s in dataset %s", ErrNotInScope, action.Action, action.DBID)
+	}
+
+	return nil
+}
+
+// allows returns true if any of the session's scopes include the procedure or
+// action in the dataset.
+func (s *Session) allows(dbid, action string) bool {
+	for _, scope := range s.Scopes {
+		if !strings.EqualFold(scope.DBID, dbid) {
+			continue
+		}
+		if len(scope.Procedures) == 0 {
+			return true
+		}
+		for _, proc := range scope.Procedures {
+			if strings.EqualFold(proc, action) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockDB stores session rows in memory, handling the queries used by this
+// package.
+type mockDB struct {
+	rows map[string][]any // session_key => row from sqlGetSession
+}
+
+func newDB() *mockDB {
+	return &mockDB{
+		rows: make(map[string][]any),
+	}
+}
+
+func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	switch stmt {
+	case sqlCreateSession:
+		key := args[0].([]byte)
+		m.rows[string(key)] = []any{args[1], args[2], args[3], args[4], args[5], args[6], args[7], false}
+		return &sql.ResultSet{}, nil
+	case sqlGetSession:
+		key := args[0].([]byte)
+		row, ok := m.rows[string(key)]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{row}}, nil
+	case sqlUpdateSessionSpent:
+		m.rows[string(args[1].([]byte))][5] = args[0]
+		return &sql.ResultSet{}, nil
+	case sqlRevokeSession:
+		m.rows[string(args[0].([]byte))][7] = true
+		return &sql.ResultSet{}, nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %s", stmt)
+	}
+}
+
+func newSession() *Session {
+	return &Session{
+		Key:           []byte("session"),
+		Delegator:     []byte("delegator"),
+		DelegatorType: "secp256k1_ep",
+		KeyType:       auth.Ed25519Auth,
+		Scopes: []*transactions.SessionScope{
+			{DBID: "xdbid", Procedures: []string{"post"}},
+			{DBID: "ydbid", Procedures: []string{}},
+		},
+		MaxFee: big.NewInt(100),
+		Spent:  big.NewInt(0),
+		Expiry: 10,
+	}
+}
+
+func executeTx(t *testing.T, dbid, action string) *transactions.Transaction {
+	tx, err := transactions.CreateTransaction(&transactions.ActionExecution{
+		DBID:   dbid,
+		Action: action,
+	}, "chainid", 1)
+	require.NoError(t, err)
+	tx.Signature = &auth.Signature{Type: auth.Ed25519Auth}
+	return tx
+}
+
+func Test_Sessions(t *testing.T) {
+	ctx := context.Background()
+	db := newDB()
+
+	err := CreateSession(ctx, db, newSession())
+	require.NoError(t, err)
+
+	err = CreateSession(ctx, db, newSession())
+	require.ErrorIs(t, err, ErrSessionExists)
+
+	s, err := GetSession(ctx, db, []byte("session"))
+	require.NoError(t, err)
+	require.Equal(t, newSession(), s)
+
+	_, err = GetSession(ctx, db, []byte("other"))
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	err = Spend(ctx, db, []byte("session"), big.NewInt(60))
+	require.NoError(t, err)
+
+	err = Spend(ctx, db, []byte("session"), big.NewInt(60))
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+
+	s, err = GetSession(ctx, db, []byte("session"))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(40), s.Remaining())
+
+	err = RevokeSession(ctx, db, []byte("other"), []byte("session"))
+	require.ErrorIs(t, err, ErrNotDelegator)
+
+	err = RevokeSession(ctx, db, []byte("delegator"), []byte("session"))
+	require.NoError(t, err)
+
+	s, err = GetSession(ctx, db, []byte("session"))
+	require.NoError(t, err)
+	require.True(t, s.Revoked)
+}
+
+func Test_Authorize(t *testing.T) {
+	transfer, err := transactions.CreateTransaction(&transactions.Transfer{
+		To:     []byte("to"),
+		Amount: "1",
+	}, "chainid", 1)
+	require.NoError(t, err)
+	transfer.Signature = &auth.Signature{Type: auth.Ed25519Auth}
+
+	otherType := executeTx(t, "xdbid", "post")
+	otherType.Signature.Type = auth.EthPersonalSignAuth
+
+	type testcase struct {
+		name    string
+		session func(s *Session)
+		height  int64
+		tx      *transactions.Transaction
+		err     error
+	}
+
+	tests := []testcase{
+		{
+			name:   "scoped procedure",
+			height: 10,
+			tx:     executeTx(t, "xdbid", "POST"),
+		},
+		{
+			name:   "any procedure in dataset",
+			height: 1,
+			tx:     executeTx(t, "ydbid", "delete_all"),
+		},
+		{
+			name:   "procedure not in scope",
+			height: 1,
+			tx:     executeTx(t, "xdbid", "delete_all"),
+			err:    ErrNotInScope,
+		},
+		{
+			name:   "dataset not in scope",
+			height: 1,
+			tx:     executeTx(t, "zdbid", "post"),
+			err:    ErrNotInScope,
+		},
+		{
+			name:   "not an execute transaction",
+			height: 1,
+			tx:     transfer,
+			err:    ErrNotInScope,
+		},
+		{
+			name:   "expired",
+			height: 11,
+			tx:     executeTx(t, "xdbid", "post"),
+			err:    ErrSessionExpired,
+		},
+		{
+			name: "revoked",
+			session: func(s *Session) {
+				s.Revoked = true
+			},
+			height: 1,
+			tx:     executeTx(t, "xdbid", "post"),
+			err:    ErrSessionRevoked,
+		},
+		{
+			name:   "signed with another key type",
+			height: 1,
+			tx:     otherType,
+			err:    ErrKeyType,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newSession()
+			if tc.session != nil {
+				tc.session(s)
+			}
+
+			err := s.Authorize(tc.height, tc.tx)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+)
+
+const (
+	schemaName = `kwild_sessions`
+
+	sessionStoreVersion = 0
+
+	sqlInitTables = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.sessions (
+		session_key BYTEA PRIMARY KEY, -- the identity of the session key
+		delegator BYTEA NOT NULL, -- the identity of the account that created the session
+		delegator_type TEXT NOT NULL, -- the authenticator type of the delegator
+		key_type TEXT NOT NULL, -- the authenticator type of the session key
+		scopes BYTEA NOT NULL, -- the serialized []*transactions.SessionScope
+		max_fee TEXT NOT NULL, -- big.Int
+		spent TEXT NOT NULL, -- big.Int
+		expiry INT8 NOT NULL, -- the last block height at which the session is valid
+		revoked BOOLEAN NOT NULL DEFAULT FALSE
+	);`
+
+	sqlCreateSession = `INSERT INTO ` + schemaName + `.sessions (session_key, delegator, delegator_type, key_type, scopes, max_fee, spent, expiry)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	sqlGetSession = `SELECT delegator, delegator_type, key_type, scopes, max_fee, spent, expiry, revoked
+		FROM ` + schemaName + `.sessions WHERE session_key = $1`
+
+	sqlUpdateSessionSpent = `UPDATE ` + schemaName + `.sessions SET spent = $1 WHERE session_key = $2`
+
+	sqlRevokeSession = `UPDATE ` + schemaName + `.sessions SET revoked = TRUE WHERE session_key = $1`
+)
+
+func initTables(ctx context.Context, tx sql.DB) error {
+	_, err := tx.Execute(ctx, sqlInitTables)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	return nil
+}
+
+// createSession inserts a new session.
+func createSession(ctx context.Context, db sql.Executor, s *Session) error {
+	scopes, err := serialize.Encode(s.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Execute(ctx, sqlCreateSession, s.Key, s.Delegator, s.DelegatorType, s.KeyType,
+		[]byte(scopes), s.MaxFee.String(), s.Spent.String(), s.Expiry)
+	return err
+}
+
+// getSession retrieves a session from the database.
+// if the session is not found, it returns nil, ErrSessionNotFound.
+func getSession(ctx context.Context, db sql.Executor, key []byte) (*Session, error) {
+	results, err := db.Execute(ctx, sqlGetSession, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.Rows) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	if len(results.Rows) > 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(results.Rows))
+	}
+
+	row := results.Rows[0]
+
+	delegator, ok := row[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored delegator to bytes")
+	}
+
+	delegatorType, ok := row[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored delegator type to string")
+	}
+
+	keyType, ok := row[2].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored key type to string")
+	}
+
+	scopeBts, ok := row[3].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored scopes to bytes")
+	}
+	var scopes []*transactions.SessionScope
+	if err = serialize.Decode(scopeBts, &scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode stored scopes: %w", err)
+	}
+
+	maxFee, err := toBigInt(row[4])
+	if err != nil {
+		return nil, err
+	}
+
+	spent, err := toBigInt(row[5])
+	if err != nil {
+		return nil, err
+	}
+
+	expiry, ok := row[6].(int64)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored expiry to int64")
+	}
+
+	revoked, ok := row[7].(bool)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored revoked to bool")
+	}
+
+	return &Session{
+		Key:           key,
+		Delegator:     delegator,
+		DelegatorType: delegatorType,
+		KeyType:       keyType,
+		Scopes:        scopes,
+		MaxFee:        maxFee,
+		Spent:         spent,
+		Expiry:        expiry,
+		Revoked:       revoked,
+	}, nil
+}
+
+// updateSessionSpent sets the total fees spent by a session key.
+func updateSessionSpent(ctx context.Context, db sql.Executor, key []byte, spent *big.Int) error {
+	_, err := db.Execute(ctx, sqlUpdateSessionSpent, spent.String(), key)
+	return err
+}
+
+// revokeSession marks a session as revoked.
+func revokeSession(ctx context.Context, db sql.Executor, key []byte) error {
+	_, err := db.Execute(ctx, sqlRevokeSession, key)
+	return err
+}
+
+func toBigInt(v any) (*big.Int, error) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored string amount to big int")
+	}
+
+	amt, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil, ErrConvertToBigInt
+	}
+	return amt, nil
+}
@@ -129,7 +129,55 @@ func NewPool(ctx context.Context, cfg *PoolConfig) (*Pool, error) {
 
 	// NOTE: we can consider changing the default exec mode at construction e.g.:
 	// pCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
-	pCfg.ConnConfig.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) {
+	setNoticeHandlers(pCfg.ConnConfig, subscribers)
+
+	pCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		return registerTypes(ctx, conn)
+	}
+
+	db, err := pgxpool.NewWithConfig(ctx, pCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	writerCfg := pCfg.Copy()
+	writerCfg.MaxConns = 2 // just one should be fine, but keep a pair for faster reconnect if it needs reconnect
+	writer, err := pgxpool.NewWithConfig(ctx, writerCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	reservedCfg := pCfg.Copy()
+	reservedCfg.MaxConns = 2 // just one should be fine, but keep a pair for faster reconnect if it needs reconnect
+	reserved, err := pgxpool.NewWithConfig(ctx, reservedCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// acquire a writer to determine the OID of the custom types
+	writerConn, err := writer.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer writerConn.Release()
+	oidTypes := oidTypesMap(writerConn.Conn().TypeMap())
+
+	pool := &Pool{
+		readers:     db,
+		writer:      writer,
+		reserved:    reserved,
+		idTypes:     oidTypes,
+		subscribers: subscribers,
+	}
+
+	return pool, db.Ping(ctx)
+}
+
+// setNoticeHandlers sets the handlers for notices and errors from postgres. The
+// handlers log the messages, and deliver the notices raised with the notice()
+// function to the subscriber for the originating transaction.
+func setNoticeHandlers(cfg *pgx.ConnConfig, subscribers *syncmap.Map[int64, chan<- string]) {
+	cfg.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) {
 		// Handling the Listen system.
 
 		// if this is the final log for a transaction, send a notice to the subscribers
@@ -170,8 +218,8 @@ func NewPool(ctx context.Context, cfg *PoolConfig) (*Pool, error) {
 			logger.Logf(level, "%v [%v]: %v / %v", n.Severity, n.Code, n.Message, n.Detail)
 		}
 	}
-	defaultOnPgError := pCfg.ConnConfig.OnPgError
-	pCfg.ConnConfig.OnPgError = func(c *pgconn.PgConn, n *pgconn.PgError) bool {
+	defaultOnPgError := cfg.OnPgError
+	cfg.OnPgError = func(c *pgconn.PgConn, n *pgconn.PgError) bool {
 		level := log.WarnLevel
 		if strings.HasPrefix(n.SchemaName, "ds_") { // user query error
 			level = log.DebugLevel
@@ -187,47 +235,6 @@ func NewPool(ctx context.Context, cfg *PoolConfig) (*Pool, error) {
 		}
 		return defaultOnPgError(c, n) // automatically close any fatal errors (default we are overridding)
 	}
-
-	pCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		return registerTypes(ctx, conn)
-	}
-
-	db, err := pgxpool.NewWithConfig(ctx, pCfg)
-	if err != nil {
-		return nil, err
-	}
-
-	writerCfg := pCfg.Copy()
-	writerCfg.MaxConns = 2 // just one should be fine, but keep a pair for faster reconnect if it needs reconnect
-	writer, err := pgxpool.NewWithConfig(ctx, writerCfg)
-	if err != nil {
-		return nil, err
-	}
-
-	reservedCfg := pCfg.Copy()
-	reservedCfg.MaxConns = 2 // just one should be fine, but keep a pair for faster reconnect if it needs reconnect
-	reserved, err := pgxpool.NewWithConfig(ctx, reservedCfg)
-	if err != nil {
-		return nil, err
-	}
-
-	// acquire a writer to determine the OID of the custom types
-	writerConn, err := writer.Acquire(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer writerConn.Release()
-	oidTypes := oidTypesMap(writerConn.Conn().TypeMap())
-
-	pool := &Pool{
-		readers:     db,
-		writer:      writer,
-		reserved:    reserved,
-		idTypes:     oidTypes,
-		subscribers: subscribers,
-	}
-
-	return pool, db.Ping(ctx)
 }
 
 // registerTypes ensures that the custom types used by Kwil are registered with
@@ -238,6 +245,12 @@ func registerTypes(ctx context.Context, conn *pgx.Conn) error {
 		return err
 	}
 
+	return loadTypes(ctx, conn)
+}
+
+// loadTypes registers the custom types used by Kwil with the pgx connection.
+// The types must already exist, as they do on a read replica.
+func loadTypes(ctx context.Context, conn *pgx.Conn) error {
 	// PostgreSQL "domains" will use codec of the underlying type, but the
 	// dynamic OID of the custom domain needs to be registered with pgx.
 
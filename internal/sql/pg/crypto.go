@@ -0,0 +1,573 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The cryptographic functions below back the keccak256, ecrecover,
+// ed25519_verify, and eth_address built-in functions. pgcrypto has no keccak
+// or elliptic curve support, so they are implemented in plpgsql with the
+// NUMERIC type for field arithmetic. They are deterministic, but expensive
+// relative to most built-in functions, so each call uses gas, which is added to
+// the GasUsedSetting of the transaction. The engine adds it to the gas used by
+// an execution, and a call fails once MaxGas is used. The gas of a call is:
+//
+//	keccak256:      100 + 100 per 136 byte block of the (padded) input
+//	eth_address:    2000
+//	ecrecover:      5000
+//	ed25519_verify: 5000 + 1 per 64 bytes of the message
+//
+// Since they set the gas used, the functions are VOLATILE and PARALLEL UNSAFE.
+// Their COST is the estimate used by the PostgreSQL planner. Schemas may use
+// them once the "crypto_funcs" hard fork is active.
+//
+// The kwil_ prefixed functions are helpers that are not exposed to procedures.
+
+// GasUsedSetting is the transaction-local setting that holds the gas used by
+// the cryptographic functions.
+const GasUsedSetting = "kwil.gas_used"
+
+// MaxGas is the limit of the gas used by an execution, after which a
+// procedure call or a call to a cryptographic function fails.
+const MaxGas = 10000000
+
+const (
+	// kwil_use_gas adds to the gas used in the transaction, failing if the
+	// limit is reached.
+	sqlCreateFuncUseGas = `CREATE OR REPLACE FUNCTION kwil_use_gas(amount INT8)
+	RETURNS VOID AS $$
+	DECLARE
+		used INT8 := COALESCE(NULLIF(current_setting('kwil.gas_used', true), ''), '0')::INT8 + amount;
+	BEGIN
+		IF used >= 10000000 THEN
+			RAISE EXCEPTION 'out of gas';
+		END IF;
+		PERFORM set_config('kwil.gas_used', used::TEXT, true);
+	END;
+	$$ LANGUAGE plpgsql VOLATILE PARALLEL UNSAFE;`
+
+	// kwil_keccak256 is the original Keccak-256 (0x01 padding) used by Ethereum,
+	// not the NIST SHA3-256. The state is 25 little-endian 64-bit lanes held
+	// as INT8, which wraps on shifts.
+	sqlCreateFuncKwilKeccak256 = `CREATE OR REPLACE FUNCTION kwil_keccak256(data BYTEA)
+	RETURNS BYTEA AS $$
+	DECLARE
+		rc INT8[] := ARRAY[1, 32898, -9223372036854742902, -9223372034707259392, 32907, 2147483649,
+			-9223372034707259263, -9223372036854743031, 138, 136, 2147516425, 2147483658,
+			2147516555, -9223372036854775669, -9223372036854742903, -9223372036854743037,
+			-9223372036854743038, -9223372036854775680, 32778, -9223372034707292150,
+			-9223372034707259263, -9223372036854742912, 2147483649, -9223372034707259384]::INT8[];
+		rot INT[] := ARRAY[0, 1, 62, 28, 27, 36, 44, 6, 55, 20, 3, 10, 43, 25, 39,
+			41, 45, 15, 21, 8, 18, 2, 61, 56, 14];
+		st INT8[] := array_fill(0::INT8, ARRAY[25]);
+		b INT8[] := array_fill(0::INT8, ARRAY[25]);
+		c INT8[] := array_fill(0::INT8, ARRAY[5]);
+		msg_len INT := length(data);
+		pad INT := 136 - length(data) % 136;
+		msg BYTEA;
+		lane INT8;
+		d INT8;
+		n INT;
+		result BYTEA;
+	BEGIN
+		msg := data || decode(repeat('00', pad), 'hex');
+		msg := set_byte(msg, msg_len, get_byte(msg, msg_len) # 1);
+		msg := set_byte(msg, msg_len + pad - 1, get_byte(msg, msg_len + pad - 1) # 128);
+
+		FOR blk IN 0 .. (msg_len + pad) / 136 - 1 LOOP
+			-- absorb the block into the first 17 lanes
+			FOR i IN 0 .. 16 LOOP
+				lane := 0;
+				FOR j IN 0 .. 7 LOOP
+					lane := lane | (get_byte(msg, blk * 136 + i * 8 + j)::INT8 << (8 * j));
+				END LOOP;
+				st[i + 1] := st[i + 1] # lane;
+			END LOOP;
+
+			FOR rnd IN 1 .. 24 LOOP
+				-- theta
+				FOR x IN 0 .. 4 LOOP
+					c[x + 1] := st[x + 1] # st[x + 6] # st[x + 11] # st[x + 16] # st[x + 21];
+				END LOOP;
+				FOR x IN 0 .. 4 LOOP
+					d := c[(x + 4) % 5 + 1] # ((c[(x + 1) % 5 + 1] << 1) | ((c[(x + 1) % 5 + 1] >> 63) & 1));
+					FOR y IN 0 .. 4 LOOP
+						st[x + 5 * y + 1] := st[x + 5 * y + 1] # d;
+					END LOOP;
+				END LOOP;
+				-- rho and pi
+				FOR x IN 0 .. 4 LOOP
+					FOR y IN 0 .. 4 LOOP
+						lane := st[x + 5 * y + 1];
+						n := rot[x + 5 * y + 1];
+						IF n > 0 THEN
+							lane := (lane << n) | ((lane >> (64 - n)) & (~((-1)::INT8 << n)));
+						END IF;
+						b[y + 5 * ((2 * x + 3 * y) % 5) + 1] := lane;
+					END LOOP;
+				END LOOP;
+				-- chi
+				FOR x IN 0 .. 4 LOOP
+					FOR y IN 0 .. 4 LOOP
+						st[x + 5 * y + 1] := b[x + 5 * y + 1] # ((~b[(x + 1) % 5 + 5 * y + 1]) & b[(x + 2) % 5 + 5 * y + 1]);
+					END LOOP;
+				END LOOP;
+				-- iota
+				st[1] := st[1] # rc[rnd];
+			END LOOP;
+		END LOOP;
+
+		result := decode(repeat('00', 32), 'hex');
+		FOR i IN 0 .. 3 LOOP
+			FOR j IN 0 .. 7 LOOP
+				result := set_byte(result, i * 8 + j, ((st[i + 1] >> (8 * j)) & 255)::INT);
+			END LOOP;
+		END LOOP;
+		RETURN result;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE COST 1000;`
+
+	sqlCreateFuncKeccak256 = `CREATE OR REPLACE FUNCTION keccak256(data BYTEA)
+	RETURNS BYTEA AS $$
+	BEGIN
+		PERFORM kwil_use_gas(100 + 100 * (length(data) / 136 + 1));
+		RETURN kwil_keccak256(data);
+	END;
+	$$ LANGUAGE plpgsql VOLATILE STRICT PARALLEL UNSAFE COST 1000;`
+
+	// Like digest, text is hashed as its UTF-8 bytes.
+	sqlCreateFuncKeccak256Text = `CREATE OR REPLACE FUNCTION keccak256(data TEXT)
+	RETURNS BYTEA AS $$
+		SELECT keccak256(convert_to(data, 'UTF8'));
+	$$ LANGUAGE sql VOLATILE STRICT PARALLEL UNSAFE COST 1000;`
+
+	// kwil_bytes_to_num reads a big-endian unsigned integer.
+	sqlCreateFuncBytesToNum = `CREATE OR REPLACE FUNCTION kwil_bytes_to_num(b BYTEA)
+	RETURNS NUMERIC AS $$
+	DECLARE
+		n NUMERIC := 0;
+	BEGIN
+		FOR i IN 0 .. length(b) - 1 LOOP
+			n := n * 256 + get_byte(b, i);
+		END LOOP;
+		RETURN n;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_bytes_to_num_le reads a little-endian unsigned integer.
+	sqlCreateFuncBytesToNumLE = `CREATE OR REPLACE FUNCTION kwil_bytes_to_num_le(b BYTEA)
+	RETURNS NUMERIC AS $$
+	DECLARE
+		n NUMERIC := 0;
+	BEGIN
+		FOR i IN REVERSE length(b) - 1 .. 0 LOOP
+			n := n * 256 + get_byte(b, i);
+		END LOOP;
+		RETURN n;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_num_to_bytes writes a non-negative integer as size big-endian bytes.
+	sqlCreateFuncNumToBytes = `CREATE OR REPLACE FUNCTION kwil_num_to_bytes(n NUMERIC, size INT)
+	RETURNS BYTEA AS $$
+	DECLARE
+		result BYTEA := decode(repeat('00', size), 'hex');
+		v NUMERIC := n;
+	BEGIN
+		FOR i IN REVERSE size - 1 .. 0 LOOP
+			result := set_byte(result, i, (v % 256)::INT);
+			v := div(v, 256);
+		END LOOP;
+		RETURN result;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_mod_pow computes base^ex mod m by square-and-multiply.
+	sqlCreateFuncModPow = `CREATE OR REPLACE FUNCTION kwil_mod_pow(base NUMERIC, ex NUMERIC, m NUMERIC)
+	RETURNS NUMERIC AS $$
+	DECLARE
+		result NUMERIC := 1;
+		b NUMERIC := base % m;
+		e NUMERIC := ex;
+	BEGIN
+		WHILE e > 0 LOOP
+			IF e % 2 = 1 THEN
+				result := result * b % m;
+			END IF;
+			b := b * b % m;
+			e := div(e, 2);
+		END LOOP;
+		RETURN result;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// secp256k1 points are in Jacobian coordinates {X, Y, Z}, with Z = 0 for
+	// the point at infinity.
+	sqlCreateFuncSecp256k1Double = `CREATE OR REPLACE FUNCTION kwil_secp256k1_double(pt NUMERIC[])
+	RETURNS NUMERIC[] AS $$
+	DECLARE
+		p NUMERIC := 115792089237316195423570985008687907853269984665640564039457584007908834671663;
+		a NUMERIC;
+		b NUMERIC;
+		c NUMERIC;
+		d NUMERIC;
+		e NUMERIC;
+		f NUMERIC;
+		x3 NUMERIC;
+	BEGIN
+		IF pt[3] = 0 OR pt[2] = 0 THEN
+			RETURN ARRAY[0, 1, 0]::NUMERIC[];
+		END IF;
+		a := pt[1] * pt[1] % p;
+		b := pt[2] * pt[2] % p;
+		c := b * b % p;
+		d := 2 * ((pt[1] + b) * (pt[1] + b) - a - c) % p;
+		e := 3 * a % p;
+		f := e * e % p;
+		x3 := (f - 2 * d + 2 * p) % p;
+		RETURN ARRAY[x3, (e * ((d - x3 + p) % p) + 8 * (p - c)) % p, 2 * pt[2] * pt[3] % p];
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	sqlCreateFuncSecp256k1Add = `CREATE OR REPLACE FUNCTION kwil_secp256k1_add(p1 NUMERIC[], p2 NUMERIC[])
+	RETURNS NUMERIC[] AS $$
+	DECLARE
+		p NUMERIC := 115792089237316195423570985008687907853269984665640564039457584007908834671663;
+		z1z1 NUMERIC;
+		z2z2 NUMERIC;
+		u1 NUMERIC;
+		u2 NUMERIC;
+		s1 NUMERIC;
+		s2 NUMERIC;
+		h NUMERIC;
+		r NUMERIC;
+		hh NUMERIC;
+		hhh NUMERIC;
+		v NUMERIC;
+		x3 NUMERIC;
+	BEGIN
+		IF p1[3] = 0 THEN
+			RETURN p2;
+		END IF;
+		IF p2[3] = 0 THEN
+			RETURN p1;
+		END IF;
+		z1z1 := p1[3] * p1[3] % p;
+		z2z2 := p2[3] * p2[3] % p;
+		u1 := p1[1] * z2z2 % p;
+		u2 := p2[1] * z1z1 % p;
+		s1 := p1[2] * p2[3] * z2z2 % p;
+		s2 := p2[2] * p1[3] * z1z1 % p;
+		IF u1 = u2 THEN
+			IF s1 = s2 THEN
+				RETURN kwil_secp256k1_double(p1);
+			END IF;
+			RETURN ARRAY[0, 1, 0]::NUMERIC[];
+		END IF;
+		h := (u2 - u1 + p) % p;
+		r := (s2 - s1 + p) % p;
+		hh := h * h % p;
+		hhh := h * hh % p;
+		v := u1 * hh % p;
+		x3 := (r * r + 3 * p - hhh - 2 * v) % p;
+		RETURN ARRAY[x3, (r * ((v - x3 + p) % p) + p - s1 * hhh % p) % p, h * p1[3] * p2[3] % p];
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_secp256k1_mul2 computes k1*P1 + k2*P2 with Shamir's trick, and
+	// returns the affine {x, y}, or NULL for the point at infinity.
+	sqlCreateFuncSecp256k1Mul2 = `CREATE OR REPLACE FUNCTION kwil_secp256k1_mul2(k1 NUMERIC, p1 NUMERIC[], k2 NUMERIC, p2 NUMERIC[])
+	RETURNS NUMERIC[] AS $$
+	DECLARE
+		p NUMERIC := 115792089237316195423570985008687907853269984665640564039457584007908834671663;
+		a BYTEA := kwil_num_to_bytes(k1, 32);
+		b BYTEA := kwil_num_to_bytes(k2, 32);
+		p12 NUMERIC[] := kwil_secp256k1_add(p1, p2);
+		r NUMERIC[] := ARRAY[0, 1, 0]::NUMERIC[];
+		bit_a BOOLEAN;
+		bit_b BOOLEAN;
+		zi NUMERIC;
+		zi2 NUMERIC;
+	BEGIN
+		FOR i IN REVERSE 255 .. 0 LOOP
+			r := kwil_secp256k1_double(r);
+			bit_a := (get_byte(a, 31 - i / 8) >> (i % 8)) & 1 = 1;
+			bit_b := (get_byte(b, 31 - i / 8) >> (i % 8)) & 1 = 1;
+			IF bit_a AND bit_b THEN
+				r := kwil_secp256k1_add(r, p12);
+			ELSIF bit_a THEN
+				r := kwil_secp256k1_add(r, p1);
+			ELSIF bit_b THEN
+				r := kwil_secp256k1_add(r, p2);
+			END IF;
+		END LOOP;
+		IF r[3] = 0 THEN
+			RETURN NULL;
+		END IF;
+		zi := kwil_mod_pow(r[3], p - 2, p);
+		zi2 := zi * zi % p;
+		RETURN ARRAY[r[1] * zi2 % p, r[2] * zi2 % p * zi % p];
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_secp256k1_y returns the y coordinate for x with the given parity,
+	// or NULL if x is not on the curve.
+	sqlCreateFuncSecp256k1Y = `CREATE OR REPLACE FUNCTION kwil_secp256k1_y(x NUMERIC, odd BOOLEAN)
+	RETURNS NUMERIC AS $$
+	DECLARE
+		p NUMERIC := 115792089237316195423570985008687907853269984665640564039457584007908834671663;
+		y2 NUMERIC := (x * x % p * x + 7) % p;
+		y NUMERIC;
+	BEGIN
+		y := kwil_mod_pow(y2, 28948022309329048855892746252171976963317496166410141009864396001977208667916, p);
+		IF y * y % p <> y2 THEN
+			RETURN NULL;
+		END IF;
+		IF (y % 2 = 1) <> odd THEN
+			y := p - y;
+		END IF;
+		RETURN y;
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// eth_address returns the 20 byte Ethereum address of a secp256k1 public
+	// key, which may be compressed (33 bytes), uncompressed (65 bytes), or the
+	// raw 64 byte X || Y.
+	sqlCreateFuncEthAddress = `CREATE OR REPLACE FUNCTION eth_address(pubkey BYTEA)
+	RETURNS BYTEA AS $$
+	DECLARE
+		p NUMERIC := 115792089237316195423570985008687907853269984665640564039457584007908834671663;
+		x NUMERIC;
+		y NUMERIC;
+	BEGIN
+		PERFORM kwil_use_gas(2000);
+		IF length(pubkey) = 33 THEN
+			x := kwil_bytes_to_num(substring(pubkey FROM 2 FOR 32));
+			IF get_byte(pubkey, 0) IN (2, 3) AND x < p THEN
+				y := kwil_secp256k1_y(x, get_byte(pubkey, 0) = 3);
+			END IF;
+		ELSIF length(pubkey) = 64 OR length(pubkey) = 65 THEN
+			x := kwil_bytes_to_num(substring(pubkey FROM length(pubkey) - 63 FOR 32));
+			y := kwil_bytes_to_num(substring(pubkey FROM length(pubkey) - 31 FOR 32));
+			IF length(pubkey) = 65 AND get_byte(pubkey, 0) <> 4
+				OR x >= p OR y >= p OR y * y % p <> (x * x % p * x + 7) % p THEN
+				y := NULL;
+			END IF;
+		END IF;
+		IF y IS NULL THEN
+			RAISE EXCEPTION 'invalid secp256k1 public key';
+		END IF;
+		RETURN substring(kwil_keccak256(kwil_num_to_bytes(x, 32) || kwil_num_to_bytes(y, 32)) FROM 13 FOR 20);
+	END;
+	$$ LANGUAGE plpgsql VOLATILE STRICT PARALLEL UNSAFE COST 2000;`
+
+	// ecrecover returns the 20 byte Ethereum address that signed the 32 byte
+	// hash with the 65 byte signature R || S || V, where V is 0, 1, 27, or 28.
+	// It returns NULL if the signature is invalid.
+	sqlCreateFuncEcrecover = `CREATE OR REPLACE FUNCTION ecrecover(hash BYTEA, sig BYTEA)
+	RETURNS BYTEA AS $$
+	DECLARE
+		n NUMERIC := 115792089237316195423570985008687907852837564279074904382605163141518161494337;
+		g NUMERIC[] := ARRAY[55066263022277343669578718895168534326250603453777594175500187360389116729240,
+			32670510020758816978083085130507043184471273380659243275938904335757337482424, 1]::NUMERIC[];
+		v INT;
+		r NUMERIC;
+		s NUMERIC;
+		y NUMERIC;
+		e NUMERIC;
+		ri NUMERIC;
+		q NUMERIC[];
+	BEGIN
+		PERFORM kwil_use_gas(5000);
+		IF length(hash) <> 32 THEN
+			RAISE EXCEPTION 'ecrecover: hash must be 32 bytes, got %', length(hash);
+		END IF;
+		IF length(sig) <> 65 THEN
+			RAISE EXCEPTION 'ecrecover: signature must be 65 bytes, got %', length(sig);
+		END IF;
+
+		v := get_byte(sig, 64);
+		IF v >= 27 THEN
+			v := v - 27;
+		END IF;
+		IF v > 1 THEN
+			RETURN NULL;
+		END IF;
+		r := kwil_bytes_to_num(substring(sig FROM 1 FOR 32));
+		s := kwil_bytes_to_num(substring(sig FROM 33 FOR 32));
+		IF r = 0 OR r >= n OR s = 0 OR s >= n THEN
+			RETURN NULL;
+		END IF;
+		y := kwil_secp256k1_y(r, v = 1);
+		IF y IS NULL THEN
+			RETURN NULL;
+		END IF;
+
+		-- Q = r^-1 (s*R - e*G)
+		e := kwil_bytes_to_num(hash) % n;
+		ri := kwil_mod_pow(r, n - 2, n);
+		q := kwil_secp256k1_mul2((n - e) * ri % n, g, s * ri % n, ARRAY[r, y, 1]::NUMERIC[]);
+		IF q IS NULL THEN
+			RETURN NULL;
+		END IF;
+		RETURN substring(kwil_keccak256(kwil_num_to_bytes(q[1], 32) || kwil_num_to_bytes(q[2], 32)) FROM 13 FOR 20);
+	END;
+	$$ LANGUAGE plpgsql VOLATILE STRICT PARALLEL UNSAFE COST 5000;`
+
+	// ed25519 points are in extended coordinates {X, Y, Z, T}. The addition
+	// formula is complete, so it is also used for doubling.
+	sqlCreateFuncEd25519Add = `CREATE OR REPLACE FUNCTION kwil_ed25519_add(p1 NUMERIC[], p2 NUMERIC[])
+	RETURNS NUMERIC[] AS $$
+	DECLARE
+		p NUMERIC := 57896044618658097711785492504343953926634992332820282019728792003956564819949;
+		d2 NUMERIC := 16295367250680780974490674513165176452449235426866156013048779062215315747161;
+		a NUMERIC := (p1[2] - p1[1] + p) % p * ((p2[2] - p2[1] + p) % p) % p;
+		b NUMERIC := (p1[2] + p1[1]) * (p2[2] + p2[1]) % p;
+		c NUMERIC := p1[4] * d2 % p * p2[4] % p;
+		d NUMERIC := 2 * p1[3] * p2[3] % p;
+		e NUMERIC := (b - a + p) % p;
+		f NUMERIC := (d - c + p) % p;
+		g NUMERIC := (d + c) % p;
+		h NUMERIC := (b + a) % p;
+	BEGIN
+		RETURN ARRAY[e * f % p, g * h % p, f * g % p, e * h % p];
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// kwil_ed25519_decode decodes a compressed point, or returns NULL if it is
+	// not on the curve. Like Go's crypto/ed25519, non-canonical encodings of y
+	// are accepted.
+	sqlCreateFuncEd25519Decode = `CREATE OR REPLACE FUNCTION kwil_ed25519_decode(enc BYTEA)
+	RETURNS NUMERIC[] AS $$
+	DECLARE
+		p NUMERIC := 57896044618658097711785492504343953926634992332820282019728792003956564819949;
+		d NUMERIC := 37095705934669439343138083508754565189542113879843219016388785533085940283555;
+		sqrtm1 NUMERIC := 19681161376707505956807079304988542015446066515923890162744021073123829784752;
+		sign INT := get_byte(enc, 31) >> 7;
+		y NUMERIC := kwil_bytes_to_num_le(set_byte(enc, 31, get_byte(enc, 31) & 127)) % p;
+		x2 NUMERIC;
+		x NUMERIC;
+	BEGIN
+		-- x^2 = (y^2 - 1) / (d*y^2 + 1)
+		x2 := (y * y - 1 + p) % p * kwil_mod_pow((d * y % p * y + 1) % p, p - 2, p) % p;
+		x := kwil_mod_pow(x2, 7237005577332262213973186563042994240829374041602535252466099000494570602494, p);
+		IF x * x % p <> x2 THEN
+			x := x * sqrtm1 % p;
+		END IF;
+		IF x * x % p <> x2 THEN
+			RETURN NULL;
+		END IF;
+		IF x % 2 <> sign THEN
+			x := (p - x) % p;
+		END IF;
+		RETURN ARRAY[x, y, 1, x * y % p];
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	sqlCreateFuncEd25519Encode = `CREATE OR REPLACE FUNCTION kwil_ed25519_encode(pt NUMERIC[])
+	RETURNS BYTEA AS $$
+	DECLARE
+		p NUMERIC := 57896044618658097711785492504343953926634992332820282019728792003956564819949;
+		zi NUMERIC := kwil_mod_pow(pt[3], p - 2, p);
+		x NUMERIC := pt[1] * zi % p;
+		v NUMERIC := pt[2] * zi % p;
+		result BYTEA := decode(repeat('00', 32), 'hex');
+	BEGIN
+		FOR i IN 0 .. 31 LOOP
+			result := set_byte(result, i, (v % 256)::INT);
+			v := div(v, 256);
+		END LOOP;
+		RETURN set_byte(result, 31, get_byte(result, 31) | ((x % 2)::INT << 7));
+	END;
+	$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE;`
+
+	// ed25519_verify checks a 64 byte signature of msg by a 32 byte public key
+	// as specified by RFC 8032, matching Go's crypto/ed25519. It computes
+	// R' = [S]B - [h]A with Shamir's trick and compares its encoding with R.
+	sqlCreateFuncEd25519Verify = `CREATE OR REPLACE FUNCTION ed25519_verify(pubkey BYTEA, msg BYTEA, sig BYTEA)
+	RETURNS BOOLEAN AS $$
+	DECLARE
+		p NUMERIC := 57896044618658097711785492504343953926634992332820282019728792003956564819949;
+		l NUMERIC := 7237005577332262213973186563042994240857116359379907606001950938285454250989;
+		base_x NUMERIC := 15112221349535400772501151409588531511454012693041857206046113283949847762202;
+		base_y NUMERIC := 46316835694926478169428394003475163141307993866256225615783033603165251855960;
+		base NUMERIC[] := ARRAY[base_x, base_y, 1, base_x * base_y % p];
+		s NUMERIC;
+		h NUMERIC;
+		a NUMERIC[];
+		s_bytes BYTEA;
+		h_bytes BYTEA;
+		r NUMERIC[] := ARRAY[0, 1, 1, 0]::NUMERIC[];
+		p12 NUMERIC[];
+		bit_s BOOLEAN;
+		bit_h BOOLEAN;
+	BEGIN
+		PERFORM kwil_use_gas(5000 + length(msg) / 64);
+		IF length(pubkey) <> 32 THEN
+			RAISE EXCEPTION 'ed25519_verify: public key must be 32 bytes, got %', length(pubkey);
+		END IF;
+		IF length(sig) <> 64 THEN
+			RETURN FALSE;
+		END IF;
+		s := kwil_bytes_to_num_le(substring(sig FROM 33 FOR 32));
+		IF s >= l THEN
+			RETURN FALSE;
+		END IF;
+		a := kwil_ed25519_decode(pubkey);
+		IF a IS NULL THEN
+			RETURN FALSE;
+		END IF;
+		a := ARRAY[(p - a[1]) % p, a[2], a[3], (p - a[4]) % p]; -- negate A
+		h := kwil_bytes_to_num_le(digest(substring(sig FROM 1 FOR 32) || pubkey || msg, 'sha512')) % l;
+
+		s_bytes := kwil_num_to_bytes(s, 32);
+		h_bytes := kwil_num_to_bytes(h, 32);
+		p12 := kwil_ed25519_add(base, a);
+		FOR i IN REVERSE 255 .. 0 LOOP
+			r := kwil_ed25519_add(r, r);
+			bit_s := (get_byte(s_bytes, 31 - i / 8) >> (i % 8)) & 1 = 1;
+			bit_h := (get_byte(h_bytes, 31 - i / 8) >> (i % 8)) & 1 = 1;
+			IF bit_s AND bit_h THEN
+				r := kwil_ed25519_add(r, p12);
+			ELSIF bit_s THEN
+				r := kwil_ed25519_add(r, base);
+			ELSIF bit_h THEN
+				r := kwil_ed25519_add(r, a);
+			END IF;
+		END LOOP;
+		RETURN kwil_ed25519_encode(r) = substring(sig FROM 1 FOR 32);
+	END;
+	$$ LANGUAGE plpgsql VOLATILE STRICT PARALLEL UNSAFE COST 5000;`
+)
+
+// ensureCryptoFuncs creates the cryptographic functions. Helpers are created
+// before the functions that use them.
+func ensureCryptoFuncs(ctx context.Context, conn *pgx.Conn) error {
+	for _, stmt := range []string{
+		sqlCreateFuncUseGas,
+		sqlCreateFuncKwilKeccak256,
+		sqlCreateFuncKeccak256,
+		sqlCreateFuncKeccak256Text,
+		sqlCreateFuncBytesToNum,
+		sqlCreateFuncBytesToNumLE,
+		sqlCreateFuncNumToBytes,
+		sqlCreateFuncModPow,
+		sqlCreateFuncSecp256k1Double,
+		sqlCreateFuncSecp256k1Add,
+		sqlCreateFuncSecp256k1Mul2,
+		sqlCreateFuncSecp256k1Y,
+		sqlCreateFuncEthAddress,
+		sqlCreateFuncEcrecover,
+		sqlCreateFuncEd25519Add,
+		sqlCreateFuncEd25519Decode,
+		sqlCreateFuncEd25519Encode,
+		sqlCreateFuncEd25519Verify,
+	} {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -203,6 +203,10 @@ func NewDB(ctx context.Context, cfg *DBConfig) (*DB, error) {
 		return nil, fmt.Errorf("failed to create parse_unix_timestamp function: %w", err)
 	}
 
+	if err = ensureCryptoFuncs(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to create cryptographic functions: %w", err)
+	}
+
 	runCtx, cancel := context.WithCancelCause(context.Background())
 
 	db := &DB{
@@ -23,7 +23,11 @@ const (
 		RETURNS void AS $$
 		DECLARE txid bigint;
 		BEGIN
-			SELECT txid_current() INTO txid;
+			IF pg_is_in_recovery() THEN
+				SELECT pg_backend_pid() INTO txid;
+			ELSE
+				SELECT txid_current() INTO txid;
+			END IF;
 			raise notice 'pgtx:% %', txid, payload;
 		END;
 		$$ LANGUAGE plpgsql;`
@@ -35,7 +39,11 @@ const (
 		END;
 		$$ LANGUAGE plpgsql;`
 
-	sqlGetTxID = `SELECT txid_current();`
+	// The transaction ID identifies the transaction that raised a notice. A
+	// read-only hot standby cannot assign transaction IDs, so on a replica the
+	// backend PID is used instead, since a connection has at most one
+	// transaction at a time. This must match the ID raised by notice().
+	sqlGetTxID = `SELECT CASE WHEN pg_is_in_recovery() THEN pg_backend_pid()::bigint ELSE txid_current() END;`
 )
 
 func ensureErrorPLFunc(ctx context.Context, conn *pgx.Conn) error {
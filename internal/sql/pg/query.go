@@ -192,7 +192,15 @@ func query(ctx context.Context, oidToDataType map[uint32]*datatype, cq connQuery
 		oids = append(oids, colInfo.DataTypeOID)
 	}
 
+	// Rows are collected one at a time so that a query exceeding the row limit
+	// of the context is stopped before the entire result is read.
+	maxRows := sql.MaxRows(ctx)
+	var n int64
 	resSet.Rows, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) ([]any, error) {
+		n++
+		if maxRows > 0 && n > maxRows {
+			return nil, sql.ErrTooManyRows
+		}
 		pgxVals, err := row.Values()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,199 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kwilteam/kwil-db/common/sql"
+	syncmap "github.com/kwilteam/kwil-db/internal/utils/sync_map"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplicaPool is a pool of read-only connections to a PostgreSQL read replica,
+// such as a hot standby that uses streaming replication from the primary
+// database. It is used to serve reads that do not need to be performed on the
+// primary, such as ad-hoc queries from the RPC service.
+type ReplicaPool struct {
+	readers     *pgxpool.Pool
+	idTypes     map[uint32]*datatype
+	subscribers *syncmap.Map[int64, chan<- string]
+}
+
+// NewReplicaPool creates a connection pool to a read replica. The connection
+// string may be in either the DSN (key=value) or URL format. The replica must
+// already contain the custom types that Kwil creates on the primary.
+func NewReplicaPool(ctx context.Context, connStr string, maxConns uint32) (*ReplicaPool, error) {
+	if maxConns == 0 {
+		return nil, errors.New("at least one connection is required")
+	}
+
+	pCfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+	pCfg.MaxConns = int32(maxConns)
+
+	subscribers := syncmap.New[int64, chan<- string]()
+	setNoticeHandlers(pCfg.ConnConfig, subscribers)
+
+	pCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		return loadTypes(ctx, conn)
+	}
+
+	readers, err := pgxpool.NewWithConfig(ctx, pCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := readers.Acquire(ctx)
+	if err != nil {
+		readers.Close()
+		return nil, err
+	}
+	defer conn.Release()
+
+	var inRecovery bool
+	if err = conn.QueryRow(ctx, "SELECT pg_is_in_recovery();").Scan(&inRecovery); err != nil {
+		readers.Close()
+		return nil, err
+	}
+	if !inRecovery {
+		logger.Warnf("Read replica %v is not in recovery. It may not be a replica.", pCfg.ConnConfig.Host)
+	}
+
+	return &ReplicaPool{
+		readers:     readers,
+		idTypes:     oidTypesMap(conn.Conn().TypeMap()),
+		subscribers: subscribers,
+	}, nil
+}
+
+// BeginReadTx starts a read-only transaction on the replica.
+func (p *ReplicaPool) BeginReadTx(ctx context.Context) (sql.OuterReadTx, error) {
+	conn, err := p.readers.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{
+		AccessMode: pgx.ReadOnly,
+		IsoLevel:   pgx.RepeatableRead,
+	})
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+
+	return &readTx{
+		nestedTx: &nestedTx{
+			Tx:         tx,
+			accessMode: sql.ReadOnly,
+			oidTypes:   p.idTypes,
+		},
+		release:     conn.Release,
+		subscribers: p.subscribers,
+	}, nil
+}
+
+// Close closes all connections to the replica.
+func (p *ReplicaPool) Close() error {
+	p.readers.Close()
+	return nil
+}
+
+// HeightFunc returns the last committed block height as recorded in a
+// database.
+type HeightFunc func(ctx context.Context, tx sql.Executor) (int64, error)
+
+// ReadRouter creates read-only transactions on read replicas that have applied
+// at least the last committed block of the primary database. If no replica is
+// fresh, the transaction is created on the primary. Replicas are tried in
+// round-robin order.
+type ReadRouter struct {
+	primary  sql.ReadTxMaker
+	replicas []sql.ReadTxMaker
+	height   HeightFunc
+
+	next atomic.Uint32 // index of the next replica to try first
+}
+
+// NewReadRouter creates a ReadRouter. With no replicas, all transactions are
+// created on the primary.
+func NewReadRouter(primary sql.ReadTxMaker, replicas []sql.ReadTxMaker, height HeightFunc) *ReadRouter {
+	return &ReadRouter{
+		primary:  primary,
+		replicas: replicas,
+		height:   height,
+	}
+}
+
+var _ sql.ReadTxMaker = (*ReadRouter)(nil)
+var _ sql.DelayedReadTxMaker = (*ReadRouter)(nil)
+
+// BeginReadTx starts a read-only transaction on a fresh replica, or on the
+// primary if there is none.
+func (r *ReadRouter) BeginReadTx(ctx context.Context) (sql.OuterReadTx, error) {
+	if len(r.replicas) == 0 {
+		return r.primary.BeginReadTx(ctx)
+	}
+
+	committed, err := r.primaryHeight(ctx)
+	if err != nil {
+		logger.Warnf("Unable to get committed height from primary: %v", err)
+		return r.primary.BeginReadTx(ctx)
+	}
+
+	first := int(r.next.Add(1)-1) % len(r.replicas)
+	for i := range r.replicas {
+		idx := (first + i) % len(r.replicas)
+		tx, err := r.freshReplicaTx(ctx, r.replicas[idx], committed)
+		if err != nil {
+			logger.Debugf("Not using read replica %d: %v", idx, err)
+			continue
+		}
+		return tx, nil
+	}
+
+	return r.primary.BeginReadTx(ctx)
+}
+
+// BeginDelayedReadTx returns a read-only transaction that is not started on a
+// replica or the primary until it is first used.
+func (r *ReadRouter) BeginDelayedReadTx() sql.OuterReadTx {
+	return &delayedReadTx{db: r}
+}
+
+func (r *ReadRouter) primaryHeight(ctx context.Context) (int64, error) {
+	tx, err := r.primary.BeginReadTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	return r.height(ctx, tx)
+}
+
+// freshReplicaTx starts a transaction on the replica, returning an error if
+// the snapshot seen by the transaction is older than the committed height.
+func (r *ReadRouter) freshReplicaTx(ctx context.Context, replica sql.ReadTxMaker, committed int64) (sql.OuterReadTx, error) {
+	tx, err := replica.BeginReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := r.height(ctx, tx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	if height < committed {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("replica at height %d is behind committed height %d", height, committed)
+	}
+
+	return tx, nil
+}
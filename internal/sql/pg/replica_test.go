@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/common/sql"
+	"github.com/stretchr/testify/require"
+)
+
+// heightTx is a read tx from a fakeReplica that only reports its height.
+type heightTx struct {
+	sql.OuterReadTx // only Rollback is used
+	db              *fakeReplica
+}
+
+func (tx *heightTx) Rollback(context.Context) error {
+	tx.db.open--
+	return nil
+}
+
+type fakeReplica struct {
+	name   string
+	height int64
+	err    error // from BeginReadTx
+	open   int
+}
+
+func (f *fakeReplica) BeginReadTx(context.Context) (sql.OuterReadTx, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.open++
+	return &heightTx{db: f}, nil
+}
+
+func fakeHeight(_ context.Context, tx sql.Executor) (int64, error) {
+	return tx.(*heightTx).db.height, nil
+}
+
+func Test_ReadRouter(t *testing.T) {
+	ctx := context.Background()
+
+	type replica struct {
+		height int64
+		err    error
+	}
+
+	tests := []struct {
+		name     string
+		replicas []replica
+		want     []string // names of the dbs used for successive txs
+	}{
+		{"no replicas", nil, []string{"primary", "primary"}},
+		{"fresh replicas round robin", []replica{{10, nil}, {11, nil}},
+			[]string{"replica0", "replica1", "replica0"}},
+		{"skip stale replica", []replica{{9, nil}, {10, nil}},
+			[]string{"replica1", "replica1", "replica1"}},
+		{"skip unavailable replica", []replica{{10, errors.New("down")}, {10, nil}},
+			[]string{"replica1", "replica1"}},
+		{"fall back to primary", []replica{{9, nil}, {0, errors.New("down")}},
+			[]string{"primary", "primary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeReplica{name: "primary", height: 10}
+			fakes := make([]*fakeReplica, len(tt.replicas))
+			var replicas []sql.ReadTxMaker
+			for i, r := range tt.replicas {
+				fakes[i] = &fakeReplica{name: "replica" + string(rune('0'+i)), height: r.height, err: r.err}
+				replicas = append(replicas, fakes[i])
+			}
+
+			router := NewReadRouter(primary, replicas, fakeHeight)
+			for _, want := range tt.want {
+				tx, err := router.BeginReadTx(ctx)
+				require.NoError(t, err)
+				require.Equal(t, want, tx.(*heightTx).db.name)
+				require.NoError(t, tx.Rollback(ctx))
+			}
+
+			// Every tx, including those used to check heights, is closed.
+			require.Zero(t, primary.open)
+			for _, f := range fakes {
+				require.Zero(t, f.open)
+			}
+		})
+	}
+}
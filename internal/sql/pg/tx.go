@@ -157,9 +157,9 @@ func (tx *readTx) Subscribe(ctx context.Context) (ch <-chan string, done func(co
 // module is expected to control the lifetime of a read transaction, but
 // the implementation might not need to use the transaction.
 type delayedReadTx struct {
-	db *DB
+	db common.ReadTxMaker
 
-	tx *readTx
+	tx common.OuterReadTx
 }
 
 func (d *delayedReadTx) ensureTx(ctx context.Context) error {
@@ -169,7 +169,7 @@ func (d *delayedReadTx) ensureTx(ctx context.Context) error {
 			return err
 		}
 
-		d.tx = tx.(*readTx)
+		d.tx = tx
 	}
 
 	return nil
@@ -186,7 +186,7 @@ func (d *delayedReadTx) Execute(ctx context.Context, stmt string, args ...any) (
 var _ conner = (*nestedTx)(nil)
 
 func (d *delayedReadTx) Conn() *pgx.Conn {
-	return d.tx.Conn()
+	return d.tx.(conner).Conn()
 }
 
 func (d *delayedReadTx) Commit(ctx context.Context) error {
@@ -225,5 +225,5 @@ func (d *delayedReadTx) Subscribe(ctx context.Context) (ch <-chan string, done f
 		return nil, nil, err
 	}
 
-	return subscribe(ctx, d.tx, d.db.pool.subscribers)
+	return d.tx.Subscribe(ctx)
 }
@@ -776,6 +776,11 @@ func decodeFromPGVal(val any, oid uint32, oidToDataType map[uint32]*datatype) (a
 
 	dt, ok := oidToDataType[oid]
 	if !ok {
+		// JSON is not a Kwil type, but it is returned by statements such as
+		// EXPLAIN (FORMAT JSON). pgx has already unmarshalled it.
+		if oid == pgtype.JSONOID || oid == pgtype.JSONBOID {
+			return val, nil
+		}
 		return nil, fmt.Errorf("%w: %d", ErrUnsupportedOID, oid)
 	}
 
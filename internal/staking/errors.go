@@ -0,0 +1,8 @@
+package staking
+
+import "errors"
+
+var (
+	ErrInvalidAmount     = errors.New("stake amount must be positive")
+	ErrInsufficientStake = errors.New("insufficient stake")
+)
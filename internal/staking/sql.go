@@ -0,0 +1,162 @@
+package staking
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+const (
+	schemaName = `kwild_staking`
+
+	stakingStoreVersion = 0
+
+	sqlInitStakesTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.stakes (
+		staker BYTEA PRIMARY KEY, -- the identity of the account that staked
+		amount TEXT NOT NULL -- big.Int
+	);`
+
+	sqlInitUnbondingsTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.unbondings (
+		staker BYTEA NOT NULL,
+		release_height INT8 NOT NULL, -- the block height at which the tokens are returned
+		amount TEXT NOT NULL, -- big.Int
+		PRIMARY KEY (staker, release_height)
+	);`
+
+	sqlInitUnbondingsReleaseIndex = `CREATE INDEX IF NOT EXISTS unbondings_release_height ON ` + schemaName + `.unbondings (release_height);`
+
+	sqlGetStake = `SELECT amount FROM ` + schemaName + `.stakes WHERE staker = $1`
+
+	sqlUpsertStake = `INSERT INTO ` + schemaName + `.stakes (staker, amount) VALUES ($1, $2)
+		ON CONFLICT (staker) DO UPDATE SET amount = $2`
+
+	sqlDeleteStake = `DELETE FROM ` + schemaName + `.stakes WHERE staker = $1`
+
+	sqlListStakes = `SELECT staker, amount FROM ` + schemaName + `.stakes ORDER BY staker`
+
+	sqlGetUnbonding = `SELECT amount FROM ` + schemaName + `.unbondings WHERE staker = $1 AND release_height = $2`
+
+	sqlUpsertUnbonding = `INSERT INTO ` + schemaName + `.unbondings (staker, release_height, amount) VALUES ($1, $2, $3)
+		ON CONFLICT (staker, release_height) DO UPDATE SET amount = $3`
+
+	sqlDeleteUnbonding = `DELETE FROM ` + schemaName + `.unbondings WHERE staker = $1 AND release_height = $2`
+
+	sqlListUnbondings = `SELECT release_height, amount FROM ` + schemaName + `.unbondings
+		WHERE staker = $1 ORDER BY release_height`
+
+	sqlListReleasedUnbondings = `SELECT staker, release_height, amount FROM ` + schemaName + `.unbondings
+		WHERE release_height <= $1 ORDER BY release_height, staker`
+
+	sqlDeleteReleasedUnbondings = `DELETE FROM ` + schemaName + `.unbondings WHERE release_height <= $1`
+)
+
+func initTables(ctx context.Context, tx sql.DB) error {
+	initStmts := []string{sqlInitStakesTable, sqlInitUnbondingsTable, sqlInitUnbondingsReleaseIndex}
+
+	for _, stmt := range initStmts {
+		_, err := tx.Execute(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("failed to initialize tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getStake gets the amount staked by an account, which is zero if it has not
+// staked.
+func getStake(ctx context.Context, db sql.Executor, staker []byte) (*big.Int, error) {
+	res, err := db.Execute(ctx, sqlGetStake, staker)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Rows) == 0 {
+		return big.NewInt(0), nil
+	}
+
+	return amountFromRow(res.Rows[0][0])
+}
+
+// setStake sets the amount staked by an account, removing the stake if it is
+// zero.
+func setStake(ctx context.Context, db sql.Executor, staker []byte, amount *big.Int) error {
+	if amount.Sign() == 0 {
+		_, err := db.Execute(ctx, sqlDeleteStake, staker)
+		return err
+	}
+
+	_, err := db.Execute(ctx, sqlUpsertStake, staker, amount.String())
+	return err
+}
+
+// addUnbonding adds an amount to the tokens of an account that are returned
+// at the release height.
+func addUnbonding(ctx context.Context, db sql.Executor, staker []byte, releaseHeight int64, amount *big.Int) error {
+	res, err := db.Execute(ctx, sqlGetUnbonding, staker, releaseHeight)
+	if err != nil {
+		return err
+	}
+
+	total := new(big.Int).Set(amount)
+	if len(res.Rows) > 0 {
+		existing, err := amountFromRow(res.Rows[0][0])
+		if err != nil {
+			return err
+		}
+		total.Add(total, existing)
+	}
+
+	_, err = db.Execute(ctx, sqlUpsertUnbonding, staker, releaseHeight, total.String())
+	return err
+}
+
+// setUnbonding sets the tokens of an account that are returned at the release
+// height, removing the unbonding if it is zero.
+func setUnbonding(ctx context.Context, db sql.Executor, staker []byte, releaseHeight int64, amount *big.Int) error {
+	if amount.Sign() == 0 {
+		_, err := db.Execute(ctx, sqlDeleteUnbonding, staker, releaseHeight)
+		return err
+	}
+
+	_, err := db.Execute(ctx, sqlUpsertUnbonding, staker, releaseHeight, amount.String())
+	return err
+}
+
+// listUnbondings lists the pending unbondings of an account, ordered by
+// release height.
+func listUnbondings(ctx context.Context, db sql.Executor, staker []byte) ([]*Unbonding, error) {
+	res, err := db.Execute(ctx, sqlListUnbondings, staker)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondings := make([]*Unbonding, len(res.Rows))
+	for i, row := range res.Rows {
+		releaseHeight, ok := sql.Int64(row[0])
+		if !ok {
+			return nil, fmt.Errorf("invalid type for release height (%T)", row[0])
+		}
+		amt, err := amountFromRow(row[1])
+		if err != nil {
+			return nil, err
+		}
+		unbondings[i] = &Unbonding{Staker: staker, ReleaseHeight: releaseHeight, Amount: amt}
+	}
+
+	return unbondings, nil
+}
+
+func amountFromRow(v any) (*big.Int, error) {
+	amtStr, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for amount (%T)", v)
+	}
+	amt, ok := new(big.Int).SetString(amtStr, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amtStr)
+	}
+	return amt, nil
+}
@@ -0,0 +1,219 @@
+// Package staking stores tokens staked by accounts. On networks with staking
+// enabled, a validator's power is derived from its stake. Staked tokens are
+// removed from the account's balance, and unstaked tokens are returned to the
+// balance after an unbonding period.
+package staking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/kwilteam/kwil-db/common/chain"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/internal/sql/versioning"
+)
+
+// MaxTotalPower is the maximum total power of the validator set, which is
+// CometBFT's MaxTotalVotingPower. It is also the maximum power of a validator.
+const MaxTotalPower = math.MaxInt64 / 8
+
+// Stake is the amount staked by an account.
+type Stake struct {
+	Staker []byte
+	Amount *big.Int
+}
+
+// Unbonding is an amount of unstaked tokens that is returned to the staker's
+// balance at the release height.
+type Unbonding struct {
+	Staker        []byte
+	ReleaseHeight int64
+	Amount        *big.Int
+}
+
+// InitializeStakingStore initializes the staking store schema and tables.
+func InitializeStakingStore(ctx context.Context, db sql.DB) error {
+	upgradeFns := map[int64]versioning.UpgradeFunc{
+		0: initTables,
+	}
+
+	err := versioning.Upgrade(ctx, db, schemaName, upgradeFns, stakingStoreVersion)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddStake adds to the amount staked by an account. The tokens must already
+// have been removed from the staker's balance.
+func AddStake(ctx context.Context, tx sql.Executor, staker []byte, amount *big.Int) error {
+	if amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+
+	stake, err := getStake(ctx, tx, staker)
+	if err != nil {
+		return err
+	}
+
+	return setStake(ctx, tx, staker, stake.Add(stake, amount))
+}
+
+// Unstake removes an amount from an account's stake, to be returned to its
+// balance at the release height.
+func Unstake(ctx context.Context, tx sql.Executor, staker []byte, amount *big.Int, releaseHeight int64) error {
+	if amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+
+	stake, err := getStake(ctx, tx, staker)
+	if err != nil {
+		return err
+	}
+	if stake.Cmp(amount) < 0 {
+		return fmt.Errorf("%w: staked %s, unstaking %s", ErrInsufficientStake, stake, amount)
+	}
+
+	if err = setStake(ctx, tx, staker, stake.Sub(stake, amount)); err != nil {
+		return err
+	}
+
+	return addUnbonding(ctx, tx, staker, releaseHeight, amount)
+}
+
+// Slash removes up to the given amount from an account's stake, and then from
+// its pending unbondings, oldest release height first, so that unstaking does
+// not escape a slash. It returns the amount removed.
+func Slash(ctx context.Context, tx sql.Executor, staker []byte, amount *big.Int) (*big.Int, error) {
+	stake, err := getStake(ctx, tx, staker)
+	if err != nil {
+		return nil, err
+	}
+
+	slashed := new(big.Int).Set(amount)
+	if slashed.Cmp(stake) > 0 {
+		slashed.Set(stake)
+	}
+	if slashed.Sign() > 0 {
+		if err = setStake(ctx, tx, staker, stake.Sub(stake, slashed)); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining := new(big.Int).Sub(amount, slashed)
+	if remaining.Sign() <= 0 {
+		return slashed, nil
+	}
+
+	unbondings, err := listUnbondings(ctx, tx, staker)
+	if err != nil {
+		return nil, err
+	}
+	for _, unbonding := range unbondings { // ordered by release height
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		take := new(big.Int).Set(remaining)
+		if take.Cmp(unbonding.Amount) > 0 {
+			take.Set(unbonding.Amount)
+		}
+		err = setUnbonding(ctx, tx, staker, unbonding.ReleaseHeight, unbonding.Amount.Sub(unbonding.Amount, take))
+		if err != nil {
+			return nil, err
+		}
+		slashed.Add(slashed, take)
+		remaining.Sub(remaining, take)
+	}
+
+	return slashed, nil
+}
+
+// GetStake gets the amount staked by an account, which is zero if it has not
+// staked.
+func GetStake(ctx context.Context, tx sql.Executor, staker []byte) (*big.Int, error) {
+	return getStake(ctx, tx, staker)
+}
+
+// ListStakes lists the stakes of all accounts, ordered by staker.
+func ListStakes(ctx context.Context, tx sql.Executor) ([]*Stake, error) {
+	res, err := tx.Execute(ctx, sqlListStakes)
+	if err != nil {
+		return nil, err
+	}
+
+	stakes := make([]*Stake, len(res.Rows))
+	for i, row := range res.Rows {
+		staker, ok := row[0].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for staker (%T)", row[0])
+		}
+		amt, err := amountFromRow(row[1])
+		if err != nil {
+			return nil, err
+		}
+		stakes[i] = &Stake{Staker: slices.Clone(staker), Amount: amt}
+	}
+
+	return stakes, nil
+}
+
+// ReleaseUnbonded removes and returns the unbondings that are released at or
+// before the given height, ordered by release height and staker. The caller
+// must return the tokens to the stakers' balances.
+func ReleaseUnbonded(ctx context.Context, tx sql.Executor, height int64) ([]*Unbonding, error) {
+	res, err := tx.Execute(ctx, sqlListReleasedUnbondings, height)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Rows) == 0 {
+		return nil, nil
+	}
+
+	unbondings := make([]*Unbonding, len(res.Rows))
+	for i, row := range res.Rows {
+		staker, ok := row[0].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for staker (%T)", row[0])
+		}
+		releaseHeight, ok := sql.Int64(row[1])
+		if !ok {
+			return nil, fmt.Errorf("invalid type for release height (%T)", row[1])
+		}
+		amt, err := amountFromRow(row[2])
+		if err != nil {
+			return nil, err
+		}
+		unbondings[i] = &Unbonding{Staker: slices.Clone(staker), ReleaseHeight: releaseHeight, Amount: amt}
+	}
+
+	_, err = tx.Execute(ctx, sqlDeleteReleasedUnbondings, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return unbondings, nil
+}
+
+// Power returns the validator power of a stake. It is zero if the stake is
+// less than the minimum stake, and otherwise the stake divided by the stake
+// per unit of power, but at least one and at most MaxTotalPower.
+func Power(params *chain.ValidatorParams, stake *big.Int) int64 {
+	if stake.Sign() <= 0 || (params.MinStake != nil && stake.Cmp(params.MinStake) < 0) {
+		return 0
+	}
+
+	power := new(big.Int).Set(stake)
+	if params.StakePerPower != nil && params.StakePerPower.Sign() > 0 {
+		power.Quo(power, params.StakePerPower)
+	}
+	if !power.IsInt64() || power.Int64() > MaxTotalPower {
+		return MaxTotalPower
+	}
+
+	return max(power.Int64(), 1)
+}
@@ -0,0 +1,188 @@
+package staking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/common/chain"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unbondingKey struct {
+	staker        string
+	releaseHeight int64
+}
+
+// mockDB stores stakes and unbondings in memory, handling the queries used by
+// this package.
+type mockDB struct {
+	stakes     map[string]string       // staker => amount
+	unbondings map[unbondingKey]string // staker, release height => amount
+}
+
+func newDB() *mockDB {
+	return &mockDB{
+		stakes:     make(map[string]string),
+		unbondings: make(map[unbondingKey]string),
+	}
+}
+
+func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	switch stmt {
+	case sqlGetStake:
+		amt, ok := m.stakes[string(args[0].([]byte))]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{{amt}}}, nil
+	case sqlUpsertStake:
+		m.stakes[string(args[0].([]byte))] = args[1].(string)
+		return &sql.ResultSet{}, nil
+	case sqlDeleteStake:
+		delete(m.stakes, string(args[0].([]byte)))
+		return &sql.ResultSet{}, nil
+	case sqlListStakes:
+		var rows [][]any
+		for staker, amt := range m.stakes {
+			rows = append(rows, []any{[]byte(staker), amt})
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return string(rows[i][0].([]byte)) < string(rows[j][0].([]byte))
+		})
+		return &sql.ResultSet{Rows: rows}, nil
+	case sqlGetUnbonding:
+		amt, ok := m.unbondings[unbondingKey{string(args[0].([]byte)), args[1].(int64)}]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{{amt}}}, nil
+	case sqlUpsertUnbonding:
+		m.unbondings[unbondingKey{string(args[0].([]byte)), args[1].(int64)}] = args[2].(string)
+		return &sql.ResultSet{}, nil
+	case sqlListReleasedUnbondings:
+		var rows [][]any
+		for key, amt := range m.unbondings {
+			if key.releaseHeight <= args[0].(int64) {
+				rows = append(rows, []any{[]byte(key.staker), key.releaseHeight, amt})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i][1].(int64) != rows[j][1].(int64) {
+				return rows[i][1].(int64) < rows[j][1].(int64)
+			}
+			return string(rows[i][0].([]byte)) < string(rows[j][0].([]byte))
+		})
+		return &sql.ResultSet{Rows: rows}, nil
+	case sqlDeleteUnbonding:
+		delete(m.unbondings, unbondingKey{string(args[0].([]byte)), args[1].(int64)})
+		return &sql.ResultSet{}, nil
+	case sqlListUnbondings:
+		var rows [][]any
+		for key, amt := range m.unbondings {
+			if key.staker == string(args[0].([]byte)) {
+				rows = append(rows, []any{key.releaseHeight, amt})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i][0].(int64) < rows[j][0].(int64)
+		})
+		return &sql.ResultSet{Rows: rows}, nil
+	case sqlDeleteReleasedUnbondings:
+		for key := range m.unbondings {
+			if key.releaseHeight <= args[0].(int64) {
+				delete(m.unbondings, key)
+			}
+		}
+		return &sql.ResultSet{}, nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %s", stmt)
+	}
+}
+
+func Test_Staking(t *testing.T) {
+	ctx := context.Background()
+	db := newDB()
+	alice, bob := []byte("alice"), []byte("bob")
+
+	require.NoError(t, AddStake(ctx, db, alice, big.NewInt(100)))
+	require.NoError(t, AddStake(ctx, db, alice, big.NewInt(50)))
+	require.NoError(t, AddStake(ctx, db, bob, big.NewInt(10)))
+	require.ErrorIs(t, AddStake(ctx, db, bob, big.NewInt(0)), ErrInvalidAmount)
+
+	stake, err := GetStake(ctx, db, alice)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(150), stake)
+
+	// unstaking more than the stake fails
+	require.ErrorIs(t, Unstake(ctx, db, bob, big.NewInt(11), 20), ErrInsufficientStake)
+
+	// unstakes with the same release height are combined
+	require.NoError(t, Unstake(ctx, db, alice, big.NewInt(20), 20))
+	require.NoError(t, Unstake(ctx, db, alice, big.NewInt(30), 20))
+	require.NoError(t, Unstake(ctx, db, bob, big.NewInt(10), 25))
+
+	stakes, err := ListStakes(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, stakes, 1) // bob's stake is removed
+	assert.Equal(t, alice, stakes[0].Staker)
+	assert.Equal(t, big.NewInt(100), stakes[0].Amount)
+
+	require.NoError(t, Unstake(ctx, db, alice, big.NewInt(10), 15))
+
+	// slashing takes from the stake, and then from the unbondings, oldest
+	// release height first
+	slashed, err := Slash(ctx, db, alice, big.NewInt(40))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(40), slashed)
+	slashed, err = Slash(ctx, db, alice, big.NewInt(100))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), slashed) // 50 staked, 10 at height 15, 40 of 50 at height 20
+	slashed, err = Slash(ctx, db, bob, big.NewInt(100))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), slashed)
+	slashed, err = Slash(ctx, db, bob, big.NewInt(100))
+	require.NoError(t, err)
+	assert.Zero(t, slashed.Sign())
+
+	released, err := ReleaseUnbonded(ctx, db, 19)
+	require.NoError(t, err)
+	assert.Empty(t, released)
+
+	released, err = ReleaseUnbonded(ctx, db, 25)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, &Unbonding{Staker: alice, ReleaseHeight: 20, Amount: big.NewInt(10)}, released[0])
+
+	// released unbondings are removed
+	released, err = ReleaseUnbonded(ctx, db, 30)
+	require.NoError(t, err)
+	assert.Empty(t, released)
+}
+
+func Test_Power(t *testing.T) {
+	params := &chain.ValidatorParams{
+		MinStake:      big.NewInt(1000),
+		StakePerPower: big.NewInt(100),
+	}
+
+	assert.Equal(t, int64(0), Power(params, big.NewInt(0)))
+	assert.Equal(t, int64(0), Power(params, big.NewInt(999)))
+	assert.Equal(t, int64(10), Power(params, big.NewInt(1000)))
+	assert.Equal(t, int64(12), Power(params, big.NewInt(1299)))
+
+	// the power of a stake smaller than the stake per power is at least one
+	params.MinStake = nil
+	assert.Equal(t, int64(1), Power(params, big.NewInt(1)))
+
+	params.StakePerPower = nil
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+	assert.Equal(t, int64(MaxTotalPower), Power(params, huge))
+	assert.Equal(t, int64(MaxTotalPower), Power(params, big.NewInt(math.MaxInt64)))
+}
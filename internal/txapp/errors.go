@@ -3,8 +3,17 @@ package txapp
 import "errors"
 
 var (
-	ErrCallerNotValidator = errors.New("caller is not a validator")
-	ErrCallerIsValidator  = errors.New("caller is already a validator")
-	ErrCallerNotProposer  = errors.New("caller is not the block proposer")
-	ErrTargetNotValidator = errors.New("target is not a validator")
+	ErrCallerNotValidator     = errors.New("caller is not a validator")
+	ErrCallerIsValidator      = errors.New("caller is already a validator")
+	ErrCallerNotProposer      = errors.New("caller is not the block proposer")
+	ErrTargetNotValidator     = errors.New("target is not a validator")
+	ErrCallerNotJailed        = errors.New("caller is not a jailed validator")
+	ErrJailPeriodNotOver      = errors.New("jail period is not over")
+	ErrStakingDisabled        = errors.New("staking is not enabled on this network")
+	ErrWithdrawalsDisabled    = errors.New("withdrawals are not enabled on this network")
+	ErrInvalidConsent         = errors.New("invalid session key consent signature")
+	ErrSessionsNotActive      = errors.New("session keys are not enabled on this network")
+	ErrConsensusKeyInUse      = errors.New("consensus key is used by another validator")
+	ErrConsensusKeysNotActive = errors.New("consensus keys are not enabled on this network")
+	ErrWasmNotActive          = errors.New("WebAssembly modules are not enabled on this network")
 )
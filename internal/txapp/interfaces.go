@@ -6,7 +6,12 @@ import (
 	"github.com/kwilteam/kwil-db/common/sql"
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/internal/accounts"
+	"github.com/kwilteam/kwil-db/internal/scheduler"
+	"github.com/kwilteam/kwil-db/internal/sessions"
+	"github.com/kwilteam/kwil-db/internal/staking"
 	"github.com/kwilteam/kwil-db/internal/voting"
+	"github.com/kwilteam/kwil-db/internal/wasm"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
 )
 
 // Rebroadcaster is a service that marks events for rebroadcasting.
@@ -15,6 +20,15 @@ type Rebroadcaster interface {
 	MarkRebroadcast(ctx context.Context, ids []*types.UUID) error
 }
 
+// EventStore is the local store of events observed by this node, which are
+// broadcast to the network for voting.
+type EventStore interface {
+	Rebroadcaster
+	// Store stores an event, such as this validator's attestation of a
+	// withdrawal epoch.
+	Store(ctx context.Context, data []byte, eventType string) error
+}
+
 // DB is the interface for the main SQL database. All queries must be executed
 // from within a transaction. A DB can create read transactions or the special
 // two-phase outer write transaction.
@@ -58,6 +72,14 @@ var (
 	getVoterPower                    = voting.GetValidatorPower
 	resolutionExists                 = voting.ResolutionExists
 	resolutionByID                   = voting.GetResolutionInfo
+	recordLiveness                   = voting.RecordLiveness
+	jailValidator                    = voting.JailValidator
+	unjailValidator                  = voting.UnjailValidator
+	getLiveness                      = voting.GetLiveness
+	storeEvidence                    = voting.StoreEvidence
+	storeResolutionResult            = voting.StoreResolutionResult
+	setConsensusKey                  = voting.SetConsensusKey
+	getConsensusKeyOwner             = voting.GetConsensusKeyOwner
 	// deleteResolution                 = voting.DeleteResolution
 
 	// account functions
@@ -66,4 +88,33 @@ var (
 	spend      = accounts.Spend
 	applySpend = accounts.ApplySpend
 	transfer   = accounts.Transfer
+	burn       = accounts.Burn
+
+	// session functions
+	getSession    = sessions.GetSession
+	createSession = sessions.CreateSession
+	revokeSession = sessions.RevokeSession
+	sessionSpend  = sessions.Spend
+
+	// staking functions
+	addStake        = staking.AddStake
+	unstake         = staking.Unstake
+	slashStake      = staking.Slash
+	getStake        = staking.GetStake
+	listStakes      = staking.ListStakes
+	releaseUnbonded = staking.ReleaseUnbonded
+
+	// withdrawal functions
+	withdraw    = withdrawals.Withdraw
+	sealEpoch   = withdrawals.SealEpoch
+	getAttester = withdrawals.GetAttester
+
+	// scheduler functions
+	addSchedule     = scheduler.AddSchedule
+	deleteSchedules = scheduler.DeleteSchedules
+
+	// WebAssembly module functions
+	loadWasmModules    = wasm.LoadModules
+	commitWasmModules  = wasm.CommitModules
+	discardWasmModules = wasm.DiscardModules
 )
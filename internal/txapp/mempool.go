@@ -10,9 +10,12 @@ import (
 	"sync"
 
 	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain/forks"
 	sql "github.com/kwilteam/kwil-db/common/sql"
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/internal/sessions"
 	"github.com/kwilteam/kwil-db/internal/voting"
 )
 
@@ -21,6 +24,7 @@ type mempool struct {
 	acctsMtx sync.Mutex // protects accounts
 
 	nodeAddr []byte
+	forks    *forks.Forks // the network's hard forks, shared with the TxApp
 }
 
 // accountInfo retrieves the account info from the mempool state or the account store.
@@ -68,6 +72,12 @@ func (m *mempool) applyTransaction(ctx *common.TxContext, tx *transactions.Trans
 			return fmt.Errorf("validator joins are not allowed during migration")
 		case transactions.PayloadTypeValidatorLeave:
 			return fmt.Errorf("validator leaves are not allowed during migration")
+		case transactions.PayloadTypeValidatorUnjail:
+			return fmt.Errorf("validator unjails are not allowed during migration")
+		case transactions.PayloadTypeStake:
+			return fmt.Errorf("stake transactions are not allowed during migration")
+		case transactions.PayloadTypeUnstake:
+			return fmt.Errorf("unstake transactions are not allowed during migration")
 		case transactions.PayloadTypeValidatorApprove:
 			return fmt.Errorf("validator approvals are not allowed during migration")
 		case transactions.PayloadTypeValidatorRemove:
@@ -82,6 +92,8 @@ func (m *mempool) applyTransaction(ctx *common.TxContext, tx *transactions.Trans
 			return fmt.Errorf("drop schema transactions are not allowed during migration")
 		case transactions.PayloadTypeTransfer:
 			return fmt.Errorf("transfer transactions are not allowed during migration")
+		case transactions.PayloadTypeWithdraw:
+			return fmt.Errorf("withdraw transactions are not allowed during migration")
 		}
 	}
 
@@ -141,6 +153,18 @@ func (m *mempool) applyTransaction(ctx *common.TxContext, tx *transactions.Trans
 		return fmt.Errorf("validator vote bodies can not enter the mempool, and can only be submitted during block proposal")
 	}
 
+	// Transactions signed by a session key use the session key's nonce, but
+	// the fee is paid by the session's delegator.
+	if m.forks.IsActive(consensus.ForkSessions, uint64(ctx.BlockContext.Height)) {
+		session, err := getSession(ctx.Ctx, dbTx, tx.Sender)
+		if err != nil && !errors.Is(err, sessions.ErrSessionNotFound) {
+			return err
+		}
+		if session != nil {
+			return m.applySessionTransaction(ctx, tx, dbTx, session)
+		}
+	}
+
 	// get account info from mempool state or account store
 	acct, err := m.accountInfo(ctx.Ctx, dbTx, tx.Sender)
 	if err != nil {
@@ -204,6 +228,48 @@ func (m *mempool) applyTransaction(ctx *common.TxContext, tx *transactions.Trans
 			return transactions.ErrInsufficientBalance
 		}
 
+		spend.Add(spend, amt)
+	case transactions.PayloadTypeWithdraw:
+		withdraw := &transactions.Withdraw{}
+		err = withdraw.UnmarshalBinary(tx.Body.Payload)
+		if err != nil {
+			return err
+		}
+
+		amt, ok := big.NewInt(0).SetString(withdraw.Amount, 10)
+		if !ok {
+			return transactions.ErrInvalidAmount
+		}
+
+		if amt.Sign() <= 0 {
+			return errors.Join(transactions.ErrInvalidAmount, errors.New("withdrawal amount must be positive"))
+		}
+
+		if amt.Cmp(acct.Balance) > 0 {
+			return transactions.ErrInsufficientBalance
+		}
+
+		spend.Add(spend, amt)
+	case transactions.PayloadTypeStake:
+		stake := &transactions.Stake{}
+		err = stake.UnmarshalBinary(tx.Body.Payload)
+		if err != nil {
+			return err
+		}
+
+		amt, ok := big.NewInt(0).SetString(stake.Amount, 10)
+		if !ok {
+			return transactions.ErrInvalidAmount
+		}
+
+		if amt.Sign() <= 0 {
+			return errors.Join(transactions.ErrInvalidAmount, errors.New("stake amount must be positive"))
+		}
+
+		if amt.Cmp(acct.Balance) > 0 {
+			return transactions.ErrInsufficientBalance
+		}
+
 		spend.Add(spend, amt)
 	}
 
@@ -234,6 +300,51 @@ func (m *mempool) applyTransaction(ctx *common.TxContext, tx *transactions.Trans
 	return nil
 }
 
+// applySessionTransaction validates and applies a transaction signed by a
+// session key to the mempool state. The caller must hold acctsMtx.
+func (m *mempool) applySessionTransaction(ctx *common.TxContext, tx *transactions.Transaction, dbTx sql.Executor, session *sessions.Session) error {
+	if err := session.Authorize(ctx.BlockContext.Height, tx); err != nil {
+		return err
+	}
+
+	key, err := m.accountInfo(ctx.Ctx, dbTx, tx.Sender)
+	if err != nil {
+		return err
+	}
+
+	if tx.Body.Nonce != uint64(key.Nonce)+1 {
+		return fmt.Errorf("%w for account %s: got %d, expected %d", transactions.ErrInvalidNonce,
+			hex.EncodeToString(tx.Sender), tx.Body.Nonce, key.Nonce+1)
+	}
+
+	delegator, err := m.accountInfo(ctx.Ctx, dbTx, session.Delegator)
+	if err != nil {
+		return err
+	}
+
+	// reject the transactions from unfunded delegators or exhausted sessions
+	// in gasEnabled mode
+	if !ctx.BlockContext.ChainContext.NetworkParameters.DisabledGasCosts {
+		if delegator.Balance.Sign() == 0 {
+			return transactions.ErrInsufficientBalance
+		}
+		if tx.Body.Fee.Cmp(session.Remaining()) > 0 {
+			return errors.Join(transactions.ErrInsufficientBalance, sessions.ErrBudgetExceeded)
+		}
+	}
+
+	// reduce the delegator's pending balance, but no lower than zero
+	if tx.Body.Fee.Cmp(delegator.Balance) > 0 {
+		delegator.Balance.SetUint64(0)
+	} else {
+		delegator.Balance.Sub(delegator.Balance, tx.Body.Fee)
+	}
+
+	key.Nonce = int64(tx.Body.Nonce)
+
+	return nil
+}
+
 // reset clears the in-memory unconfirmed account states.
 // This should be done at the end of block commit.
 func (m *mempool) reset() {
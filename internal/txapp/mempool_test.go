@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain/forks"
 	sql "github.com/kwilteam/kwil-db/common/sql"
 	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/types"
@@ -16,6 +17,7 @@ import (
 func Test_MempoolWithoutGas(t *testing.T) {
 	m := &mempool{
 		accounts: make(map[string]*types.Account),
+		forks:    &forks.Forks{},
 	}
 
 	ctx := context.Background()
@@ -66,6 +68,7 @@ func Test_MempoolWithoutGas(t *testing.T) {
 func Test_MempoolWithGas(t *testing.T) {
 	m := &mempool{
 		accounts: make(map[string]*types.Account),
+		forks:    &forks.Forks{},
 	}
 
 	txCtx := &common.TxContext{
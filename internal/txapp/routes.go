@@ -3,8 +3,11 @@ package txapp
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sync"
 
@@ -14,11 +17,18 @@ import (
 	"github.com/kwilteam/kwil-db/core/log"
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
+	authExt "github.com/kwilteam/kwil-db/extensions/auth"
 	"github.com/kwilteam/kwil-db/extensions/consensus"
 	"github.com/kwilteam/kwil-db/extensions/resolutions"
 	"github.com/kwilteam/kwil-db/internal/accounts"
 	"github.com/kwilteam/kwil-db/internal/engine/execution"
+	"github.com/kwilteam/kwil-db/internal/scheduler"
+	"github.com/kwilteam/kwil-db/internal/sessions"
+	"github.com/kwilteam/kwil-db/internal/staking"
 	"github.com/kwilteam/kwil-db/internal/voting"
+	"github.com/kwilteam/kwil-db/internal/wasm"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
+	"github.com/kwilteam/kwil-db/parse"
 )
 
 func init() {
@@ -31,10 +41,16 @@ func init() {
 		RegisterRoute(transactions.PayloadTypeValidatorApprove, NewRoute(&validatorApproveRoute{})),
 		RegisterRoute(transactions.PayloadTypeValidatorRemove, NewRoute(&validatorRemoveRoute{})),
 		RegisterRoute(transactions.PayloadTypeValidatorLeave, NewRoute(&validatorLeaveRoute{})),
+		RegisterRoute(transactions.PayloadTypeValidatorUnjail, NewRoute(&validatorUnjailRoute{})),
 		RegisterRoute(transactions.PayloadTypeValidatorVoteIDs, NewRoute(&validatorVoteIDsRoute{})),
 		RegisterRoute(transactions.PayloadTypeValidatorVoteBodies, NewRoute(&validatorVoteBodiesRoute{})),
 		RegisterRoute(transactions.PayloadTypeCreateResolution, NewRoute(&createResolutionRoute{})),
 		RegisterRoute(transactions.PayloadTypeApproveResolution, NewRoute(&approveResolutionRoute{})),
+		RegisterRoute(transactions.PayloadTypeCreateSession, NewRoute(&createSessionRoute{})),
+		RegisterRoute(transactions.PayloadTypeRevokeSession, NewRoute(&revokeSessionRoute{})),
+		RegisterRoute(transactions.PayloadTypeWithdraw, NewRoute(&withdrawRoute{})),
+		RegisterRoute(transactions.PayloadTypeStake, NewRoute(&stakeRoute{})),
+		RegisterRoute(transactions.PayloadTypeUnstake, NewRoute(&unstakeRoute{})),
 	)
 	if err != nil {
 		panic(fmt.Sprintf("failed to register routes: %s", err))
@@ -155,10 +171,28 @@ func (d *baseRoute) Execute(ctx *common.TxContext, router *TxApp, db sql.DB, tx
 		return txRes(nil, transactions.CodeUnknownError, err)
 	}
 
-	spend, code, err := router.checkAndSpend(ctx, tx, d, dbTx)
+	// Transactions signed by a session key are authorized by the session, and
+	// the fee is paid by the session's delegator.
+	var session *sessions.Session
+	if router.forks.IsActive(consensus.ForkSessions, uint64(ctx.BlockContext.Height)) {
+		session, err = getSession(ctx.Ctx, dbTx, tx.Sender)
+		if err != nil && !errors.Is(err, sessions.ErrSessionNotFound) {
+			logErr(&router.service.Logger, dbTx.Rollback(ctx.Ctx))
+			return txRes(nil, transactions.CodeUnknownError, err)
+		}
+	}
+
+	var spend *big.Int
+	var code transactions.TxCode
+	if session != nil {
+		spend, code, err = router.checkAndSpendSession(ctx, tx, d, dbTx, session)
+	} else {
+		spend, code, err = router.checkAndSpend(ctx, tx, d, dbTx)
+	}
 	if err != nil {
 		switch code {
-		case transactions.CodeOk, transactions.CodeInsufficientBalance, transactions.CodeInsufficientFee:
+		case transactions.CodeOk, transactions.CodeInsufficientBalance, transactions.CodeInsufficientFee,
+			transactions.CodeInvalidSender: // an unauthorized session key still uses its nonce
 			logErr(&router.service.Logger, dbTx.Commit(ctx.Ctx))
 		default:
 			logErr(&router.service.Logger, dbTx.Rollback(ctx.Ctx))
@@ -182,6 +216,15 @@ func (d *baseRoute) Execute(ctx *common.TxContext, router *TxApp, db sql.DB, tx
 		return txRes(spend, code, err)
 	}
 
+	// A session key's transaction executes as the delegator, so @caller is the
+	// delegator's identifier.
+	if session != nil {
+		ctx, err = delegatedContext(ctx, session)
+		if err != nil {
+			return txRes(spend, transactions.CodeUnknownError, err)
+		}
+	}
+
 	tx2, err := dbTx.BeginTx(ctx.Ctx)
 	if err != nil {
 		return txRes(spend, transactions.CodeUnknownError, err)
@@ -207,6 +250,21 @@ func (d *baseRoute) Execute(ctx *common.TxContext, router *TxApp, db sql.DB, tx
 	return txRes(spend, transactions.CodeOk, nil)
 }
 
+// delegatedContext returns a copy of the transaction context in which the
+// session's delegator is the signer and caller.
+func delegatedContext(ctx *common.TxContext, session *sessions.Session) (*common.TxContext, error) {
+	caller, err := ident.Identifier(session.DelegatorType, session.Delegator)
+	if err != nil {
+		return nil, err
+	}
+
+	delegated := *ctx
+	delegated.Signer = session.Delegator
+	delegated.Caller = caller
+	delegated.Authenticator = session.DelegatorType
+	return &delegated, nil
+}
+
 // ========================== route implementations ==========================
 // Each of the following route implementation satisfy the consensus.Route
 // interface, which is embedded by the baseRoute for used by TxApp.
@@ -217,6 +275,7 @@ func (d *baseRoute) Execute(ctx *common.TxContext, router *TxApp, db sql.DB, tx
 
 type deployDatasetRoute struct {
 	schema     *types.Schema // set by PreTx
+	schedules  []*scheduler.Schedule
 	identifier string
 	authType   string
 }
@@ -248,6 +307,35 @@ func (d *deployDatasetRoute) PreTx(ctx *common.TxContext, svc *common.Service, t
 		return transactions.CodeUnknownError, err
 	}
 
+	if !forkActive(svc, consensus.ForkCryptoFuncs, ctx.BlockContext.Height) {
+		fn, err := calledFunction(d.schema, cryptoFuncs)
+		if err != nil {
+			return transactions.CodeInvalidSchema, err
+		}
+		if fn != "" {
+			return transactions.CodeInvalidSchema, fmt.Errorf("function %s requires the %q hard fork", fn, consensus.ForkCryptoFuncs)
+		}
+	}
+
+	if !forkActive(svc, consensus.ForkWasm, ctx.BlockContext.Height) {
+		for _, ext := range d.schema.Extensions {
+			if ext.Name == wasm.PrecompileName {
+				return transactions.CodeInvalidSchema, ErrWasmNotActive
+			}
+		}
+	}
+
+	d.schedules = nil
+	if forkActive(svc, consensus.ForkScheduledProcedures, ctx.BlockContext.Height) {
+		d.schedules, err = scheduler.Schedules(d.schema)
+		if err != nil {
+			return transactions.CodeInvalidSchema, err
+		}
+		if len(d.schedules) > 0 && ctx.BlockContext.ChainContext.NetworkParameters.DisabledGasCosts {
+			return transactions.CodeInvalidSchema, scheduler.ErrGasDisabled
+		}
+	}
+
 	d.identifier, err = ident.Identifier(tx.Signature.Type, tx.Sender)
 	if err != nil {
 		return transactions.CodeUnknownError, err
@@ -258,11 +346,66 @@ func (d *deployDatasetRoute) PreTx(ctx *common.TxContext, svc *common.Service, t
 	return 0, nil
 }
 
+// cryptoFuncs are the built-in functions enabled by the crypto_funcs hard fork.
+var cryptoFuncs = []string{"keccak256", "ecrecover", "ed25519_verify", "eth_address"}
+
+// calledFunction returns the first of the named built-in functions that is
+// called by a procedure or action in the schema, or "" if none are. Errors in
+// the bodies are left for dataset creation to report.
+func calledFunction(schema *types.Schema, fns []string) (string, error) {
+	called := make(map[string]bool)
+	visit := func(ast any) {
+		// The traversal order is not deterministic, but the set of calls is.
+		parse.RecursivelyVisitPositions(ast, func(p parse.GetPositioner) {
+			if call, ok := p.(*parse.ExpressionFunctionCall); ok {
+				called[call.Name] = true
+			}
+		})
+	}
+
+	for _, proc := range schema.Procedures {
+		res, err := parse.ParseProcedure(proc, schema)
+		if err != nil {
+			return "", err
+		}
+		visit(res.AST)
+	}
+	for _, action := range schema.Actions {
+		res, err := parse.ParseAction(action, schema)
+		if err != nil {
+			return "", err
+		}
+		visit(res.AST)
+	}
+
+	for _, fn := range fns {
+		if called[fn] {
+			return fn, nil
+		}
+	}
+	return "", nil
+}
+
 func (d *deployDatasetRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
 	err := app.Engine.CreateDataset(ctx, app.DB, d.schema)
 	if err != nil {
 		return transactions.CodeUnknownError, err
 	}
+
+	// scheduled procedures are run as the owner that deployed the dataset
+	for _, schedule := range d.schedules {
+		schedule.DBID = d.schema.DBID()
+		schedule.Owner = tx.Sender
+		schedule.Caller = d.identifier
+		schedule.Authenticator = d.authType
+		schedule.StartHeight = ctx.BlockContext.Height
+
+		err = addSchedule(ctx.Ctx, app.DB, schedule)
+		if err != nil {
+			return transactions.CodeInvalidSchema, err
+		}
+	}
+
 	return 0, nil
 }
 
@@ -301,6 +444,12 @@ func (d *dropDatasetRoute) InTx(ctx *common.TxContext, app *common.App, tx *tran
 	if err != nil {
 		return transactions.CodeUnknownError, err
 	}
+
+	err = deleteSchedules(ctx.Ctx, app.DB, d.dbid)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
 	return 0, nil
 }
 
@@ -426,7 +575,8 @@ func (d *transferRoute) InTx(ctx *common.TxContext, app *common.App, tx *transac
 }
 
 type validatorJoinRoute struct {
-	power uint64
+	power        uint64
+	consensusKey []byte
 }
 
 var _ consensus.Route = (*validatorJoinRoute)(nil)
@@ -452,6 +602,16 @@ func (d *validatorJoinRoute) PreTx(ctx *common.TxContext, svc *common.Service, t
 	}
 
 	d.power = join.Power
+	d.consensusKey = nil
+	if len(join.ConsensusKey) > 0 && !bytes.Equal(join.ConsensusKey, tx.Sender) {
+		if !forkActive(svc, consensus.ForkConsensusKeys, ctx.BlockContext.Height) {
+			return transactions.CodeInvalidTxType, ErrConsensusKeysNotActive
+		}
+		if len(join.ConsensusKey) != ed25519.PublicKeySize {
+			return transactions.CodeEncodingError, fmt.Errorf("invalid consensus key length %d", len(join.ConsensusKey))
+		}
+		d.consensusKey = join.ConsensusKey
+	}
 	return 0, nil
 }
 
@@ -475,10 +635,32 @@ func (d *validatorJoinRoute) InTx(ctx *common.TxContext, app *common.App, tx *tr
 		return transactions.CodeInvalidSender, fmt.Errorf("validator already has a pending join request")
 	}
 
+	if forkActive(app.Service, consensus.ForkConsensusKeys, ctx.BlockContext.Height) {
+		code, err := d.setConsensusKey(ctx, app, tx.Sender)
+		if err != nil {
+			return code, err
+		}
+	}
+
+	// with staking enabled, the requested power is ignored and the power is
+	// derived from the candidate's stake
+	joinPower := int64(d.power)
+	params := &app.Service.GenesisConfig.ConsensusParams.Validator
+	if params.Staking {
+		stake, err := getStake(ctx.Ctx, app.DB, tx.Sender)
+		if err != nil {
+			return transactions.CodeUnknownError, err
+		}
+		joinPower = staking.Power(params, stake)
+		if joinPower == 0 {
+			return transactions.CodeInvalidAmount, fmt.Errorf("%w: staked %s, minimum stake %s", staking.ErrInsufficientStake, stake, params.MinStake)
+		}
+	}
+
 	// there are no pending join requests, so we can create a new one
 	joinReq := &voting.UpdatePowerRequest{
 		PubKey: tx.Sender,
-		Power:  int64(d.power),
+		Power:  joinPower,
 	}
 	bts, err := joinReq.MarshalBinary()
 	if err != nil {
@@ -498,6 +680,43 @@ func (d *validatorJoinRoute) InTx(ctx *common.TxContext, app *common.App, tx *tr
 	return 0, nil
 }
 
+// setConsensusKey records the candidate's consensus key, which must not be the
+// consensus key of another validator or candidate, since CometBFT identifies
+// validators by their consensus key.
+func (d *validatorJoinRoute) setConsensusKey(ctx *common.TxContext, app *common.App, candidate []byte) (transactions.TxCode, error) {
+	consensusKey := candidate
+	if d.consensusKey != nil {
+		consensusKey = d.consensusKey
+
+		// validators and candidates without a consensus key use their own
+		power, err := getVoterPower(ctx.Ctx, app.DB, consensusKey)
+		if err != nil {
+			return transactions.CodeUnknownError, err
+		}
+		pending, err := getResolutionsByTypeAndProposer(ctx.Ctx, app.DB, voting.ValidatorJoinEventType, consensusKey)
+		if err != nil {
+			return transactions.CodeUnknownError, err
+		}
+		if power > 0 || len(pending) > 0 {
+			return transactions.CodeInvalidSender, ErrConsensusKeyInUse
+		}
+	}
+
+	owner, err := getConsensusKeyOwner(ctx.Ctx, app.DB, consensusKey)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+	if owner != nil && !bytes.Equal(owner, candidate) {
+		return transactions.CodeInvalidSender, ErrConsensusKeyInUse
+	}
+
+	err = setConsensusKey(ctx.Ctx, app.DB, candidate, d.consensusKey)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+	return 0, nil
+}
+
 type validatorApproveRoute struct {
 	candidate []byte
 }
@@ -561,6 +780,11 @@ func (d *validatorApproveRoute) InTx(ctx *common.TxContext, app *common.App, tx
 		return transactions.CodeUnknownError, err
 	}
 
+	err = onVote(ctx, app, pending[0], tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
 	return 0, nil
 }
 
@@ -650,6 +874,11 @@ func (d *validatorRemoveRoute) InTx(ctx *common.TxContext, app *common.App, tx *
 		return transactions.CodeUnknownError, err
 	}
 
+	err = onVote(ctx, app, event.ID(), tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
 	return 0, nil
 }
 
@@ -691,6 +920,64 @@ func (d *validatorLeaveRoute) InTx(ctx *common.TxContext, app *common.App, tx *t
 	return 0, nil
 }
 
+// validatorUnjailRoute is a route for a jailed validator to restore the power
+// it had when it was jailed, once the jail period has passed.
+type validatorUnjailRoute struct{}
+
+var _ consensus.Route = (*validatorUnjailRoute)(nil)
+
+func (d *validatorUnjailRoute) Name() string {
+	return transactions.PayloadTypeValidatorUnjail.String()
+}
+
+func (d *validatorUnjailRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(10000000000000), nil
+}
+
+func (d *validatorUnjailRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationInProgress ||
+		ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationCompleted {
+		return transactions.CodeNetworkInMigration, errors.New("cannot unjail validator during migration")
+	}
+	return 0, nil // no payload to decode or validate for this route
+}
+
+func (d *validatorUnjailRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	liveness, err := getLiveness(ctx.Ctx, app.DB, tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+	if liveness == nil || !liveness.Jailed {
+		return transactions.CodeInvalidSender, ErrCallerNotJailed
+	}
+
+	jailPeriod := app.Service.GenesisConfig.ConsensusParams.Validator.JailPeriod
+	if releaseHeight := liveness.JailedHeight + jailPeriod; ctx.BlockContext.Height < releaseHeight {
+		return transactions.CodeInvalidSender, fmt.Errorf("%w: jailed until height %d", ErrJailPeriodNotOver, releaseHeight)
+	}
+
+	power, err := unjailValidator(ctx.Ctx, app.DB, tx.Sender, ctx.BlockContext.Height)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	// A validator that rejoined while jailed keeps its current power.
+	currentPower, err := getVoterPower(ctx.Ctx, app.DB, tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+	if currentPower > 0 {
+		return 0, nil
+	}
+
+	err = setVoterPower(ctx.Ctx, app.DB, tx.Sender, power)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
 // validatorVoteIDsRoute is a route for approving a set of votes based on their IDs.
 type validatorVoteIDsRoute struct{}
 
@@ -748,6 +1035,11 @@ func (d *validatorVoteIDsRoute) InTx(ctx *common.TxContext, app *common.App, tx
 			return transactions.CodeUnknownError, err
 		}
 
+		err = onVote(ctx, app, voteID, tx.Sender)
+		if err != nil {
+			return transactions.CodeUnknownError, err
+		}
+
 		// if from local validator, delete the event now that we have voted on it and network already has the event body
 		if fromLocalValidator {
 			err = deleteEvent(ctx.Ctx, app.DB, voteID)
@@ -830,7 +1122,7 @@ func (d *validatorVoteBodiesRoute) InTx(ctx *common.TxContext, app *common.App,
 			Body: event.Body,
 		}
 
-		expiryHeight := ctx.BlockContext.Height + resCfg.ExpirationPeriod
+		expiryHeight := resCfg.ExpirationHeight(event.Body, ctx.BlockContext.Height)
 		err = createResolution(ctx.Ctx, app.DB, ev, expiryHeight, tx.Sender)
 		if err != nil {
 			return transactions.CodeUnknownError, err
@@ -843,6 +1135,11 @@ func (d *validatorVoteBodiesRoute) InTx(ctx *common.TxContext, app *common.App,
 			return transactions.CodeUnknownError, err
 		}
 
+		err = onVote(ctx, app, ev.ID(), tx.Sender)
+		if err != nil {
+			return transactions.CodeUnknownError, err
+		}
+
 		// If the local validator is the proposer, then we should delete the event from the event store.
 		if fromLocalValidator {
 			err = deleteEvent(ctx.Ctx, app.DB, ev.ID())
@@ -855,6 +1152,64 @@ func (d *validatorVoteBodiesRoute) InTx(ctx *common.TxContext, app *common.App,
 	return 0, nil
 }
 
+// onVote calls the OnVote function of a resolution's type, if it has one,
+// after a validator has voted for the resolution. The function is called in a
+// nested transaction, and if it fails, its changes are rolled back and the
+// error is logged, since the vote itself is still valid.
+func onVote(ctx *common.TxContext, app *common.App, resolutionID *types.UUID, voter []byte) error {
+	// avoid reading the resolution if no resolution type has an OnVote function
+	if !hasOnVote() {
+		return nil
+	}
+
+	resolution, err := resolutionByID(ctx.Ctx, app.DB, resolutionID)
+	if err != nil {
+		return err
+	}
+
+	resCfg, err := resolutions.GetResolution(resolution.Type)
+	if err != nil {
+		return err
+	}
+	if resCfg.OnVote == nil {
+		return nil
+	}
+
+	tx, err := app.DB.BeginTx(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+
+	err = resCfg.OnVote(ctx.Ctx, &common.App{
+		Service: app.Service.NamedLogger(resolution.Type),
+		DB:      tx,
+		Engine:  app.Engine,
+	}, resolution, voter, ctx.BlockContext)
+	if err != nil {
+		err2 := tx.Rollback(ctx.Ctx)
+		if err2 != nil {
+			return fmt.Errorf("error rolling back transaction: %s, error: %s", err.Error(), err2.Error())
+		}
+
+		app.Service.Logger.Warn("error running resolution vote function", log.String("type", resolution.Type), log.String("id", resolutionID.String()), log.Error(err))
+		return nil
+	}
+
+	return tx.Commit(ctx.Ctx)
+}
+
+// hasOnVote returns true if any registered resolution type has an OnVote
+// function.
+func hasOnVote() bool {
+	for _, name := range resolutions.ListResolutions() {
+		resCfg, err := resolutions.GetResolution(name)
+		if err == nil && resCfg.OnVote != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type createResolutionRoute struct {
 	resolution *types.VotableEvent
 	expiry     int64
@@ -904,9 +1259,12 @@ func (d *createResolutionRoute) PreTx(ctx *common.TxContext, svc *common.Service
 	if err != nil {
 		return transactions.CodeInvalidResolutionType, err
 	}
+	if res.Resolution.Type == wasm.ModuleDeploymentType && !forkActive(svc, consensus.ForkWasm, ctx.BlockContext.Height) {
+		return transactions.CodeInvalidResolutionType, ErrWasmNotActive
+	}
 
 	d.resolution = (*types.VotableEvent)(res.Resolution)
-	d.expiry = resCfg.ExpirationPeriod + ctx.BlockContext.Height
+	d.expiry = resCfg.ExpirationHeight(res.Resolution.Body, ctx.BlockContext.Height)
 
 	return 0, nil
 }
@@ -935,6 +1293,11 @@ func (d *createResolutionRoute) InTx(ctx *common.TxContext, app *common.App, tx
 		return transactions.CodeUnknownError, err
 	}
 
+	err = onVote(ctx, app, d.resolution.ID(), tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
 	return 0, nil
 }
 
@@ -999,6 +1362,349 @@ func (d *approveResolutionRoute) InTx(ctx *common.TxContext, app *common.App, tx
 		return transactions.CodeUnknownError, err
 	}
 
+	err = onVote(ctx, app, d.resolutionID, tx.Sender)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
+type createSessionRoute struct {
+	session *sessions.Session
+}
+
+var _ consensus.Route = (*createSessionRoute)(nil)
+
+func (d *createSessionRoute) Name() string {
+	return transactions.PayloadTypeCreateSession.String()
+}
+
+func (d *createSessionRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(210_000), nil
+}
+
+func (d *createSessionRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if !forkActive(svc, consensus.ForkSessions, ctx.BlockContext.Height) {
+		return transactions.CodeInvalidTxType, ErrSessionsNotActive
+	}
+
+	create := &transactions.CreateSession{}
+	err := create.UnmarshalBinary(tx.Body.Payload)
+	if err != nil {
+		return transactions.CodeEncodingError, err
+	}
+
+	if len(create.SessionKey) == 0 {
+		return transactions.CodeInvalidSender, errors.New("session key is required")
+	}
+	if bytes.Equal(create.SessionKey, tx.Sender) {
+		return transactions.CodeInvalidSender, errors.New("an account cannot be its own session key")
+	}
+
+	if len(create.Scopes) == 0 {
+		return transactions.CodeUnknownError, errors.New("session must have at least one scope")
+	}
+	for _, scope := range create.Scopes {
+		if scope.DBID == "" {
+			return transactions.CodeUnknownError, errors.New("session scope must have a dataset")
+		}
+	}
+
+	maxFee, ok := new(big.Int).SetString(create.MaxFee, 10)
+	if !ok {
+		return transactions.CodeInvalidAmount, fmt.Errorf("failed to parse max fee: %s", create.MaxFee)
+	}
+	if maxFee.Sign() < 0 {
+		return transactions.CodeInvalidAmount, fmt.Errorf("invalid max fee: %s", create.MaxFee)
+	}
+
+	if create.Expiry > math.MaxInt64 || int64(create.Expiry) <= ctx.BlockContext.Height {
+		return transactions.CodeUnknownError, fmt.Errorf("session expiry %d is not after the current height %d",
+			create.Expiry, ctx.BlockContext.Height)
+	}
+
+	// The session key must consent to acting for the sender, or anyone could
+	// claim an account as their session key.
+	if create.Consent == nil {
+		return transactions.CodeInvalidSignature, errors.New("session key consent signature is required")
+	}
+	authn, err := authExt.GetAuthenticator(create.Consent.Type)
+	if err != nil {
+		return transactions.CodeInvalidSignature, err
+	}
+	msg := create.ConsentMessage(tx.Sender, ctx.BlockContext.ChainContext.ChainID)
+	if err = authn.Verify(create.SessionKey, msg, create.Consent.Signature); err != nil {
+		svc.Logger.Debug("session key consent verification failed", log.Error(err))
+		return transactions.CodeInvalidSignature, ErrInvalidConsent
+	}
+
+	d.session = &sessions.Session{
+		Key:           create.SessionKey,
+		Delegator:     tx.Sender,
+		DelegatorType: tx.Signature.Type,
+		KeyType:       create.Consent.Type,
+		Scopes:        create.Scopes,
+		MaxFee:        maxFee,
+		Spent:         big.NewInt(0),
+		Expiry:        int64(create.Expiry),
+	}
+	return 0, nil
+}
+
+func (d *createSessionRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	// The session key must be a new key. An existing account could otherwise
+	// have pending transactions of its own that would execute as the delegator.
+	acct, err := getAccount(ctx.Ctx, app.DB, d.session.Key)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+	if acct.Nonce != 0 || acct.Balance.Sign() != 0 {
+		return transactions.CodeInvalidSender, errors.New("session key is already an account")
+	}
+
+	err = createSession(ctx.Ctx, app.DB, d.session)
+	if err != nil {
+		if errors.Is(err, sessions.ErrSessionExists) {
+			return transactions.CodeInvalidSender, err
+		}
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
+type revokeSessionRoute struct {
+	sessionKey []byte
+}
+
+var _ consensus.Route = (*revokeSessionRoute)(nil)
+
+func (d *revokeSessionRoute) Name() string {
+	return transactions.PayloadTypeRevokeSession.String()
+}
+
+func (d *revokeSessionRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(210_000), nil
+}
+
+func (d *revokeSessionRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if !forkActive(svc, consensus.ForkSessions, ctx.BlockContext.Height) {
+		return transactions.CodeInvalidTxType, ErrSessionsNotActive
+	}
+
+	revoke := &transactions.RevokeSession{}
+	err := revoke.UnmarshalBinary(tx.Body.Payload)
+	if err != nil {
+		return transactions.CodeEncodingError, err
+	}
+
+	d.sessionKey = revoke.SessionKey
+	return 0, nil
+}
+
+func (d *revokeSessionRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	err := revokeSession(ctx.Ctx, app.DB, tx.Sender, d.sessionKey)
+	if err != nil {
+		if errors.Is(err, sessions.ErrSessionNotFound) || errors.Is(err, sessions.ErrNotDelegator) {
+			return transactions.CodeInvalidSender, err
+		}
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
+type withdrawRoute struct {
+	recipient []byte
+	amt       *big.Int
+}
+
+var _ consensus.Route = (*withdrawRoute)(nil)
+
+func (d *withdrawRoute) Name() string {
+	return transactions.PayloadTypeWithdraw.String()
+}
+
+func (d *withdrawRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(210_000), nil
+}
+
+func (d *withdrawRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if !svc.GenesisConfig.ConsensusParams.Withdrawals.Enabled() {
+		return transactions.CodeInvalidTxType, ErrWithdrawalsDisabled
+	}
+
+	if ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationInProgress ||
+		ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationCompleted {
+		return transactions.CodeNetworkInMigration, errors.New("cannot withdraw during migration")
+	}
+
+	withdrawBody := &transactions.Withdraw{}
+	err := withdrawBody.UnmarshalBinary(tx.Body.Payload)
+	if err != nil {
+		return transactions.CodeEncodingError, err
+	}
+
+	bigAmt, ok := new(big.Int).SetString(withdrawBody.Amount, 10)
+	if !ok {
+		return transactions.CodeInvalidAmount, fmt.Errorf("failed to parse amount: %s", withdrawBody.Amount)
+	}
+	if bigAmt.Sign() <= 0 {
+		return transactions.CodeInvalidAmount, fmt.Errorf("invalid withdrawal amount: %s", withdrawBody.Amount)
+	}
+
+	if len(withdrawBody.Recipient) != 20 {
+		return transactions.CodeEncodingError, withdrawals.ErrInvalidRecipient
+	}
+
+	d.recipient = withdrawBody.Recipient
+	d.amt = bigAmt
+	return 0, nil
+}
+
+func (d *withdrawRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	txHash, err := hex.DecodeString(ctx.TxID)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	err = burn(ctx.Ctx, app.DB, tx.Sender, d.amt)
+	if err != nil {
+		if errors.Is(err, accounts.ErrInsufficientFunds) {
+			return transactions.CodeInsufficientBalance, err
+		}
+		return transactions.CodeUnknownError, err
+	}
+
+	epochLength := app.Service.GenesisConfig.ConsensusParams.Withdrawals.EpochLength
+	err = withdraw(ctx.Ctx, app.DB, ctx.BlockContext.Height, epochLength, &withdrawals.Withdrawal{
+		TxHash:    txHash,
+		Sender:    tx.Sender,
+		Recipient: d.recipient,
+		Amount:    d.amt,
+	})
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
+type stakeRoute struct {
+	amt *big.Int
+}
+
+var _ consensus.Route = (*stakeRoute)(nil)
+
+func (d *stakeRoute) Name() string {
+	return transactions.PayloadTypeStake.String()
+}
+
+func (d *stakeRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(210_000), nil
+}
+
+func (d *stakeRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if !svc.GenesisConfig.ConsensusParams.Validator.Staking {
+		return transactions.CodeInvalidTxType, ErrStakingDisabled
+	}
+
+	if ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationInProgress ||
+		ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationCompleted {
+		return transactions.CodeNetworkInMigration, errors.New("cannot stake during migration")
+	}
+
+	stakeBody := &transactions.Stake{}
+	err := stakeBody.UnmarshalBinary(tx.Body.Payload)
+	if err != nil {
+		return transactions.CodeEncodingError, err
+	}
+
+	bigAmt, ok := new(big.Int).SetString(stakeBody.Amount, 10)
+	if !ok {
+		return transactions.CodeInvalidAmount, fmt.Errorf("failed to parse amount: %s", stakeBody.Amount)
+	}
+	if bigAmt.Sign() <= 0 {
+		return transactions.CodeInvalidAmount, fmt.Errorf("invalid stake amount: %s", stakeBody.Amount)
+	}
+
+	d.amt = bigAmt
+	return 0, nil
+}
+
+func (d *stakeRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	err := burn(ctx.Ctx, app.DB, tx.Sender, d.amt)
+	if err != nil {
+		if errors.Is(err, accounts.ErrInsufficientFunds) {
+			return transactions.CodeInsufficientBalance, err
+		}
+		return transactions.CodeUnknownError, err
+	}
+
+	err = addStake(ctx.Ctx, app.DB, tx.Sender, d.amt)
+	if err != nil {
+		return transactions.CodeUnknownError, err
+	}
+
+	return 0, nil
+}
+
+type unstakeRoute struct {
+	amt *big.Int
+}
+
+var _ consensus.Route = (*unstakeRoute)(nil)
+
+func (d *unstakeRoute) Name() string {
+	return transactions.PayloadTypeUnstake.String()
+}
+
+func (d *unstakeRoute) Price(ctx context.Context, app *common.App, tx *transactions.Transaction) (*big.Int, error) {
+	return big.NewInt(210_000), nil
+}
+
+func (d *unstakeRoute) PreTx(ctx *common.TxContext, svc *common.Service, tx *transactions.Transaction) (transactions.TxCode, error) {
+	if !svc.GenesisConfig.ConsensusParams.Validator.Staking {
+		return transactions.CodeInvalidTxType, ErrStakingDisabled
+	}
+
+	if ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationInProgress ||
+		ctx.BlockContext.ChainContext.NetworkParameters.MigrationStatus == types.MigrationCompleted {
+		return transactions.CodeNetworkInMigration, errors.New("cannot unstake during migration")
+	}
+
+	unstakeBody := &transactions.Unstake{}
+	err := unstakeBody.UnmarshalBinary(tx.Body.Payload)
+	if err != nil {
+		return transactions.CodeEncodingError, err
+	}
+
+	bigAmt, ok := new(big.Int).SetString(unstakeBody.Amount, 10)
+	if !ok {
+		return transactions.CodeInvalidAmount, fmt.Errorf("failed to parse amount: %s", unstakeBody.Amount)
+	}
+	if bigAmt.Sign() <= 0 {
+		return transactions.CodeInvalidAmount, fmt.Errorf("invalid unstake amount: %s", unstakeBody.Amount)
+	}
+
+	d.amt = bigAmt
+	return 0, nil
+}
+
+func (d *unstakeRoute) InTx(ctx *common.TxContext, app *common.App, tx *transactions.Transaction) (transactions.TxCode, error) {
+	// the unstaked tokens are returned to the sender's balance once the
+	// unbonding period is over, and the sender's power is reduced at the end
+	// of the block.
+	releaseHeight := ctx.BlockContext.Height + app.Service.GenesisConfig.ConsensusParams.Validator.UnbondingPeriod
+	err := unstake(ctx.Ctx, app.DB, tx.Sender, d.amt, releaseHeight)
+	if err != nil {
+		if errors.Is(err, staking.ErrInsufficientStake) {
+			return transactions.CodeInvalidAmount, err
+		}
+		return transactions.CodeUnknownError, err
+	}
+
 	return 0, nil
 }
 
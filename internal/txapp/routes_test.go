@@ -1,6 +1,7 @@
 package txapp
 
 import (
+	"bytes"
 	"testing"
 
 	"context"
@@ -8,19 +9,29 @@ import (
 	"math/big"
 
 	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain"
 	sql "github.com/kwilteam/kwil-db/common/sql"
 	"github.com/kwilteam/kwil-db/core/crypto"
 	"github.com/kwilteam/kwil-db/core/crypto/auth"
 	"github.com/kwilteam/kwil-db/core/log"
 	"github.com/kwilteam/kwil-db/core/types"
 	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
 	"github.com/kwilteam/kwil-db/extensions/resolutions"
+	"github.com/kwilteam/kwil-db/internal/accounts"
+	"github.com/kwilteam/kwil-db/internal/sessions"
+	"github.com/kwilteam/kwil-db/internal/staking"
 	"github.com/kwilteam/kwil-db/internal/voting"
+	"github.com/kwilteam/kwil-db/internal/wasm"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-const testType = "test"
+const (
+	testType  = "test"
+	hooksType = "test_hooks" // registered by tests that use resolution hooks
+)
 
 func init() {
 	err := resolutions.RegisterResolution(testType, resolutions.ModAdd, resolutions.ResolutionConfig{})
@@ -62,6 +73,47 @@ func Test_Routes(t *testing.T) {
 		ctx     *common.TxContext                   // optional, if nil, will automatically create a mock
 		from    auth.Signer                         // optional, if nil, will automatically use default validatorSigner1
 		err     error                               // if not nil, expect this error
+		genesis *chain.GenesisConfig                // optional, if nil, will automatically use the default
+	}
+
+	stakingGenesis := chain.DefaultGenesisConfig()
+	stakingGenesis.ConsensusParams.Validator.Staking = true
+	withdrawalGenesis := chain.DefaultGenesisConfig()
+	withdrawalGenesis.ConsensusParams.Withdrawals.Contract = make([]byte, 20)
+	stakingGenesis.ConsensusParams.Validator.MinStake = big.NewInt(1000)
+	stakingGenesis.ConsensusParams.Validator.StakePerPower = big.NewInt(100)
+	stakingGenesis.ConsensusParams.Validator.UnbondingPeriod = 50
+	consensusKeysGenesis := chain.DefaultGenesisConfig()
+	consensusKeysGenesis.ForkHeights = map[string]*uint64{consensus.ForkConsensusKeys: new(uint64)}
+	sessionsGenesis := chain.DefaultGenesisConfig()
+	sessionsGenesis.ForkHeights = map[string]*uint64{consensus.ForkSessions: new(uint64)}
+
+	// createSession makes a payload for validatorSigner2 as the session key of
+	// validatorSigner1, with consent signed for the delegator and chain ID.
+	createSessionPayload := func(delegator []byte, chainID string) *transactions.CreateSession {
+		create := &transactions.CreateSession{
+			SessionKey: validatorSigner2().Identity(),
+			Scopes:     []*transactions.SessionScope{{DBID: "xdbid", Procedures: []string{"post"}}},
+			MaxFee:     "1000",
+			Expiry:     10,
+		}
+		sig, err := validatorSigner2().Sign(create.ConsentMessage(delegator, chainID))
+		if err != nil {
+			panic(err)
+		}
+		create.Consent = sig
+		return create
+	}
+	sessionCtx := func() *common.TxContext {
+		return &common.TxContext{
+			BlockContext: &common.BlockContext{
+				ChainContext: &common.ChainContext{
+					ChainID:           "chainid",
+					NetworkParameters: &common.NetworkParameters{},
+				},
+				Height: 1,
+			},
+		}
 	}
 
 	// due to the relative simplicity of routes and pricing, I have only tested a few complex ones.
@@ -235,6 +287,474 @@ func Test_Routes(t *testing.T) {
 			from: validatorSigner2(),
 			err:  ErrCallerNotProposer,
 		},
+		{
+			// testing withdraw, which burns the amount and records the
+			// withdrawal in the epoch of the block
+			name: "withdraw",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				var burned *big.Int
+				var withdrawal *withdrawals.Withdrawal
+
+				burn = func(_ context.Context, _ sql.Executor, _ []byte, amt *big.Int) error {
+					burned = amt
+					return nil
+				}
+				withdraw = func(_ context.Context, _ sql.Executor, height, epochLength int64, w *withdrawals.Withdrawal) error {
+					assert.Equal(t, int64(5), height)
+					assert.Equal(t, int64(600), epochLength)
+					withdrawal = w
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, big.NewInt(100), burned)
+				require.NotNil(t, withdrawal)
+				assert.Equal(t, []byte{0xab, 0xcd}, withdrawal.TxHash)
+				assert.Equal(t, validatorSigner1().Identity(), withdrawal.Sender)
+			},
+			payload: &transactions.Withdraw{
+				Recipient: make([]byte, 20),
+				Amount:    "100",
+			},
+			ctx: &common.TxContext{
+				TxID: "abcd",
+				BlockContext: &common.BlockContext{
+					Height: 5,
+				},
+			},
+			genesis: withdrawalGenesis,
+		},
+		{
+			// testing withdraw to an address that is not an Ethereum address
+			// should fail
+			name: "withdraw, invalid recipient",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				burnCount := 0
+
+				burn = func(_ context.Context, _ sql.Executor, _ []byte, _ *big.Int) error {
+					burnCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, burnCount)
+			},
+			payload: &transactions.Withdraw{
+				Recipient: []byte("recipient"),
+				Amount:    "100",
+			},
+			genesis: withdrawalGenesis,
+			err:     withdrawals.ErrInvalidRecipient,
+		},
+		{
+			// withdrawals are disabled without a bridge contract
+			name: "withdraw, withdrawals disabled",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				burnCount := 0
+
+				burn = func(_ context.Context, _ sql.Executor, _ []byte, _ *big.Int) error {
+					burnCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, burnCount)
+			},
+			payload: &transactions.Withdraw{
+				Recipient: make([]byte, 20),
+				Amount:    "100",
+			},
+			err: ErrWithdrawalsDisabled,
+		},
+		{
+			// a jailed validator is restored to its power once the jail
+			// period is over
+			name: "validator_unjail",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				var restoredPower int64
+
+				getLiveness = func(_ context.Context, _ sql.Executor, validator []byte) (*voting.Liveness, error) {
+					return &voting.Liveness{Validator: validator, Jailed: true, JailedHeight: 10, JailedPower: 5}, nil
+				}
+				unjailValidator = func(_ context.Context, _ sql.Executor, _ []byte, _ int64) (int64, error) {
+					return 5, nil
+				}
+				getVoterPower = func(ctx context.Context, db sql.Executor, identifier []byte) (int64, error) {
+					return 0, nil
+				}
+				setVoterPower = func(_ context.Context, _ sql.Executor, _ []byte, power int64) error {
+					restoredPower = power
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, int64(5), restoredPower)
+			},
+			payload: &transactions.ValidatorUnjail{},
+			ctx: &common.TxContext{
+				BlockContext: &common.BlockContext{
+					Height: 10 + chain.DefaultGenesisConfig().ConsensusParams.Validator.JailPeriod,
+				},
+			},
+		},
+		{
+			name: "validator_unjail, not jailed",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				unjailCount := 0
+
+				getLiveness = func(_ context.Context, _ sql.Executor, validator []byte) (*voting.Liveness, error) {
+					return &voting.Liveness{Validator: validator}, nil
+				}
+				unjailValidator = func(_ context.Context, _ sql.Executor, _ []byte, _ int64) (int64, error) {
+					unjailCount++
+					return 0, nil
+				}
+
+				callback()
+				assert.Equal(t, 0, unjailCount)
+			},
+			payload: &transactions.ValidatorUnjail{},
+			err:     ErrCallerNotJailed,
+		},
+		{
+			// testing stake, which burns the amount from the balance and
+			// adds it to the sender's stake
+			name: "stake",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				var burned, staked *big.Int
+
+				burn = func(_ context.Context, _ sql.Executor, _ []byte, amt *big.Int) error {
+					burned = amt
+					return nil
+				}
+				addStake = func(_ context.Context, _ sql.Executor, staker []byte, amt *big.Int) error {
+					assert.Equal(t, validatorSigner1().Identity(), staker)
+					staked = amt
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, big.NewInt(100), burned)
+				assert.Equal(t, big.NewInt(100), staked)
+			},
+			payload: &transactions.Stake{Amount: "100"},
+			genesis: stakingGenesis,
+		},
+		{
+			name: "stake, staking disabled",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				stakeCount := 0
+
+				addStake = func(_ context.Context, _ sql.Executor, _ []byte, _ *big.Int) error {
+					stakeCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, stakeCount)
+			},
+			payload: &transactions.Stake{Amount: "100"},
+			err:     ErrStakingDisabled,
+		},
+		{
+			// unstaked tokens are released after the unbonding period
+			name: "unstake",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				var releaseHeight int64
+
+				unstake = func(_ context.Context, _ sql.Executor, _ []byte, amt *big.Int, height int64) error {
+					assert.Equal(t, big.NewInt(100), amt)
+					releaseHeight = height
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, int64(55), releaseHeight)
+			},
+			payload: &transactions.Unstake{Amount: "100"},
+			ctx: &common.TxContext{
+				BlockContext: &common.BlockContext{
+					Height: 5,
+				},
+			},
+			genesis: stakingGenesis,
+		},
+		{
+			name: "unstake, insufficient stake",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				unstake = func(_ context.Context, _ sql.Executor, _ []byte, _ *big.Int, _ int64) error {
+					return staking.ErrInsufficientStake
+				}
+
+				callback()
+			},
+			payload: &transactions.Unstake{Amount: "100"},
+			genesis: stakingGenesis,
+			err:     staking.ErrInsufficientStake,
+		},
+		{
+			// with staking enabled, the join power is derived from the stake
+			name: "validator_join, staking",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				var joinPower int64
+
+				getVoterPower = func(_ context.Context, _ sql.Executor, _ []byte) (int64, error) {
+					return 0, nil
+				}
+				getResolutionsByTypeAndProposer = func(_ context.Context, _ sql.Executor, _ string, _ []byte) ([]*types.UUID, error) {
+					return nil, nil
+				}
+				getStake = func(_ context.Context, _ sql.Executor, _ []byte) (*big.Int, error) {
+					return big.NewInt(1250), nil
+				}
+				createResolution = func(_ context.Context, _ sql.TxMaker, event *types.VotableEvent, _ int64, _ []byte) error {
+					req := &voting.UpdatePowerRequest{}
+					require.NoError(t, req.UnmarshalBinary(event.Body))
+					joinPower = req.Power
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, int64(12), joinPower)
+			},
+			payload: &transactions.ValidatorJoin{Power: 1},
+			genesis: stakingGenesis,
+		},
+		{
+			// a join request may set a consensus key other than the sender's
+			name: "validator_join, consensus key",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				var id, key []byte
+
+				getVoterPower = func(_ context.Context, _ sql.Executor, _ []byte) (int64, error) {
+					return 0, nil
+				}
+				getResolutionsByTypeAndProposer = func(_ context.Context, _ sql.Executor, _ string, _ []byte) ([]*types.UUID, error) {
+					return nil, nil
+				}
+				getConsensusKeyOwner = func(_ context.Context, _ sql.Executor, _ []byte) ([]byte, error) {
+					return nil, nil
+				}
+				setConsensusKey = func(_ context.Context, _ sql.Executor, identifier, consensusKey []byte) error {
+					id, key = identifier, consensusKey
+					return nil
+				}
+				createResolution = func(_ context.Context, _ sql.TxMaker, _ *types.VotableEvent, _ int64, _ []byte) error {
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, validatorSigner1().Identity(), id)
+				assert.Equal(t, validatorSigner2().Identity(), key)
+			},
+			payload: &transactions.ValidatorJoin{Power: 1, ConsensusKey: validatorSigner2().Identity()},
+			genesis: consensusKeysGenesis,
+		},
+		{
+			// a validator's key cannot be another validator's consensus key
+			name: "validator_join, consensus key of a validator",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				setCount := 0
+
+				getVoterPower = func(_ context.Context, _ sql.Executor, identifier []byte) (int64, error) {
+					if bytes.Equal(identifier, validatorSigner2().Identity()) {
+						return 1, nil
+					}
+					return 0, nil
+				}
+				getResolutionsByTypeAndProposer = func(_ context.Context, _ sql.Executor, _ string, _ []byte) ([]*types.UUID, error) {
+					return nil, nil
+				}
+				getConsensusKeyOwner = func(_ context.Context, _ sql.Executor, _ []byte) ([]byte, error) {
+					return nil, nil
+				}
+				setConsensusKey = func(_ context.Context, _ sql.Executor, _, _ []byte) error {
+					setCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, setCount)
+			},
+			payload: &transactions.ValidatorJoin{Power: 1, ConsensusKey: validatorSigner2().Identity()},
+			genesis: consensusKeysGenesis,
+			err:     ErrConsensusKeyInUse,
+		},
+		{
+			// consensus keys cannot be set before the fork activates
+			name: "validator_join, consensus keys not active",
+			fee:  10000000000000,
+			fn: func(t *testing.T, callback func()) {
+				setCount := 0
+				setConsensusKey = func(_ context.Context, _ sql.Executor, _, _ []byte) error {
+					setCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, setCount)
+			},
+			payload: &transactions.ValidatorJoin{Power: 1, ConsensusKey: validatorSigner2().Identity()},
+			err:     ErrConsensusKeysNotActive,
+		},
+		{
+			// the session key consents to acting for the sender
+			name: "create_session",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				var created *sessions.Session
+				createSession = func(_ context.Context, _ sql.Executor, session *sessions.Session) error {
+					created = session
+					return nil
+				}
+
+				callback()
+				require.NotNil(t, created)
+				assert.Equal(t, validatorSigner2().Identity(), created.Key)
+				assert.Equal(t, validatorSigner1().Identity(), created.Delegator)
+				assert.Equal(t, auth.Ed25519Auth, created.KeyType)
+			},
+			payload: createSessionPayload(validatorSigner1().Identity(), "chainid"),
+			ctx:     sessionCtx(),
+			genesis: sessionsGenesis,
+		},
+		{
+			// sessions cannot be created before the fork activates
+			name: "create_session, sessions not active",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				createCount := 0
+				createSession = func(_ context.Context, _ sql.Executor, _ *sessions.Session) error {
+					createCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, createCount)
+			},
+			payload: createSessionPayload(validatorSigner1().Identity(), "chainid"),
+			ctx:     sessionCtx(),
+			err:     ErrSessionsNotActive,
+		},
+		{
+			// consent for another delegator cannot be reused
+			name: "create_session, consent for another delegator",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				createCount := 0
+				createSession = func(_ context.Context, _ sql.Executor, _ *sessions.Session) error {
+					createCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, createCount)
+			},
+			payload: createSessionPayload(validatorSigner2().Identity(), "chainid"),
+			ctx:     sessionCtx(),
+			genesis: sessionsGenesis,
+			err:     ErrInvalidConsent,
+		},
+		{
+			// consent for another chain cannot be reused
+			name: "create_session, consent for another chain",
+			fee:  210_000,
+			fn: func(t *testing.T, callback func()) {
+				createCount := 0
+				createSession = func(_ context.Context, _ sql.Executor, _ *sessions.Session) error {
+					createCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, createCount)
+			},
+			payload: createSessionPayload(validatorSigner1().Identity(), "otherchain"),
+			ctx:     sessionCtx(),
+			genesis: sessionsGenesis,
+			err:     ErrInvalidConsent,
+		},
+		{
+			// a resolution type can set the expiration of each resolution,
+			// and is notified of the proposer's vote
+			name: "create_resolution, with hooks",
+			fee:  4 * ValidatorVoteBodyBytePrice,
+			fn: func(t *testing.T, callback func()) {
+				var expiry int64
+				var voters [][]byte
+
+				err := resolutions.RegisterResolution(hooksType, resolutions.ModAdd, resolutions.ResolutionConfig{
+					ExpirationPeriodFunc: func(body []byte) int64 {
+						return int64(len(body)) * 10
+					},
+					OnVote: func(_ context.Context, _ *common.App, resolution *resolutions.Resolution, voter []byte, _ *common.BlockContext) error {
+						assert.Equal(t, hooksType, resolution.Type)
+						voters = append(voters, voter)
+						return nil
+					},
+				})
+				require.NoError(t, err)
+				defer resolutions.RegisterResolution(hooksType, resolutions.ModRemove, resolutions.ResolutionConfig{})
+
+				getVoterPower = func(_ context.Context, _ sql.Executor, _ []byte) (int64, error) {
+					return 1, nil
+				}
+				createResolution = func(_ context.Context, _ sql.TxMaker, _ *types.VotableEvent, expiration int64, _ []byte) error {
+					expiry = expiration
+					return nil
+				}
+				approveResolution = func(_ context.Context, _ sql.TxMaker, _ *types.UUID, _ []byte) error {
+					return nil
+				}
+				resolutionByID = func(_ context.Context, _ sql.Executor, id *types.UUID) (*resolutions.Resolution, error) {
+					return &resolutions.Resolution{ID: id, Type: hooksType}, nil
+				}
+
+				callback()
+				assert.Equal(t, int64(40), expiry)
+				assert.Equal(t, [][]byte{validatorSigner1().Identity()}, voters)
+			},
+			payload: &transactions.CreateResolution{
+				Resolution: &transactions.VotableEvent{
+					Type: hooksType,
+					Body: []byte("body"),
+				},
+			},
+		},
+		{
+			// WebAssembly modules cannot be proposed before the wasm fork
+			name: "create_resolution, wasm not active",
+			fee:  4 * ValidatorVoteBodyBytePrice,
+			fn: func(t *testing.T, callback func()) {
+				createCount := 0
+				createResolution = func(_ context.Context, _ sql.TxMaker, _ *types.VotableEvent, _ int64, _ []byte) error {
+					createCount++
+					return nil
+				}
+
+				callback()
+				assert.Equal(t, 0, createCount)
+			},
+			payload: &transactions.CreateResolution{
+				Resolution: &transactions.VotableEvent{
+					Type: wasm.ModuleDeploymentType,
+					Body: []byte("body"),
+				},
+			},
+			err: ErrWasmNotActive,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -281,8 +801,12 @@ func Test_Routes(t *testing.T) {
 				// if one isn't provided
 				if app.service == nil {
 					app.service = &common.Service{
-						Logger:   log.New(log.Config{}).Sugar(),
-						Identity: app.signer.Identity(),
+						Logger:        log.New(log.Config{}).Sugar(),
+						Identity:      app.signer.Identity(),
+						GenesisConfig: chain.DefaultGenesisConfig(),
+					}
+					if tc.genesis != nil {
+						app.service.GenesisConfig = tc.genesis
 					}
 				}
 
@@ -317,3 +841,188 @@ func Test_Routes(t *testing.T) {
 		})
 	}
 }
+
+// mockEngine records the context of executed procedures.
+type mockEngine struct {
+	common.Engine
+	callers []string
+}
+
+func (m *mockEngine) Procedure(ctx *common.TxContext, tx sql.DB, options *common.ExecutionData) (*sql.ResultSet, error) {
+	m.callers = append(m.callers, ctx.Caller)
+	return &sql.ResultSet{}, nil
+}
+
+func Test_SessionExecute(t *testing.T) {
+	delegator := validatorSigner2()
+	delegatorID, err := auth.Ed25519Authenticator{}.Identifier(delegator.Identity())
+	require.NoError(t, err)
+
+	type testcase struct {
+		name    string
+		session *sessions.Session
+		payload transactions.Payload
+		fee     int64
+		code    transactions.TxCode
+		charged int64 // expected charge to the delegator
+		callers []string
+	}
+
+	newSession := func() *sessions.Session {
+		return &sessions.Session{
+			Key:           validatorSigner1().Identity(),
+			Delegator:     delegator.Identity(),
+			DelegatorType: auth.Ed25519Auth,
+			KeyType:       auth.Ed25519Auth,
+			Scopes:        []*transactions.SessionScope{{DBID: "xdbid", Procedures: []string{"post"}}},
+			MaxFee:        big.NewInt(1e16),
+			Spent:         big.NewInt(0),
+			Expiry:        10,
+		}
+	}
+
+	testCases := []testcase{
+		{
+			name:    "executes as delegator",
+			session: newSession(),
+			payload: &transactions.ActionExecution{DBID: "xdbid", Action: "post"},
+			fee:     2e15,
+			code:    transactions.CodeOk,
+			charged: 2e15,
+			callers: []string{delegatorID},
+		},
+		{
+			name:    "not in scope",
+			session: newSession(),
+			payload: &transactions.ActionExecution{DBID: "xdbid", Action: "delete"},
+			fee:     2e15,
+			code:    transactions.CodeInvalidSender,
+		},
+		{
+			name:    "session cannot transfer",
+			session: newSession(),
+			payload: &transactions.Transfer{To: []byte("to"), Amount: "1"},
+			fee:     2e15,
+			code:    transactions.CodeInvalidSender,
+		},
+		{
+			name: "budget exceeded",
+			session: func() *sessions.Session {
+				s := newSession()
+				s.Spent = big.NewInt(9e15)
+				return s
+			}(),
+			payload: &transactions.ActionExecution{DBID: "xdbid", Action: "post"},
+			fee:     2e15,
+			code:    transactions.CodeInsufficientBalance,
+		},
+	}
+
+	defer func() {
+		getSession = sessions.GetSession
+		sessionSpend = sessions.Spend
+		getAccount = accounts.GetAccount
+		spend = accounts.Spend
+		credit = accounts.Credit
+	}()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var nonces []int64
+			charged := big.NewInt(0)
+
+			getSession = func(_ context.Context, _ sql.Executor, key []byte) (*sessions.Session, error) {
+				return tc.session, nil
+			}
+			sessionSpend = func(_ context.Context, _ sql.Executor, _ []byte, _ *big.Int) error {
+				return nil
+			}
+			getAccount = func(_ context.Context, _ sql.Executor, acctID []byte) (*types.Account, error) {
+				return &types.Account{
+					Identifier: acctID,
+					Balance:    big.NewInt(1e18),
+				}, nil
+			}
+			spend = func(_ context.Context, _ sql.Executor, acctID []byte, amt *big.Int, nonce int64) error {
+				require.Equal(t, tc.session.Key, acctID)
+				require.Zero(t, amt.Sign())
+				nonces = append(nonces, nonce)
+				return nil
+			}
+			credit = func(_ context.Context, _ sql.Executor, acctID []byte, amt *big.Int) error {
+				require.Equal(t, tc.session.Delegator, acctID)
+				charged.Sub(charged, amt)
+				return nil
+			}
+
+			tx, err := transactions.CreateTransaction(tc.payload, "chainid", 1)
+			require.NoError(t, err)
+			tx.Body.Fee = big.NewInt(tc.fee)
+			require.NoError(t, tx.Sign(validatorSigner1()))
+
+			engine := &mockEngine{}
+			app := &TxApp{
+				Engine: engine,
+				signer: validatorSigner1(),
+			}
+			app.forks.FromMap(map[string]*uint64{consensus.ForkSessions: new(uint64)})
+			app.service = &common.Service{
+				Logger:   log.New(log.Config{}).Sugar(),
+				Identity: app.signer.Identity(),
+			}
+
+			res := app.Execute(&common.TxContext{
+				Ctx: context.Background(),
+				BlockContext: &common.BlockContext{
+					Height: 5,
+					ChainContext: &common.ChainContext{
+						NetworkParameters: &common.NetworkParameters{},
+					},
+				},
+			}, &mockTx{&mockDb{}}, tx)
+
+			assert.Equal(t, tc.code, res.ResponseCode, res.Error)
+			assert.Equal(t, []int64{1}, nonces) // the session key's nonce is always used
+			assert.Equal(t, tc.charged, charged.Int64())
+			assert.Equal(t, tc.callers, engine.callers)
+		})
+	}
+}
+
+func Test_calledFunction(t *testing.T) {
+	schema := &types.Schema{
+		Name: "crypto",
+		Tables: []*types.Table{{
+			Name: "sigs",
+			Columns: []*types.Column{
+				{Name: "id", Type: types.IntType, Attributes: []*types.Attribute{{Type: types.PRIMARY_KEY}}},
+				{Name: "sig", Type: types.BlobType},
+			},
+		}},
+		Procedures: []*types.Procedure{{
+			Name:       "hash",
+			Parameters: []*types.ProcedureParameter{{Name: "$msg", Type: types.BlobType}},
+			Public:     true,
+			Returns:    &types.ProcedureReturn{Fields: []*types.NamedType{{Name: "h", Type: types.BlobType}}},
+			Body:       `return keccak256($msg);`,
+		}},
+		Actions: []*types.Action{{
+			Name:       "verify",
+			Parameters: []string{"$msg"},
+			Public:     true,
+			Body:       `SELECT id FROM sigs WHERE eth_address(sig) = $msg;`,
+		}},
+	}
+
+	fn, err := calledFunction(schema, cryptoFuncs)
+	require.NoError(t, err)
+	assert.Equal(t, "keccak256", fn)
+
+	fn, err = calledFunction(schema, []string{"ecrecover", "eth_address"})
+	require.NoError(t, err)
+	assert.Equal(t, "eth_address", fn)
+
+	fn, err = calledFunction(schema, []string{"ed25519_verify"})
+	require.NoError(t, err)
+	assert.Empty(t, fn)
+}
@@ -4,6 +4,8 @@ package txapp
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -24,7 +26,10 @@ import (
 	"github.com/kwilteam/kwil-db/extensions/hooks"
 	"github.com/kwilteam/kwil-db/extensions/resolutions"
 	"github.com/kwilteam/kwil-db/internal/accounts"
+	"github.com/kwilteam/kwil-db/internal/sessions"
+	"github.com/kwilteam/kwil-db/internal/staking"
 	"github.com/kwilteam/kwil-db/internal/voting"
+	"github.com/kwilteam/kwil-db/internal/withdrawals"
 )
 
 // TxApp maintains the state for Kwil's ABCI application.
@@ -40,7 +45,7 @@ type TxApp struct {
 
 	forks forks.Forks
 
-	events Rebroadcaster
+	events EventStore
 	signer *auth.Ed25519Signer
 
 	mempool    *mempool
@@ -57,11 +62,25 @@ type TxApp struct {
 
 	// list of pubkeys of join candidates approved by this node in the current block
 	approvedJoins [][]byte
+
+	// withdrawal epoch sealed in the current block, which this node attests
+	// to on commit if it is a validator
+	sealedEpoch *withdrawals.Epoch
+
+	// attester is this node's withdrawal attestation key, and attesterReg is
+	// its registration, which is proposed on commit until the key is
+	// registered. registerAttester is set when finalizing a block if it is
+	// not registered. If the node has no attestation key, it does not attest
+	// to withdrawal epochs.
+	attester         *ecdsa.PrivateKey
+	attesterReg      *withdrawals.AttesterRegistration
+	registerAttester bool
 }
 
-// NewTxApp creates a new router.
+// NewTxApp creates a new router. The attester is the node's withdrawal
+// attestation key, which may be nil.
 func NewTxApp(ctx context.Context, db sql.Executor, engine common.Engine, signer *auth.Ed25519Signer,
-	events Rebroadcaster, service *common.Service) (*TxApp, error) {
+	attester *ecdsa.PrivateKey, events EventStore, service *common.Service) (*TxApp, error) {
 	voteBodyTxSize, err := computeEmptyVoteBodyTxSize(service.GenesisConfig.ChainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute empty vote body tx size: %w", err)
@@ -70,6 +89,14 @@ func NewTxApp(ctx context.Context, db sql.Executor, engine common.Engine, signer
 	resTypes := resolutions.ListResolutions()
 	slices.Sort(resTypes)
 
+	var attesterReg *withdrawals.AttesterRegistration
+	if attester != nil {
+		attesterReg, err = withdrawals.NewAttesterRegistration(attester, service.GenesisConfig.ChainID, signer.Identity())
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign withdrawal attestation key registration: %w", err)
+		}
+	}
+
 	t := &TxApp{
 		Engine: engine,
 		events: events,
@@ -77,11 +104,14 @@ func NewTxApp(ctx context.Context, db sql.Executor, engine common.Engine, signer
 			nodeAddr: signer.Identity(),
 		},
 		signer:              signer,
+		attester:            attester,
+		attesterReg:         attesterReg,
 		emptyVoteBodyTxSize: voteBodyTxSize,
 		resTypes:            resTypes,
 		service:             service,
 	}
 	t.forks.FromMap(service.GenesisConfig.ForkHeights)
+	t.mempool.forks = &t.forks
 	return t, nil
 }
 
@@ -102,6 +132,12 @@ func (r *TxApp) GenesisInit(ctx context.Context, db sql.DB, validators []*types.
 		if err != nil {
 			return err
 		}
+		if len(validator.ConsensusKey) > 0 {
+			err = setConsensusKey(ctx, db, validator.PubKey, validator.ConsensusKey)
+			if err != nil {
+				return err
+			}
+		}
 		voters = append(voters, validator)
 	}
 	r.validators = voters
@@ -131,6 +167,12 @@ func (r *TxApp) GenesisInit(ctx context.Context, db sql.DB, validators []*types.
 
 // Reload reloads the database state into the engine.
 func (r *TxApp) Reload(ctx context.Context, db sql.DB) error {
+	// WebAssembly modules are loaded first, since the engine initializes the
+	// precompiles of the datasets that use them
+	if err := loadWasmModules(ctx, db); err != nil {
+		return err
+	}
+
 	// Reload the engine internal state from the updated database state
 	return r.Engine.Reload(ctx, db)
 }
@@ -142,6 +184,135 @@ func (r *TxApp) UpdateValidator(ctx context.Context, db sql.DB, validator []byte
 	return setVoterPower(ctx, db, validator, power)
 }
 
+// TrackLiveness records which validators signed the block at the given
+// height, and jails any validator that has missed more than the allowed number
+// of blocks in the missed blocks window by setting its power to zero. A
+// validator is not jailed if it would leave the validator set without power.
+// It does nothing if the network does not track validator liveness. It can
+// only be called in between Begin and Finalize.
+func (r *TxApp) TrackLiveness(ctx context.Context, db sql.DB, height int64, signed, missed [][]byte) error {
+	params := r.service.GenesisConfig.ConsensusParams.Validator
+	if params.MissedBlocksWindow <= 0 {
+		return nil
+	}
+
+	missedBlocks, err := recordLiveness(ctx, db, height, params.MissedBlocksWindow, signed, missed)
+	if err != nil {
+		return err
+	}
+	if len(missedBlocks) == 0 {
+		return nil
+	}
+
+	totalPower, err := r.validatorSetPower(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, validator := range missed { // ordered by the consensus engine
+		if missedBlocks[string(validator)] <= params.MaxMissedBlocks {
+			continue
+		}
+
+		power, err := getVoterPower(ctx, db, validator)
+		if err != nil {
+			return err
+		}
+		if power <= 0 {
+			continue // already removed
+		}
+		if power >= totalPower {
+			r.service.Logger.Warn("not jailing the remaining validators", log.String("validator", hex.EncodeToString(validator)),
+				log.Int("missed_blocks", missedBlocks[string(validator)]))
+			continue
+		}
+
+		if err = jailValidator(ctx, db, validator, height, power); err != nil {
+			return err
+		}
+		if err = r.UpdateValidator(ctx, db, validator, 0); err != nil {
+			return err
+		}
+		totalPower -= power
+
+		r.service.Logger.Info("jailed validator", log.String("validator", hex.EncodeToString(validator)),
+			log.Int("missed_blocks", missedBlocks[string(validator)]), log.Int("power", power))
+	}
+
+	return nil
+}
+
+// Punish punishes a validator reported by the consensus engine for
+// misbehavior. The validator is removed if the network's validator params
+// require it, otherwise its power is reduced by one. Its power is not changed
+// if it would leave the validator set without power. If the network has a
+// slash amount, up to that amount is burned from the validator's account. The
+// evidence is recorded with the punishment applied, which is set on ev. It can
+// only be called in between Begin and Finalize, once the validator_evidence
+// hard fork is active.
+//
+// With staking enabled, the slash amount is taken from the validator's stake
+// and then its pending unbondings, and its power is only changed here if it is
+// removed, since its power is otherwise derived from the slashed stake in
+// Finalize.
+func (r *TxApp) Punish(ctx context.Context, db sql.DB, ev *types.ValidatorEvidence) error {
+	params := r.service.GenesisConfig.ConsensusParams.Validator
+
+	power, err := getVoterPower(ctx, db, ev.Validator)
+	if err != nil {
+		return err
+	}
+	ev.PowerBefore, ev.PowerAfter = power, power
+
+	if power > 0 && (params.RemoveOnMisbehavior || !params.Staking) {
+		const punishDelta = 1
+		newPower := max(power-punishDelta, 0)
+		if params.RemoveOnMisbehavior {
+			newPower = 0
+		}
+
+		totalPower, err := r.validatorSetPower(ctx, db)
+		if err != nil {
+			return err
+		}
+		if totalPower-power+newPower > 0 {
+			if err = r.UpdateValidator(ctx, db, ev.Validator, newPower); err != nil {
+				return err
+			}
+			ev.PowerAfter = newPower
+		} else {
+			r.service.Logger.Warn("not removing the remaining validators", log.String("validator", hex.EncodeToString(ev.Validator)))
+		}
+	}
+
+	slashed := big.NewInt(0)
+	if params.SlashAmount != nil && params.SlashAmount.Sign() > 0 && params.Staking {
+		if slashed, err = slashStake(ctx, db, ev.Validator, params.SlashAmount); err != nil {
+			return err
+		}
+	} else if params.SlashAmount != nil && params.SlashAmount.Sign() > 0 {
+		acct, err := getAccount(ctx, db, ev.Validator)
+		if err != nil {
+			return err
+		}
+		if slashed.Set(params.SlashAmount); slashed.Cmp(acct.Balance) > 0 {
+			slashed.Set(acct.Balance)
+		}
+		if slashed.Sign() > 0 {
+			if err = burn(ctx, db, ev.Validator, slashed); err != nil {
+				return err
+			}
+		}
+	}
+	ev.Slashed = slashed.String()
+
+	r.service.Logger.Info("punished validator", log.String("validator", hex.EncodeToString(ev.Validator)),
+		log.String("type", ev.Type), log.Int("height", ev.Height), log.Int("power", ev.PowerAfter),
+		log.String("slashed", ev.Slashed))
+
+	return storeEvidence(ctx, db, ev)
+}
+
 // SubscribeValidators creates and returns a new channel on which the current
 // validator set will be sent for each block Commit. The receiver will miss
 // updates if they are unable to receive fast enough. This should generally
@@ -245,6 +416,29 @@ func (r *TxApp) Execute(ctx *common.TxContext, db sql.DB, tx *transactions.Trans
 	return route.Execute(ctx, r, db, tx)
 }
 
+// TxResult runs the tx result hooks for a transaction that has been executed
+// with Execute, whether or not it succeeded.
+func (r *TxApp) TxResult(ctx *common.TxContext, db sql.DB, tx *transactions.Transaction, res *TxResponse) error {
+	result := &hooks.TxResult{
+		Code:  res.ResponseCode,
+		Spend: res.Spend,
+		Error: res.Error,
+	}
+
+	for _, hook := range hooks.ListTxResultHooks() {
+		err := hook.Hook(ctx, &common.App{
+			Service: r.service.NamedLogger(hook.Name),
+			DB:      db,
+			Engine:  r.Engine,
+		}, tx, result)
+		if err != nil {
+			return fmt.Errorf("error running tx result hook: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (r *TxApp) logValidatorJoinApprovals(tx *transactions.Transaction) {
 	if tx.Body.PayloadType != transactions.PayloadTypeValidatorApprove {
 		return
@@ -267,11 +461,16 @@ func (r *TxApp) logValidatorJoinApprovals(tx *transactions.Transaction) {
 // transaction that may be committed, or rolled back on error or crash.
 // It is given the starting networkParams, and is expected to use them to
 // use them to store any changes to the network parameters in the database during Finalize.
-func (r *TxApp) Begin(ctx context.Context, height int64) error {
+// After activating any hard forks, it runs the begin block hooks.
+func (r *TxApp) Begin(ctx context.Context, db sql.DB, block *common.BlockContext) error {
+	// WebAssembly modules deployed in a block that was not committed must not
+	// be used
+	discardWasmModules()
+
 	// Before executing transaction in this block, add/remove/update functionality.
-	forks := r.activations(height)
+	forks := r.activations(block.Height)
 	if len(forks) > 0 {
-		r.service.Logger.S.Infof("Forks activating at height %d: %v", height, len(forks))
+		r.service.Logger.S.Infof("Forks activating at height %d: %v", block.Height, len(forks))
 	}
 	for _, fork := range forks {
 		r.service.Logger.S.Info("Hardfork activating", log.String("fork", fork.Name))
@@ -297,6 +496,18 @@ func (r *TxApp) Begin(ctx context.Context, height int64) error {
 		}
 	}
 
+	// begin block hooks
+	for _, hook := range hooks.ListBeginBlockHooks() {
+		err := hook.Hook(ctx, &common.App{
+			Service: r.service.NamedLogger(hook.Name),
+			DB:      db,
+			Engine:  r.Engine,
+		}, block)
+		if err != nil {
+			return fmt.Errorf("error running begin block hook: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -317,6 +528,12 @@ func (r *TxApp) activations(height int64) []*consensus.Hardfork {
 	return activations
 }
 
+// forkActive reports whether the named hard fork is active at the height,
+// according to the genesis config.
+func forkActive(svc *common.Service, fork string, height int64) bool {
+	return svc.GenesisConfig.Forks().IsActive(fork, uint64(height))
+}
+
 // Finalize signals that a block has been finalized. No more changes can be
 // applied to the database. It returns the apphash and the validator set. And
 // state modifications specified by hardforks activating at this height are
@@ -328,6 +545,25 @@ func (r *TxApp) Finalize(ctx context.Context, db sql.DB, block *common.BlockCont
 		return nil, nil, nil, err
 	}
 
+	if wdParams := r.service.GenesisConfig.ConsensusParams.Withdrawals; wdParams.Enabled() {
+		r.sealedEpoch, err = sealEpoch(ctx, db, block.Height, wdParams.EpochLength)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to seal withdrawal epoch: %w", err)
+		}
+
+		if r.attester != nil {
+			attester, err := getAttester(ctx, db, r.signer.Identity())
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to get withdrawal attestation key: %w", err)
+			}
+			r.registerAttester = !bytes.Equal(attester, r.attesterReg.Address)
+		}
+	}
+
+	if err = r.processStakes(ctx, db, block.Height); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to process stakes: %w", err)
+	}
+
 	finalValidators, err = getAllVoters(ctx, db)
 	if err != nil {
 		return nil, nil, nil, err
@@ -369,6 +605,65 @@ func (r *TxApp) Finalize(ctx context.Context, db sql.DB, block *common.BlockCont
 	return finalValidators, r.approvedJoins, expiredJoins, nil
 }
 
+// processStakes returns released unbondings to the stakers' balances, and
+// sets the power of each validator to the power of its stake. Validators
+// without power, such as jailed or removed validators, are not given power by
+// their stake, and a validator's power is not changed if it would leave the
+// validator set without power. It does nothing if staking is not enabled.
+func (r *TxApp) processStakes(ctx context.Context, db sql.DB, height int64) error {
+	params := &r.service.GenesisConfig.ConsensusParams.Validator
+	if !params.Staking {
+		return nil
+	}
+
+	released, err := releaseUnbonded(ctx, db, height)
+	if err != nil {
+		return err
+	}
+	for _, unbonding := range released {
+		if err = credit(ctx, db, unbonding.Staker, unbonding.Amount); err != nil {
+			return err
+		}
+	}
+
+	validators, err := getAllVoters(ctx, db)
+	if err != nil {
+		return err
+	}
+	totalPower := validatorSetPower(validators)
+
+	for _, val := range validators { // ordered by pubkey
+		stake, err := getStake(ctx, db, val.PubKey)
+		if err != nil {
+			return err
+		}
+
+		// the total power must not exceed the consensus engine's limit
+		power := staking.Power(params, stake)
+		if limit := staking.MaxTotalPower - (totalPower - val.Power); power > limit {
+			power = max(limit, 0)
+		}
+		if power == val.Power {
+			continue
+		}
+		if totalPower-val.Power+power <= 0 {
+			r.service.Logger.Warn("not removing the remaining validators", log.String("validator", hex.EncodeToString(val.PubKey)),
+				log.String("stake", stake.String()))
+			continue
+		}
+
+		if err = r.UpdateValidator(ctx, db, val.PubKey, power); err != nil {
+			return err
+		}
+		totalPower += power - val.Power
+
+		r.service.Logger.Info("updated validator power from stake", log.String("validator", hex.EncodeToString(val.PubKey)),
+			log.String("stake", stake.String()), log.Int("power", power))
+	}
+
+	return nil
+}
+
 // processVotes confirms resolutions that have been approved by the network,
 // expires resolutions that have expired, and properly credits proposers and voters.
 func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.BlockContext) ([][]byte, error) {
@@ -382,10 +677,10 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 	// we track this and execute all of these functions after we have found all confirmed resolutions
 	// because a resolve function can change a validator's power. This would then change the required power
 	// for subsequent resolutions in the same block, which should not happen.
-	var resolveFuncs []*struct {
-		Resolution  *resolutions.Resolution
-		ResolveFunc func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error
-	}
+	var resolveFuncs []*resolutionFunc
+
+	// the outcome of closed resolutions is recorded once the fork is active
+	storeResults := r.forks.IsActive(consensus.ForkResolutionResults, uint64(block.Height))
 
 	totalPower, err := r.validatorSetPower(ctx, db)
 	if err != nil {
@@ -407,17 +702,22 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 			credits.applyResolution(resolution)
 			finalizedIDs = append(finalizedIDs, resolution.ID)
 
+			if storeResults {
+				err = storeResolutionResult(ctx, db, resolution, types.ResolutionStatusApproved,
+					requiredPower(ctx, db, cfg.ConfirmationThreshold, totalPower), block.Height)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// we do not want to mark processed for validator join and remove events, as they can occur again
 			if resolution.Type != voting.ValidatorJoinEventType && resolution.Type != voting.ValidatorRemoveEventType {
 				markProcessedIDs = append(markProcessedIDs, resolution.ID)
 			}
 
-			resolveFuncs = append(resolveFuncs, &struct {
-				Resolution  *resolutions.Resolution
-				ResolveFunc func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error
-			}{
-				Resolution:  resolution,
-				ResolveFunc: cfg.ResolveFunc,
+			resolveFuncs = append(resolveFuncs, &resolutionFunc{
+				Resolution: resolution,
+				Func:       cfg.ResolveFunc,
 			})
 		}
 	}
@@ -426,29 +726,7 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 	for _, resolveFunc := range resolveFuncs {
 		r.service.Logger.Debug("resolving resolution", log.String("type", resolveFunc.Resolution.Type), log.String("id", resolveFunc.Resolution.ID.String()))
 
-		tx, err := db.BeginTx(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		err = resolveFunc.ResolveFunc(ctx, &common.App{
-			Service: r.service.NamedLogger(resolveFunc.Resolution.Type),
-			DB:      tx,
-			Engine:  r.Engine,
-		}, resolveFunc.Resolution, block)
-		if err != nil {
-			err2 := tx.Rollback(ctx)
-			if err2 != nil {
-				return nil, fmt.Errorf("error rolling back transaction: %s, error: %s", err.Error(), err2.Error())
-			}
-
-			// if the resolveFunc fails, we should still continue on, since it simply means
-			// some business logic failed in a deployed schema.
-			r.service.Logger.Warn("error resolving resolution", log.String("type", resolveFunc.Resolution.Type), log.String("id", resolveFunc.Resolution.ID.String()), log.Error(err))
-			continue
-		}
-
-		err = tx.Commit(ctx)
+		err = r.runResolutionFunc(ctx, db, resolveFunc, block)
 		if err != nil {
 			return nil, err
 		}
@@ -463,6 +741,9 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 	expiredIDs := make([]*types.UUID, 0, len(expired))
 	requiredPowerMap := make(map[string]int64) // map of resolution type to required power
 	var expiredJoins [][]byte
+	// like resolve functions, expire functions are called after all expired
+	// resolutions are found, since they may change a validator's power.
+	var expireFuncs []*resolutionFunc
 
 	for _, resolution := range expired {
 		expiredIDs = append(expiredIDs, resolution.ID)
@@ -479,13 +760,21 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 			expiredJoins = append(expiredJoins, req.PubKey)
 		}
 
-		threshold, ok := requiredPowerMap[resolution.Type]
-		if !ok {
-			cfg, err := resolutions.GetResolution(resolution.Type)
+		cfg, err := resolutions.GetResolution(resolution.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		if storeResults {
+			err = storeResolutionResult(ctx, db, resolution, types.ResolutionStatusExpired,
+				requiredPower(ctx, db, cfg.ConfirmationThreshold, totalPower), block.Height)
 			if err != nil {
 				return nil, err
 			}
+		}
 
+		threshold, ok := requiredPowerMap[resolution.Type]
+		if !ok {
 			// we need to use each configured resolutions refund threshold
 			requiredPowerMap[resolution.Type] = requiredPower(ctx, db, cfg.RefundThreshold, totalPower)
 		}
@@ -497,6 +786,20 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 
 		r.service.Logger.Debug("expiring resolution", log.String("type", resolution.Type),
 			log.String("id", resolution.ID.String()), log.Bool("refunded", refunded))
+
+		if cfg.ExpireFunc != nil {
+			expireFuncs = append(expireFuncs, &resolutionFunc{
+				Resolution: resolution,
+				Func:       cfg.ExpireFunc,
+			})
+		}
+	}
+
+	for _, expireFunc := range expireFuncs {
+		err = r.runResolutionFunc(ctx, db, expireFunc, block)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	allIDs := append(finalizedIDs, expiredIDs...)
@@ -533,6 +836,42 @@ func (r *TxApp) processVotes(ctx context.Context, db sql.DB, block *common.Block
 	return expiredJoins, nil
 }
 
+// resolutionFunc is a resolve or expire function of a resolution's type, to be
+// called for the resolution.
+type resolutionFunc struct {
+	Resolution *resolutions.Resolution
+	Func       func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error
+}
+
+// runResolutionFunc calls a resolve or expire function in a nested
+// transaction. If the function fails, its changes are rolled back and the
+// error is logged, since it simply means some business logic failed in an
+// extension or a deployed schema. An error is only returned if the nested
+// transaction itself fails.
+func (r *TxApp) runResolutionFunc(ctx context.Context, db sql.DB, fn *resolutionFunc, block *common.BlockContext) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn.Func(ctx, &common.App{
+		Service: r.service.NamedLogger(fn.Resolution.Type),
+		DB:      tx,
+		Engine:  r.Engine,
+	}, fn.Resolution, block)
+	if err != nil {
+		err2 := tx.Rollback(ctx)
+		if err2 != nil {
+			return fmt.Errorf("error rolling back transaction: %s, error: %s", err.Error(), err2.Error())
+		}
+
+		r.service.Logger.Warn("error running resolution function", log.String("type", fn.Resolution.Type), log.String("id", fn.Resolution.ID.String()), log.Error(err))
+		return nil
+	}
+
+	return tx.Commit(ctx)
+}
+
 var (
 	ValidatorVoteBodyBytePrice int64 = 1000                  // Per byte cost
 	ValidatorVoteIDPrice             = big.NewInt(1000 * 16) // 16 bytes for the UUID
@@ -573,10 +912,78 @@ func (c creditMap) applyResolution(res *resolutions.Resolution) {
 
 // Commit signals that a block's state changes should be committed.
 func (r *TxApp) Commit(ctx context.Context) {
+	commitWasmModules()
 	r.announceValidators()
 	r.mempool.reset()
 	r.approvedJoins = nil
 	r.spends = nil // reset spends for the next block
+
+	if r.sealedEpoch != nil {
+		if err := r.attestEpoch(ctx, r.sealedEpoch); err != nil {
+			r.service.Logger.Error("failed to attest to withdrawal epoch",
+				log.Int("epoch", r.sealedEpoch.Epoch), log.Error(err))
+		}
+		r.sealedEpoch = nil
+	}
+
+	if r.registerAttester {
+		if err := r.proposeAttester(ctx); err != nil {
+			r.service.Logger.Error("failed to register withdrawal attestation key", log.Error(err))
+		}
+		r.registerAttester = false
+	}
+}
+
+// isValidator returns true if this node is in the current validator set.
+func (r *TxApp) isValidator() bool {
+	validators, _ := r.CachedValidators()
+	return slices.ContainsFunc(validators, func(v *types.Validator) bool {
+		return bytes.Equal(v.PubKey, r.signer.Identity())
+	})
+}
+
+// proposeAttester stores the registration of this node's attestation key in
+// the event store to be proposed as a resolution, if this node is a validator.
+func (r *TxApp) proposeAttester(ctx context.Context) error {
+	if !r.isValidator() {
+		return nil
+	}
+
+	body, err := r.attesterReg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return r.events.Store(ctx, body, withdrawals.AttesterEventType)
+}
+
+// attestEpoch signs the root of a sealed withdrawal epoch with this node's
+// attestation key, if this node is a validator, and stores the attestation in
+// the event store to be proposed as a resolution. A validator without an
+// attestation key, or whose key was not registered when the epoch was sealed,
+// does not attest to it.
+func (r *TxApp) attestEpoch(ctx context.Context, epoch *withdrawals.Epoch) error {
+	if r.attester == nil || !r.isValidator() || r.registerAttester {
+		return nil
+	}
+
+	sig, err := withdrawals.SignAttestation(r.attester, r.service.GenesisConfig.ConsensusParams.Withdrawals.Contract,
+		r.service.GenesisConfig.ChainID, epoch.Epoch, epoch.Root)
+	if err != nil {
+		return err
+	}
+
+	att := &withdrawals.Attestation{
+		Epoch:     epoch.Epoch,
+		Root:      epoch.Root,
+		Signature: sig,
+	}
+	body, err := att.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return r.events.Store(ctx, body, withdrawals.AttestationEventType)
 }
 
 // ApplyMempool applies the transactions in the mempool.
@@ -884,6 +1291,75 @@ func (r *TxApp) checkAndSpend(ctx *common.TxContext, tx *transactions.Transactio
 	return amt, transactions.CodeOk, nil
 }
 
+// checkAndSpendSession is checkAndSpend for transactions signed by a session
+// key. The session key's nonce is always incremented, but the fee is paid by
+// the delegator out of the session's fee budget. If the session does not
+// authorize the transaction, or the fee cannot be paid, the delegator is not
+// charged.
+func (r *TxApp) checkAndSpendSession(ctx *common.TxContext, tx *transactions.Transaction, pricer Pricer, dbTx sql.DB, session *sessions.Session) (*big.Int, transactions.TxCode, error) {
+	zero := big.NewInt(0)
+
+	// use the session key's nonce, even if the transaction is not authorized
+	err := spend(ctx.Ctx, dbTx, tx.Sender, zero, int64(tx.Body.Nonce))
+	if err != nil {
+		return nil, transactions.CodeUnknownError, err
+	}
+
+	err = session.Authorize(ctx.BlockContext.Height, tx)
+	if err != nil {
+		return zero, transactions.CodeInvalidSender, err
+	}
+
+	amt := big.NewInt(0)
+	if !ctx.BlockContext.ChainContext.NetworkParameters.DisabledGasCosts {
+		amt, err = pricer.Price(ctx.Ctx, r, dbTx, tx)
+		if err != nil {
+			return nil, transactions.CodeUnknownError, err
+		}
+	}
+
+	// If the transaction does not consent to spending required tokens for the
+	// transaction execution, spend the approved tx fee and terminate the
+	// transaction.
+	fee, code := amt, transactions.CodeOk
+	if tx.Body.Fee.Cmp(amt) < 0 {
+		fee, code = tx.Body.Fee, transactions.CodeInsufficientFee
+	}
+
+	if fee.Cmp(session.Remaining()) > 0 {
+		return zero, transactions.CodeInsufficientBalance, fmt.Errorf("%w: transaction tries to spend %s tokens, but session only has %s tokens remaining",
+			sessions.ErrBudgetExceeded, fee.String(), session.Remaining().String())
+	}
+
+	account, err := getAccount(ctx.Ctx, dbTx, session.Delegator)
+	if err != nil {
+		return nil, transactions.CodeUnknownError, err
+	}
+	if account.Balance.Cmp(fee) < 0 {
+		return zero, transactions.CodeInsufficientBalance, fmt.Errorf("transaction tries to spend %s tokens, but delegator has %s tokens",
+			fee.String(), account.Balance.String())
+	}
+
+	err = credit(ctx.Ctx, dbTx, session.Delegator, new(big.Int).Neg(fee))
+	if err != nil {
+		return nil, transactions.CodeUnknownError, err
+	}
+
+	err = sessionSpend(ctx.Ctx, dbTx, tx.Sender, fee)
+	if err != nil {
+		return nil, transactions.CodeUnknownError, err
+	}
+
+	// Record spend here
+	r.recordSpend(ctx, &Spend{Account: session.Delegator, Amount: fee, Nonce: tx.Body.Nonce})
+
+	if code != transactions.CodeOk {
+		return fee, code, fmt.Errorf("transaction does not consent to spending enough tokens. transaction fee: %s, required fee: %s", tx.Body.Fee.String(), amt.String())
+	}
+
+	return fee, transactions.CodeOk, nil
+}
+
 // txRes wraps a spend, tx code, and error into a tx response.
 // the spend amount is included because an error can occur after the tokens
 // are spent.
@@ -0,0 +1,367 @@
+package txapp
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/transactions"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/extensions/hooks"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+	"github.com/kwilteam/kwil-db/internal/staking"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TrackLiveness(t *testing.T) {
+	genesis := chain.DefaultGenesisConfig()
+	maxMissed := genesis.ConsensusParams.Validator.MaxMissedBlocks
+
+	app := &TxApp{
+		service: &common.Service{
+			Logger:        log.New(log.Config{}).Sugar(),
+			GenesisConfig: genesis,
+		},
+	}
+
+	powers := map[string]int64{"a": 1, "b": 1, "c": 1}
+	missedBlocks := map[string]int64{}
+	jailed := map[string]int64{}
+
+	recordLiveness = func(_ context.Context, _ sql.Executor, _, _ int64, _, missed [][]byte) (map[string]int64, error) {
+		counts := make(map[string]int64)
+		for _, v := range missed {
+			counts[string(v)] = missedBlocks[string(v)]
+		}
+		return counts, nil
+	}
+	getAllVoters = func(_ context.Context, _ sql.Executor) ([]*types.Validator, error) {
+		var vals []*types.Validator
+		for v, power := range powers {
+			vals = append(vals, &types.Validator{PubKey: []byte(v), Power: power})
+		}
+		return vals, nil
+	}
+	getVoterPower = func(_ context.Context, _ sql.Executor, identifier []byte) (int64, error) {
+		return powers[string(identifier)], nil
+	}
+	setVoterPower = func(_ context.Context, _ sql.Executor, validator []byte, power int64) error {
+		if power == 0 {
+			delete(powers, string(validator))
+			return nil
+		}
+		powers[string(validator)] = power
+		return nil
+	}
+	jailValidator = func(_ context.Context, _ sql.Executor, validator []byte, height, power int64) error {
+		jailed[string(validator)] = power
+		return nil
+	}
+
+	ctx := context.Background()
+	db := &mockTx{&mockDb{}}
+
+	// a validator within the allowed missed blocks is not jailed
+	missedBlocks["a"] = maxMissed
+	err := app.TrackLiveness(ctx, db, 100, [][]byte{[]byte("b"), []byte("c")}, [][]byte{[]byte("a")})
+	require.NoError(t, err)
+	assert.Empty(t, jailed)
+
+	missedBlocks["a"] = maxMissed + 1
+	err = app.TrackLiveness(ctx, db, 101, [][]byte{[]byte("b"), []byte("c")}, [][]byte{[]byte("a")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1}, jailed)
+	assert.NotContains(t, powers, "a")
+
+	// the last validator is never jailed
+	missedBlocks["b"] = maxMissed + 1
+	missedBlocks["c"] = maxMissed + 1
+	err = app.TrackLiveness(ctx, db, 102, nil, [][]byte{[]byte("b"), []byte("c")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1, "b": 1}, jailed)
+	assert.Equal(t, map[string]int64{"c": 1}, powers)
+
+	// liveness is not tracked with a zero window
+	genesis.ConsensusParams.Validator.MissedBlocksWindow = 0
+	recordLiveness = func(_ context.Context, _ sql.Executor, _, _ int64, _, _ [][]byte) (map[string]int64, error) {
+		t.Fatal("liveness should not be recorded")
+		return nil, nil
+	}
+	require.NoError(t, app.TrackLiveness(ctx, db, 103, nil, [][]byte{[]byte("c")}))
+}
+
+func Test_Punish(t *testing.T) {
+	genesis := chain.DefaultGenesisConfig()
+	app := &TxApp{
+		service: &common.Service{
+			Logger:        log.New(log.Config{}).Sugar(),
+			GenesisConfig: genesis,
+		},
+	}
+
+	powers := map[string]int64{"a": 3, "b": 1}
+	balances := map[string]*big.Int{"a": big.NewInt(50)}
+	var stored []*types.ValidatorEvidence
+
+	getAllVoters = func(_ context.Context, _ sql.Executor) ([]*types.Validator, error) {
+		var vals []*types.Validator
+		for v, power := range powers {
+			vals = append(vals, &types.Validator{PubKey: []byte(v), Power: power})
+		}
+		return vals, nil
+	}
+	getVoterPower = func(_ context.Context, _ sql.Executor, identifier []byte) (int64, error) {
+		return powers[string(identifier)], nil
+	}
+	setVoterPower = func(_ context.Context, _ sql.Executor, validator []byte, power int64) error {
+		if power == 0 {
+			delete(powers, string(validator))
+			return nil
+		}
+		powers[string(validator)] = power
+		return nil
+	}
+	getAccount = func(_ context.Context, _ sql.Executor, acctID []byte) (*types.Account, error) {
+		bal, ok := balances[string(acctID)]
+		if !ok {
+			bal = big.NewInt(0)
+		}
+		return &types.Account{Identifier: acctID, Balance: new(big.Int).Set(bal)}, nil
+	}
+	burn = func(_ context.Context, _ sql.Executor, account []byte, amt *big.Int) error {
+		balances[string(account)].Sub(balances[string(account)], amt)
+		return nil
+	}
+	storeEvidence = func(_ context.Context, _ sql.Executor, ev *types.ValidatorEvidence) error {
+		stored = append(stored, ev)
+		return nil
+	}
+
+	ctx := context.Background()
+	db := &mockTx{&mockDb{}}
+
+	// without removal or a slash amount, power is reduced by one
+	genesis.ConsensusParams.Validator.RemoveOnMisbehavior = false
+	ev := &types.ValidatorEvidence{Validator: []byte("a"), Type: types.EvidenceTypeDuplicateVote, Height: 5, CommitHeight: 6}
+	require.NoError(t, app.Punish(ctx, db, ev))
+	assert.Equal(t, int64(3), ev.PowerBefore)
+	assert.Equal(t, int64(2), ev.PowerAfter)
+	assert.Equal(t, "0", ev.Slashed)
+	assert.Equal(t, int64(2), powers["a"])
+
+	// removal, slashing up to the balance
+	genesis.ConsensusParams.Validator.RemoveOnMisbehavior = true
+	genesis.ConsensusParams.Validator.SlashAmount = big.NewInt(80)
+	ev = &types.ValidatorEvidence{Validator: []byte("a"), Type: types.EvidenceTypeLightClientAttack, Height: 7, CommitHeight: 8}
+	require.NoError(t, app.Punish(ctx, db, ev))
+	assert.Equal(t, int64(0), ev.PowerAfter)
+	assert.Equal(t, "50", ev.Slashed)
+	assert.NotContains(t, powers, "a")
+	assert.Equal(t, int64(0), balances["a"].Int64())
+
+	// the last validator is not removed
+	ev = &types.ValidatorEvidence{Validator: []byte("b"), Type: types.EvidenceTypeDuplicateVote, Height: 9, CommitHeight: 10}
+	require.NoError(t, app.Punish(ctx, db, ev))
+	assert.Equal(t, int64(1), ev.PowerAfter)
+	assert.Equal(t, "0", ev.Slashed)
+	assert.Equal(t, map[string]int64{"b": 1}, powers)
+
+	assert.Len(t, stored, 3)
+}
+
+func Test_ProcessStakes(t *testing.T) {
+	genesis := chain.DefaultGenesisConfig()
+	genesis.ConsensusParams.Validator.Staking = true
+	genesis.ConsensusParams.Validator.MinStake = big.NewInt(100)
+	genesis.ConsensusParams.Validator.StakePerPower = big.NewInt(100)
+
+	app := &TxApp{
+		service: &common.Service{
+			Logger:        log.New(log.Config{}).Sugar(),
+			GenesisConfig: genesis,
+		},
+	}
+
+	powers := map[string]int64{"a": 1, "b": 1}
+	stakes := map[string]*big.Int{"a": big.NewInt(350), "b": big.NewInt(50), "c": big.NewInt(1000)}
+	credited := map[string]*big.Int{}
+
+	releaseUnbonded = func(_ context.Context, _ sql.Executor, height int64) ([]*staking.Unbonding, error) {
+		assert.Equal(t, int64(10), height)
+		return []*staking.Unbonding{{Staker: []byte("d"), ReleaseHeight: 10, Amount: big.NewInt(5)}}, nil
+	}
+	credit = func(_ context.Context, _ sql.Executor, account []byte, amt *big.Int) error {
+		credited[string(account)] = amt
+		return nil
+	}
+	getAllVoters = func(_ context.Context, _ sql.Executor) ([]*types.Validator, error) {
+		var vals []*types.Validator
+		for _, v := range []string{"a", "b"} {
+			if power, ok := powers[v]; ok {
+				vals = append(vals, &types.Validator{PubKey: []byte(v), Power: power})
+			}
+		}
+		return vals, nil
+	}
+	getStake = func(_ context.Context, _ sql.Executor, staker []byte) (*big.Int, error) {
+		return stakes[string(staker)], nil
+	}
+	setVoterPower = func(_ context.Context, _ sql.Executor, validator []byte, power int64) error {
+		if power == 0 {
+			delete(powers, string(validator))
+			return nil
+		}
+		powers[string(validator)] = power
+		return nil
+	}
+
+	ctx := context.Background()
+	db := &mockTx{&mockDb{}}
+
+	// the validator below the minimum stake is removed, and stakers that are
+	// not validators are not given power
+	require.NoError(t, app.processStakes(ctx, db, 10))
+	assert.Equal(t, map[string]int64{"a": 3}, powers)
+	assert.Equal(t, map[string]*big.Int{"d": big.NewInt(5)}, credited)
+
+	// the last validator is not removed
+	stakes["a"] = big.NewInt(0)
+	releaseUnbonded = func(_ context.Context, _ sql.Executor, _ int64) ([]*staking.Unbonding, error) {
+		return nil, nil
+	}
+	require.NoError(t, app.processStakes(ctx, db, 11))
+	assert.Equal(t, map[string]int64{"a": 3}, powers)
+	// the total power is limited to the consensus engine's maximum
+	powers["b"] = 1
+	stakes["a"] = new(big.Int).Lsh(big.NewInt(1), 100)
+	stakes["b"] = big.NewInt(200)
+	require.NoError(t, app.processStakes(ctx, db, 12))
+	assert.Equal(t, map[string]int64{"a": staking.MaxTotalPower - 1, "b": 1}, powers)
+}
+
+func Test_ProcessVotes_Expired(t *testing.T) {
+	app := &TxApp{
+		service: &common.Service{
+			Logger: log.New(log.Config{}).Sugar(),
+		},
+	}
+	activation := uint64(10)
+	app.forks.FromMap(map[string]*uint64{consensus.ForkResolutionResults: &activation})
+
+	var expired []string
+	err := resolutions.RegisterResolution(hooksType, resolutions.ModAdd, resolutions.ResolutionConfig{
+		ExpireFunc: func(_ context.Context, _ *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error {
+			assert.Equal(t, int64(10), block.Height)
+			expired = append(expired, string(resolution.Body))
+			if string(resolution.Body) == "fail" {
+				return errors.New("expire failed")
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	defer resolutions.RegisterResolution(hooksType, resolutions.ModRemove, resolutions.ResolutionConfig{})
+
+	resolution := func(resType, body string) *resolutions.Resolution {
+		return &resolutions.Resolution{
+			ID:   types.NewUUIDV5([]byte(body)),
+			Type: resType,
+			Body: []byte(body),
+		}
+	}
+
+	getAllVoters = func(_ context.Context, _ sql.Executor) ([]*types.Validator, error) {
+		return []*types.Validator{{PubKey: []byte("a"), Power: 1}}, nil
+	}
+	getExpired = func(_ context.Context, _ sql.Executor, height int64) ([]*resolutions.Resolution, error) {
+		return []*resolutions.Resolution{
+			resolution(hooksType, "fail"),
+			resolution(testType, "no hook"),
+			resolution(hooksType, "ok"),
+		}, nil
+	}
+	var stored []string
+	storeResolutionResult = func(_ context.Context, _ sql.Executor, resolution *resolutions.Resolution, status string, _, _ int64) error {
+		assert.Equal(t, types.ResolutionStatusExpired, status)
+		stored = append(stored, string(resolution.Body))
+		return nil
+	}
+	var deleted int
+	deleteResolutions = func(_ context.Context, _ sql.Executor, ids ...*types.UUID) error {
+		deleted = len(ids)
+		return nil
+	}
+	markProcessed = func(_ context.Context, _ sql.Executor, _ ...*types.UUID) error {
+		return nil
+	}
+	deleteEvents = func(_ context.Context, _ sql.DB, _ ...*types.UUID) error {
+		return nil
+	}
+
+	block := &common.BlockContext{
+		Height: 10,
+		ChainContext: &common.ChainContext{
+			NetworkParameters: &common.NetworkParameters{DisabledGasCosts: true},
+		},
+	}
+
+	// a failed expire function does not stop the other resolutions from expiring
+	_, err = app.processVotes(context.Background(), &mockTx{&mockDb{}}, block)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fail", "ok"}, expired)
+	assert.Equal(t, []string{"fail", "no hook", "ok"}, stored)
+	assert.Equal(t, 3, deleted)
+	// the outcome is not recorded before the fork activates
+	activation, stored = 11, nil
+	app.forks.FromMap(map[string]*uint64{consensus.ForkResolutionResults: &activation})
+	_, err = app.processVotes(context.Background(), &mockTx{&mockDb{}}, block)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+	assert.Equal(t, 3, deleted)
+}
+
+func Test_BlockHooks(t *testing.T) {
+	app := &TxApp{
+		service: &common.Service{
+			Logger: log.New(log.Config{}).Sugar(),
+		},
+	}
+
+	var called []string
+	for _, name := range []string{"b", "a"} {
+		require.NoError(t, hooks.RegisterBeginBlockHook("test_"+name, func(_ context.Context, app *common.App, block *common.BlockContext) error {
+			assert.Equal(t, int64(5), block.Height)
+			called = append(called, "begin_"+name)
+			return nil
+		}))
+	}
+	require.NoError(t, hooks.RegisterTxResultHook("test", func(ctx *common.TxContext, _ *common.App, _ *transactions.Transaction, result *hooks.TxResult) error {
+		assert.Equal(t, "txid", ctx.TxID)
+		called = append(called, "result_"+result.Code.String())
+		return result.Error
+	}))
+
+	ctx := context.Background()
+	db := &mockTx{&mockDb{}}
+	block := &common.BlockContext{Height: 5}
+
+	// hooks are run in order of their names
+	require.NoError(t, app.Begin(ctx, db, block))
+	assert.Equal(t, []string{"begin_a", "begin_b"}, called)
+
+	txCtx := &common.TxContext{Ctx: ctx, TxID: "txid", BlockContext: block}
+	require.NoError(t, app.TxResult(txCtx, db, &transactions.Transaction{}, &TxResponse{ResponseCode: transactions.CodeOk}))
+	assert.Equal(t, "result_"+transactions.CodeOk.String(), called[2])
+
+	// an error from a hook is returned
+	failed := errors.New("failed")
+	err := app.TxResult(txCtx, db, &transactions.Transaction{}, &TxResponse{ResponseCode: transactions.CodeUnknownError, Error: failed})
+	require.ErrorIs(t, err, failed)
+}
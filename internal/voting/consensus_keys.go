@@ -0,0 +1,50 @@
+package voting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+// this file records the consensus keys of validators that sign their votes and
+// proposals with a key other than the node key that identifies them.
+
+func initConsensusKeysTable(ctx context.Context, db sql.DB) error {
+	_, err := db.Execute(ctx, tableConsensusKeys)
+	return err
+}
+
+// SetConsensusKey sets the consensus key of a validator or join candidate. If
+// the key is empty, or is the identifier itself, any consensus key it had is
+// removed, and its votes and proposals are signed with its identifier's key.
+func SetConsensusKey(ctx context.Context, db sql.Executor, identifier, consensusKey []byte) error {
+	if len(consensusKey) == 0 || slices.Equal(identifier, consensusKey) {
+		_, err := db.Execute(ctx, deleteConsensusKey, identifier)
+		return err
+	}
+
+	_, err := db.Execute(ctx, upsertConsensusKey, identifier, consensusKey)
+	return err
+}
+
+// GetConsensusKeyOwner returns the identifier of the validator or join
+// candidate that has the consensus key. If the key is not registered, it
+// returns nil.
+func GetConsensusKeyOwner(ctx context.Context, db sql.Executor, consensusKey []byte) ([]byte, error) {
+	res, err := db.Execute(ctx, getConsensusKeyOwner, consensusKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Rows) == 0 {
+		return nil, nil
+	}
+
+	owner, ok := res.Rows[0][0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for consensus key owner")
+	}
+	return slices.Clone(owner), nil
+}
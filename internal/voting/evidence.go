@@ -0,0 +1,70 @@
+package voting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types"
+)
+
+// this file records validator misbehavior reported by the consensus engine.
+
+func initEvidenceTable(ctx context.Context, db sql.DB) error {
+	_, err := db.Execute(ctx, tableEvidence)
+	return err
+}
+
+// StoreEvidence records a validator's misbehavior and punishment. If the
+// misbehavior was already recorded, nothing happens.
+func StoreEvidence(ctx context.Context, db sql.Executor, ev *types.ValidatorEvidence) error {
+	_, err := db.Execute(ctx, insertEvidence, []byte(ev.Validator), ev.Type, ev.Height, ev.Time,
+		ev.CommitHeight, ev.PowerBefore, ev.PowerAfter, ev.Slashed)
+	return err
+}
+
+// ListEvidence lists the recorded validator misbehavior, ordered by the height
+// at which it was committed. If validator is not nil, only the misbehavior of
+// that validator is listed.
+func ListEvidence(ctx context.Context, db sql.Executor, validator []byte) ([]*types.ValidatorEvidence, error) {
+	var res *sql.ResultSet
+	var err error
+	if validator == nil {
+		res, err = db.Execute(ctx, listEvidence)
+	} else {
+		res, err = db.Execute(ctx, listValidatorEvidence, validator)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]*types.ValidatorEvidence, len(res.Rows))
+	for i, row := range res.Rows {
+		if len(row) != 8 {
+			// this should never happen, just for safety
+			return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
+		}
+
+		validator, ok := row[0].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for validator (%T)", row[0])
+		}
+		ev := &types.ValidatorEvidence{Validator: slices.Clone(validator)}
+		if ev.Type, ok = row[1].(string); !ok {
+			return nil, fmt.Errorf("invalid type for evidence type (%T)", row[1])
+		}
+		ints := []*int64{&ev.Height, &ev.Time, &ev.CommitHeight, &ev.PowerBefore, &ev.PowerAfter}
+		for j, dst := range ints {
+			if *dst, ok = sql.Int64(row[2+j]); !ok {
+				return nil, fmt.Errorf("invalid type for evidence column %d (%T)", 2+j, row[2+j])
+			}
+		}
+		if ev.Slashed, ok = row[7].(string); !ok {
+			return nil, fmt.Errorf("invalid type for slashed (%T)", row[7])
+		}
+		evs[i] = ev
+	}
+
+	return evs, nil
+}
@@ -0,0 +1,178 @@
+package voting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+// this file tracks the liveness of validators, which is the number of blocks
+// they have not signed in a sliding window of recent blocks.
+
+// ErrNotJailed is returned when unjailing a validator that is not jailed.
+var ErrNotJailed = errors.New("validator is not jailed")
+
+// Liveness is the liveness of a validator.
+type Liveness struct {
+	Validator []byte
+	// StartHeight is the height at which tracking of the validator started,
+	// or at which it was last unjailed.
+	StartHeight int64
+	// MissedBlocks is the number of blocks the validator has not signed in
+	// the missed blocks window.
+	MissedBlocks int64
+	// Jailed is true if the validator has been jailed.
+	Jailed bool
+	// JailedHeight is the height at which the validator was jailed.
+	JailedHeight int64
+	// JailedPower is the power the validator had when it was jailed.
+	JailedPower int64
+}
+
+func initLivenessTables(ctx context.Context, db sql.DB) error {
+	for _, stmt := range []string{tableLiveness, tableMissedBlocks, missedBlocksHeightIndex} {
+		if _, err := db.Execute(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordLiveness records which validators signed the block at the given
+// height, and which did not. Missed blocks older than the window are pruned.
+// It returns the validators that missed the block, with their number of
+// missed blocks in the window.
+func RecordLiveness(ctx context.Context, db sql.Executor, height, window int64, signed, missed [][]byte) (map[string]int64, error) {
+	all := append(slices.Clone(signed), missed...)
+	if len(all) > 0 {
+		if _, err := db.Execute(ctx, ensureLiveness, all, height); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := db.Execute(ctx, pruneMissedBlocks, height-window); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(missed))
+	for _, validator := range missed {
+		if _, err := db.Execute(ctx, insertMissedBlock, validator, height); err != nil {
+			return nil, err
+		}
+
+		res, err := db.Execute(ctx, countMissedBlocks, validator)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Rows) != 1 || len(res.Rows[0]) != 1 {
+			// this should never happen, just for safety
+			return nil, fmt.Errorf("invalid number of rows or columns returned. this is an internal bug")
+		}
+		count, ok := sql.Int64(res.Rows[0][0])
+		if !ok {
+			return nil, fmt.Errorf("invalid type for missed blocks (%T). this is an internal bug", res.Rows[0][0])
+		}
+		counts[string(validator)] = count
+	}
+
+	return counts, nil
+}
+
+// JailValidator marks a validator as jailed at the given height, remembering
+// its power so that it can be restored when it is unjailed. It does not
+// change the validator's power.
+func JailValidator(ctx context.Context, db sql.Executor, validator []byte, height, power int64) error {
+	_, err := db.Execute(ctx, jailValidator, validator, height, power)
+	return err
+}
+
+// UnjailValidator clears a validator's jailed status and missed blocks, and
+// restarts tracking of its liveness at the given height. It returns the power
+// the validator had when it was jailed. It does not change the validator's
+// power.
+func UnjailValidator(ctx context.Context, db sql.Executor, validator []byte, height int64) (int64, error) {
+	l, err := GetLiveness(ctx, db, validator)
+	if err != nil {
+		return 0, err
+	}
+	if l == nil || !l.Jailed {
+		return 0, ErrNotJailed
+	}
+
+	if _, err = db.Execute(ctx, unjailValidator, validator, height); err != nil {
+		return 0, err
+	}
+	if _, err = db.Execute(ctx, clearMissedBlocks, validator); err != nil {
+		return 0, err
+	}
+
+	return l.JailedPower, nil
+}
+
+// GetLiveness gets the liveness of a validator. If the validator's liveness
+// is not tracked, it returns nil.
+func GetLiveness(ctx context.Context, db sql.Executor, validator []byte) (*Liveness, error) {
+	res, err := db.Execute(ctx, getLiveness, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Rows) == 0 {
+		return nil, nil
+	}
+
+	return livenessFromRow(res.Rows[0])
+}
+
+// ListLiveness lists the liveness of all tracked validators, including jailed
+// validators, ordered by validator.
+func ListLiveness(ctx context.Context, db sql.Executor) ([]*Liveness, error) {
+	res, err := db.Execute(ctx, listLiveness)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := make([]*Liveness, len(res.Rows))
+	for i, row := range res.Rows {
+		ls[i], err = livenessFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ls, nil
+}
+
+func livenessFromRow(row []any) (*Liveness, error) {
+	if len(row) != 5 {
+		// this should never happen, just for safety
+		return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
+	}
+
+	validator, ok := row[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for validator (%T)", row[0])
+	}
+	l := &Liveness{Validator: slices.Clone(validator)}
+
+	if l.StartHeight, ok = sql.Int64(row[1]); !ok {
+		return nil, fmt.Errorf("invalid type for start height (%T)", row[1])
+	}
+	if row[2] != nil {
+		l.Jailed = true
+		if l.JailedHeight, ok = sql.Int64(row[2]); !ok {
+			return nil, fmt.Errorf("invalid type for jailed height (%T)", row[2])
+		}
+	}
+	if l.JailedPower, ok = sql.Int64(row[3]); !ok {
+		return nil, fmt.Errorf("invalid type for jailed power (%T)", row[3])
+	}
+	if l.MissedBlocks, ok = sql.Int64(row[4]); !ok {
+		return nil, fmt.Errorf("invalid type for missed blocks (%T)", row[4])
+	}
+
+	return l, nil
+}
@@ -0,0 +1,199 @@
+package voting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+)
+
+// this file records the outcome of resolutions that have been approved or have
+// expired, and lists resolutions with their votes.
+
+func initResolutionResultsTable(ctx context.Context, db sql.DB) error {
+	for _, stmt := range []string{tableResolutionResults, resolutionResultsTypeIndex} {
+		if _, err := db.Execute(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StoreResolutionResult records that a resolution was approved or expired at
+// the given height. The status should be types.ResolutionStatusApproved or
+// types.ResolutionStatusExpired. Results are only recorded once the
+// resolution_results hard fork is active, so resolutions closed before it are
+// not listed.
+func StoreResolutionResult(ctx context.Context, db sql.Executor, resolution *resolutions.Resolution, status string, requiredPower, height int64) error {
+	_, err := db.Execute(ctx, insertResolutionResult, resolution.ID[:], height, resolution.Type, resolution.Proposer,
+		resolution.ExpirationHeight, status, int64(len(resolution.Voters)), resolution.ApprovedPower, requiredPower)
+	return err
+}
+
+// ListResolutions lists pending and closed resolutions that match the filter,
+// ordered by expiration height and ID. The first offset resolutions are
+// skipped, and at most limit resolutions are returned, unless limit is zero.
+// The required power of pending resolutions is based on the current validator
+// set.
+func ListResolutions(ctx context.Context, db sql.Executor, filter *types.ResolutionFilter, offset, limit int64) ([]*types.ResolutionInfo, error) {
+	args := []any{nilIfZero(filter.Type), nil, nilIfZero(filter.Status), nilIfZero(filter.MinExpiration),
+		nilIfZero(filter.MaxExpiration), nilIfZero(limit), offset}
+	if len(filter.Proposer) > 0 {
+		args[1] = []byte(filter.Proposer)
+	}
+
+	res, err := db.Execute(ctx, listResolutionsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*types.ResolutionInfo, len(res.Rows))
+	for i, row := range res.Rows {
+		infos[i], err = resolutionInfoFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = setRequiredPower(ctx, db, infos...); err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// GetResolution gets a resolution by ID. A pending resolution includes its
+// body and the validators that approved it. If the resolution is not pending,
+// the most recent result of the closed resolution is returned. If the
+// resolution is not found, it returns nil.
+func GetResolution(ctx context.Context, db sql.Executor, id *types.UUID) (*types.ResolutionInfo, error) {
+	exists, err := ResolutionExists(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		res, err := db.Execute(ctx, getLatestResolutionResult, id[:])
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Rows) == 0 {
+			return nil, nil
+		}
+		return resolutionInfoFromRow(res.Rows[0])
+	}
+
+	resolution, err := GetResolutionInfo(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &types.ResolutionInfo{
+		ID:            resolution.ID,
+		Type:          resolution.Type,
+		Proposer:      resolution.Proposer,
+		ExpiresAt:     resolution.ExpirationHeight,
+		Status:        types.ResolutionStatusPending,
+		Votes:         int64(len(resolution.Voters)),
+		ApprovedPower: resolution.ApprovedPower,
+		Body:          resolution.Body,
+	}
+	for _, voter := range resolution.Voters {
+		info.Voters = append(info.Voters, voter.PubKey)
+	}
+
+	if err = setRequiredPower(ctx, db, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// setRequiredPower sets the required power of pending resolutions from the
+// confirmation threshold of their type and the current validator set.
+func setRequiredPower(ctx context.Context, db sql.Executor, infos ...*types.ResolutionInfo) error {
+	var totalPower int64
+	var haveVoters bool
+	for _, info := range infos {
+		if info.Status != types.ResolutionStatusPending {
+			continue
+		}
+
+		cfg, err := resolutions.GetResolution(info.Type)
+		if err != nil {
+			continue // no longer registered, and cannot be approved
+		}
+
+		if !haveVoters {
+			voters, err := GetValidators(ctx, db)
+			if err != nil {
+				return err
+			}
+			for _, v := range voters {
+				totalPower += v.Power
+			}
+			haveVoters = true
+		}
+
+		info.RequiredPower = RequiredPower(ctx, db, cfg.ConfirmationThreshold, totalPower)
+	}
+
+	return nil
+}
+
+// resolutionInfoFromRow converts a row from listResolutionsQuery or
+// getLatestResolutionResult to a types.ResolutionInfo. It expects the
+// following columns: id, type, proposer, expiration, status, votes,
+// approved_power, required_power, closed_height.
+func resolutionInfoFromRow(row []any) (*types.ResolutionInfo, error) {
+	if len(row) != 9 {
+		// this should never happen, just for safety
+		return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
+	}
+
+	id, ok := row[0].([]byte)
+	if !ok || len(id) != 16 {
+		return nil, fmt.Errorf("invalid id (%T)", row[0])
+	}
+	uid := types.UUID(slices.Clone(id))
+	info := &types.ResolutionInfo{ID: &uid}
+
+	if info.Type, ok = row[1].(string); !ok {
+		return nil, fmt.Errorf("invalid type for type (%T)", row[1])
+	}
+	if row[2] != nil {
+		proposer, ok := row[2].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for proposer (%T)", row[2])
+		}
+		info.Proposer = slices.Clone(proposer)
+	}
+	if info.Status, ok = row[4].(string); !ok {
+		return nil, fmt.Errorf("invalid type for status (%T)", row[4])
+	}
+
+	ints := []*int64{&info.ExpiresAt, nil, &info.Votes, &info.ApprovedPower, &info.RequiredPower, &info.ClosedHeight}
+	for j, dst := range ints {
+		if dst == nil {
+			continue // status
+		}
+		if *dst, ok = sql.Int64(row[3+j]); !ok {
+			return nil, fmt.Errorf("invalid type for resolution column %d (%T)", 3+j, row[3+j])
+		}
+	}
+
+	return info, nil
+}
+
+// nilIfZero returns nil for the zero value, so that the query does not filter
+// on it.
+func nilIfZero[T comparable](v T) any {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return v
+}
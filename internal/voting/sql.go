@@ -28,11 +28,46 @@ votes:
 
 processed:
   - id: uuid
+
+liveness:
+  - validator: bytea
+  - start_height: int8
+  - jailed_height: int8
+  - jailed_power: int8
+
+missed_blocks:
+  - validator: bytea
+  - height: int8
+
+evidence:
+  - validator: bytea
+  - type: text
+  - height: int8
+  - time: int8
+  - commit_height: int8
+  - power_before: int8
+  - power_after: int8
+  - slashed: text
+
+resolution_results:
+  - id: uuid
+  - closed_height: int8
+  - type: text
+  - proposer: bytea
+  - expiration: int8
+  - status: text
+  - votes: int8
+  - approved_power: int8
+  - required_power: int8
+
+consensus_keys:
+  - id: bytea
+  - consensus_key: bytea
 */
 const (
 	votingSchemaName = `kwild_voting`
 
-	voteStoreVersion = 2
+	voteStoreVersion = 6
 
 	// tableResolutions is the sql table used to store resolutions that can be voted on.
 	// the vote_body_proposer is the BYTEA of the public key of the submitter, NOT the UUID
@@ -219,7 +254,8 @@ const (
 	GROUP BY r.id, r.body, t.name, r.expiration, r.vote_body_proposer
 	ORDER BY r.id;` // order by not necessary since only one result?
 
-	allVoters                      = `SELECT name, power FROM ` + votingSchemaName + `.voters;`
+	allVoters = `SELECT vr.name, vr.power, ck.consensus_key FROM ` + votingSchemaName + `.voters AS vr
+	LEFT JOIN ` + votingSchemaName + `.consensus_keys AS ck ON vr.name = ck.id;`
 	getResolutionByTypeAndProposer = `SELECT r.id FROM ` + votingSchemaName + `.resolutions AS r
 	INNER JOIN ` + votingSchemaName + `.resolution_types AS t ON r.type = t.id
 	WHERE t.name = $1 AND vote_body_proposer = $2
@@ -239,6 +275,175 @@ const (
 	dropExtraVoteID = `ALTER TABLE ` + votingSchemaName + `.resolutions DROP COLUMN extra_vote_id;`
 )
 
+// upgrades V2 -> V3
+const (
+	// tableLiveness tracks the liveness of each validator that has signed or
+	// missed a block. A jailed validator has a non-null jailed_height, and the
+	// power it had when it was jailed, which is restored when it is unjailed.
+	tableLiveness = `CREATE TABLE IF NOT EXISTS ` + votingSchemaName + `.liveness (
+		validator BYTEA PRIMARY KEY, -- validator is the identifier of the validator
+		start_height INT8 NOT NULL, -- start_height is the height at which tracking started
+		jailed_height INT8, -- jailed_height is the height at which the validator was jailed
+		jailed_power INT8 NOT NULL DEFAULT 0 -- jailed_power is the power of the validator when it was jailed
+	);`
+
+	// tableMissedBlocks contains the blocks that each validator did not sign
+	// in the missed blocks window. Older blocks are pruned.
+	tableMissedBlocks = `CREATE TABLE IF NOT EXISTS ` + votingSchemaName + `.missed_blocks (
+		validator BYTEA NOT NULL,
+		height INT8 NOT NULL,
+		FOREIGN KEY(validator) REFERENCES ` + votingSchemaName + `.liveness(validator) ON UPDATE CASCADE ON DELETE CASCADE,
+		PRIMARY KEY(validator, height)
+	);`
+
+	missedBlocksHeightIndex = `CREATE INDEX IF NOT EXISTS missed_blocks_height_index ON ` + votingSchemaName + `.missed_blocks (height);`
+)
+
+// liveness queries
+const (
+	// ensureLiveness starts tracking the liveness of validators that are not
+	// yet tracked.
+	ensureLiveness = `INSERT INTO ` + votingSchemaName + `.liveness (validator, start_height)
+		SELECT unnest($1::BYTEA[]), $2 ON CONFLICT(validator) DO NOTHING;`
+
+	insertMissedBlock = `INSERT INTO ` + votingSchemaName + `.missed_blocks (validator, height) VALUES ($1, $2)
+		ON CONFLICT(validator, height) DO NOTHING;`
+
+	// pruneMissedBlocks deletes the missed blocks that are outside the window.
+	pruneMissedBlocks = `DELETE FROM ` + votingSchemaName + `.missed_blocks WHERE height <= $1;`
+
+	countMissedBlocks = `SELECT COUNT(*) FROM ` + votingSchemaName + `.missed_blocks WHERE validator = $1;`
+
+	clearMissedBlocks = `DELETE FROM ` + votingSchemaName + `.missed_blocks WHERE validator = $1;`
+
+	jailValidator = `UPDATE ` + votingSchemaName + `.liveness SET jailed_height = $2, jailed_power = $3 WHERE validator = $1;`
+
+	unjailValidator = `UPDATE ` + votingSchemaName + `.liveness SET jailed_height = NULL, jailed_power = 0, start_height = $2
+		WHERE validator = $1;`
+
+	getLiveness = `SELECT l.validator, l.start_height, l.jailed_height, l.jailed_power, COUNT(m.height)
+	FROM ` + votingSchemaName + `.liveness AS l
+	LEFT JOIN ` + votingSchemaName + `.missed_blocks AS m ON l.validator = m.validator
+	WHERE l.validator = $1
+	GROUP BY l.validator, l.start_height, l.jailed_height, l.jailed_power;`
+
+	listLiveness = `SELECT l.validator, l.start_height, l.jailed_height, l.jailed_power, COUNT(m.height)
+	FROM ` + votingSchemaName + `.liveness AS l
+	LEFT JOIN ` + votingSchemaName + `.missed_blocks AS m ON l.validator = m.validator
+	GROUP BY l.validator, l.start_height, l.jailed_height, l.jailed_power
+	ORDER BY l.validator;` // order by validator for determinism
+)
+
+// upgrades V3 -> V4
+const (
+	// tableEvidence records validator misbehavior reported by the consensus
+	// engine, and the punishment applied to the validator.
+	tableEvidence = `CREATE TABLE IF NOT EXISTS ` + votingSchemaName + `.evidence (
+		validator BYTEA NOT NULL, -- validator is the identifier of the validator
+		type TEXT NOT NULL, -- type is the type of misbehavior
+		height INT8 NOT NULL, -- height is the height of the misbehavior
+		time INT8 NOT NULL, -- time is the unix time of the misbehavior
+		commit_height INT8 NOT NULL, -- commit_height is the height at which the validator was punished
+		power_before INT8 NOT NULL,
+		power_after INT8 NOT NULL,
+		slashed TEXT NOT NULL, -- slashed is the amount of tokens burned from the validator's account
+		PRIMARY KEY(validator, type, height)
+	);`
+)
+
+// evidence queries
+const (
+	insertEvidence = `INSERT INTO ` + votingSchemaName + `.evidence (validator, type, height, time, commit_height,
+		power_before, power_after, slashed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT(validator, type, height) DO NOTHING;`
+
+	listEvidence = `SELECT validator, type, height, time, commit_height, power_before, power_after, slashed
+	FROM ` + votingSchemaName + `.evidence ORDER BY commit_height, validator, type, height;`
+
+	listValidatorEvidence = `SELECT validator, type, height, time, commit_height, power_before, power_after, slashed
+	FROM ` + votingSchemaName + `.evidence WHERE validator = $1 ORDER BY commit_height, type, height;`
+)
+
+// upgrades V4 -> V5
+const (
+	// tableResolutionResults records the outcome of resolutions that have
+	// been approved or have expired, which are deleted from the resolutions
+	// table. The body is not kept. Validator join and remove resolutions may
+	// be closed more than once with the same id, at different heights.
+	tableResolutionResults = `CREATE TABLE IF NOT EXISTS ` + votingSchemaName + `.resolution_results (
+		id BYTEA NOT NULL,
+		closed_height INT8 NOT NULL, -- closed_height is the height at which the resolution was approved or expired
+		type TEXT NOT NULL,
+		proposer BYTEA, -- proposer is the identifier of the node that supplied the vote body
+		expiration INT8 NOT NULL,
+		status TEXT NOT NULL, -- status is either 'approved' or 'expired'
+		votes INT8 NOT NULL, -- votes is the number of validators that approved the resolution
+		approved_power INT8 NOT NULL,
+		required_power INT8 NOT NULL, -- required_power is the power that was required for approval
+		PRIMARY KEY(id, closed_height)
+	);`
+
+	resolutionResultsTypeIndex = `CREATE INDEX IF NOT EXISTS resolution_results_type_index ON ` + votingSchemaName + `.resolution_results (type);`
+)
+
+// upgrades V5 -> V6
+const (
+	// tableConsensusKeys records the consensus key of validators and join
+	// candidates whose votes and proposals are not signed with the node key
+	// that identifies them, such as with a remote signer.
+	tableConsensusKeys = `CREATE TABLE IF NOT EXISTS ` + votingSchemaName + `.consensus_keys (
+		id BYTEA PRIMARY KEY, -- id is the identifier of the validator
+		consensus_key BYTEA UNIQUE NOT NULL -- consensus_key is the ed25519 public key that signs its votes and proposals
+	);`
+
+	upsertConsensusKey = `INSERT INTO ` + votingSchemaName + `.consensus_keys (id, consensus_key) VALUES ($1, $2)
+		ON CONFLICT(id) DO UPDATE SET consensus_key = $2;`
+
+	deleteConsensusKey = `DELETE FROM ` + votingSchemaName + `.consensus_keys WHERE id = $1;`
+
+	getConsensusKeyOwner = `SELECT id FROM ` + votingSchemaName + `.consensus_keys WHERE consensus_key = $1;`
+)
+
+// resolution listing queries
+const (
+	insertResolutionResult = `INSERT INTO ` + votingSchemaName + `.resolution_results (id, closed_height, type, proposer,
+		expiration, status, votes, approved_power, required_power) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT(id, closed_height) DO NOTHING;`
+
+	// listResolutionsQuery lists active resolutions, which are pending, and
+	// the results of closed resolutions. Each filter is ignored if its
+	// parameter is NULL. The required power of pending resolutions is not
+	// known to the database, and is returned as zero.
+	listResolutionsQuery = `
+	SELECT id, type, proposer, expiration, status, votes, approved_power, required_power, closed_height FROM (
+		SELECT r.id AS id, t.name AS type, r.vote_body_proposer AS proposer, r.expiration AS expiration,
+			'pending'::TEXT AS status, COUNT(vr.id)::INT8 AS votes,
+			COALESCE(SUM(vr.power), 0)::INT8 AS approved_power, 0::INT8 AS required_power, 0::INT8 AS closed_height
+		FROM ` + votingSchemaName + `.resolutions AS r
+		INNER JOIN ` + votingSchemaName + `.resolution_types AS t ON r.type = t.id
+		LEFT JOIN ` + votingSchemaName + `.votes AS v ON r.id = v.resolution_id
+		LEFT JOIN ` + votingSchemaName + `.voters AS vr ON v.voter_id = vr.id
+		GROUP BY r.id, t.name, r.vote_body_proposer, r.expiration
+		UNION ALL
+		SELECT id, type, proposer, expiration, status, votes, approved_power, required_power, closed_height
+		FROM ` + votingSchemaName + `.resolution_results
+	) AS res
+	WHERE ($1::TEXT IS NULL OR type = $1)
+		AND ($2::BYTEA IS NULL OR proposer = $2)
+		AND ($3::TEXT IS NULL OR status = $3)
+		AND ($4::INT8 IS NULL OR expiration >= $4)
+		AND ($5::INT8 IS NULL OR expiration <= $5)
+	ORDER BY expiration, id, closed_height
+	LIMIT $6 OFFSET $7;` // order by expiration, id and closed height for determinism
+
+	// getLatestResolutionResult gets the most recent result of a closed
+	// resolution.
+	getLatestResolutionResult = `SELECT id, type, proposer, expiration, status, votes, approved_power,
+		required_power, closed_height
+	FROM ` + votingSchemaName + `.resolution_results WHERE id = $1
+	ORDER BY closed_height DESC LIMIT 1;`
+)
+
 // registered resolution types
 const (
 	// ummm.. import cycle issues, so moving them here from migrations pkg.
@@ -101,6 +101,42 @@ func Test_Voting(t *testing.T) {
 				require.Equal(t, int64(100), voterAPower)
 			},
 		},
+		{
+			name: "consensus keys",
+			startingPower: map[string]int64{
+				"a": 100,
+				"b": 100,
+			},
+			fn: func(t *testing.T, db sql.DB) {
+				ctx := context.Background()
+
+				err := SetConsensusKey(ctx, db, []byte("b"), []byte("bk"))
+				require.NoError(t, err)
+
+				owner, err := GetConsensusKeyOwner(ctx, db, []byte("bk"))
+				require.NoError(t, err)
+				require.Equal(t, []byte("b"), owner)
+
+				voters, err := GetValidators(ctx, db)
+				require.NoError(t, err)
+				require.Len(t, voters, 2)
+				for _, v := range voters {
+					if string(v.PubKey) == "b" {
+						require.Equal(t, []byte("bk"), v.ConsensusKey)
+					} else {
+						require.Nil(t, v.ConsensusKey)
+					}
+				}
+
+				// setting the validator's own key removes it
+				err = SetConsensusKey(ctx, db, []byte("b"), []byte("b"))
+				require.NoError(t, err)
+
+				owner, err = GetConsensusKeyOwner(ctx, db, []byte("bk"))
+				require.NoError(t, err)
+				require.Nil(t, owner)
+			},
+		},
 		{
 			name: "deletion and processed",
 			startingPower: map[string]int64{
@@ -281,6 +317,75 @@ func Test_Voting(t *testing.T) {
 				assert.Equal(t, len(notProcessed), 0)
 			},
 		},
+		{
+			name: "listing resolutions",
+			startingPower: map[string]int64{
+				"a": 100,
+				"b": 50,
+			},
+			fn: func(t *testing.T, db sql.DB) {
+				ctx := context.Background()
+
+				err := CreateResolution(ctx, db, testEvent, 10, []byte("a"))
+				require.NoError(t, err)
+				err = ApproveResolution(ctx, db, testEvent.ID(), []byte("a"))
+				require.NoError(t, err)
+
+				expiredEvent := &types.VotableEvent{Type: testType, Body: []byte("expired")}
+				err = CreateResolution(ctx, db, expiredEvent, 5, []byte("b"))
+				require.NoError(t, err)
+
+				expired, err := GetExpired(ctx, db, 5)
+				require.NoError(t, err)
+				require.Len(t, expired, 1)
+				err = StoreResolutionResult(ctx, db, expired[0], types.ResolutionStatusExpired, 100, 5)
+				require.NoError(t, err)
+				err = DeleteResolutions(ctx, db, expiredEvent.ID())
+				require.NoError(t, err)
+
+				infos, err := ListResolutions(ctx, db, &types.ResolutionFilter{}, 0, 0)
+				require.NoError(t, err)
+				require.Len(t, infos, 2) // ordered by expiration
+
+				assert.Equal(t, expiredEvent.ID(), infos[0].ID)
+				assert.Equal(t, types.ResolutionStatusExpired, infos[0].Status)
+				assert.Equal(t, int64(5), infos[0].ClosedHeight)
+				assert.Equal(t, int64(100), infos[0].RequiredPower)
+
+				assert.Equal(t, testEvent.ID(), infos[1].ID)
+				assert.Equal(t, types.ResolutionStatusPending, infos[1].Status)
+				assert.Equal(t, int64(1), infos[1].Votes)
+				assert.Equal(t, int64(100), infos[1].ApprovedPower)
+				assert.Equal(t, int64(100), infos[1].RequiredPower) // 2/3 of 150
+
+				infos, err = ListResolutions(ctx, db, &types.ResolutionFilter{Status: types.ResolutionStatusPending, Proposer: []byte("a")}, 0, 0)
+				require.NoError(t, err)
+				require.Len(t, infos, 1)
+				assert.Equal(t, testEvent.ID(), infos[0].ID)
+
+				infos, err = ListResolutions(ctx, db, &types.ResolutionFilter{MinExpiration: 6}, 0, 0)
+				require.NoError(t, err)
+				require.Len(t, infos, 1)
+
+				infos, err = ListResolutions(ctx, db, &types.ResolutionFilter{}, 1, 1)
+				require.NoError(t, err)
+				require.Len(t, infos, 1)
+				assert.Equal(t, testEvent.ID(), infos[0].ID)
+
+				info, err := GetResolution(ctx, db, testEvent.ID())
+				require.NoError(t, err)
+				assert.Equal(t, testEvent.Body, []byte(info.Body))
+				assert.Equal(t, []types.HexBytes{[]byte("a")}, info.Voters)
+
+				info, err = GetResolution(ctx, db, expiredEvent.ID())
+				require.NoError(t, err)
+				assert.Equal(t, types.ResolutionStatusExpired, info.Status)
+
+				info, err = GetResolution(ctx, db, dummyEvent.ID())
+				require.NoError(t, err)
+				assert.Nil(t, info)
+			},
+		},
 		{
 			name: "no resolutions",
 			startingPower: map[string]int64{
@@ -28,6 +28,10 @@ func initializeVoteStore(ctx context.Context, db sql.TxMaker) error {
 		0: initVotingTables,
 		1: dropHeight,
 		2: dropExtraVoteIDColumn,
+		3: initLivenessTables,
+		4: initEvidenceTable,
+		5: initResolutionResultsTable,
+		6: initConsensusKeysTable,
 	}
 
 	err := versioning.Upgrade(ctx, db, votingSchemaName, upgradeFns, voteStoreVersion)
@@ -515,14 +519,14 @@ func GetValidators(ctx context.Context, db sql.Executor) ([]*types.Validator, er
 		return nil, nil
 	}
 
-	if len(res.Rows[0]) != 2 {
+	if len(res.Rows[0]) != 3 {
 		// this should never happen, just for safety
 		return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
 	}
 
 	voters := make([]*types.Validator, len(res.Rows))
 	for i, row := range res.Rows {
-		if len(row) != 2 {
+		if len(row) != 3 {
 			// this should never happen, just for safety
 			return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
 		}
@@ -539,6 +543,13 @@ func GetValidators(ctx context.Context, db sql.Executor) ([]*types.Validator, er
 			PubKey: slices.Clone(voterBts),
 			Power:  power,
 		}
+		if row[2] != nil {
+			consensusKey, ok := row[2].([]byte)
+			if !ok {
+				return nil, fmt.Errorf("invalid type for consensus key")
+			}
+			voters[i].ConsensusKey = slices.Clone(consensusKey)
+		}
 	}
 
 	return voters, nil
@@ -0,0 +1,11 @@
+package wasm
+
+import "errors"
+
+var (
+	ErrInvalidModule  = errors.New("invalid WebAssembly module")
+	ErrModuleExists   = errors.New("WebAssembly module already exists")
+	ErrModuleNotFound = errors.New("WebAssembly module not found")
+	ErrOutOfGas       = errors.New("out of gas")
+	ErrNotActive      = errors.New("WebAssembly modules are not enabled on this network")
+)
@@ -0,0 +1,652 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// this file validates WebAssembly modules and instruments them with fuel
+// metering. wazero does not meter execution, so a mutable i64 global holding
+// the remaining fuel is added to the module and exported as fuelExport. Code
+// that decrements the fuel, and traps if it is exhausted, is inserted at the
+// start of every function and every loop, charging the number of instructions
+// in the function or loop body. Since every backward branch targets a loop,
+// this bounds the execution of any module. Bulk memory instructions are
+// charged for the number of bytes they copy or fill.
+
+const (
+	fuelExport = "kwil_fuel"
+
+	// instantiationFuel is the fuel available to the module's start function
+	// and initialization, before the fuel of a call is set.
+	instantiationFuel = 1_000_000
+)
+
+// section IDs
+const (
+	sectionCustom    byte = 0
+	sectionType      byte = 1
+	sectionImport    byte = 2
+	sectionFunction  byte = 3
+	sectionGlobal    byte = 6
+	sectionExport    byte = 7
+	sectionStart     byte = 8
+	sectionElement   byte = 9
+	sectionCode      byte = 10
+	sectionData      byte = 11
+	sectionDataCount byte = 12
+)
+
+var (
+	wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+	errFloat = errors.New("floating point instructions are not allowed, since they are not deterministic")
+)
+
+type section struct {
+	id      byte
+	content []byte
+}
+
+// sectionOrder returns the position of a section in a module, since the data
+// count section comes before the code section.
+func sectionOrder(id byte) int {
+	switch id {
+	case sectionDataCount:
+		return int(sectionCode)
+	case sectionCode, sectionData:
+		return int(id) + 1
+	default:
+		return int(id)
+	}
+}
+
+// meter validates a module and returns it instrumented with fuel metering.
+// Only imports from the kwil host module are allowed, and floating point and
+// SIMD instructions are rejected.
+func meter(bin []byte) ([]byte, error) {
+	if !bytes.HasPrefix(bin, wasmHeader) {
+		return nil, errors.New("not a WebAssembly binary module")
+	}
+
+	var sections []*section
+	r := &reader{b: bin[len(wasmHeader):]}
+	for r.len() > 0 {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.vec()
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, &section{id: id, content: content})
+	}
+
+	var importedGlobals, definedGlobals uint32
+	var typeParams, funcTypes []uint32
+	for _, s := range sections {
+		var err error
+		switch s.id {
+		case sectionType:
+			typeParams, err = readTypeParams(s.content)
+		case sectionImport:
+			importedGlobals, err = checkImports(s.content)
+		case sectionFunction:
+			funcTypes, err = readU32s(s.content)
+		case sectionGlobal:
+			definedGlobals, err = (&reader{b: s.content}).u32()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	fuelGlobal := importedGlobals + definedGlobals
+
+	// the number of parameters of each function in the code section
+	funcParams := make([]uint32, len(funcTypes))
+	for i, typ := range funcTypes {
+		if int(typ) >= len(typeParams) {
+			return nil, fmt.Errorf("function %d has unknown type %d", i, typ)
+		}
+		funcParams[i] = typeParams[typ]
+	}
+
+	// the fuel global and its export are added, creating the sections if
+	// the module does not have them
+	sections = ensureSection(sections, sectionGlobal)
+	sections = ensureSection(sections, sectionExport)
+
+	for _, s := range sections {
+		var err error
+		switch s.id {
+		case sectionGlobal:
+			// mutable i64 initialized with i64.const instantiationFuel
+			global := []byte{0x7e, 0x01, 0x42}
+			global = appendS64(global, instantiationFuel)
+			global = append(global, 0x0b)
+			s.content, err = appendToVec(s.content, global)
+		case sectionExport:
+			s.content, err = addFuelExport(s.content, fuelGlobal)
+		case sectionCode:
+			s.content, err = meterCode(s.content, fuelGlobal, funcParams)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := bytes.Clone(wasmHeader)
+	for _, s := range sections {
+		out = append(out, s.id)
+		out = binary.AppendUvarint(out, uint64(len(s.content)))
+		out = append(out, s.content...)
+	}
+
+	return out, nil
+}
+
+// ensureSection adds an empty section if the module does not have it.
+func ensureSection(sections []*section, id byte) []*section {
+	pos := len(sections)
+	for i, s := range sections {
+		if s.id == id {
+			return sections
+		}
+		if s.id != sectionCustom && sectionOrder(s.id) > sectionOrder(id) && pos == len(sections) {
+			pos = i
+		}
+	}
+
+	return append(sections[:pos], append([]*section{{id: id, content: []byte{0}}}, sections[pos:]...)...)
+}
+
+// appendToVec appends an encoded entry to a vector, incrementing its length.
+func appendToVec(vec []byte, entry []byte) ([]byte, error) {
+	r := &reader{b: vec}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+
+	out := binary.AppendUvarint(nil, uint64(n)+1)
+	out = append(out, r.b...)
+	return append(out, entry...), nil
+}
+
+// checkImports checks that a module only imports functions from the kwil
+// host module, and returns the number of imported globals, which is always
+// zero.
+func checkImports(content []byte) (uint32, error) {
+	r := &reader{b: content}
+	n, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := uint32(0); i < n; i++ {
+		module, err := r.vec()
+		if err != nil {
+			return 0, err
+		}
+		name, err := r.vec()
+		if err != nil {
+			return 0, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		if string(module) != hostModule || kind != 0x00 {
+			return 0, fmt.Errorf("import %s.%s is not allowed, only functions of the %s module may be imported", module, name, hostModule)
+		}
+		if _, err = r.u32(); err != nil { // type index
+			return 0, err
+		}
+	}
+
+	return 0, nil
+}
+
+// readTypeParams returns the number of parameters of each function type in the
+// type section.
+func readTypeParams(content []byte) ([]uint32, error) {
+	r := &reader{b: content}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]uint32, n)
+	for i := range params {
+		form, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if form != 0x60 {
+			return nil, fmt.Errorf("invalid function type 0x%x", form)
+		}
+
+		p, err := r.vec()
+		if err != nil {
+			return nil, err
+		}
+		params[i] = uint32(len(p))
+
+		if _, err = r.vec(); err != nil { // results
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
+
+// readU32s reads a vector of unsigned integers, such as the type indices of
+// the function section.
+func readU32s(content []byte) ([]uint32, error) {
+	r := &reader{b: content}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > r.len() {
+		return nil, errUnexpectedEnd
+	}
+
+	vals := make([]uint32, n)
+	for i := range vals {
+		if vals[i], err = r.u32(); err != nil {
+			return nil, err
+		}
+	}
+
+	return vals, nil
+}
+
+// addFuelExport adds the export of the fuel global to the export section.
+func addFuelExport(content []byte, fuelGlobal uint32) ([]byte, error) {
+	r := &reader{b: content}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < n; i++ {
+		name, err := r.vec()
+		if err != nil {
+			return nil, err
+		}
+		if string(name) == fuelExport {
+			return nil, fmt.Errorf("module may not export %s", fuelExport)
+		}
+		if _, err = r.byte(); err != nil { // kind
+			return nil, err
+		}
+		if _, err = r.u32(); err != nil { // index
+			return nil, err
+		}
+	}
+
+	export := binary.AppendUvarint(nil, uint64(len(fuelExport)))
+	export = append(export, fuelExport...)
+	export = append(export, 0x03) // global
+	export = binary.AppendUvarint(export, uint64(fuelGlobal))
+
+	return appendToVec(content, export)
+}
+
+// meterCode instruments every function body in the code section, given the
+// number of parameters of each function.
+func meterCode(content []byte, fuelGlobal uint32, funcParams []uint32) ([]byte, error) {
+	r := &reader{b: content}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(funcParams) {
+		return nil, errors.New("code and function sections have different lengths")
+	}
+
+	out := binary.AppendUvarint(nil, uint64(n))
+	for i := uint32(0); i < n; i++ {
+		body, err := r.vec()
+		if err != nil {
+			return nil, err
+		}
+
+		metered, err := meterFunction(body, fuelGlobal, funcParams[i])
+		if err != nil {
+			return nil, fmt.Errorf("function %d: %w", i, err)
+		}
+
+		out = binary.AppendUvarint(out, uint64(len(metered)))
+		out = append(out, metered...)
+	}
+
+	if r.len() > 0 {
+		return nil, errors.New("unexpected bytes after code section")
+	}
+
+	return out, nil
+}
+
+// charge is fuel that is charged at a position in a function body. A static
+// charge is the number of instructions in a function or loop body, and is
+// charged when the body is entered. A dynamic charge is proportional to the
+// length operand of a bulk memory instruction, and is charged before it.
+type charge struct {
+	pos     int
+	cost    int64
+	dynamic bool
+}
+
+// bulkBytesPerFuelShift charges a bulk memory instruction one unit of fuel
+// for each 64 bytes.
+const bulkBytesPerFuelShift = 6
+
+// meterFunction inserts fuel charges at the start of a function body, of
+// every loop in it, and before every bulk memory instruction. A function with
+// bulk memory instructions is given an extra i32 local to hold their length.
+func meterFunction(body []byte, fuelGlobal uint32, params uint32) ([]byte, error) {
+	r := &reader{b: body}
+
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	declsStart := r.pos(len(body))
+
+	// count the locals, so the index of the extra local is known
+	numLocals := uint64(params)
+	for i := uint32(0); i < n; i++ {
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = r.byte(); err != nil {
+			return nil, err
+		}
+		numLocals += uint64(count)
+	}
+	if numLocals >= 50000 {
+		return nil, errors.New("too many locals")
+	}
+	codeStart := r.pos(len(body))
+
+	charges := []*charge{{pos: codeStart}}
+	open := []int{0} // the static charge of each open function or loop body
+	// blocks tracks whether each open block is a loop, starting with the
+	// function body
+	blocks := []bool{false}
+	var hasBulk bool
+
+	for len(blocks) > 0 {
+		pos := r.pos(len(body))
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		charges[open[len(open)-1]].cost++
+
+		switch {
+		case op == 0x02 || op == 0x04: // block, if
+			if _, err = r.s64(); err != nil { // block type
+				return nil, err
+			}
+			blocks = append(blocks, false)
+		case op == 0x03: // loop
+			if _, err = r.s64(); err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, true)
+			charges = append(charges, &charge{pos: r.pos(len(body))})
+			open = append(open, len(charges)-1)
+		case op == 0x0b: // end
+			if blocks[len(blocks)-1] {
+				open = open[:len(open)-1]
+			}
+			blocks = blocks[:len(blocks)-1]
+		case op == 0xfc:
+			bulk, err := skipPrefixed(r)
+			if err != nil {
+				return nil, err
+			}
+			if bulk {
+				charges = append(charges, &charge{pos: pos, dynamic: true})
+				hasBulk = true
+			}
+		default:
+			if err = skipImmediates(r, op); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if r.len() > 0 {
+		return nil, errors.New("unexpected bytes after function body")
+	}
+
+	out := make([]byte, 0, len(body)+len(charges)*32)
+	if hasBulk {
+		out = binary.AppendUvarint(out, uint64(n)+1)
+		out = append(out, body[declsStart:codeStart]...)
+		out = append(out, 0x01, 0x7f) // one i32
+	} else {
+		out = append(out, body[:codeStart]...)
+	}
+
+	last := codeStart
+	for _, c := range charges {
+		out = append(out, body[last:c.pos]...)
+		if c.dynamic {
+			out = appendBulkCharge(out, fuelGlobal, uint32(numLocals))
+		} else {
+			out = appendCharge(out, fuelGlobal, c.cost)
+		}
+		last = c.pos
+	}
+	return append(out, body[last:]...), nil
+}
+
+// appendCharge appends code that subtracts the cost from the fuel global, and
+// traps if the fuel is exhausted.
+func appendCharge(out []byte, fuelGlobal uint32, cost int64) []byte {
+	out = append(out, 0x23) // global.get
+	out = binary.AppendUvarint(out, uint64(fuelGlobal))
+	out = append(out, 0x42) // i64.const
+	out = appendS64(out, cost)
+	return appendSubFuel(out, fuelGlobal)
+}
+
+// appendBulkCharge appends code that charges a bulk memory instruction for the
+// length operand on top of the stack, which is kept in the given local and
+// left on the stack.
+func appendBulkCharge(out []byte, fuelGlobal uint32, local uint32) []byte {
+	out = append(out, 0x22) // local.tee
+	out = binary.AppendUvarint(out, uint64(local))
+	out = append(out, 0x23) // global.get
+	out = binary.AppendUvarint(out, uint64(fuelGlobal))
+	out = append(out, 0x20) // local.get
+	out = binary.AppendUvarint(out, uint64(local))
+	// i64.extend_i32_u, i64.const shift, i64.shr_u
+	out = append(out, 0xad, 0x42, bulkBytesPerFuelShift, 0x88)
+	return appendSubFuel(out, fuelGlobal)
+}
+
+// appendSubFuel appends code that subtracts the value on top of the stack
+// from the fuel global, which is below it, and traps if the fuel is
+// exhausted.
+func appendSubFuel(out []byte, fuelGlobal uint32) []byte {
+	out = append(out, 0x7d, 0x24) // i64.sub, global.set
+	out = binary.AppendUvarint(out, uint64(fuelGlobal))
+	out = append(out, 0x23) // global.get
+	out = binary.AppendUvarint(out, uint64(fuelGlobal))
+	// i64.const 0, i64.lt_s, if (empty block type), unreachable, end
+	return append(out, 0x42, 0x00, 0x53, 0x04, 0x40, 0x00, 0x0b)
+}
+
+// skipImmediates skips the immediate arguments of an instruction, rejecting
+// instructions that are not allowed.
+func skipImmediates(r *reader, op byte) error {
+	var err error
+	switch {
+	case op == 0x00 || op == 0x01 || op == 0x05 || op == 0x0f || op == 0x1a || op == 0x1b || op == 0xd1:
+		// unreachable, nop, else, return, drop, select, ref.is_null
+	case op == 0x0c || op == 0x0d || op == 0x10 || op == 0xd2 || (op >= 0x20 && op <= 0x26):
+		// br, br_if, call, ref.func, local, global and table get/set
+		_, err = r.u32()
+	case op == 0x0e: // br_table
+		var n uint32
+		if n, err = r.u32(); err != nil {
+			return err
+		}
+		for i := uint32(0); i <= n && err == nil; i++ {
+			_, err = r.u32()
+		}
+	case op == 0x11: // call_indirect
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x1c: // select with types
+		_, err = r.vec()
+	case op == 0x2a || op == 0x2b || op == 0x38 || op == 0x39: // float loads and stores
+		return errFloat
+	case op >= 0x28 && op <= 0x3e: // loads and stores
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x3f || op == 0x40 || op == 0xd0: // memory.size, memory.grow, ref.null
+		_, err = r.byte()
+	case op == 0x41: // i32.const
+		_, err = r.s64()
+	case op == 0x42: // i64.const
+		_, err = r.s64()
+	case op == 0x43 || op == 0x44: // f32.const, f64.const
+		return errFloat
+	case (op >= 0x5b && op <= 0x66) || (op >= 0x8b && op <= 0xa6) ||
+		(op >= 0xa8 && op <= 0xab) || (op >= 0xae && op <= 0xbf):
+		// float comparisons, arithmetic and conversions
+		return errFloat
+	case op >= 0x45 && op <= 0xc4: // integer instructions
+	default:
+		return fmt.Errorf("instruction 0x%x is not allowed", op)
+	}
+	return err
+}
+
+// skipPrefixed skips a 0xfc prefixed instruction, and returns whether it is a
+// bulk memory instruction that must be charged for its length. Table
+// instructions that copy, fill or grow tables are not allowed, since they are
+// not metered.
+func skipPrefixed(r *reader) (bool, error) {
+	op, err := r.u32()
+	if err != nil {
+		return false, err
+	}
+
+	var immediates int
+	var bulk bool
+	switch op {
+	case 0, 1, 2, 3, 4, 5, 6, 7: // saturating float truncation
+		return false, errFloat
+	case 9, 13, 16: // data.drop, elem.drop, table.size
+		immediates = 1
+	case 11: // memory.fill
+		immediates, bulk = 1, true
+	case 8, 10: // memory.init, memory.copy
+		immediates, bulk = 2, true
+	default:
+		return false, fmt.Errorf("instruction 0xfc %d is not allowed", op)
+	}
+
+	for i := 0; i < immediates; i++ {
+		if _, err = r.u32(); err != nil {
+			return false, err
+		}
+	}
+	return bulk, nil
+}
+
+// appendS64 appends a signed LEB128 integer.
+func appendS64(b []byte, v int64) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+// reader reads the WebAssembly binary format.
+type reader struct {
+	b []byte
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of module")
+
+func (r *reader) len() int {
+	return len(r.b)
+}
+
+// pos returns the position of the reader in the original bytes, given their
+// length.
+func (r *reader) pos(total int) int {
+	return total - len(r.b)
+}
+
+func (r *reader) byte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, errUnexpectedEnd
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 || n > 5 || v > 0xffffffff {
+		return 0, errors.New("invalid unsigned integer")
+	}
+	r.b = r.b[n:]
+	return uint32(v), nil
+}
+
+func (r *reader) s64() (int64, error) {
+	var v int64
+	var shift uint
+	for {
+		c, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		if shift >= 64 {
+			return 0, errors.New("invalid signed integer")
+		}
+		v |= int64(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			if shift < 64 && c&0x40 != 0 {
+				v |= -1 << shift
+			}
+			return v, nil
+		}
+	}
+}
+
+// vec reads a length-prefixed byte vector.
+func (r *reader) vec() ([]byte, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > len(r.b) {
+		return nil, errUnexpectedEnd
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
@@ -0,0 +1,73 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+const (
+	schemaName = `kwild_wasm`
+
+	wasmStoreVersion = 0
+
+	sqlInitModulesTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.modules (
+		name TEXT PRIMARY KEY,
+		module BYTEA NOT NULL, -- the module as deployed, before it is metered
+		height INT8 NOT NULL -- the height at which the module was deployed
+	);`
+
+	sqlInsertModule = `INSERT INTO ` + schemaName + `.modules (name, module, height) VALUES ($1, $2, $3)`
+
+	sqlModuleExists = `SELECT 1 FROM ` + schemaName + `.modules WHERE name = $1`
+
+	sqlListModules = `SELECT name, module FROM ` + schemaName + `.modules ORDER BY name`
+)
+
+func initTables(ctx context.Context, tx sql.DB) error {
+	_, err := tx.Execute(ctx, sqlInitModulesTable)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	return nil
+}
+
+// moduleExists checks if a module with the given name has been deployed.
+func moduleExists(ctx context.Context, db sql.Executor, name string) (bool, error) {
+	res, err := db.Execute(ctx, sqlModuleExists, name)
+	if err != nil {
+		return false, err
+	}
+
+	return len(res.Rows) > 0, nil
+}
+
+// listModules lists the deployed modules by name.
+func listModules(ctx context.Context, db sql.Executor) (map[string][]byte, error) {
+	res, err := db.Execute(ctx, sqlListModules)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make(map[string][]byte, len(res.Rows))
+	for _, row := range res.Rows {
+		if len(row) != 2 {
+			// this should never happen, just for safety
+			return nil, fmt.Errorf("invalid number of columns returned. this is an internal bug")
+		}
+
+		name, ok := row[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for name (%T)", row[0])
+		}
+		bin, ok := row[1].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for module (%T)", row[1])
+		}
+		modules[name] = bin
+	}
+
+	return modules, nil
+}
@@ -0,0 +1,548 @@
+// Package wasm provides a precompile that runs WebAssembly modules, allowing a
+// network to add logic without rebuilding kwild. Modules are deployed by a
+// governance resolution of type ModuleDeploymentType, and are used in a
+// schema with `use wasm {module: 'name'} as alias`.
+//
+// A module must export its memory as "memory", and an allocation function
+// "alloc(size i32) -> i32" that returns a pointer to size bytes of memory.
+// Each method that can be called is an exported function with the signature
+// "(ptr i32, len i32) -> i64". The input of a method is a JSON array of the
+// arguments of the call, written to memory allocated with alloc. A method
+// returns the location of its output packed as ptr<<32 | len, and the output is
+// a JSON array of results, which may be integers, strings, booleans or null. A
+// method without results may return zero.
+//
+// Execution is deterministic. A module may only import functions of the "kwil"
+// host module, which provides no clock, randomness or I/O, and floating point
+// and SIMD instructions are rejected. A module can abort a call with an error
+// message by calling "kwil.fail(ptr i32, len i32)". Each call runs in a new
+// instance of the module, and is metered with fuel that is charged as gas to
+// the procedure context.
+//
+// Modules may only be deployed and used once the wasm hard fork is active. A
+// deployed module is available to schemas from the block after the one that
+// deployed it, once that block is committed.
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/kwilteam/kwil-db/common"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/extensions/precompiles"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+	"github.com/kwilteam/kwil-db/internal/sql/versioning"
+)
+
+const (
+	// PrecompileName is the name of the precompile in a schema's use
+	// statement.
+	PrecompileName = "wasm"
+	// ModuleDeploymentType is the resolution type that deploys a module.
+	ModuleDeploymentType = "wasm_module"
+
+	hostModule  = "kwil"
+	allocExport = "alloc"
+
+	// maxGas is the gas limit of a procedure execution, which is enforced by
+	// the engine.
+	maxGas = 10000000
+	// fuelPerGas is the number of instructions that are executed for each
+	// unit of gas.
+	fuelPerGas = 10
+	// memoryLimitPages limits the memory of a module to 16 MiB.
+	memoryLimitPages = 256
+	// maxNameLength is the maximum length of a module name.
+	maxNameLength = 64
+	// maxFailureLength is the maximum length of an error message passed to
+	// kwil.fail.
+	maxFailureLength = 1024
+)
+
+// ModuleDeploymentResolution is the resolution that deploys a WebAssembly
+// module. Modules are immutable, so a module cannot be deployed with the name
+// of an existing module.
+var ModuleDeploymentResolution = resolutions.ResolutionConfig{
+	ConfirmationThreshold: big.NewRat(2, 3),
+	ExpirationPeriod:      100800, // 1 week
+	ResolveFunc:           deployModule,
+}
+
+func init() {
+	err := precompiles.RegisterPrecompile(PrecompileName, initializeInstance)
+	if err != nil {
+		panic(err)
+	}
+
+	err = resolutions.RegisterResolution(ModuleDeploymentType, resolutions.ModAdd, ModuleDeploymentResolution)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ModuleDeployment is the body of a module deployment resolution.
+type ModuleDeployment struct {
+	// Name is the name used to reference the module in a schema.
+	Name string
+	// Module is the WebAssembly binary module.
+	Module []byte
+}
+
+// MarshalBinary marshals the ModuleDeployment into a binary format.
+func (md *ModuleDeployment) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(md)
+}
+
+// UnmarshalBinary unmarshals the ModuleDeployment from a binary format.
+func (md *ModuleDeployment) UnmarshalBinary(data []byte) error {
+	return serialize.Decode(data, md)
+}
+
+// ValidateModule checks that a module can be deployed with the given name,
+// without compiling it. It allows a module to be checked before it is
+// proposed.
+func ValidateModule(name string, bin []byte) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	if _, err := meter(bin); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+
+	return nil
+}
+
+// validateName checks that a module name is made of lowercase letters, digits
+// and underscores.
+func validateName(name string) error {
+	if len(name) == 0 || len(name) > maxNameLength {
+		return fmt.Errorf("%w: name must be between 1 and %d characters", ErrInvalidModule, maxNameLength)
+	}
+
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return fmt.Errorf("%w: name may only contain lowercase letters, digits and underscores", ErrInvalidModule)
+		}
+	}
+
+	return nil
+}
+
+// InitializeWasmStore initializes the WebAssembly module store schema and
+// tables.
+func InitializeWasmStore(ctx context.Context, db sql.DB) error {
+	upgradeFns := map[int64]versioning.UpgradeFunc{
+		0: initTables,
+	}
+
+	err := versioning.Upgrade(ctx, db, schemaName, upgradeFns, wasmStoreVersion)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadModules compiles the deployed modules, replacing any modules that were
+// loaded or deployed before. It must be called before the engine loads the datasets that
+// use them, such as at startup or after the state is restored from a snapshot.
+func LoadModules(ctx context.Context, db sql.Executor) error {
+	bins, err := listModules(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	modules := make(map[string]wazero.CompiledModule, len(bins))
+	for name, bin := range bins {
+		modules[name], err = registry.compile(ctx, bin)
+		if err != nil {
+			return fmt.Errorf("failed to load module %s: %w", name, err)
+		}
+	}
+
+	registry.replace(modules)
+
+	return nil
+}
+
+// CommitModules makes the modules deployed in the current block available to
+// schemas. It must be called after the block is committed.
+func CommitModules() {
+	registry.commit()
+}
+
+// DiscardModules discards the modules deployed since the last committed block.
+// It must be called before a block is executed, in case the last one was not
+// committed.
+func DiscardModules() {
+	registry.discard()
+}
+
+// deployModule is the ResolveFunc of ModuleDeploymentResolution. It stores the
+// module, which is available to schemas once the block is committed.
+func deployModule(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error {
+	if !app.Service.GenesisConfig.Forks().IsActive(consensus.ForkWasm, uint64(block.Height)) {
+		return ErrNotActive
+	}
+
+	md := &ModuleDeployment{}
+	if err := md.UnmarshalBinary(resolution.Body); err != nil {
+		return err
+	}
+
+	if err := validateName(md.Name); err != nil {
+		return err
+	}
+
+	exists, err := moduleExists(ctx, app.DB, md.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrModuleExists, md.Name)
+	}
+
+	compiled, err := registry.compile(ctx, md.Module)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB.Execute(ctx, sqlInsertModule, md.Name, md.Module, block.Height)
+	if err != nil {
+		return err
+	}
+
+	registry.stage(md.Name, compiled)
+	app.Service.Logger.Info("deployed WebAssembly module", log.String("name", md.Name), log.Int("height", block.Height))
+
+	return nil
+}
+
+// moduleRegistry holds the compiled modules, and the modules deployed in the
+// current block, which are pending until it is committed. The runtime is
+// created when it is first used.
+type moduleRegistry struct {
+	mu      sync.RWMutex
+	modules map[string]wazero.CompiledModule
+	pending map[string]wazero.CompiledModule
+
+	once    sync.Once
+	runtime wazero.Runtime
+	err     error
+}
+
+var registry = &moduleRegistry{
+	modules: make(map[string]wazero.CompiledModule),
+	pending: make(map[string]wazero.CompiledModule),
+}
+
+func (m *moduleRegistry) getRuntime() (wazero.Runtime, error) {
+	m.once.Do(func() {
+		m.runtime, m.err = newRuntime(context.Background())
+	})
+	return m.runtime, m.err
+}
+
+// newRuntime creates a runtime that uses the interpreter, so that execution
+// does not depend on the platform, with the kwil host module.
+func newRuntime(ctx context.Context) (wazero.Runtime, error) {
+	cfg := wazero.NewRuntimeConfigInterpreter().
+		WithCoreFeatures(api.CoreFeaturesV2.SetEnabled(api.CoreFeatureSIMD, false)).
+		WithMemoryLimitPages(memoryLimitPages)
+	r := wazero.NewRuntimeWithConfig(ctx, cfg)
+
+	_, err := r.NewHostModuleBuilder(hostModule).
+		NewFunctionBuilder().WithFunc(fail).Export("fail").
+		Instantiate(ctx)
+	if err != nil {
+		r.Close(ctx)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// compile meters and compiles a module, and checks that it implements the ABI.
+func (m *moduleRegistry) compile(ctx context.Context, bin []byte) (wazero.CompiledModule, error) {
+	rt, err := m.getRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	metered, err := meter(bin)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+
+	compiled, err := rt.CompileModule(ctx, metered)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+
+	if err = checkABI(compiled); err != nil {
+		compiled.Close(ctx)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+
+	return compiled, nil
+}
+
+func (m *moduleRegistry) get(name string) (wazero.CompiledModule, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	compiled, ok := m.modules[name]
+	return compiled, ok
+}
+
+// stage adds a module that is pending until commit is called.
+func (m *moduleRegistry) stage(name string, compiled wazero.CompiledModule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[name] = compiled
+}
+
+// commit adds the pending modules.
+func (m *moduleRegistry) commit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, compiled := range m.pending {
+		m.modules[name] = compiled
+	}
+	clear(m.pending)
+}
+
+// discard closes and drops the pending modules, which have not been used.
+func (m *moduleRegistry) discard() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, compiled := range m.pending {
+		compiled.Close(context.Background())
+	}
+	clear(m.pending)
+}
+
+// replace replaces all modules, and drops the pending modules. The replaced
+// modules are not closed, since they may still be in use.
+func (m *moduleRegistry) replace(modules map[string]wazero.CompiledModule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.modules = modules
+	clear(m.pending)
+}
+
+// checkABI checks that a compiled module exports its memory and an alloc
+// function, and only imports kwil.fail.
+func checkABI(compiled wazero.CompiledModule) error {
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		return errors.New("module must export its memory as memory")
+	}
+
+	alloc, ok := compiled.ExportedFunctions()[allocExport]
+	if !ok || !hasSignature(alloc, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return errors.New("module must export alloc(i32) -> i32")
+	}
+
+	for _, fn := range compiled.ImportedFunctions() {
+		_, name, _ := fn.Import()
+		if name != "fail" || !hasSignature(fn, pointerParams, nil) {
+			return fmt.Errorf("unknown import %s.%s", hostModule, name)
+		}
+	}
+
+	return nil
+}
+
+// pointerParams are the parameters of a function that takes a pointer and
+// length, such as a method or kwil.fail.
+var pointerParams = []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}
+
+// hasSignature checks the parameter and result types of a function.
+func hasSignature(fn api.FunctionDefinition, params, results []api.ValueType) bool {
+	return slices.Equal(fn.ParamTypes(), params) && slices.Equal(fn.ResultTypes(), results)
+}
+
+// callState records a call of kwil.fail during a call to a module.
+type callState struct {
+	failed  bool
+	message string
+}
+
+type callStateKey struct{}
+
+var errModuleFailed = errors.New("module failed")
+
+// fail is the kwil.fail host function, which aborts a call with an error
+// message.
+func fail(ctx context.Context, m api.Module, ptr, length uint32) {
+	msg, ok := m.Memory().Read(ptr, min(length, maxFailureLength))
+	if !ok {
+		msg = []byte("invalid error message")
+	}
+
+	if state, ok := ctx.Value(callStateKey{}).(*callState); ok {
+		state.failed = true
+		state.message = string(msg)
+	}
+
+	// the interpreter recovers the panic, and returns it as an error
+	panic(errModuleFailed)
+}
+
+// initializeInstance initializes an instance of the precompile, which requires
+// the name of a deployed module, such as `use wasm {module: 'name'} as alias`.
+func initializeInstance(ctx *precompiles.DeploymentContext, service *common.Service, metadata map[string]string) (precompiles.Instance, error) {
+	name, ok := metadata["module"]
+	if !ok {
+		return nil, errors.New("wasm precompile requires a module")
+	}
+
+	if _, ok = registry.get(name); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+
+	return &instance{module: name}, nil
+}
+
+// instance is an instance of the precompile for a module.
+type instance struct {
+	module string
+}
+
+// Call calls a method of the module. The fuel used by the call, including the
+// instantiation of the module, is added to the used gas of the procedure
+// context.
+func (i *instance) Call(scoper *precompiles.ProcedureContext, app *common.App, method string, inputs []any) ([]any, error) {
+	compiled, ok := registry.get(i.module)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, i.module)
+	}
+
+	def, ok := compiled.ExportedFunctions()[method]
+	if !ok || method == allocExport || !hasSignature(def, pointerParams, []api.ValueType{api.ValueTypeI64}) {
+		return nil, fmt.Errorf("method %s not found in module %s", method, i.module)
+	}
+
+	if scoper.UsedGas >= maxGas {
+		return nil, ErrOutOfGas
+	}
+
+	if inputs == nil {
+		inputs = []any{}
+	}
+	args, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	rt, err := registry.getRuntime()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &callState{}
+	ctx := context.WithValue(scoper.TxCtx.Ctx, callStateKey{}, state)
+
+	mod, err := rt.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName("").WithStartFunctions())
+	if err != nil {
+		scoper.UsedGas += instantiationFuel / fuelPerGas
+		return nil, fmt.Errorf("failed to instantiate module %s: %w", i.module, err)
+	}
+	defer mod.Close(ctx)
+
+	fuel := mod.ExportedGlobal(fuelExport).(api.MutableGlobal)
+	// the instantiation, such as the module's start function, is charged
+	// from its own fuel
+	spent := instantiationFuel - int64(fuel.Get())
+	budget := int64(maxGas-scoper.UsedGas) * fuelPerGas
+	fuel.Set(uint64(budget))
+
+	results, err := callMethod(ctx, mod, method, args)
+
+	remaining := int64(fuel.Get())
+	used := spent + budget - remaining
+	scoper.UsedGas += uint64((used + fuelPerGas - 1) / fuelPerGas)
+
+	switch {
+	case remaining < 0:
+		return nil, fmt.Errorf("%w: module %s", ErrOutOfGas, i.module)
+	case state.failed:
+		return nil, fmt.Errorf("module %s failed: %s", i.module, state.message)
+	case err != nil:
+		return nil, fmt.Errorf("module %s: %w", i.module, err)
+	}
+
+	return results, nil
+}
+
+// callMethod writes the arguments to the module's memory, calls the method,
+// and decodes its results.
+func callMethod(ctx context.Context, mod api.Module, method string, args []byte) ([]any, error) {
+	res, err := mod.ExportedFunction(allocExport).Call(ctx, uint64(len(args)))
+	if err != nil {
+		return nil, err
+	}
+
+	ptr := uint32(res[0])
+	if !mod.Memory().Write(ptr, args) {
+		return nil, errors.New("alloc returned memory out of range")
+	}
+
+	res, err = mod.ExportedFunction(method).Call(ctx, uint64(ptr), uint64(len(args)))
+	if err != nil {
+		return nil, err
+	}
+
+	out, ok := mod.Memory().Read(uint32(res[0]>>32), uint32(res[0]))
+	if !ok {
+		return nil, errors.New("method returned memory out of range")
+	}
+
+	return decodeResults(out)
+}
+
+// decodeResults decodes the JSON array of results returned by a method.
+// Numbers must be integers, and arrays and objects are not supported. Empty
+// output has no results.
+func decodeResults(out []byte) ([]any, error) {
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.UseNumber()
+
+	var results []any
+	if err := dec.Decode(&results); err != nil {
+		return nil, fmt.Errorf("invalid results: %w", err)
+	}
+
+	for i, v := range results {
+		switch v := v.(type) {
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("result %d is not an integer: %s", i, v)
+			}
+			results[i] = n
+		case string, bool, nil:
+		default:
+			return nil, fmt.Errorf("result %d has unsupported type %T", i, v)
+		}
+	}
+
+	return results, nil
+}
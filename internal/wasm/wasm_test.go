@@ -0,0 +1,310 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/kwilteam/kwil-db/common"
+	"github.com/kwilteam/kwil-db/common/chain"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/log"
+	"github.com/kwilteam/kwil-db/extensions/consensus"
+	"github.com/kwilteam/kwil-db/extensions/precompiles"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+)
+
+// buildModule assembles a module from its sections.
+func buildModule(sections ...[]byte) []byte {
+	bin := append([]byte{}, wasmHeader...)
+	for _, s := range sections {
+		bin = append(bin, s[0])
+		bin = binary.AppendUvarint(bin, uint64(len(s)-1))
+		bin = append(bin, s[1:]...)
+	}
+	return bin
+}
+
+// name encodes a name in a module.
+func name(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// testModule implements the ABI with the methods echo, which returns its
+// input, spin, which never returns, fails, which calls kwil.fail with its
+// input, and fill, which fills 64 KiB of memory.
+var testModule = buildModule(
+	// types: (i32) -> i32, (i32, i32) -> i64, (i32, i32) -> ()
+	[]byte{1, 3, 0x60, 1, 0x7f, 1, 0x7f, 0x60, 2, 0x7f, 0x7f, 1, 0x7e, 0x60, 2, 0x7f, 0x7f, 0},
+	concat([]byte{2, 1}, name("kwil"), name("fail"), []byte{0, 2}),
+	// alloc, echo, spin, fails, fill
+	[]byte{3, 5, 0, 1, 1, 1, 1},
+	// one page of memory
+	[]byte{5, 1, 0, 1},
+	// the heap pointer, starting at 1024
+	[]byte{6, 1, 0x7f, 1, 0x41, 0x80, 0x08, 0x0b},
+	concat([]byte{7, 6}, name("memory"), []byte{2, 0}, name("alloc"), []byte{0, 1},
+		name("echo"), []byte{0, 2}, name("spin"), []byte{0, 3}, name("fails"), []byte{0, 4}, name("fill"), []byte{0, 5}),
+	concat([]byte{10, 5},
+		[]byte{11, 0, 0x23, 0, 0x23, 0, 0x20, 0, 0x6a, 0x24, 0, 0x0b},
+		[]byte{12, 0, 0x20, 0, 0xad, 0x42, 0x20, 0x86, 0x20, 1, 0xad, 0x84, 0x0b},
+		[]byte{9, 0, 0x03, 0x40, 0x0c, 0, 0x0b, 0x42, 0, 0x0b},
+		[]byte{10, 0, 0x20, 0, 0x20, 1, 0x10, 0, 0x42, 0, 0x0b},
+		[]byte{15, 0, 0x41, 0, 0x41, 0, 0x41, 0x80, 0x80, 0x04, 0xfc, 0x0b, 0, 0x42, 0, 0x0b},
+	),
+)
+
+func Test_Meter(t *testing.T) {
+	type testcase struct {
+		name   string
+		module []byte
+		err    bool
+	}
+
+	tests := []testcase{
+		{
+			name:   "valid",
+			module: testModule,
+		},
+		{
+			name:   "not a module",
+			module: []byte("not a module"),
+			err:    true,
+		},
+		{
+			name: "floating point",
+			module: buildModule(
+				[]byte{1, 1, 0x60, 0, 0},
+				[]byte{3, 1, 0},
+				// f32.const 0, drop
+				[]byte{10, 1, 8, 0, 0x43, 0, 0, 0, 0, 0x1a, 0x0b},
+			),
+			err: true,
+		},
+		{
+			name: "other imports",
+			module: buildModule(
+				[]byte{1, 1, 0x60, 0, 0},
+				concat([]byte{2, 1}, name("wasi_snapshot_preview1"), name("clock_time_get"), []byte{0, 0}),
+			),
+			err: true,
+		},
+		{
+			name: "table grow",
+			module: buildModule(
+				[]byte{1, 1, 0x60, 0, 0},
+				[]byte{3, 1, 0},
+				[]byte{4, 1, 0x70, 0, 0},
+				// ref.null func, i32.const 1, table.grow 0, drop
+				[]byte{10, 1, 10, 0, 0xd0, 0x70, 0x41, 1, 0xfc, 15, 0, 0x1a, 0x0b},
+			),
+			err: true,
+		},
+		{
+			name: "exports fuel",
+			module: buildModule(
+				[]byte{6, 1, 0x7e, 1, 0x42, 0, 0x0b},
+				concat([]byte{7, 1}, name(fuelExport), []byte{3, 0}),
+			),
+			err: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateModule("test", tc.module)
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidModule)
+				return
+			}
+			require.NoError(t, err)
+
+			// the metered module must still compile
+			_, err = registry.compile(context.Background(), tc.module)
+			require.NoError(t, err)
+		})
+	}
+}
+
+// wasmGenesis returns a genesis config that activates the wasm hard fork at
+// the given height.
+func wasmGenesis(activation uint64) *chain.GenesisConfig {
+	genesis := chain.DefaultGenesisConfig()
+	genesis.ForkHeights = map[string]*uint64{consensus.ForkWasm: &activation}
+	return genesis
+}
+
+func Test_DeployModule(t *testing.T) {
+	ctx := context.Background()
+	db := &mockDB{modules: make(map[string][]byte)}
+	app := &common.App{
+		Service: &common.Service{Logger: log.New(log.Config{}).Sugar(), GenesisConfig: wasmGenesis(5)},
+		DB:      db,
+	}
+	block := &common.BlockContext{Height: 4}
+
+	deploy := func(name string, module []byte) error {
+		body, err := (&ModuleDeployment{Name: name, Module: module}).MarshalBinary()
+		require.NoError(t, err)
+		return deployModule(ctx, app, &resolutions.Resolution{Body: body, Type: ModuleDeploymentType}, block)
+	}
+
+	// nothing is deployed before the fork activates
+	require.ErrorIs(t, deploy("deployed", testModule), ErrNotActive)
+
+	// a module deployed in a block that is not committed is discarded
+	block.Height = 5
+	require.NoError(t, deploy("discarded", testModule))
+	DiscardModules()
+	CommitModules()
+	_, ok := registry.get("discarded")
+	assert.False(t, ok)
+
+	// a deployed module is available once the block is committed
+	require.NoError(t, deploy("deployed", testModule))
+	_, ok = registry.get("deployed")
+	assert.False(t, ok)
+	CommitModules()
+	_, ok = registry.get("deployed")
+	assert.True(t, ok)
+
+	require.ErrorIs(t, deploy("deployed", testModule), ErrModuleExists)
+	require.ErrorIs(t, deploy("Invalid-Name", testModule), ErrInvalidModule)
+	require.ErrorIs(t, deploy("missing_alloc", buildModule([]byte{5, 1, 0, 1}, concat([]byte{7, 1}, name("memory"), []byte{2, 0}))), ErrInvalidModule)
+	_, ok = registry.get("missing_alloc")
+	assert.False(t, ok)
+
+	// modules are loaded from the store, replacing those in memory
+	registry.replace(make(map[string]wazero.CompiledModule))
+	require.NoError(t, LoadModules(ctx, db))
+	_, ok = registry.get("deployed")
+	assert.True(t, ok)
+}
+
+func Test_Call(t *testing.T) {
+	ctx := context.Background()
+	db := &mockDB{modules: make(map[string][]byte)}
+	app := &common.App{
+		Service: &common.Service{Logger: log.New(log.Config{}).Sugar(), GenesisConfig: wasmGenesis(0)},
+		DB:      db,
+	}
+
+	body, err := (&ModuleDeployment{Name: "calls", Module: testModule}).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, deployModule(ctx, app, &resolutions.Resolution{Body: body}, &common.BlockContext{Height: 1}))
+	CommitModules()
+
+	_, err = initializeInstance(&precompiles.DeploymentContext{Ctx: ctx}, app.Service, map[string]string{"module": "unknown"})
+	require.ErrorIs(t, err, ErrModuleNotFound)
+
+	inst, err := initializeInstance(&precompiles.DeploymentContext{Ctx: ctx}, app.Service, map[string]string{"module": "calls"})
+	require.NoError(t, err)
+
+	newScope := func() *precompiles.ProcedureContext {
+		return &precompiles.ProcedureContext{
+			TxCtx: &common.TxContext{Ctx: ctx, BlockContext: &common.BlockContext{Height: 2}},
+		}
+	}
+
+	t.Run("echo", func(t *testing.T) {
+		scope := newScope()
+		results, err := inst.Call(scope, app, "echo", []any{int64(1), "a", true, nil})
+		require.NoError(t, err)
+		assert.Equal(t, []any{int64(1), "a", true, nil}, results)
+		assert.Greater(t, scope.UsedGas, uint64(0))
+		assert.Less(t, scope.UsedGas, uint64(10))
+	})
+
+	t.Run("bulk memory", func(t *testing.T) {
+		scope := newScope()
+		results, err := inst.Call(scope, app, "fill", nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		// 64 KiB are charged at one fuel per 64 bytes
+		assert.Greater(t, scope.UsedGas, uint64(65536/64/fuelPerGas))
+	})
+
+	t.Run("unsupported result", func(t *testing.T) {
+		_, err := inst.Call(newScope(), app, "echo", []any{[]any{int64(1)}})
+		require.Error(t, err)
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		_, err := inst.Call(newScope(), app, "fails", []any{"bad input"})
+		require.ErrorContains(t, err, `["bad input"]`)
+	})
+
+	t.Run("out of gas", func(t *testing.T) {
+		scope := newScope()
+		scope.UsedGas = maxGas - 1000
+		_, err := inst.Call(scope, app, "spin", nil)
+		require.ErrorIs(t, err, ErrOutOfGas)
+		assert.GreaterOrEqual(t, scope.UsedGas, uint64(maxGas))
+
+		// no fuel is given to calls once the gas is used
+		_, err = inst.Call(scope, app, "echo", nil)
+		require.ErrorIs(t, err, ErrOutOfGas)
+	})
+
+	t.Run("not a method", func(t *testing.T) {
+		for _, method := range []string{"alloc", "memory", fuelExport, "unknown"} {
+			_, err := inst.Call(newScope(), app, method, nil)
+			require.Error(t, err, method)
+		}
+	})
+}
+
+// mockDB stores modules in memory, handling the queries used by this package.
+type mockDB struct {
+	modules map[string][]byte
+}
+
+func (m *mockDB) BeginTx(ctx context.Context) (sql.Tx, error) {
+	return &mockTx{m}, nil
+}
+
+func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	switch stmt {
+	case sqlInsertModule:
+		m.modules[args[0].(string)] = args[1].([]byte)
+		return &sql.ResultSet{}, nil
+	case sqlModuleExists:
+		if _, ok := m.modules[args[0].(string)]; ok {
+			return &sql.ResultSet{Rows: [][]any{{int64(1)}}}, nil
+		}
+		return &sql.ResultSet{}, nil
+	case sqlListModules:
+		var rows [][]any
+		for name, bin := range m.modules {
+			rows = append(rows, []any{name, bin})
+		}
+		return &sql.ResultSet{Rows: rows}, nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %s", stmt)
+	}
+}
+
+type mockTx struct {
+	*mockDB
+}
+
+func (m *mockTx) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockTx) Rollback(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,137 @@
+package withdrawals
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// file contains the registration of validator attestation keys. Validators
+// attest to epochs with secp256k1 keys rather than their ed25519 node keys, so
+// that the bridge contract can verify the attestations with ecrecover. Each
+// validator registers the Ethereum address of its attestation key, and its
+// attestations must recover to that address.
+
+// AttesterRegistration is the resolution body of a validator's registration of
+// its attestation key. The validator is the proposer of the resolution.
+type AttesterRegistration struct {
+	// Address is the Ethereum address of the attestation key.
+	Address []byte
+	// Signature is the attestation key's signature of the validator's
+	// RegistrationDigest, which proves the validator holds the key.
+	Signature []byte
+}
+
+// MarshalBinary returns the binary representation of the registration.
+func (a *AttesterRegistration) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(a)
+}
+
+// UnmarshalBinary unmarshals the registration from its binary representation.
+func (a *AttesterRegistration) UnmarshalBinary(data []byte) error {
+	return serialize.Decode(data, a)
+}
+
+// LoadAttesterKey loads a validator's attestation key from a file containing
+// the hex encoded secp256k1 private key.
+func LoadAttesterKey(path string) (*ecdsa.PrivateKey, error) {
+	return crypto.LoadECDSA(path)
+}
+
+// DeriveAttesterKey derives a validator's attestation key from its node
+// private key, so that a validator does not need to manage another key. Anyone
+// holding the node key, such as a remote signer, can then sign attestations,
+// so this must be opted into.
+func DeriveAttesterKey(nodeKey []byte) (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(crypto.Keccak256([]byte("kwil withdrawal attester"), nodeKey))
+}
+
+// RegistrationDigest returns the digest that a validator's attestation key
+// signs to register it. The chain ID and validator identity prevent the
+// registration from being replayed on another network, or by another
+// validator.
+func RegistrationDigest(chainID string, validator []byte) []byte {
+	return crypto.Keccak256([]byte("kwil withdrawal attester"), []byte(chainID), validator)
+}
+
+// NewAttesterRegistration creates the registration of a validator's
+// attestation key.
+func NewAttesterRegistration(key *ecdsa.PrivateKey, chainID string, validator []byte) (*AttesterRegistration, error) {
+	sig, err := sign(key, RegistrationDigest(chainID, validator))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttesterRegistration{
+		Address:   crypto.PubkeyToAddress(key.PublicKey).Bytes(),
+		Signature: sig,
+	}, nil
+}
+
+// SignAttestation signs the AttestationDigest of an epoch's root with a
+// validator's attestation key.
+func SignAttestation(key *ecdsa.PrivateKey, contract []byte, chainID string, epoch int64, root []byte) ([]byte, error) {
+	return sign(key, AttestationDigest(contract, chainID, epoch, root))
+}
+
+// RegisterAttester records the attestation key of a validator, replacing any
+// key it registered before.
+func RegisterAttester(ctx context.Context, tx sql.Executor, chainID string, validator []byte, reg *AttesterRegistration) error {
+	power, err := getValidatorPower(ctx, tx, validator)
+	if err != nil {
+		return err
+	}
+	if power <= 0 {
+		return ErrNotValidator
+	}
+
+	addr, err := recoverAddress(RegistrationDigest(chainID, validator), reg.Signature)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(addr, reg.Address) {
+		return ErrInvalidSignature
+	}
+
+	return upsertAttester(ctx, tx, validator, reg.Address)
+}
+
+// GetAttester returns the Ethereum address of a validator's attestation key.
+// If the validator has not registered a key, it returns nil.
+func GetAttester(ctx context.Context, tx sql.Executor, validator []byte) ([]byte, error) {
+	return getAttester(ctx, tx, validator)
+}
+
+// sign signs a digest, returning the 65 byte signature with a recovery ID of
+// 27 or 28, as expected by ecrecover.
+func sign(key *ecdsa.PrivateKey, digest []byte) ([]byte, error) {
+	sig, err := crypto.Sign(digest, key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// recoverAddress returns the Ethereum address that signed the digest.
+func recoverAddress(digest, sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	sig = bytes.Clone(sig)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub).Bytes(), nil
+}
@@ -0,0 +1,14 @@
+package withdrawals
+
+import "errors"
+
+var (
+	ErrWithdrawalNotFound = errors.New("withdrawal not found")
+	ErrEpochNotFound      = errors.New("epoch not found")
+	ErrInvalidRecipient   = errors.New("recipient must be a 20 byte Ethereum address")
+	ErrInvalidAmount      = errors.New("withdrawal amount must be positive")
+	ErrRootMismatch       = errors.New("attested root does not match the epoch")
+	ErrInvalidSignature   = errors.New("invalid attestation signature")
+	ErrNotValidator       = errors.New("attestation not signed by a validator")
+	ErrNoAttester         = errors.New("validator has not registered an attestation key")
+)
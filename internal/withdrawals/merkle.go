@@ -0,0 +1,91 @@
+package withdrawals
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// file contains the Merkle tree of an epoch's withdrawals. The tree is built
+// to be verifiable with OpenZeppelin's MerkleProof library: nodes are the
+// keccak256 hash of their sorted children, and a node without a sibling is
+// promoted to the next level unchanged.
+
+// Leaf returns the Merkle leaf of a withdrawal, which is
+// keccak256(abi.encodePacked(address recipient, uint256 amount, bytes32 txHash)).
+func Leaf(w *Withdrawal) []byte {
+	return crypto.Keccak256(w.Recipient, math.PaddedBigBytes(w.Amount, 32), w.TxHash)
+}
+
+// hashPair hashes two nodes of the tree, in sorted order.
+func hashPair(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256(a, b)
+}
+
+// MerkleRoot returns the root of the tree with the given leaves. The leaves
+// must not be empty.
+func MerkleRoot(leaves [][]byte) []byte {
+	level := leaves
+	for len(level) > 1 {
+		level = nextLevel(level)
+	}
+	return level[0]
+}
+
+// MerkleProof returns the sibling hashes from the leaf at index to the root.
+func MerkleProof(leaves [][]byte, index int) [][]byte {
+	var proof [][]byte
+	level := leaves
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+		level = nextLevel(level)
+	}
+	return proof
+}
+
+// VerifyProof returns true if the proof shows the leaf is in the tree with the
+// given root.
+func VerifyProof(leaf []byte, proof [][]byte, root []byte) bool {
+	node := leaf
+	for _, sibling := range proof {
+		node = hashPair(node, sibling)
+	}
+	return bytes.Equal(node, root)
+}
+
+func nextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, hashPair(level[i], level[i+1]))
+	}
+	return next
+}
+
+// AttestationDigest returns the digest that validators sign with their
+// attestation keys to attest to an epoch's root. It is the EIP-191 signed
+// message hash of
+//
+//	keccak256(abi.encode(address contract, bytes32 keccak256(chainID), uint256 epoch, bytes32 root))
+//
+// so the bridge contract can check a signature with ecrecover. The contract
+// address and chain ID prevent an attestation from being replayed to another
+// contract or network.
+func AttestationDigest(contract []byte, chainID string, epoch int64, root []byte) []byte {
+	msg := crypto.Keccak256(common.LeftPadBytes(contract, 32), crypto.Keccak256([]byte(chainID)),
+		math.U256Bytes(big.NewInt(epoch)), root)
+	return crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), msg)
+}
@@ -0,0 +1,284 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+)
+
+const (
+	schemaName = `kwild_withdrawals`
+
+	withdrawalStoreVersion = 0
+
+	sqlInitWithdrawalsTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.withdrawals (
+		tx_hash BYTEA PRIMARY KEY, -- the hash of the withdraw transaction
+		epoch INT8 NOT NULL, -- the epoch that includes the withdrawal
+		sender BYTEA NOT NULL, -- the identity of the account that withdrew
+		recipient BYTEA NOT NULL, -- the Ethereum address that receives the tokens
+		amount TEXT NOT NULL -- big.Int
+	);`
+
+	sqlInitWithdrawalsEpochIndex = `CREATE INDEX IF NOT EXISTS withdrawals_epoch ON ` + schemaName + `.withdrawals (epoch);`
+
+	sqlInitEpochsTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.epochs (
+		epoch INT8 PRIMARY KEY,
+		root BYTEA NOT NULL, -- the Merkle root of the epoch's withdrawals
+		height INT8 NOT NULL, -- the block height at which the epoch was sealed
+		confirmed BOOLEAN NOT NULL DEFAULT FALSE -- true once 2/3 of the voting power signed the root
+	);`
+
+	sqlInitSignaturesTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.signatures (
+		epoch INT8 NOT NULL REFERENCES ` + schemaName + `.epochs(epoch) ON DELETE CASCADE,
+		validator BYTEA NOT NULL,
+		attester BYTEA NOT NULL, -- the Ethereum address of the validator's attestation key
+		signature BYTEA NOT NULL,
+		PRIMARY KEY (epoch, validator)
+	);`
+
+	sqlInitAttestersTable = `CREATE TABLE IF NOT EXISTS ` + schemaName + `.attesters (
+		validator BYTEA PRIMARY KEY,
+		address BYTEA NOT NULL -- the Ethereum address of the validator's attestation key
+	);`
+
+	sqlInsertWithdrawal = `INSERT INTO ` + schemaName + `.withdrawals (tx_hash, epoch, sender, recipient, amount)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	sqlGetWithdrawal = `SELECT epoch, sender, recipient, amount FROM ` + schemaName + `.withdrawals WHERE tx_hash = $1`
+
+	sqlListEpochWithdrawals = `SELECT tx_hash, sender, recipient, amount FROM ` + schemaName + `.withdrawals
+		WHERE epoch = $1 ORDER BY tx_hash`
+
+	sqlInsertEpoch = `INSERT INTO ` + schemaName + `.epochs (epoch, root, height) VALUES ($1, $2, $3)`
+
+	sqlGetEpoch = `SELECT root, height, confirmed FROM ` + schemaName + `.epochs WHERE epoch = $1`
+
+	sqlConfirmEpoch = `UPDATE ` + schemaName + `.epochs SET confirmed = TRUE WHERE epoch = $1`
+
+	sqlInsertSignature = `INSERT INTO ` + schemaName + `.signatures (epoch, validator, attester, signature)
+		VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+
+	sqlListSignatures = `SELECT validator, attester, signature FROM ` + schemaName + `.signatures
+		WHERE epoch = $1 ORDER BY validator`
+
+	sqlUpsertAttester = `INSERT INTO ` + schemaName + `.attesters (validator, address) VALUES ($1, $2)
+		ON CONFLICT (validator) DO UPDATE SET address = $2`
+
+	sqlGetAttester = `SELECT address FROM ` + schemaName + `.attesters WHERE validator = $1`
+)
+
+func initTables(ctx context.Context, tx sql.DB) error {
+	initStmts := []string{sqlInitWithdrawalsTable, sqlInitWithdrawalsEpochIndex,
+		sqlInitEpochsTable, sqlInitSignaturesTable, sqlInitAttestersTable} // order important
+
+	for _, stmt := range initStmts {
+		_, err := tx.Execute(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("failed to initialize tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertWithdrawal records a withdrawal.
+func insertWithdrawal(ctx context.Context, db sql.Executor, w *Withdrawal) error {
+	_, err := db.Execute(ctx, sqlInsertWithdrawal, w.TxHash, w.Epoch, w.Sender, w.Recipient, w.Amount.String())
+	return err
+}
+
+// getWithdrawal retrieves a withdrawal by the hash of its transaction.
+// If the withdrawal is not found, it returns nil, ErrWithdrawalNotFound.
+func getWithdrawal(ctx context.Context, db sql.Executor, txHash []byte) (*Withdrawal, error) {
+	results, err := db.Execute(ctx, sqlGetWithdrawal, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.Rows) == 0 {
+		return nil, ErrWithdrawalNotFound
+	}
+	if len(results.Rows) > 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(results.Rows))
+	}
+
+	row := results.Rows[0]
+	epoch, ok := row[0].(int64)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored epoch to int64")
+	}
+
+	w, err := withdrawalFromRow(append([]any{txHash}, row[1:]...))
+	if err != nil {
+		return nil, err
+	}
+	w.Epoch = epoch
+
+	return w, nil
+}
+
+// listEpochWithdrawals lists the withdrawals in an epoch, ordered by their
+// transaction hash.
+func listEpochWithdrawals(ctx context.Context, db sql.Executor, epoch int64) ([]*Withdrawal, error) {
+	results, err := db.Execute(ctx, sqlListEpochWithdrawals, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := make([]*Withdrawal, len(results.Rows))
+	for i, row := range results.Rows {
+		ws[i], err = withdrawalFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		ws[i].Epoch = epoch
+	}
+
+	return ws, nil
+}
+
+// withdrawalFromRow converts a row of tx_hash, sender, recipient, and amount
+// to a Withdrawal.
+func withdrawalFromRow(row []any) (*Withdrawal, error) {
+	txHash, ok := row[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored tx hash to bytes")
+	}
+	sender, ok := row[1].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored sender to bytes")
+	}
+	recipient, ok := row[2].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored recipient to bytes")
+	}
+	amountStr, ok := row[3].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored amount to string")
+	}
+	amount, ok := new(big.Int).SetString(amountStr, 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored amount to big.Int")
+	}
+
+	return &Withdrawal{
+		TxHash:    txHash,
+		Sender:    sender,
+		Recipient: recipient,
+		Amount:    amount,
+	}, nil
+}
+
+// insertEpoch records a sealed epoch.
+func insertEpoch(ctx context.Context, db sql.Executor, e *Epoch) error {
+	_, err := db.Execute(ctx, sqlInsertEpoch, e.Epoch, e.Root, e.Height)
+	return err
+}
+
+// getEpoch retrieves a sealed epoch. If the epoch has not been sealed, it
+// returns nil, ErrEpochNotFound.
+func getEpoch(ctx context.Context, db sql.Executor, epoch int64) (*Epoch, error) {
+	results, err := db.Execute(ctx, sqlGetEpoch, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.Rows) == 0 {
+		return nil, ErrEpochNotFound
+	}
+	if len(results.Rows) > 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(results.Rows))
+	}
+
+	row := results.Rows[0]
+	root, ok := row[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored root to bytes")
+	}
+	height, ok := row[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored height to int64")
+	}
+	confirmed, ok := row[2].(bool)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored confirmed to bool")
+	}
+
+	return &Epoch{
+		Epoch:     epoch,
+		Root:      root,
+		Height:    height,
+		Confirmed: confirmed,
+	}, nil
+}
+
+// confirmEpoch marks an epoch as confirmed.
+func confirmEpoch(ctx context.Context, db sql.Executor, epoch int64) error {
+	_, err := db.Execute(ctx, sqlConfirmEpoch, epoch)
+	return err
+}
+
+// insertSignature records a validator's signature of an epoch. If the
+// validator already signed the epoch, it does nothing.
+func insertSignature(ctx context.Context, db sql.Executor, epoch int64, sig *Signature) error {
+	_, err := db.Execute(ctx, sqlInsertSignature, epoch, sig.Validator, sig.Attester, sig.Signature)
+	return err
+}
+
+// listSignatures lists the validator signatures of an epoch, ordered by the
+// validator.
+func listSignatures(ctx context.Context, db sql.Executor, epoch int64) ([]*Signature, error) {
+	results, err := db.Execute(ctx, sqlListSignatures, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make([]*Signature, len(results.Rows))
+	for i, row := range results.Rows {
+		validator, ok := row[0].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert stored validator to bytes")
+		}
+		attester, ok := row[1].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert stored attester to bytes")
+		}
+		signature, ok := row[2].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert stored signature to bytes")
+		}
+		sigs[i] = &Signature{Validator: validator, Attester: attester, Signature: signature}
+	}
+
+	return sigs, nil
+}
+
+// upsertAttester records the address of a validator's attestation key.
+func upsertAttester(ctx context.Context, db sql.Executor, validator, address []byte) error {
+	_, err := db.Execute(ctx, sqlUpsertAttester, validator, address)
+	return err
+}
+
+// getAttester retrieves the address of a validator's attestation key. If the
+// validator has not registered a key, it returns nil.
+func getAttester(ctx context.Context, db sql.Executor, validator []byte) ([]byte, error) {
+	results, err := db.Execute(ctx, sqlGetAttester, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.Rows) == 0 {
+		return nil, nil
+	}
+	if len(results.Rows) > 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(results.Rows))
+	}
+
+	address, ok := results.Rows[0][0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert stored address to bytes")
+	}
+	return address, nil
+}
@@ -0,0 +1,333 @@
+// Package withdrawals stores withdrawals of tokens from the network back to
+// Ethereum. Withdrawals are batched into epochs, whose length in blocks is set
+// in the genesis withdrawal params. When an epoch ends, the Merkle root of its
+// withdrawals is sealed, and each validator signs an attestation of the root
+// with its registered secp256k1 attestation key. The attestations are collected
+// as resolutions, so that a bridge contract can verify a withdrawal's Merkle
+// proof against a root signed by the validators, and release the funds.
+package withdrawals
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kwilteam/kwil-db/common"
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/types"
+	"github.com/kwilteam/kwil-db/core/types/serialize"
+	"github.com/kwilteam/kwil-db/extensions/resolutions"
+	"github.com/kwilteam/kwil-db/internal/sql/versioning"
+	"github.com/kwilteam/kwil-db/internal/voting"
+)
+
+const (
+	// AttestationEventType is the resolution type of a validator's signature
+	// of an epoch's root.
+	AttestationEventType = "withdrawal_attestation"
+
+	// AttesterEventType is the resolution type of a validator's registration
+	// of its attestation key.
+	AttesterEventType = "withdrawal_attester"
+)
+
+// these can be overridden for testing
+var (
+	getValidatorPower = voting.GetValidatorPower
+	getValidators     = voting.GetValidators
+)
+
+func init() {
+	err := resolutions.RegisterResolution(AttestationEventType, resolutions.ModAdd, resolutions.ResolutionConfig{
+		// Each validator proposes its own attestation, and its signature is
+		// verified when it is resolved, so no other votes are needed.
+		ConfirmationThreshold: big.NewRat(0, 1),
+		ResolveFunc: func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error {
+			att := &Attestation{}
+			if err := att.UnmarshalBinary(resolution.Body); err != nil {
+				return fmt.Errorf("failed to unmarshal withdrawal attestation: %w", err)
+			}
+
+			contract := app.Service.GenesisConfig.ConsensusParams.Withdrawals.Contract
+			return Attest(ctx, app.DB, contract, block.ChainContext.ChainID, resolution.Proposer, att)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = resolutions.RegisterResolution(AttesterEventType, resolutions.ModAdd, resolutions.ResolutionConfig{
+		// Each validator proposes its own registration, and the key's
+		// signature is verified when it is resolved.
+		ConfirmationThreshold: big.NewRat(0, 1),
+		ResolveFunc: func(ctx context.Context, app *common.App, resolution *resolutions.Resolution, block *common.BlockContext) error {
+			reg := &AttesterRegistration{}
+			if err := reg.UnmarshalBinary(resolution.Body); err != nil {
+				return fmt.Errorf("failed to unmarshal withdrawal attester registration: %w", err)
+			}
+
+			return RegisterAttester(ctx, app.DB, block.ChainContext.ChainID, resolution.Proposer, reg)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Withdrawal is a withdrawal of tokens to an Ethereum address.
+type Withdrawal struct {
+	// TxHash is the hash of the withdraw transaction.
+	TxHash []byte
+	// Epoch is the epoch that includes the withdrawal.
+	Epoch int64
+	// Sender is the identity of the account that withdrew the tokens.
+	Sender []byte
+	// Recipient is the Ethereum address that receives the tokens.
+	Recipient []byte
+	// Amount is the amount of tokens withdrawn.
+	Amount *big.Int
+}
+
+// Epoch is a sealed withdrawal epoch.
+type Epoch struct {
+	Epoch int64
+	// Root is the Merkle root of the epoch's withdrawals.
+	Root []byte
+	// Height is the block height at which the epoch was sealed.
+	Height int64
+	// Confirmed is true once validators with at least two thirds of the
+	// voting power have signed the root.
+	Confirmed bool
+}
+
+// Signature is a validator's signature of an epoch's attestation.
+type Signature struct {
+	Validator []byte
+	// Attester is the Ethereum address of the validator's attestation key.
+	Attester  []byte
+	Signature []byte
+}
+
+// Attestation is the resolution body of a validator's signature of an epoch's
+// root. The validator is the proposer of the resolution.
+type Attestation struct {
+	Epoch     int64
+	Root      []byte
+	Signature []byte
+}
+
+// MarshalBinary returns the binary representation of the attestation.
+func (a *Attestation) MarshalBinary() ([]byte, error) {
+	return serialize.Encode(a)
+}
+
+// UnmarshalBinary unmarshals the attestation from its binary representation.
+func (a *Attestation) UnmarshalBinary(data []byte) error {
+	return serialize.Decode(data, a)
+}
+
+// InitializeWithdrawalStore initializes the withdrawal store schema and tables.
+func InitializeWithdrawalStore(ctx context.Context, db sql.DB) error {
+	upgradeFns := map[int64]versioning.UpgradeFunc{
+		0: initTables,
+	}
+
+	err := versioning.Upgrade(ctx, db, schemaName, upgradeFns, withdrawalStoreVersion)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EpochOf returns the epoch that includes the block height.
+func EpochOf(height, epochLength int64) int64 {
+	return height / epochLength
+}
+
+// Withdraw records a withdrawal in the epoch of the given block height. The
+// tokens must already have been removed from the sender's balance.
+func Withdraw(ctx context.Context, tx sql.Executor, height, epochLength int64, w *Withdrawal) error {
+	if len(w.Recipient) != 20 {
+		return ErrInvalidRecipient
+	}
+	if w.Amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+
+	w.Epoch = EpochOf(height, epochLength)
+	return insertWithdrawal(ctx, tx, w)
+}
+
+// SealEpoch seals the epoch that ends at the given block height, storing the
+// Merkle root of its withdrawals. If the height does not end an epoch, or the
+// epoch has no withdrawals, it returns nil.
+func SealEpoch(ctx context.Context, tx sql.Executor, height, epochLength int64) (*Epoch, error) {
+	if (height+1)%epochLength != 0 {
+		return nil, nil
+	}
+
+	epoch := EpochOf(height, epochLength)
+	ws, err := listEpochWithdrawals(ctx, tx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(ws) == 0 {
+		return nil, nil
+	}
+
+	e := &Epoch{
+		Epoch:  epoch,
+		Root:   MerkleRoot(leaves(ws)),
+		Height: height,
+	}
+	return e, insertEpoch(ctx, tx, e)
+}
+
+// Attest records a validator's signature of an epoch's root. The signature
+// must be of the epoch's AttestationDigest, by the validator's registered
+// attestation key. Once validators with at least two thirds of the voting
+// power have signed, the epoch is confirmed.
+func Attest(ctx context.Context, tx sql.Executor, contract []byte, chainID string, validator []byte, att *Attestation) error {
+	power, err := getValidatorPower(ctx, tx, validator)
+	if err != nil {
+		return err
+	}
+	if power <= 0 {
+		return ErrNotValidator
+	}
+
+	e, err := getEpoch(ctx, tx, att.Epoch)
+	if err != nil {
+		return err
+	}
+	if string(e.Root) != string(att.Root) {
+		return ErrRootMismatch
+	}
+
+	attester, err := getAttester(ctx, tx, validator)
+	if err != nil {
+		return err
+	}
+	if attester == nil {
+		return ErrNoAttester
+	}
+
+	addr, err := recoverAddress(AttestationDigest(contract, chainID, e.Epoch, e.Root), att.Signature)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(addr, attester) {
+		return ErrInvalidSignature
+	}
+
+	err = insertSignature(ctx, tx, e.Epoch, &Signature{
+		Validator: validator,
+		Attester:  attester,
+		Signature: att.Signature,
+	})
+	if err != nil {
+		return err
+	}
+	if e.Confirmed {
+		return nil
+	}
+
+	// the epoch is confirmed by the power of the current validators that
+	// have signed it
+	validators, err := getValidators(ctx, tx)
+	if err != nil {
+		return err
+	}
+	sigs, err := listSignatures(ctx, tx, e.Epoch)
+	if err != nil {
+		return err
+	}
+	signed := make(map[string]bool, len(sigs))
+	for _, sig := range sigs {
+		signed[string(sig.Validator)] = true
+	}
+
+	var totalPower, signedPower int64
+	for _, v := range validators {
+		totalPower += v.Power
+		if signed[string(v.PubKey)] {
+			signedPower += v.Power
+		}
+	}
+
+	if signedPower < voting.RequiredPower(ctx, tx, big.NewRat(2, 3), totalPower) {
+		return nil
+	}
+
+	return confirmEpoch(ctx, tx, e.Epoch)
+}
+
+// GetProof returns the Merkle proof and validator signatures for a withdrawal.
+// If the withdrawal's epoch has not ended, only the withdrawal and its epoch
+// are set.
+func GetProof(ctx context.Context, tx sql.Executor, txHash []byte) (*types.WithdrawalProof, error) {
+	w, err := getWithdrawal(ctx, tx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &types.WithdrawalProof{
+		TxHash:    w.TxHash,
+		Recipient: w.Recipient,
+		Amount:    w.Amount.String(),
+		Epoch:     w.Epoch,
+	}
+
+	e, err := getEpoch(ctx, tx, w.Epoch)
+	if errors.Is(err, ErrEpochNotFound) {
+		return proof, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	proof.Root = e.Root
+	proof.Confirmed = e.Confirmed
+
+	ws, err := listEpochWithdrawals(ctx, tx, w.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, ew := range ws {
+		if string(ew.TxHash) == string(txHash) {
+			index = i
+			break
+		}
+	}
+	if index == -1 { // should be impossible since the withdrawal is in the epoch
+		return nil, fmt.Errorf("withdrawal missing from epoch %d", w.Epoch)
+	}
+	for _, sibling := range MerkleProof(leaves(ws), index) {
+		proof.Proof = append(proof.Proof, sibling)
+	}
+
+	sigs, err := listSignatures(ctx, tx, w.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range sigs {
+		proof.Signatures = append(proof.Signatures, &types.WithdrawalSignature{
+			Validator: sig.Validator,
+			Attester:  sig.Attester,
+			Signature: sig.Signature,
+		})
+	}
+
+	return proof, nil
+}
+
+func leaves(ws []*Withdrawal) [][]byte {
+	ls := make([][]byte, len(ws))
+	for i, w := range ws {
+		ls[i] = Leaf(w)
+	}
+	return ls
+}
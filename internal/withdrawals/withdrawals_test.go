@@ -0,0 +1,284 @@
+package withdrawals
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	sql "github.com/kwilteam/kwil-db/common/sql"
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/types"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDB stores withdrawals, epochs, signatures, and attesters in memory,
+// handling the queries used by this package.
+type mockDB struct {
+	withdrawals map[string][]any           // tx_hash => epoch, sender, recipient, amount
+	epochs      map[int64][]any            // epoch => root, height, confirmed
+	signatures  map[int64]map[string][]any // epoch => validator => attester, signature
+	attesters   map[string][]byte
+}
+
+func newDB() *mockDB {
+	return &mockDB{
+		withdrawals: make(map[string][]any),
+		epochs:      make(map[int64][]any),
+		signatures:  make(map[int64]map[string][]any),
+		attesters:   make(map[string][]byte),
+	}
+}
+
+func (m *mockDB) Execute(ctx context.Context, stmt string, args ...any) (*sql.ResultSet, error) {
+	switch stmt {
+	case sqlInsertWithdrawal:
+		m.withdrawals[string(args[0].([]byte))] = args[1:]
+		return &sql.ResultSet{}, nil
+	case sqlGetWithdrawal:
+		row, ok := m.withdrawals[string(args[0].([]byte))]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{row}}, nil
+	case sqlListEpochWithdrawals:
+		var rows [][]any
+		for txHash, row := range m.withdrawals {
+			if row[0] == args[0] {
+				rows = append(rows, []any{[]byte(txHash), row[1], row[2], row[3]})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return string(rows[i][0].([]byte)) < string(rows[j][0].([]byte))
+		})
+		return &sql.ResultSet{Rows: rows}, nil
+	case sqlInsertEpoch:
+		m.epochs[args[0].(int64)] = []any{args[1], args[2], false}
+		return &sql.ResultSet{}, nil
+	case sqlGetEpoch:
+		row, ok := m.epochs[args[0].(int64)]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{row}}, nil
+	case sqlConfirmEpoch:
+		m.epochs[args[0].(int64)][2] = true
+		return &sql.ResultSet{}, nil
+	case sqlInsertSignature:
+		epoch := args[0].(int64)
+		if m.signatures[epoch] == nil {
+			m.signatures[epoch] = make(map[string][]any)
+		}
+		if _, ok := m.signatures[epoch][string(args[1].([]byte))]; !ok {
+			m.signatures[epoch][string(args[1].([]byte))] = args[2:]
+		}
+		return &sql.ResultSet{}, nil
+	case sqlListSignatures:
+		var rows [][]any
+		for validator, sig := range m.signatures[args[0].(int64)] {
+			rows = append(rows, append([]any{[]byte(validator)}, sig...))
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return string(rows[i][0].([]byte)) < string(rows[j][0].([]byte))
+		})
+		return &sql.ResultSet{Rows: rows}, nil
+	case sqlUpsertAttester:
+		m.attesters[string(args[0].([]byte))] = args[1].([]byte)
+		return &sql.ResultSet{}, nil
+	case sqlGetAttester:
+		address, ok := m.attesters[string(args[0].([]byte))]
+		if !ok {
+			return &sql.ResultSet{}, nil
+		}
+		return &sql.ResultSet{Rows: [][]any{{address}}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %s", stmt)
+	}
+}
+
+func recipient(b byte) []byte {
+	r := make([]byte, 20)
+	r[19] = b
+	return r
+}
+
+func txHash(b byte) []byte {
+	h := make([]byte, 32)
+	h[0] = b
+	return h
+}
+
+func Test_MerkleProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		var ls [][]byte
+		for i := 0; i < n; i++ {
+			ls = append(ls, Leaf(&Withdrawal{
+				TxHash:    txHash(byte(i)),
+				Recipient: recipient(byte(i)),
+				Amount:    big.NewInt(int64(i + 1)),
+			}))
+		}
+
+		root := MerkleRoot(ls)
+		for i, leaf := range ls {
+			proof := MerkleProof(ls, i)
+			assert.True(t, VerifyProof(leaf, proof, root), "leaves %d, index %d", n, i)
+			assert.False(t, VerifyProof(txHash(byte(i)), proof, root), "leaves %d, index %d", n, i)
+		}
+	}
+}
+
+func Test_Withdrawals(t *testing.T) {
+	ctx := context.Background()
+	db := newDB()
+	contract := recipient(0xff)
+
+	vals := make([]*crypto.Ed25519PrivateKey, 3)
+	attesters := make([]*ecdsa.PrivateKey, 3)
+	validators := make([]*types.Validator, 3)
+	for i := range vals {
+		var err error
+		vals[i], err = crypto.GenerateEd25519Key()
+		require.NoError(t, err)
+		attesters[i], err = DeriveAttesterKey(vals[i].Bytes())
+		require.NoError(t, err)
+		validators[i] = &types.Validator{PubKey: vals[i].PubKey().Bytes(), Power: 1}
+	}
+	getValidators = func(ctx context.Context, db sql.Executor) ([]*types.Validator, error) {
+		return validators, nil
+	}
+	getValidatorPower = func(ctx context.Context, db sql.Executor, identifier []byte) (int64, error) {
+		for _, v := range validators {
+			if string(v.PubKey) == string(identifier) {
+				return v.Power, nil
+			}
+		}
+		return 0, nil
+	}
+
+	const epochLength = 600
+
+	err := Withdraw(ctx, db, 10, epochLength, &Withdrawal{TxHash: txHash(1), Sender: []byte("a"), Recipient: []byte("short"), Amount: big.NewInt(1)})
+	require.ErrorIs(t, err, ErrInvalidRecipient)
+	err = Withdraw(ctx, db, 10, epochLength, &Withdrawal{TxHash: txHash(1), Sender: []byte("a"), Recipient: recipient(1), Amount: big.NewInt(0)})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	for i := byte(1); i <= 3; i++ {
+		err = Withdraw(ctx, db, epochLength+int64(i), epochLength, &Withdrawal{TxHash: txHash(i), Sender: []byte("a"), Recipient: recipient(i), Amount: big.NewInt(int64(i))})
+		require.NoError(t, err)
+	}
+
+	// the first epoch has no withdrawals, and the height must end an epoch
+	e, err := SealEpoch(ctx, db, epochLength-1, epochLength)
+	require.NoError(t, err)
+	require.Nil(t, e)
+	e, err = SealEpoch(ctx, db, epochLength+10, epochLength)
+	require.NoError(t, err)
+	require.Nil(t, e)
+
+	// the proof is incomplete until the epoch is sealed
+	proof, err := GetProof(ctx, db, txHash(2))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), proof.Epoch)
+	assert.Equal(t, "2", proof.Amount)
+	assert.Nil(t, proof.Root)
+
+	_, err = GetProof(ctx, db, txHash(4))
+	require.ErrorIs(t, err, ErrWithdrawalNotFound)
+
+	e, err = SealEpoch(ctx, db, 2*epochLength-1, epochLength)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), e.Epoch)
+
+	attest := func(i int, root []byte) error {
+		sig, err := SignAttestation(attesters[i], contract, "chain", e.Epoch, root)
+		require.NoError(t, err)
+		return Attest(ctx, db, contract, "chain", vals[i].PubKey().Bytes(), &Attestation{Epoch: e.Epoch, Root: root, Signature: sig})
+	}
+
+	// validators must register their attestation keys
+	require.ErrorIs(t, attest(0, e.Root), ErrNoAttester)
+
+	nonValidator, err := crypto.GenerateEd25519Key()
+	require.NoError(t, err)
+	reg, err := NewAttesterRegistration(attesters[0], "chain", nonValidator.PubKey().Bytes())
+	require.NoError(t, err)
+	require.ErrorIs(t, RegisterAttester(ctx, db, "chain", nonValidator.PubKey().Bytes(), reg), ErrNotValidator)
+
+	// a registration cannot be replayed by another validator
+	reg, err = NewAttesterRegistration(attesters[0], "chain", vals[0].PubKey().Bytes())
+	require.NoError(t, err)
+	require.ErrorIs(t, RegisterAttester(ctx, db, "chain", vals[1].PubKey().Bytes(), reg), ErrInvalidSignature)
+
+	for i := range vals {
+		reg, err := NewAttesterRegistration(attesters[i], "chain", vals[i].PubKey().Bytes())
+		require.NoError(t, err)
+		require.NoError(t, RegisterAttester(ctx, db, "chain", vals[i].PubKey().Bytes(), reg))
+	}
+
+	err = attest(0, []byte("wrong root"))
+	require.ErrorIs(t, err, ErrRootMismatch)
+
+	// signed by another validator's key, for another contract, or for another chain
+	sig, err := SignAttestation(attesters[1], contract, "chain", e.Epoch, e.Root)
+	require.NoError(t, err)
+	err = Attest(ctx, db, contract, "chain", vals[0].PubKey().Bytes(), &Attestation{Epoch: e.Epoch, Root: e.Root, Signature: sig})
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	sig, err = SignAttestation(attesters[0], recipient(0xfe), "chain", e.Epoch, e.Root)
+	require.NoError(t, err)
+	err = Attest(ctx, db, contract, "chain", vals[0].PubKey().Bytes(), &Attestation{Epoch: e.Epoch, Root: e.Root, Signature: sig})
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	sig, err = SignAttestation(attesters[0], contract, "other chain", e.Epoch, e.Root)
+	require.NoError(t, err)
+	err = Attest(ctx, db, contract, "chain", vals[0].PubKey().Bytes(), &Attestation{Epoch: e.Epoch, Root: e.Root, Signature: sig})
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// 2 of 3 validators confirm the epoch
+	require.NoError(t, attest(0, e.Root))
+	proof, err = GetProof(ctx, db, txHash(2))
+	require.NoError(t, err)
+	assert.False(t, proof.Confirmed)
+	assert.Len(t, proof.Signatures, 1)
+
+	require.NoError(t, attest(1, e.Root))
+	proof, err = GetProof(ctx, db, txHash(2))
+	require.NoError(t, err)
+	assert.True(t, proof.Confirmed)
+	assert.Len(t, proof.Signatures, 2)
+
+	assert.Equal(t, types.HexBytes(e.Root), proof.Root)
+	var siblings [][]byte
+	for _, p := range proof.Proof {
+		siblings = append(siblings, p)
+	}
+	leaf := Leaf(&Withdrawal{TxHash: txHash(2), Recipient: recipient(2), Amount: big.NewInt(2)})
+	assert.True(t, VerifyProof(leaf, siblings, e.Root))
+
+	// the signatures recover to the attesters' addresses, as with ecrecover
+	for _, s := range proof.Signatures {
+		assert.Contains(t, []byte{27, 28}, s.Signature[64])
+		addr, err := recoverAddress(AttestationDigest(contract, "chain", e.Epoch, e.Root), s.Signature)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(s.Attester), addr)
+	}
+}
+
+func Test_AttestationDigest(t *testing.T) {
+	// the digest of an attestation that a contract computes with
+	// MessageHashUtils.toEthSignedMessageHash(keccak256(abi.encode(
+	// contract, keccak256(bytes(chainID)), epoch, root)))
+	contract := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	root := common.HexToHash("0x0102030405060708091011121314151617181920212223242526272829303132")
+	digest := AttestationDigest(contract.Bytes(), "kwil-chain", 7, root.Bytes())
+
+	msg := ethcrypto.Keccak256(common.LeftPadBytes(contract.Bytes(), 32), ethcrypto.Keccak256([]byte("kwil-chain")),
+		common.LeftPadBytes([]byte{7}, 32), root.Bytes())
+	assert.Equal(t, accounts.TextHash(msg), digest)
+}
@@ -210,6 +210,72 @@ var (
 				return fmt.Sprintf(`(select 'x' || encode(sha224(lower(%s)::bytea || %s), 'hex'))`, inputs[0], inputs[1]), nil
 			},
 		},
+		// cryptographic functions, implemented in internal/sql/pg/crypto.go.
+		// Deployed schemas may only use them once the "crypto_funcs" hard fork
+		// is active.
+		"keccak256": {
+			ValidateArgs: func(args []*types.DataType) (*types.DataType, error) {
+				// first must be either text or blob
+				if len(args) != 1 {
+					return nil, wrapErrArgumentNumber(1, len(args))
+				}
+
+				if !args[0].EqualsStrict(types.TextType) && !args[0].EqualsStrict(types.BlobType) {
+					return nil, fmt.Errorf("%w: expected argument to be text or blob, got %s", ErrType, args[0].String())
+				}
+
+				return types.BlobType, nil
+			},
+			PGFormat: defaultFormat("keccak256"),
+		},
+		"ecrecover": {
+			ValidateArgs: func(args []*types.DataType) (*types.DataType, error) {
+				// hash and signature must both be blobs
+				if len(args) != 2 {
+					return nil, wrapErrArgumentNumber(2, len(args))
+				}
+
+				for _, arg := range args {
+					if !arg.EqualsStrict(types.BlobType) {
+						return nil, wrapErrArgumentType(types.BlobType, arg)
+					}
+				}
+
+				return types.BlobType, nil
+			},
+			PGFormat: defaultFormat("ecrecover"),
+		},
+		"ed25519_verify": {
+			ValidateArgs: func(args []*types.DataType) (*types.DataType, error) {
+				// public key, message, and signature must all be blobs
+				if len(args) != 3 {
+					return nil, wrapErrArgumentNumber(3, len(args))
+				}
+
+				for _, arg := range args {
+					if !arg.EqualsStrict(types.BlobType) {
+						return nil, wrapErrArgumentType(types.BlobType, arg)
+					}
+				}
+
+				return types.BoolType, nil
+			},
+			PGFormat: defaultFormat("ed25519_verify"),
+		},
+		"eth_address": {
+			ValidateArgs: func(args []*types.DataType) (*types.DataType, error) {
+				if len(args) != 1 {
+					return nil, wrapErrArgumentNumber(1, len(args))
+				}
+
+				if !args[0].EqualsStrict(types.BlobType) {
+					return nil, wrapErrArgumentType(types.BlobType, args[0])
+				}
+
+				return types.BlobType, nil
+			},
+			PGFormat: defaultFormat("eth_address"),
+		},
 		// array functions
 		"array_append": {
 			ValidateArgs: func(args []*types.DataType) (*types.DataType, error) {
@@ -1847,6 +1847,58 @@ func Test_Procedure(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "cryptographic functions",
+			proc: `
+			$addr := ecrecover(keccak256($msg), $sig);
+			$ok := ed25519_verify($pk, $msg, $sig);
+			`,
+			inputs: map[string]*types.DataType{
+				"$msg": types.BlobType,
+				"$sig": types.BlobType,
+				"$pk":  types.BlobType,
+			},
+			want: &parse.ProcedureParseResult{
+				Variables: map[string]*types.DataType{
+					"$addr": types.BlobType,
+					"$ok":   types.BoolType,
+				},
+				AST: []parse.ProcedureStmt{
+					&parse.ProcedureStmtCall{
+						Receivers: []*parse.ExpressionVariable{exprVar("$addr")},
+						Call: &parse.ExpressionFunctionCall{
+							Name: "ecrecover",
+							Args: []parse.Expression{
+								&parse.ExpressionFunctionCall{
+									Name: "keccak256",
+									Args: []parse.Expression{exprVar("$msg")},
+								},
+								exprVar("$sig"),
+							},
+						},
+					},
+					&parse.ProcedureStmtCall{
+						Receivers: []*parse.ExpressionVariable{exprVar("$ok")},
+						Call: &parse.ExpressionFunctionCall{
+							Name: "ed25519_verify",
+							Args: []parse.Expression{
+								exprVar("$pk"),
+								exprVar("$msg"),
+								exprVar("$sig"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ecrecover requires blobs",
+			proc: `$addr := ecrecover($hash, 'signature');`,
+			inputs: map[string]*types.DataType{
+				"$hash": types.BlobType,
+			},
+			err: parse.ErrFunctionSignature,
+		},
 		{
 			name: "sum types - failure",
 			proc: `
@@ -0,0 +1,107 @@
+// extsigner is a reference external signer for testing kwil-cli and client
+// applications with the external signer protocol of the
+// core/crypto/auth/external package. It signs with a private key given in the
+// EXTSIGNER_KEY environment variable, which it would instead get from an HSM
+// or custody service.
+//
+// Without -listen, it handles one request on stdin and stdout, for use as a
+// signer command:
+//
+//	EXTSIGNER_KEY=<hex key> kwil-cli --signer-cmd extsigner account id
+//
+// With -listen, it serves requests over HTTP on a TCP address or a unix socket:
+//
+//	EXTSIGNER_KEY=<hex key> extsigner -listen unix:///tmp/signer.sock
+//	kwil-cli --signer-url unix:///tmp/signer.sock account id
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kwilteam/kwil-db/core/crypto"
+	"github.com/kwilteam/kwil-db/core/crypto/auth"
+	"github.com/kwilteam/kwil-db/core/crypto/auth/external"
+)
+
+var (
+	listen  string
+	keyType string
+)
+
+func main() {
+	flag.StringVar(&listen, "listen", "", "serve HTTP on a host:port or unix:///path/to/socket instead of handling one request on stdin")
+	flag.StringVar(&keyType, "key-type", "secp256k1", "the type of the key: secp256k1 (Ethereum personal sign) or ed25519")
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	signer, err := newSigner(keyType, os.Getenv("EXTSIGNER_KEY"))
+	if err != nil {
+		return err
+	}
+
+	if listen == "" {
+		return external.ServeStdio(signer, os.Stdin, os.Stdout)
+	}
+
+	network, addr := "tcp", listen
+	if sockPath, ok := strings.CutPrefix(listen, "unix://"); ok {
+		network, addr = "unix", sockPath
+		os.Remove(sockPath)
+	}
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Handler: external.NewHandler(signer)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "serving signer %x (%s) on %s\n", signer.Identity(), signer.AuthType(), listen)
+	if err = srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func newSigner(keyType, keyHex string) (auth.Signer, error) {
+	if keyHex == "" {
+		return nil, errors.New("EXTSIGNER_KEY is not set")
+	}
+
+	switch keyType {
+	case "secp256k1":
+		key, err := crypto.Secp256k1PrivateKeyFromHex(keyHex)
+		if err != nil {
+			return nil, err
+		}
+		return &auth.EthPersonalSigner{Key: *key}, nil
+	case "ed25519":
+		key, err := crypto.Ed25519PrivateKeyFromHex(keyHex)
+		if err != nil {
+			return nil, err
+		}
+		return &auth.Ed25519Signer{Ed25519PrivateKey: *key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+}